@@ -0,0 +1,121 @@
+package iplocate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// ErrNoAbuseContact is returned when the API has no abuse contact for an IP address
+var ErrNoAbuseContact = errors.New("no abuse contact found")
+
+// AbuseContact returns the abuse contact of the network ip belongs to. Only
+// the abuse field is requested from the API. It returns ErrNoAbuseContact
+// if the API doesn't have one, and neither does RDAP when WithRDAPFallback
+// is set.
+func (c *Client) AbuseContact(ctx context.Context, ip string) (*Abuse, error) {
+	resp, err := c.LookupContext(ctx, ip, Fields("ip", "abuse"))
+	if err != nil {
+		return nil, err
+	}
+	if hasAbuseContact(resp.Abuse) {
+		return resp.Abuse, nil
+	}
+	if c.rdapURL == "" {
+		return nil, ErrNoAbuseContact
+	}
+
+	rdap, err := c.rdapAbuseContact(ctx, ip)
+	if err != nil {
+		return nil, err
+	}
+	if rdap == nil {
+		return nil, ErrNoAbuseContact
+	}
+	return mergeAbuse(resp.Abuse, rdap), nil
+}
+
+// hasAbuseContact reports whether abuse holds a way to reach the contact
+func hasAbuseContact(abuse *Abuse) bool {
+	return abuse != nil && (abuse.Email != nil || abuse.Phone != nil || abuse.Address != nil)
+}
+
+// AbuseReport describes an incident to report to the abuse contact of an IP address
+type AbuseReport struct {
+	// Reporter is the name or organization sending the report
+	Reporter string
+	// ReplyTo is where the recipient should reply, if not the sender
+	ReplyTo string
+	// Incident describes what happened, e.g. "SSH brute force attempts"
+	Incident string
+	// Time is when the incident was observed
+	Time time.Time
+	// Evidence holds log lines or other material, quoted verbatim
+	Evidence []string
+}
+
+// AbuseEmail is a plain text email ready to be sent
+type AbuseEmail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// DefaultAbuseReportTemplate renders the body of abuse report emails. It is
+// executed with a struct holding the AbuseReport as Report, the lookup as
+// Response and the abuse contact as Abuse.
+var DefaultAbuseReportTemplate = template.Must(template.New("abuse").Parse(`Hello{{with .Abuse.Name}} {{.}}{{end}},
+
+We observed abuse originating from {{.Response.IP}}{{with .Response.Network}}, part of {{.}}{{end}}, for which you are listed as the abuse contact.
+
+Incident: {{.Report.Incident}}
+{{- if not .Report.Time.IsZero}}
+Time: {{.Report.Time.UTC.Format "2006-01-02 15:04:05 MST"}}
+{{- end}}
+{{- with .Response.ASN}}
+Network: {{.ASN}} {{.Name}}
+{{- end}}
+{{- if .Report.Evidence}}
+
+Evidence:
+{{- range .Report.Evidence}}
+> {{.}}
+{{- end}}
+{{- end}}
+
+Please investigate and take appropriate action.
+{{- with .Report.ReplyTo}}
+Replies can be sent to {{.}}.
+{{- end}}
+
+Regards,
+{{if .Report.Reporter}}{{.Report.Reporter}}{{else}}Abuse Desk{{end}}
+`))
+
+// AbuseReportEmail renders an abuse report about the IP as an email to its
+// abuse contact with DefaultAbuseReportTemplate. The response must include
+// the abuse field; it returns ErrNoAbuseContact if there is no email address.
+func (r *LookupResponse) AbuseReportEmail(report AbuseReport) (*AbuseEmail, error) {
+	if r.Abuse == nil || r.Abuse.Email == nil || *r.Abuse.Email == "" {
+		return nil, ErrNoAbuseContact
+	}
+
+	var body strings.Builder
+	err := DefaultAbuseReportTemplate.Execute(&body, struct {
+		Report   AbuseReport
+		Response *LookupResponse
+		Abuse    FlatAbuse
+	}{report, r, r.Flat().Abuse})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render abuse report: %w", err)
+	}
+
+	subject := "Abuse report for " + r.IP
+	if report.Incident != "" {
+		subject += ": " + report.Incident
+	}
+	return &AbuseEmail{To: *r.Abuse.Email, Subject: subject, Body: body.String()}, nil
+}
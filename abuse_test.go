@@ -0,0 +1,80 @@
+package iplocate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAbuseContact(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "ip,abuse", r.URL.Query().Get("fields"))
+		if r.URL.Path == "/lookup/192.0.2.1" {
+			json.NewEncoder(w).Encode(LookupResponse{IP: "192.0.2.1"})
+			return
+		}
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8", Abuse: &Abuse{Email: stringPtr("network-abuse@google.com")}})
+	}))
+	defer server.Close()
+
+	client := NewClient(nil).WithBaseURL(server.URL)
+
+	abuse, err := client.AbuseContact(context.Background(), "8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "network-abuse@google.com", *abuse.Email)
+
+	_, err = client.AbuseContact(context.Background(), "192.0.2.1")
+	assert.ErrorIs(t, err, ErrNoAbuseContact)
+}
+
+func TestAbuseReportEmail(t *testing.T) {
+	resp := &LookupResponse{
+		IP:      "203.0.113.7",
+		Network: stringPtr("203.0.113.0/24"),
+		ASN:     &ASN{ASN: "AS64500", Name: "Example Hosting"},
+		Abuse:   &Abuse{Email: stringPtr("abuse@example.net"), Name: stringPtr("Example NOC")},
+	}
+
+	email, err := resp.AbuseReportEmail(AbuseReport{
+		Reporter: "Acme Security",
+		ReplyTo:  "security@acme.test",
+		Incident: "SSH brute force attempts",
+		Time:     time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC),
+		Evidence: []string{"sshd: Failed password for root from 203.0.113.7", "sshd: Failed password for admin from 203.0.113.7"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "abuse@example.net", email.To)
+	assert.Equal(t, "Abuse report for 203.0.113.7: SSH brute force attempts", email.Subject)
+	assert.Equal(t, `Hello Example NOC,
+
+We observed abuse originating from 203.0.113.7, part of 203.0.113.0/24, for which you are listed as the abuse contact.
+
+Incident: SSH brute force attempts
+Time: 2024-05-01 12:30:00 UTC
+Network: AS64500 Example Hosting
+
+Evidence:
+> sshd: Failed password for root from 203.0.113.7
+> sshd: Failed password for admin from 203.0.113.7
+
+Please investigate and take appropriate action.
+Replies can be sent to security@acme.test.
+
+Regards,
+Acme Security
+`, email.Body)
+}
+
+func TestAbuseReportEmail_NoContact(t *testing.T) {
+	_, err := (&LookupResponse{IP: "8.8.8.8"}).AbuseReportEmail(AbuseReport{})
+	assert.ErrorIs(t, err, ErrNoAbuseContact)
+
+	_, err = (&LookupResponse{IP: "8.8.8.8", Abuse: &Abuse{Phone: stringPtr("+1 555")}}).AbuseReportEmail(AbuseReport{})
+	assert.ErrorIs(t, err, ErrNoAbuseContact)
+}
@@ -0,0 +1,164 @@
+// Package analyze rolls up sets of IPLocate lookup results for reports:
+// grouping by country or ASN, counting privacy flags and ranking the most
+// common values. Functions take an iter.Seq so they work on slices, batch
+// results and streams alike:
+//
+//	summary := analyze.Summarize(slices.Values(responses), 10)
+//	summary = analyze.Summarize(analyze.FromStream(client.LookupStream(ctx, ips)), 10)
+package analyze
+
+import (
+	"iter"
+	"sort"
+
+	"github.com/iplocate/go-iplocate"
+)
+
+// Count is the number of results sharing a key, such as a country code
+type Count struct {
+	Key   string
+	Count int
+}
+
+// Summary is a rollup of a set of results
+type Summary struct {
+	Total int
+	// Countries are the most common country codes, most frequent first
+	Countries []Count
+	// ASNs are the most common ASNs, most frequent first
+	ASNs []Count
+	// Flags counts the results with each privacy flag set
+	Flags map[string]int
+}
+
+// FromStream returns the successful responses received from a LookupStream
+// channel, skipping failed lookups. Ranging over it drains the channel.
+func FromStream(results <-chan iplocate.Result) iter.Seq[*iplocate.LookupResponse] {
+	return func(yield func(*iplocate.LookupResponse) bool) {
+		for result := range results {
+			if result.Err != nil || result.Response == nil {
+				continue
+			}
+			if !yield(result.Response) {
+				return
+			}
+		}
+	}
+}
+
+// FromBatch returns the successful responses of a LookupBatch result
+func FromBatch(batch *iplocate.BatchResult) iter.Seq[*iplocate.LookupResponse] {
+	return func(yield func(*iplocate.LookupResponse) bool) {
+		for _, resp := range batch.Responses {
+			if !yield(resp) {
+				return
+			}
+		}
+	}
+}
+
+// GroupByCountry groups responses by country code. Responses without a
+// country are grouped under the empty string.
+func GroupByCountry(resps iter.Seq[*iplocate.LookupResponse]) map[string][]*iplocate.LookupResponse {
+	return groupBy(resps, countryKey)
+}
+
+// GroupByASN groups responses by ASN, e.g. "AS15169". Responses without an
+// ASN are grouped under the empty string.
+func GroupByASN(resps iter.Seq[*iplocate.LookupResponse]) map[string][]*iplocate.LookupResponse {
+	return groupBy(resps, asnKey)
+}
+
+// CountPrivacyFlags counts the responses with each privacy flag set, keyed
+// by the names returned by iplocate.Privacy.Flags
+func CountPrivacyFlags(resps iter.Seq[*iplocate.LookupResponse]) map[string]int {
+	counts := make(map[string]int)
+	for resp := range resps {
+		for _, flag := range resp.Privacy.Flags() {
+			counts[flag]++
+		}
+	}
+	return counts
+}
+
+// TopCountries returns the n most common country codes
+func TopCountries(resps iter.Seq[*iplocate.LookupResponse], n int) []Count {
+	return TopN(countBy(resps, countryKey), n)
+}
+
+// TopASNs returns the n most common ASNs
+func TopASNs(resps iter.Seq[*iplocate.LookupResponse], n int) []Count {
+	return TopN(countBy(resps, asnKey), n)
+}
+
+// TopN returns the n largest counts, most frequent first, with ties ordered
+// by key. Empty keys, which stand for missing values, are left out. A
+// non-positive n returns every count.
+func TopN(counts map[string]int, n int) []Count {
+	top := make([]Count, 0, len(counts))
+	for key, count := range counts {
+		if key != "" {
+			top = append(top, Count{Key: key, Count: count})
+		}
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return top[i].Key < top[j].Key
+	})
+	if n > 0 && len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
+
+// Summarize counts responses, their top n countries and ASNs and their
+// privacy flags in a single pass, so it can consume a stream
+func Summarize(resps iter.Seq[*iplocate.LookupResponse], n int) Summary {
+	countries := make(map[string]int)
+	asns := make(map[string]int)
+	summary := Summary{Flags: make(map[string]int)}
+	for resp := range resps {
+		summary.Total++
+		countries[countryKey(resp)]++
+		asns[asnKey(resp)]++
+		for _, flag := range resp.Privacy.Flags() {
+			summary.Flags[flag]++
+		}
+	}
+	summary.Countries = TopN(countries, n)
+	summary.ASNs = TopN(asns, n)
+	return summary
+}
+
+func groupBy(resps iter.Seq[*iplocate.LookupResponse], key func(*iplocate.LookupResponse) string) map[string][]*iplocate.LookupResponse {
+	groups := make(map[string][]*iplocate.LookupResponse)
+	for resp := range resps {
+		k := key(resp)
+		groups[k] = append(groups[k], resp)
+	}
+	return groups
+}
+
+func countBy(resps iter.Seq[*iplocate.LookupResponse], key func(*iplocate.LookupResponse) string) map[string]int {
+	counts := make(map[string]int)
+	for resp := range resps {
+		counts[key(resp)]++
+	}
+	return counts
+}
+
+func countryKey(resp *iplocate.LookupResponse) string {
+	if resp.CountryCode == nil {
+		return ""
+	}
+	return *resp.CountryCode
+}
+
+func asnKey(resp *iplocate.LookupResponse) string {
+	if resp.ASN == nil {
+		return ""
+	}
+	return resp.ASN.ASN
+}
@@ -0,0 +1,86 @@
+package analyze
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/iplocate/go-iplocate"
+	"github.com/stretchr/testify/assert"
+)
+
+func response(ip, country, asn string, privacy iplocate.Privacy) *iplocate.LookupResponse {
+	resp := &iplocate.LookupResponse{IP: ip, Privacy: privacy}
+	if country != "" {
+		resp.CountryCode = &country
+	}
+	if asn != "" {
+		resp.ASN = &iplocate.ASN{ASN: asn}
+	}
+	return resp
+}
+
+func testResponses() []*iplocate.LookupResponse {
+	return []*iplocate.LookupResponse{
+		response("8.8.8.8", "US", "AS15169", iplocate.Privacy{IsHosting: true}),
+		response("8.8.4.4", "US", "AS15169", iplocate.Privacy{IsHosting: true}),
+		response("1.1.1.1", "AU", "AS13335", iplocate.Privacy{IsHosting: true}),
+		response("185.220.101.1", "DE", "AS60729", iplocate.Privacy{IsTor: true, IsAnonymous: true}),
+		response("192.0.2.1", "", "", iplocate.Privacy{IsBogon: true}),
+	}
+}
+
+func TestGroupByCountry(t *testing.T) {
+	groups := GroupByCountry(slices.Values(testResponses()))
+	assert.Len(t, groups, 4)
+	assert.Len(t, groups["US"], 2)
+	assert.Equal(t, "192.0.2.1", groups[""][0].IP)
+}
+
+func TestGroupByASN(t *testing.T) {
+	groups := GroupByASN(slices.Values(testResponses()))
+	assert.Len(t, groups["AS15169"], 2)
+	assert.Len(t, groups["AS13335"], 1)
+	assert.Len(t, groups[""], 1)
+}
+
+func TestCountPrivacyFlags(t *testing.T) {
+	assert.Equal(t, map[string]int{"hosting": 3, "tor": 1, "anonymous": 1, "bogon": 1}, CountPrivacyFlags(slices.Values(testResponses())))
+}
+
+func TestTopN(t *testing.T) {
+	counts := map[string]int{"US": 3, "DE": 1, "AU": 1, "": 5}
+	assert.Equal(t, []Count{{"US", 3}, {"AU", 1}}, TopN(counts, 2))
+	assert.Equal(t, []Count{{"US", 3}, {"AU", 1}, {"DE", 1}}, TopN(counts, 0))
+}
+
+func TestTopCountriesAndASNs(t *testing.T) {
+	resps := testResponses()
+	assert.Equal(t, []Count{{"US", 2}}, TopCountries(slices.Values(resps), 1))
+	assert.Equal(t, []Count{{"AS15169", 2}, {"AS13335", 1}}, TopASNs(slices.Values(resps), 2))
+}
+
+func TestSummarize(t *testing.T) {
+	summary := Summarize(slices.Values(testResponses()), 2)
+	assert.Equal(t, 5, summary.Total)
+	assert.Equal(t, []Count{{"US", 2}, {"AU", 1}}, summary.Countries)
+	assert.Equal(t, []Count{{"AS15169", 2}, {"AS13335", 1}}, summary.ASNs)
+	assert.Equal(t, 3, summary.Flags["hosting"])
+}
+
+func TestFromStream(t *testing.T) {
+	results := make(chan iplocate.Result, 3)
+	results <- iplocate.Result{IP: "8.8.8.8", Response: response("8.8.8.8", "US", "", iplocate.Privacy{})}
+	results <- iplocate.Result{IP: "192.0.2.1", Err: assert.AnError}
+	results <- iplocate.Result{IP: "1.1.1.1", Response: response("1.1.1.1", "AU", "", iplocate.Privacy{})}
+	close(results)
+
+	summary := Summarize(FromStream(results), 0)
+	assert.Equal(t, 2, summary.Total)
+}
+
+func TestFromBatch(t *testing.T) {
+	batch := &iplocate.BatchResult{Responses: map[string]*iplocate.LookupResponse{
+		"8.8.8.8": response("8.8.8.8", "US", "", iplocate.Privacy{}),
+	}}
+	assert.Equal(t, []Count{{"US", 1}}, TopCountries(FromBatch(batch), 5))
+}
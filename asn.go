@@ -0,0 +1,63 @@
+package iplocate
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strconv"
+)
+
+// asnPath is the ASN details endpoint, relative to the base URL
+const asnPath = "/asn/"
+
+// ASNDetails describes an autonomous system and the networks it announces
+type ASNDetails struct {
+	ASN         string `json:"asn"`
+	Name        string `json:"name"`
+	Netname     string `json:"netname"`
+	Domain      string `json:"domain"`
+	CountryCode string `json:"country_code"`
+	Type        string `json:"type"`
+	// RIR is the regional internet registry the ASN was allocated by
+	RIR string `json:"rir"`
+	// Allocated is the allocation date reported by the registry, if known
+	Allocated string `json:"allocated,omitempty"`
+	// Prefixes are the IPv4 networks announced by the ASN
+	Prefixes []string `json:"prefixes"`
+	// PrefixesV6 are the IPv6 networks announced by the ASN
+	PrefixesV6 []string `json:"prefixes_v6"`
+}
+
+// Contains reports whether ip falls within any prefix announced by the ASN
+func (d *ASNDetails) Contains(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefixes := range [][]string{d.Prefixes, d.PrefixesV6} {
+		for _, p := range prefixes {
+			if prefix, err := netip.ParsePrefix(p); err == nil && prefix.Contains(addr) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// LookupASN returns the name, registry data and announced prefixes of an
+// autonomous system, given with or without the "AS" prefix, e.g. "AS15169"
+// or "15169"
+func (c *Client) LookupASN(ctx context.Context, asn string) (*ASNDetails, error) {
+	number, err := strconv.ParseUint(normalizeASN(asn), 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ASN: %s", asn)
+	}
+
+	var details ASNDetails
+	path := asnPath + "AS" + strconv.FormatUint(number, 10)
+	if _, err := c.doRequest(ctx, apiRequest{path: path}, &details); err != nil {
+		return nil, err
+	}
+	return &details, nil
+}
@@ -0,0 +1,72 @@
+package iplocate
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupASN(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/asn/AS15169", r.URL.Path)
+		assert.Equal(t, "test-api-key", r.URL.Query().Get("apikey"))
+		w.Write([]byte(`{
+			"asn": "AS15169",
+			"name": "Google LLC",
+			"netname": "GOOGLE",
+			"domain": "google.com",
+			"country_code": "US",
+			"type": "hosting",
+			"rir": "ARIN",
+			"allocated": "2000-03-30",
+			"prefixes": ["8.8.8.0/24", "8.8.4.0/24"],
+			"prefixes_v6": ["2001:4860::/32"]
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(nil).WithAPIKey("test-api-key").WithBaseURL(server.URL)
+
+	for _, asn := range []string{"AS15169", "15169", " as15169 "} {
+		details, err := client.LookupASN(context.Background(), asn)
+		require.NoError(t, err)
+		assert.Equal(t, "Google LLC", details.Name)
+		assert.Equal(t, "ARIN", details.RIR)
+		assert.Equal(t, []string{"8.8.8.0/24", "8.8.4.0/24"}, details.Prefixes)
+		assert.Equal(t, []string{"2001:4860::/32"}, details.PrefixesV6)
+	}
+}
+
+func TestLookupASN_Invalid(t *testing.T) {
+	client := NewClient(nil).WithBaseURL("http://127.0.0.1:0")
+	for _, asn := range []string{"", "AS", "google", "AS99999999999"} {
+		_, err := client.LookupASN(context.Background(), asn)
+		assert.EqualError(t, err, "invalid ASN: "+asn)
+	}
+}
+
+func TestLookupASN_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"ASN not found"}`))
+	}))
+	defer server.Close()
+
+	_, err := NewClient(nil).WithBaseURL(server.URL).LookupASN(context.Background(), "AS64512")
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+}
+
+func TestASNDetails_Contains(t *testing.T) {
+	details := &ASNDetails{Prefixes: []string{"8.8.8.0/24"}, PrefixesV6: []string{"2001:4860::/32"}}
+	assert.True(t, details.Contains("8.8.8.8"))
+	assert.True(t, details.Contains("::ffff:8.8.8.8"))
+	assert.True(t, details.Contains("2001:4860::8888"))
+	assert.False(t, details.Contains("1.1.1.1"))
+	assert.False(t, details.Contains("invalid"))
+}
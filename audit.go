@@ -0,0 +1,176 @@
+package iplocate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuditRecord records who looked up which IP address and when
+type AuditRecord struct {
+	Time time.Time `json:"time"`
+	// Actor is the user or service the lookup was made for; see
+	// ContextWithAuditActor
+	Actor string `json:"actor,omitempty"`
+	// RequestID is the request ID stored with ContextWithRequestID
+	RequestID string `json:"request_id,omitempty"`
+	// IP is the looked up address, redacted as configured in WithAuditSink
+	IP      string `json:"ip"`
+	Success bool   `json:"success"`
+	// Error is the text of the lookup error, with IP addresses redacted
+	// like IP
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// AuditSink receives a record for every lookup the client finishes. Like
+// EventSink, Audit is called from the goroutine that made the lookup.
+type AuditSink interface {
+	Audit(ctx context.Context, record AuditRecord)
+}
+
+// AuditSinkFunc adapts a function to the AuditSink interface
+type AuditSinkFunc func(ctx context.Context, record AuditRecord)
+
+// Audit calls f(ctx, record)
+func (f AuditSinkFunc) Audit(ctx context.Context, record AuditRecord) {
+	f(ctx, record)
+}
+
+// Redaction sets how IP addresses appear in audit records
+type Redaction int
+
+const (
+	// RedactNone records the full IP address
+	RedactNone Redaction = iota
+	// RedactNetwork records the /24 network of IPv4 and the /48 network of
+	// IPv6 addresses, e.g. "203.0.113.0/24"
+	RedactNetwork
+	// RedactHash records the client's HashIP of the address
+	RedactHash
+	// RedactAll leaves the IP address out
+	RedactAll
+)
+
+type auditActorKey struct{}
+
+// ContextWithAuditActor returns a copy of ctx carrying the user or service
+// a lookup is made for, recorded as AuditRecord.Actor
+func ContextWithAuditActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+// AuditActorFromContext returns the actor stored with ContextWithAuditActor,
+// or an empty string
+func AuditActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(auditActorKey{}).(string)
+	return actor
+}
+
+// WithAuditSink records every lookup to sink, with IP addresses redacted as
+// given, for compliance with policies on enriching customer IPs. With
+// PrivacyStrict, addresses are hashed before any redaction is applied.
+func (c *Client) WithAuditSink(sink AuditSink, redaction Redaction) *Client {
+	return c.WithEventSink(&auditEventSink{client: c, sink: sink, redaction: redaction})
+}
+
+// auditEventSink turns lookup events into audit records
+type auditEventSink struct {
+	client    *Client
+	sink      AuditSink
+	redaction Redaction
+}
+
+func (a *auditEventSink) Emit(ctx context.Context, event LookupEvent) {
+	record := AuditRecord{
+		Time:      event.Time,
+		Actor:     AuditActorFromContext(ctx),
+		RequestID: RequestIDFromContext(ctx),
+		IP:        a.redact(event.IP),
+		Success:   event.Err == nil,
+		Duration:  event.Duration,
+	}
+	if event.Err != nil {
+		record.Error = a.redactText(event.Err.Error())
+	}
+	a.sink.Audit(ctx, record)
+}
+
+// redact applies the redaction to ip. Values that aren't IP addresses, such
+// as hashes, are kept by RedactNetwork.
+func (a *auditEventSink) redact(ip string) string {
+	switch a.redaction {
+	case RedactNetwork:
+		addr, err := netip.ParseAddr(ip)
+		if err != nil {
+			return ip
+		}
+		addr = addr.Unmap()
+		bits := 24
+		if addr.Is6() {
+			bits = 48
+		}
+		prefix, _ := addr.Prefix(bits)
+		return prefix.String()
+	case RedactHash:
+		return a.client.HashIP(ip)
+	case RedactAll:
+		return ""
+	default:
+		return ip
+	}
+}
+
+// redactText applies the redaction to every IP address in text, such as the
+// request URL in a transport error, and removes the client's API keys
+func (a *auditEventSink) redactText(text string) string {
+	for _, key := range a.client.allKeys() {
+		text = strings.ReplaceAll(text, key, redacted)
+	}
+	return ipCandidate.ReplaceAllStringFunc(text, func(s string) string {
+		if _, err := netip.ParseAddr(s); err != nil {
+			return s
+		}
+		if ip := a.redact(s); ip != "" {
+			return ip
+		}
+		return redacted
+	})
+}
+
+// AuditLog is an AuditSink that writes records as JSON lines, e.g. to an
+// append-only file. It is safe for concurrent use.
+type AuditLog struct {
+	mu      sync.Mutex
+	w       io.Writer
+	onError func(error)
+}
+
+// NewAuditLog creates an audit log writing to w
+func NewAuditLog(w io.Writer) *AuditLog {
+	return &AuditLog{w: w}
+}
+
+// WithErrorHandler sets a callback for failed writes, which are otherwise dropped
+func (l *AuditLog) WithErrorHandler(fn func(error)) *AuditLog {
+	l.onError = fn
+	return l
+}
+
+// Audit writes the record as a line of JSON
+func (l *AuditLog) Audit(ctx context.Context, record AuditRecord) {
+	data, err := json.Marshal(record)
+	if err == nil {
+		l.mu.Lock()
+		_, err = l.w.Write(append(data, '\n'))
+		l.mu.Unlock()
+	}
+	if err != nil && l.onError != nil {
+		l.onError(fmt.Errorf("failed to write audit record: %w", err))
+	}
+}
@@ -0,0 +1,113 @@
+package iplocate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func auditServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(LookupResponse{IP: "203.0.113.7"})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWithAuditSink(t *testing.T) {
+	var records []AuditRecord
+	client := NewClient(nil).
+		WithBaseURL(auditServer(t).URL).
+		WithAuditSink(AuditSinkFunc(func(ctx context.Context, record AuditRecord) {
+			records = append(records, record)
+		}), RedactNone)
+
+	ctx := ContextWithAuditActor(ContextWithRequestID(context.Background(), "req-1"), "alice@example.com")
+	_, err := client.LookupContext(ctx, "203.0.113.7")
+	require.NoError(t, err)
+	_, err = client.LookupContext(ctx, "not-an-ip")
+	require.Error(t, err)
+
+	require.Len(t, records, 2)
+	assert.Equal(t, "alice@example.com", records[0].Actor)
+	assert.Equal(t, "req-1", records[0].RequestID)
+	assert.Equal(t, "203.0.113.7", records[0].IP)
+	assert.True(t, records[0].Success)
+	assert.False(t, records[0].Time.IsZero())
+	assert.False(t, records[1].Success)
+	assert.Contains(t, records[1].Error, "invalid IP address")
+}
+
+func TestWithAuditSink_Redaction(t *testing.T) {
+	client := NewClient(nil).WithIPHashKey([]byte("secret"))
+	for _, tt := range []struct {
+		redaction Redaction
+		ip        string
+		want      string
+	}{
+		{RedactNone, "203.0.113.7", "203.0.113.7"},
+		{RedactNetwork, "203.0.113.7", "203.0.113.0/24"},
+		{RedactNetwork, "::ffff:203.0.113.7", "203.0.113.0/24"},
+		{RedactNetwork, "2001:db8:1234:5678::1", "2001:db8:1234::/48"},
+		{RedactNetwork, "ip-0123456789abcdef", "ip-0123456789abcdef"},
+		{RedactHash, "203.0.113.7", client.HashIP("203.0.113.7")},
+		{RedactAll, "203.0.113.7", ""},
+	} {
+		sink := &auditEventSink{client: client, redaction: tt.redaction}
+		assert.Equal(t, tt.want, sink.redact(tt.ip), "%d %s", tt.redaction, tt.ip)
+	}
+}
+
+func TestWithAuditSink_RedactsErrors(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	serverURL := server.URL
+	server.Close()
+
+	var records []AuditRecord
+	client := NewClient(nil).
+		WithAPIKey("secret-key").
+		WithBaseURL(serverURL).
+		WithAuditSink(AuditSinkFunc(func(ctx context.Context, record AuditRecord) {
+			records = append(records, record)
+		}), RedactAll)
+
+	_, err := client.Lookup("203.0.113.7")
+	require.Error(t, err)
+	require.Len(t, records, 1)
+	assert.Contains(t, records[0].Error, "request failed")
+	assert.NotContains(t, records[0].Error, "203.0.113.7")
+	assert.NotContains(t, records[0].Error, "secret-key")
+}
+
+func TestAuditLog(t *testing.T) {
+	var buf bytes.Buffer
+	client := NewClient(nil).WithBaseURL(auditServer(t).URL).WithAuditSink(NewAuditLog(&buf), RedactNetwork)
+
+	_, err := client.LookupContext(ContextWithAuditActor(context.Background(), "billing"), "203.0.113.7")
+	require.NoError(t, err)
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "billing", record["actor"])
+	assert.Equal(t, "203.0.113.0/24", record["ip"])
+	assert.Equal(t, true, record["success"])
+	assert.Equal(t, byte('\n'), buf.Bytes()[buf.Len()-1])
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestAuditLog_ErrorHandler(t *testing.T) {
+	var got error
+	log := NewAuditLog(failingWriter{}).WithErrorHandler(func(err error) { got = err })
+	log.Audit(context.Background(), AuditRecord{IP: "203.0.113.7"})
+	assert.ErrorContains(t, got, "failed to write audit record: disk full")
+}
@@ -0,0 +1,131 @@
+package iplocate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// BatchResult holds the outcome of LookupBatch
+type BatchResult struct {
+	// Responses holds the successful lookups by IP address
+	Responses map[string]*LookupResponse
+	// Errors holds the failed lookups by IP address
+	Errors map[string]error
+	// Stale holds the IP addresses answered with an expired cache entry
+	// because the API failed; see WithServeStaleOnError
+	Stale map[string]bool
+}
+
+// Err returns a *BatchError listing the failed lookups, or nil if every
+// lookup succeeded
+func (r *BatchResult) Err() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+	return &BatchError{Errors: r.Errors}
+}
+
+// BatchError reports the lookups of a batch that failed
+type BatchError struct {
+	Errors map[string]error
+}
+
+func (e *BatchError) Error() string {
+	ips := e.ips()
+	parts := make([]string, len(ips))
+	for i, ip := range ips {
+		parts[i] = fmt.Sprintf("%s: %v", ip, e.Errors[ip])
+	}
+	return fmt.Sprintf("%d lookups failed: %s", len(ips), strings.Join(parts, "; "))
+}
+
+// Unwrap returns the errors of the failed lookups, ordered by IP address, so
+// errors.Is and errors.As match any of them
+func (e *BatchError) Unwrap() []error {
+	ips := e.ips()
+	errs := make([]error, len(ips))
+	for i, ip := range ips {
+		errs[i] = e.Errors[ip]
+	}
+	return errs
+}
+
+func (e *BatchError) ips() []string {
+	ips := make([]string, 0, len(e.Errors))
+	for ip := range e.Errors {
+		ips = append(ips, ip)
+	}
+	sort.Strings(ips)
+	return ips
+}
+
+// FailFast makes LookupBatch stop at the first failed lookup, canceling the
+// lookups still running, instead of collecting every failure
+func FailFast() LookupOption {
+	return func(o *lookupOptions) {
+		o.failFast = true
+	}
+}
+
+// LookupBatch looks up ips concurrently like LookupStream and collects the
+// results. By default every lookup runs and the returned error is the
+// result's Err, listing all failures. With FailFast, the batch stops at the
+// first failure and returns it; the result then holds the lookups that
+// succeeded before it.
+func (c *Client) LookupBatch(ctx context.Context, ips []string, opts ...LookupOption) (*BatchResult, error) {
+	failFast := c.lookupOptions(opts).failFast
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	queue := make(chan string)
+	go func() {
+		defer close(queue)
+		for _, ip := range ips {
+			select {
+			case queue <- ip:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	result := &BatchResult{
+		Responses: make(map[string]*LookupResponse),
+		Errors:    make(map[string]error),
+		Stale:     make(map[string]bool),
+	}
+	var firstErr error
+	received := 0
+	for r := range c.stream(ctx, queue, len(ips), opts) {
+		received++
+		if r.Err == nil {
+			result.Responses[r.IP] = r.Response
+			if r.Stale {
+				result.Stale[r.IP] = true
+			}
+			continue
+		}
+		if !failFast {
+			result.Errors[r.IP] = r.Err
+			continue
+		}
+		// Lookups canceled because of the first failure are not failures themselves
+		if firstErr == nil {
+			result.Errors[r.IP] = r.Err
+			firstErr = fmt.Errorf("lookup of %s failed: %w", r.IP, r.Err)
+			cancel()
+		}
+	}
+
+	if firstErr == nil {
+		// The caller's context may have ended the batch early
+		if err := ctx.Err(); err != nil && received < len(ips) {
+			return result, err
+		}
+		return result, result.Err()
+	}
+	return result, firstErr
+}
@@ -0,0 +1,96 @@
+package iplocate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFailingServer answers lookups of failIP with a 404 and echoes all others
+func newFailingServer(t *testing.T, failIP string, calls *int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		ip := strings.TrimPrefix(r.URL.Path, "/lookup/")
+		if ip == failIP {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"Not found"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(LookupResponse{IP: ip})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestLookupBatch_CollectAll(t *testing.T) {
+	var calls int32
+	server := newFailingServer(t, "192.0.2.1", &calls)
+	client := NewClient(nil).WithBaseURL(server.URL)
+
+	ips := []string{"8.8.8.8", "192.0.2.1", "1.1.1.1", "invalid"}
+	result, err := client.LookupBatch(context.Background(), ips)
+
+	require.Error(t, err)
+	var batchErr *BatchError
+	require.ErrorAs(t, err, &batchErr)
+	assert.Len(t, batchErr.Errors, 2)
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr, "BatchError unwraps to the individual errors")
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+
+	assert.Len(t, result.Responses, 2)
+	assert.Equal(t, "8.8.8.8", result.Responses["8.8.8.8"].IP)
+	assert.Contains(t, result.Errors, "192.0.2.1")
+	assert.Contains(t, result.Errors, "invalid")
+	assert.Contains(t, err.Error(), "2 lookups failed")
+}
+
+func TestLookupBatch_AllSucceed(t *testing.T) {
+	var calls int32
+	server := newFailingServer(t, "", &calls)
+	client := NewClient(nil).WithBaseURL(server.URL)
+
+	result, err := client.LookupBatch(context.Background(), []string{"8.8.8.8", "1.1.1.1"})
+	require.NoError(t, err)
+	assert.Len(t, result.Responses, 2)
+	assert.Empty(t, result.Errors)
+	assert.NoError(t, result.Err())
+}
+
+func TestLookupBatch_FailFast(t *testing.T) {
+	var calls int32
+	server := newFailingServer(t, "192.0.2.1", &calls)
+	client := NewClient(nil).WithBaseURL(server.URL)
+
+	ips := []string{"192.0.2.1"}
+	for i := 0; i < 100; i++ {
+		ips = append(ips, "8.8.8.8")
+	}
+	result, err := client.LookupBatch(context.Background(), ips, FailFast())
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Contains(t, err.Error(), "192.0.2.1")
+	assert.Len(t, result.Errors, 1)
+	assert.Less(t, int(atomic.LoadInt32(&calls)), len(ips), "remaining lookups are canceled")
+}
+
+func TestLookupBatch_Canceled(t *testing.T) {
+	var calls int32
+	server := newFailingServer(t, "", &calls)
+	client := NewClient(nil).WithBaseURL(server.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := client.LookupBatch(ctx, []string{"8.8.8.8", "1.1.1.1"})
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,148 @@
+package iplocate
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Cache stores serialized values by key. Implementations must be safe for
+// concurrent use. Backends that can fail, such as remote caches, should
+// treat errors as misses.
+type Cache interface {
+	// Get returns the value stored for key, if it exists and has not expired
+	Get(key string) (value []byte, ok bool)
+	// Set stores value for key. A ttl of zero means the value doesn't expire.
+	Set(key string, value []byte, ttl time.Duration)
+	// Delete removes key
+	Delete(key string)
+}
+
+// Flusher is implemented by caches that can write buffered changes to
+// durable storage. Client.Close flushes the caches of the client.
+type Flusher interface {
+	Flush() error
+}
+
+// DefaultMemoryCacheSize is the number of entries a MemoryCache holds when
+// created with a size of zero
+const DefaultMemoryCacheSize = 10000
+
+// MemoryCache is an in-memory Cache that evicts the least recently used
+// entries once it is full
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List
+	now        func() time.Time
+}
+
+type memoryCacheEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewMemoryCache creates a cache holding at most maxEntries entries.
+// If maxEntries is zero or negative, DefaultMemoryCacheSize is used.
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMemoryCacheSize
+	}
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+		now:        time.Now,
+	}
+}
+
+// Get returns the value stored for key
+func (m *MemoryCache) Get(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	elem, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*memoryCacheEntry)
+	if !entry.expiresAt.IsZero() && !m.now().Before(entry.expiresAt) {
+		m.remove(elem)
+		return nil, false
+	}
+	m.order.MoveToFront(elem)
+	return entry.value, true
+}
+
+// Set stores value for key, evicting the least recently used entry if the
+// cache is full
+func (m *MemoryCache) Set(key string, value []byte, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = m.now().Add(ttl)
+	}
+
+	if elem, ok := m.entries[key]; ok {
+		entry := elem.Value.(*memoryCacheEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		m.order.MoveToFront(elem)
+		return
+	}
+
+	m.entries[key] = m.order.PushFront(&memoryCacheEntry{key: key, value: value, expiresAt: expiresAt})
+	for m.order.Len() > m.maxEntries {
+		m.remove(m.order.Back())
+	}
+}
+
+// Delete removes key
+func (m *MemoryCache) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if elem, ok := m.entries[key]; ok {
+		m.remove(elem)
+	}
+}
+
+// Range calls fn for each entry that has not expired, most recently used
+// first, until fn returns false. Entries are listed from a snapshot, so fn
+// may use the cache.
+func (m *MemoryCache) Range(fn func(key string, value []byte) bool) error {
+	m.mu.Lock()
+	now := m.now()
+	entries := make([]memoryCacheEntry, 0, m.order.Len())
+	for elem := m.order.Front(); elem != nil; elem = elem.Next() {
+		entry := elem.Value.(*memoryCacheEntry)
+		if entry.expiresAt.IsZero() || now.Before(entry.expiresAt) {
+			entries = append(entries, *entry)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, entry := range entries {
+		if !fn(entry.key, entry.value) {
+			break
+		}
+	}
+	return nil
+}
+
+// Len returns the number of entries, including expired ones not yet evicted
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.order.Len()
+}
+
+// remove deletes an entry. The caller must hold m.mu.
+func (m *MemoryCache) remove(elem *list.Element) {
+	m.order.Remove(elem)
+	delete(m.entries, elem.Value.(*memoryCacheEntry).key)
+}
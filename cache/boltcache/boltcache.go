@@ -0,0 +1,319 @@
+// Package boltcache provides an iplocate.Cache stored on disk with bbolt, so
+// CLI tools and long-running daemons keep their lookup cache across
+// restarts.
+package boltcache
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/iplocate/go-iplocate"
+	bolt "go.etcd.io/bbolt"
+)
+
+// DefaultMaxEntries is the number of entries a Cache holds unless configured
+// otherwise with WithMaxEntries
+const DefaultMaxEntries = 100000
+
+var (
+	// entriesBucket maps keys to an expiry, a write sequence number and the value
+	entriesBucket = []byte("entries")
+	// orderBucket maps write sequence numbers to keys, oldest first
+	orderBucket = []byte("order")
+)
+
+// headerSize is the length of the expiry and sequence number prefix of values
+const headerSize = 16
+
+var _ iplocate.Cache = (*Cache)(nil)
+
+// Cache is an iplocate.Cache backed by a bbolt database file. Once it holds
+// more than its maximum number of entries, the oldest written entries are
+// evicted. Expired entries are not returned, and are removed from the file
+// by Compact. It is safe for concurrent use; the file can only be opened by
+// one process at a time.
+type Cache struct {
+	db  *bolt.DB
+	now func() time.Time
+
+	mu         sync.Mutex
+	maxEntries int
+	count      int
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// Open opens or creates the cache file at path
+func Open(path string) (*Cache, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cache: %w", err)
+	}
+
+	c := &Cache{db: db, now: time.Now, maxEntries: DefaultMaxEntries}
+	err = db.Update(func(tx *bolt.Tx) error {
+		entries, err := tx.CreateBucketIfNotExists(entriesBucket)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucketIfNotExists(orderBucket); err != nil {
+			return err
+		}
+		c.count = entries.Stats().KeyN
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize cache: %w", err)
+	}
+	return c, nil
+}
+
+// WithMaxEntries sets the maximum number of entries. If the cache holds more,
+// the oldest are evicted on the next write.
+func (c *Cache) WithMaxEntries(maxEntries int) *Cache {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	c.maxEntries = maxEntries
+	return c
+}
+
+// Get returns the value stored for key. Errors reading the file are treated
+// as misses.
+func (c *Cache) Get(key string) ([]byte, bool) {
+	var value []byte
+	c.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(entriesBucket).Get([]byte(key))
+		if len(data) < headerSize || c.expired(data) {
+			return nil
+		}
+		// Data is only valid during the transaction
+		value = append([]byte(nil), data[headerSize:]...)
+		return nil
+	})
+	return value, value != nil
+}
+
+// Set stores value for key. A ttl of zero means the value doesn't expire.
+// Errors writing the file are ignored, as for a cache miss.
+func (c *Cache) Set(key string, value []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expires int64
+	if ttl > 0 {
+		expires = c.now().Add(ttl).UnixNano()
+	}
+
+	var count int
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		entries := tx.Bucket(entriesBucket)
+		order := tx.Bucket(orderBucket)
+		count = c.count
+
+		if old := entries.Get([]byte(key)); old != nil {
+			if err := deleteEntry(entries, order, []byte(key), old); err != nil {
+				return err
+			}
+			count--
+		}
+
+		seq, err := order.NextSequence()
+		if err != nil {
+			return err
+		}
+		data := make([]byte, headerSize+len(value))
+		binary.BigEndian.PutUint64(data[0:8], uint64(expires))
+		binary.BigEndian.PutUint64(data[8:16], seq)
+		copy(data[headerSize:], value)
+		if err := entries.Put([]byte(key), data); err != nil {
+			return err
+		}
+		if err := order.Put(sequenceKey(seq), []byte(key)); err != nil {
+			return err
+		}
+		count++
+
+		// Evict the oldest entries
+		cursor := order.Cursor()
+		for seqKey, oldest := cursor.First(); seqKey != nil && count > c.maxEntries; seqKey, oldest = cursor.First() {
+			data := entries.Get(oldest)
+			if data == nil {
+				if err := order.Delete(seqKey); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := deleteEntry(entries, order, oldest, data); err != nil {
+				return err
+			}
+			count--
+		}
+		return nil
+	})
+	if err == nil {
+		c.count = count
+	}
+}
+
+// Delete removes key
+func (c *Cache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		entries := tx.Bucket(entriesBucket)
+		data := entries.Get([]byte(key))
+		if data == nil {
+			return errNotFound
+		}
+		return deleteEntry(entries, tx.Bucket(orderBucket), []byte(key), data)
+	})
+	if err == nil {
+		c.count--
+	}
+}
+
+// Range calls fn for each entry that has not expired, in key order, until
+// fn returns false. fn runs inside a read transaction and must not write to
+// the cache.
+func (c *Cache) Range(fn func(key string, value []byte) bool) error {
+	err := c.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(entriesBucket).Cursor()
+		for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
+			if len(v) < headerSize || c.expired(v) {
+				continue
+			}
+			if !fn(string(k), append([]byte(nil), v[headerSize:]...)) {
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read cache: %w", err)
+	}
+	return nil
+}
+
+// Len returns the number of entries in the file, including expired entries
+// that have not been compacted yet
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.count
+}
+
+// Compact removes expired entries from the file and returns how many were
+// removed
+func (c *Cache) Compact() (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		entries := tx.Bucket(entriesBucket)
+		order := tx.Bucket(orderBucket)
+
+		var expired [][]byte
+		err := entries.ForEach(func(k, v []byte) error {
+			if len(v) < headerSize || c.expired(v) {
+				expired = append(expired, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, key := range expired {
+			if err := deleteEntry(entries, order, key, entries.Get(key)); err != nil {
+				return err
+			}
+		}
+		removed = len(expired)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to compact cache: %w", err)
+	}
+	c.count -= removed
+	return removed, nil
+}
+
+// StartCompaction runs Compact in the background every interval until Close
+// is called. Errors are passed to onError, which may be nil. StartCompaction
+// must be called at most once.
+func (c *Cache) StartCompaction(interval time.Duration, onError func(error)) {
+	c.stop = make(chan struct{})
+	c.done = make(chan struct{})
+
+	go func() {
+		defer close(c.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-c.stop:
+				return
+			case <-ticker.C:
+				if _, err := c.Compact(); err != nil && onError != nil {
+					onError(err)
+				}
+			}
+		}
+	}()
+}
+
+// Flush syncs the file to disk
+func (c *Cache) Flush() error {
+	if err := c.db.Sync(); err != nil {
+		return fmt.Errorf("failed to flush cache: %w", err)
+	}
+	return nil
+}
+
+// Close stops background compaction and closes the file
+func (c *Cache) Close() error {
+	c.stopOnce.Do(func() {
+		if c.stop != nil {
+			close(c.stop)
+			<-c.done
+		}
+	})
+	return c.db.Close()
+}
+
+// errNotFound aborts a transaction that has nothing to do
+var errNotFound = errors.New("not found")
+
+// expired reports whether a stored value has expired
+func (c *Cache) expired(data []byte) bool {
+	expires := int64(binary.BigEndian.Uint64(data[0:8]))
+	return expires != 0 && c.now().UnixNano() >= expires
+}
+
+// deleteEntry removes key and its position in the write order
+func deleteEntry(entries, order *bolt.Bucket, key, data []byte) error {
+	if len(data) >= headerSize {
+		if err := order.Delete(data[8:16]); err != nil {
+			return err
+		}
+	}
+	return entries.Delete(key)
+}
+
+func sequenceKey(seq uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return key
+}
@@ -0,0 +1,175 @@
+package boltcache
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/iplocate/go-iplocate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openTestCache(t *testing.T) (*Cache, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "cache.db")
+	c, err := Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Close() })
+	return c, path
+}
+
+func TestCache_GetSetDelete(t *testing.T) {
+	c, _ := openTestCache(t)
+
+	_, ok := c.Get("missing")
+	assert.False(t, ok)
+
+	c.Set("a", []byte("1"), 0)
+	value, ok := c.Get("a")
+	require.True(t, ok)
+	assert.Equal(t, "1", string(value))
+
+	c.Set("a", []byte("2"), 0)
+	value, _ = c.Get("a")
+	assert.Equal(t, "2", string(value))
+	assert.Equal(t, 1, c.Len())
+
+	c.Delete("a")
+	_, ok = c.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Len())
+	c.Delete("a")
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestCache_Persists(t *testing.T) {
+	c, path := openTestCache(t)
+	c.Set("a", []byte("1"), time.Hour)
+	require.NoError(t, c.Close())
+
+	reopened, err := Open(path)
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	value, ok := reopened.Get("a")
+	require.True(t, ok)
+	assert.Equal(t, "1", string(value))
+	assert.Equal(t, 1, reopened.Len())
+}
+
+func TestCache_Range(t *testing.T) {
+	c, _ := openTestCache(t)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	c.Set("b", []byte("2"), 0)
+	c.Set("a", []byte("1"), time.Minute)
+	c.Set("expired", []byte("3"), time.Second)
+	now = now.Add(2 * time.Second)
+
+	var keys, values []string
+	require.NoError(t, c.Range(func(key string, value []byte) bool {
+		keys = append(keys, key)
+		values = append(values, string(value))
+		return true
+	}))
+	assert.Equal(t, []string{"a", "b"}, keys)
+	assert.Equal(t, []string{"1", "2"}, values)
+
+	calls := 0
+	require.NoError(t, c.Range(func(key string, value []byte) bool {
+		calls++
+		return false
+	}))
+	assert.Equal(t, 1, calls)
+}
+
+func TestCache_TTLAndCompact(t *testing.T) {
+	c, _ := openTestCache(t)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	c.Set("short", []byte("1"), time.Minute)
+	c.Set("long", []byte("2"), time.Hour)
+	c.Set("forever", []byte("3"), 0)
+
+	now = now.Add(2 * time.Minute)
+	_, ok := c.Get("short")
+	assert.False(t, ok)
+	assert.Equal(t, 3, c.Len(), "expired entries stay until compacted")
+
+	removed, err := c.Compact()
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	assert.Equal(t, 2, c.Len())
+
+	_, ok = c.Get("long")
+	assert.True(t, ok)
+	_, ok = c.Get("forever")
+	assert.True(t, ok)
+}
+
+func TestCache_MaxEntries(t *testing.T) {
+	c, _ := openTestCache(t)
+	c.WithMaxEntries(2)
+
+	c.Set("a", []byte("1"), 0)
+	c.Set("b", []byte("2"), 0)
+	c.Set("a", []byte("3"), 0) // rewriting makes a the newest entry
+	c.Set("c", []byte("4"), 0)
+
+	_, ok := c.Get("b")
+	assert.False(t, ok, "oldest entry evicted")
+	_, ok = c.Get("a")
+	assert.True(t, ok)
+	_, ok = c.Get("c")
+	assert.True(t, ok)
+	assert.Equal(t, 2, c.Len())
+}
+
+func TestCache_StartCompaction(t *testing.T) {
+	c, _ := openTestCache(t)
+	c.Set("a", []byte("1"), time.Millisecond)
+
+	c.StartCompaction(5*time.Millisecond, nil)
+	require.Eventually(t, func() bool { return c.Len() == 0 }, 5*time.Second, 5*time.Millisecond)
+	require.NoError(t, c.Close())
+}
+
+func TestCache_WithClient(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		json.NewEncoder(w).Encode(iplocate.LookupResponse{IP: "8.8.8.8"})
+	}))
+	defer server.Close()
+
+	c, _ := openTestCache(t)
+	client := iplocate.NewClient(nil).WithBaseURL(server.URL).WithCache(c, time.Hour)
+
+	for i := 0; i < 2; i++ {
+		result, err := client.Lookup("8.8.8.8")
+		require.NoError(t, err)
+		assert.Equal(t, "8.8.8.8", result.IP)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestCache_Flush(t *testing.T) {
+	c, path := openTestCache(t)
+	c.Set("a", []byte("1"), 0)
+	require.NoError(t, c.Flush())
+	require.NoError(t, c.Close())
+
+	reopened, err := Open(path)
+	require.NoError(t, err)
+	defer reopened.Close()
+	value, ok := reopened.Get("a")
+	require.True(t, ok)
+	assert.Equal(t, "1", string(value))
+}
@@ -0,0 +1,160 @@
+// Package cache provides helpers around iplocate.Cache backends: wrappers
+// that namespace and encrypt stored values, so a cache shared between
+// tenants or applications doesn't expose their data to each other, and
+// tools to prewarm, export and import cached lookups.
+package cache
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/iplocate/go-iplocate"
+)
+
+// ErrRangeUnsupported is returned when listing the entries of a cache that
+// doesn't implement Ranger
+var ErrRangeUnsupported = errors.New("cache does not support listing its entries")
+
+// Ranger is implemented by caches that can list their entries, such as
+// iplocate.MemoryCache and the boltcache and sqlitecache backends.
+type Ranger interface {
+	// Range calls fn for each entry that has not expired until fn returns false
+	Range(fn func(key string, value []byte) bool) error
+}
+
+// rangeCache lists the entries of c, or returns ErrRangeUnsupported
+func rangeCache(c iplocate.Cache, fn func(key string, value []byte) bool) error {
+	r, ok := c.(Ranger)
+	if !ok {
+		return ErrRangeUnsupported
+	}
+	return r.Range(fn)
+}
+
+// flush flushes c if it implements iplocate.Flusher
+func flush(c iplocate.Cache) error {
+	if flusher, ok := c.(iplocate.Flusher); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
+// namespaced prefixes every key of the wrapped cache
+type namespaced struct {
+	cache  iplocate.Cache
+	prefix string
+}
+
+// Namespace returns a cache that stores its entries in c under keys starting
+// with prefix, e.g. "tenant-a:", so several clients can share a backend such
+// as a Redis cluster without their entries colliding
+func Namespace(c iplocate.Cache, prefix string) iplocate.Cache {
+	return &namespaced{cache: c, prefix: prefix}
+}
+
+func (n *namespaced) Get(key string) ([]byte, bool) {
+	return n.cache.Get(n.prefix + key)
+}
+
+func (n *namespaced) Set(key string, value []byte, ttl time.Duration) {
+	n.cache.Set(n.prefix+key, value, ttl)
+}
+
+func (n *namespaced) Delete(key string) {
+	n.cache.Delete(n.prefix + key)
+}
+
+// Flush flushes the wrapped cache if it implements iplocate.Flusher
+func (n *namespaced) Flush() error {
+	return flush(n.cache)
+}
+
+// Range lists the entries under the prefix, with the prefix removed
+func (n *namespaced) Range(fn func(key string, value []byte) bool) error {
+	return rangeCache(n.cache, func(key string, value []byte) bool {
+		if !strings.HasPrefix(key, n.prefix) {
+			return true
+		}
+		return fn(strings.TrimPrefix(key, n.prefix), value)
+	})
+}
+
+// encrypted seals the values of the wrapped cache with AES-GCM
+type encrypted struct {
+	cache iplocate.Cache
+	aead  cipher.AEAD
+}
+
+// Encrypt returns a cache that encrypts values with AES-GCM before storing
+// them in c. key must be 16, 24 or 32 bytes long to select AES-128, AES-192
+// or AES-256. Values are bound to their cache key, so a value copied to
+// another key fails to decrypt. Values that fail to decrypt, for example
+// after the key changed, are treated as misses. Cache keys, which contain
+// the looked up IP addresses, are stored as is; combine Encrypt with
+// Namespace to separate tenants.
+func Encrypt(c iplocate.Cache, key []byte) (iplocate.Cache, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	return &encrypted{cache: c, aead: aead}, nil
+}
+
+func (e *encrypted) Get(key string) ([]byte, bool) {
+	sealed, ok := e.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	return e.open(key, sealed)
+}
+
+// open decrypts a value sealed for key
+func (e *encrypted) open(key string, sealed []byte) ([]byte, bool) {
+	if len(sealed) < e.aead.NonceSize() {
+		return nil, false
+	}
+	nonce, ciphertext := sealed[:e.aead.NonceSize()], sealed[e.aead.NonceSize():]
+	value, err := e.aead.Open(nil, nonce, ciphertext, []byte(key))
+	if err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// Flush flushes the wrapped cache if it implements iplocate.Flusher
+func (e *encrypted) Flush() error {
+	return flush(e.cache)
+}
+
+// Range lists the decrypted entries, skipping values that fail to decrypt
+func (e *encrypted) Range(fn func(key string, value []byte) bool) error {
+	return rangeCache(e.cache, func(key string, sealed []byte) bool {
+		value, ok := e.open(key, sealed)
+		if !ok {
+			return true
+		}
+		return fn(key, value)
+	})
+}
+
+func (e *encrypted) Set(key string, value []byte, ttl time.Duration) {
+	nonce := make([]byte, e.aead.NonceSize(), e.aead.NonceSize()+len(value)+e.aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return
+	}
+	e.cache.Set(key, e.aead.Seal(nonce, nonce, value, []byte(key)), ttl)
+}
+
+func (e *encrypted) Delete(key string) {
+	e.cache.Delete(key)
+}
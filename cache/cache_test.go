@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/iplocate/go-iplocate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mapCache is a Cache that exposes its raw contents
+type mapCache map[string][]byte
+
+func (m mapCache) Get(key string) ([]byte, bool) {
+	value, ok := m[key]
+	return value, ok
+}
+
+func (m mapCache) Set(key string, value []byte, ttl time.Duration) {
+	m[key] = value
+}
+
+func (m mapCache) Delete(key string) {
+	delete(m, key)
+}
+
+func TestNamespace(t *testing.T) {
+	backend := mapCache{}
+	a := Namespace(backend, "tenant-a:")
+	b := Namespace(backend, "tenant-b:")
+
+	a.Set("lookup:8.8.8.8", []byte("a"), 0)
+	b.Set("lookup:8.8.8.8", []byte("b"), 0)
+	assert.Equal(t, mapCache{"tenant-a:lookup:8.8.8.8": []byte("a"), "tenant-b:lookup:8.8.8.8": []byte("b")}, backend)
+
+	value, ok := a.Get("lookup:8.8.8.8")
+	require.True(t, ok)
+	assert.Equal(t, "a", string(value))
+
+	a.Delete("lookup:8.8.8.8")
+	_, ok = a.Get("lookup:8.8.8.8")
+	assert.False(t, ok)
+	_, ok = b.Get("lookup:8.8.8.8")
+	assert.True(t, ok)
+}
+
+func TestEncrypt(t *testing.T) {
+	backend := mapCache{}
+	key := bytes.Repeat([]byte{1}, 32)
+	c, err := Encrypt(backend, key)
+	require.NoError(t, err)
+
+	plaintext := []byte(`{"response":{"ip":"8.8.8.8","country_code":"US"}}`)
+	c.Set("lookup:8.8.8.8", plaintext, 0)
+
+	assert.NotContains(t, string(backend["lookup:8.8.8.8"]), "country_code")
+	value, ok := c.Get("lookup:8.8.8.8")
+	require.True(t, ok)
+	assert.Equal(t, plaintext, value)
+
+	// Values moved to another key don't decrypt
+	backend["lookup:1.1.1.1"] = backend["lookup:8.8.8.8"]
+	_, ok = c.Get("lookup:1.1.1.1")
+	assert.False(t, ok)
+
+	// Neither do values sealed with another key
+	other, err := Encrypt(backend, bytes.Repeat([]byte{2}, 32))
+	require.NoError(t, err)
+	_, ok = other.Get("lookup:8.8.8.8")
+	assert.False(t, ok)
+
+	c.Delete("lookup:8.8.8.8")
+	assert.NotContains(t, backend, "lookup:8.8.8.8")
+}
+
+func TestEncrypt_InvalidKey(t *testing.T) {
+	_, err := Encrypt(mapCache{}, []byte("short"))
+	assert.Error(t, err)
+}
+
+func TestEncrypt_WithClient(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		json.NewEncoder(w).Encode(iplocate.LookupResponse{IP: "8.8.8.8"})
+	}))
+	defer server.Close()
+
+	c, err := Encrypt(Namespace(iplocate.NewMemoryCache(10), "app:"), bytes.Repeat([]byte{1}, 16))
+	require.NoError(t, err)
+	client := iplocate.NewClient(nil).WithBaseURL(server.URL).WithCache(c, time.Hour)
+
+	for i := 0; i < 2; i++ {
+		_, err := client.Lookup("8.8.8.8")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+// flushMapCache is a mapCache that counts flushes
+type flushMapCache struct {
+	mapCache
+	flushes int
+}
+
+func (f *flushMapCache) Flush() error {
+	f.flushes++
+	return nil
+}
+
+func TestWrappers_Flush(t *testing.T) {
+	backend := &flushMapCache{mapCache: mapCache{}}
+	encrypted, err := Encrypt(Namespace(backend, "a:"), bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+
+	require.NoError(t, encrypted.(iplocate.Flusher).Flush())
+	assert.Equal(t, 1, backend.flushes)
+
+	require.NoError(t, Namespace(mapCache{}, "a:").(iplocate.Flusher).Flush(), "caches without Flush are skipped")
+}
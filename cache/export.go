@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/iplocate/go-iplocate"
+)
+
+// record is the NDJSON form of a cached lookup
+type record struct {
+	Key string `json:"key"`
+	iplocate.CacheEntry
+}
+
+// Export writes the lookups cached in c to w as newline-delimited JSON, one
+// object per entry holding its cache key and the fields of
+// iplocate.CacheEntry. Values that are not lookups stored by the client are
+// skipped. c must implement Ranger, directly or through Namespace and
+// Encrypt. Export returns the number of entries written.
+func Export(w io.Writer, c iplocate.Cache) (int, error) {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+
+	n := 0
+	var writeErr error
+	err := rangeCache(c, func(key string, value []byte) bool {
+		entry, err := iplocate.DecodeCacheEntry(value)
+		if err != nil {
+			return true
+		}
+		if writeErr = enc.Encode(record{Key: key, CacheEntry: *entry}); writeErr != nil {
+			return false
+		}
+		n++
+		return true
+	})
+	if err != nil {
+		return n, err
+	}
+	if writeErr != nil {
+		return n, fmt.Errorf("failed to write export: %w", writeErr)
+	}
+	if err := bw.Flush(); err != nil {
+		return n, fmt.Errorf("failed to write export: %w", err)
+	}
+	return n, nil
+}
+
+// Import reads lookups written by Export from r and stores them in c for
+// ttl, which should match how long the client keeps entries; zero means
+// they don't expire. Entries keep the time they were originally stored, so
+// the client revalidates those older than its cache TTL. Import returns the
+// number of entries stored.
+func Import(r io.Reader, c iplocate.Cache, ttl time.Duration) (int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	n := 0
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var rec record
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			return n, fmt.Errorf("line %d: invalid JSON: %w", lineNum, err)
+		}
+		if rec.Key == "" {
+			return n, fmt.Errorf("line %d: missing key", lineNum)
+		}
+		if rec.Response == nil && rec.Error == nil {
+			return n, fmt.Errorf("line %d: no response or error", lineNum)
+		}
+
+		value, err := rec.CacheEntry.Encode()
+		if err != nil {
+			return n, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		c.Set(rec.Key, value, ttl)
+		n++
+	}
+	if err := scanner.Err(); err != nil {
+		return n, fmt.Errorf("failed to read import: %w", err)
+	}
+	return n, nil
+}
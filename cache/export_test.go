@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/iplocate/go-iplocate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportImport(t *testing.T) {
+	var calls int32
+	server := newLookupServer(t, &calls)
+	source := iplocate.NewMemoryCache(100)
+	client := iplocate.NewClient(nil).WithBaseURL(server.URL).WithCache(source, time.Hour)
+
+	for _, ip := range []string{"8.8.8.8", "1.1.1.1"} {
+		_, err := client.Lookup(ip)
+		require.NoError(t, err)
+	}
+	source.Set("unrelated", []byte("not a lookup"), 0)
+
+	var buf bytes.Buffer
+	n, err := Export(&buf, source)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, 2, strings.Count(buf.String(), "\n"))
+	assert.Contains(t, buf.String(), `"key":"lookup:8.8.8.8"`)
+
+	target := iplocate.NewMemoryCache(100)
+	n, err = Import(bytes.NewReader(buf.Bytes()), target, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	restored := iplocate.NewClient(nil).WithBaseURL(server.URL).WithCache(target, time.Hour)
+	result, err := restored.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "US", *result.CountryCode)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "imported entries are served from the cache")
+}
+
+func TestExport_NamespacedEncrypted(t *testing.T) {
+	backend := iplocate.NewMemoryCache(100)
+	c, err := Encrypt(Namespace(backend, "tenant-a:"), bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+	Namespace(backend, "tenant-b:").Set("lookup:9.9.9.9", []byte(`{"response":{"ip":"9.9.9.9"}}`), 0)
+
+	n, err := Import(strings.NewReader(`{"key":"lookup:8.8.8.8","response":{"ip":"8.8.8.8"},"stored_at":"2024-01-01T00:00:00Z"}`+"\n"), c, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	var buf bytes.Buffer
+	n, err = Export(&buf, c)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Contains(t, buf.String(), `"key":"lookup:8.8.8.8"`)
+	assert.Contains(t, buf.String(), `"stored_at":"2024-01-01T00:00:00Z"`)
+}
+
+func TestExport_Unsupported(t *testing.T) {
+	_, err := Export(&bytes.Buffer{}, mapCache{})
+	assert.ErrorIs(t, err, ErrRangeUnsupported)
+
+	_, err = Export(&bytes.Buffer{}, Namespace(mapCache{}, "a:"))
+	assert.ErrorIs(t, err, ErrRangeUnsupported)
+}
+
+func TestImport_Invalid(t *testing.T) {
+	c := iplocate.NewMemoryCache(10)
+
+	_, err := Import(strings.NewReader(`{"response":{"ip":"8.8.8.8"}}`), c, 0)
+	assert.ErrorContains(t, err, "line 1: missing key")
+
+	_, err = Import(strings.NewReader("\n{oops"), c, 0)
+	assert.ErrorContains(t, err, "line 2: invalid JSON")
+
+	_, err = Import(strings.NewReader(`{"key":"lookup:8.8.8.8"}`), c, 0)
+	assert.ErrorContains(t, err, "no response or error")
+}
+
+func TestPrewarm_FromExport(t *testing.T) {
+	source := iplocate.NewMemoryCache(10)
+	_, err := Import(strings.NewReader(`{"key":"lookup:8.8.8.8","response":{"ip":"8.8.8.8","country_code":"US"}}`), source, 0)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	_, err = Export(&buf, source)
+	require.NoError(t, err)
+
+	client := iplocate.NewClient(nil).WithCache(iplocate.NewMemoryCache(10), time.Hour)
+	n, err := Prewarm(context.Background(), client, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
@@ -0,0 +1,135 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/iplocate/go-iplocate"
+)
+
+// maxLineSize is the longest line Prewarm reads
+const maxLineSize = 1 << 20
+
+// Prewarm populates the cache of client from r ahead of traffic, to smooth
+// the latency and quota spikes of a cold cache after a deploy. Each line of r
+// is either an IP address, which is looked up through the client like
+// LookupStream, or a JSON object holding a lookup response, such as the
+// output of Export or a saved LookupResponse, which is stored without
+// calling the API. Blank lines and lines starting with # are skipped.
+//
+// Prewarm returns the number of entries cached. Failed lookups don't stop
+// it; they are reported in a *iplocate.BatchError once r is read. opts apply
+// to the lookups and select the cache keys the entries are stored under.
+func Prewarm(ctx context.Context, client *iplocate.Client, r io.Reader, opts ...iplocate.LookupOption) (int, error) {
+	if client.Cache() == nil {
+		return 0, iplocate.ErrNoCache
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ips := make(chan string)
+	results := client.LookupStream(ctx, ips, opts...)
+
+	var mu sync.Mutex
+	warmed := 0
+	failed := make(map[string]error)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for result := range results {
+			mu.Lock()
+			if result.Err != nil {
+				failed[result.IP] = result.Err
+			} else {
+				warmed++
+			}
+			mu.Unlock()
+		}
+	}()
+
+	readErr := readPrewarm(ctx, client, r, ips, opts, func() {
+		mu.Lock()
+		warmed++
+		mu.Unlock()
+	})
+	close(ips)
+	if readErr != nil {
+		cancel()
+	}
+	wg.Wait()
+
+	if readErr != nil {
+		return warmed, readErr
+	}
+	if err := ctx.Err(); err != nil {
+		return warmed, err
+	}
+	if len(failed) > 0 {
+		return warmed, &iplocate.BatchError{Errors: failed}
+	}
+	return warmed, nil
+}
+
+// readPrewarm sends the IP addresses read from r to ips and preloads the
+// responses, calling loaded for each
+func readPrewarm(ctx context.Context, client *iplocate.Client, r io.Reader, ips chan<- string, opts []iplocate.LookupOption, loaded func()) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if !strings.HasPrefix(line, "{") {
+			select {
+			case ips <- line:
+			case <-ctx.Done():
+				return nil
+			}
+			continue
+		}
+
+		resp, err := decodeResponse([]byte(line))
+		if err != nil {
+			return fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		if err := client.Preload(resp, opts...); err != nil {
+			return fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		loaded()
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read prewarm input: %w", err)
+	}
+	return nil
+}
+
+// decodeResponse decodes a lookup response, either on its own or in the
+// "response" field of an exported record
+func decodeResponse(data []byte) (*iplocate.LookupResponse, error) {
+	var record struct {
+		Response *iplocate.LookupResponse `json:"response"`
+	}
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if record.Response != nil {
+		return record.Response, nil
+	}
+
+	var resp iplocate.LookupResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	return &resp, nil
+}
@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/iplocate/go-iplocate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLookupServer echoes the looked up IP, failing lookups of 192.0.2.1
+func newLookupServer(t *testing.T, calls *int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		ip := strings.TrimPrefix(r.URL.Path, "/lookup/")
+		if ip == "192.0.2.1" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"Not found"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(iplocate.LookupResponse{IP: ip, CountryCode: stringPtr("US")})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestPrewarm(t *testing.T) {
+	var calls int32
+	server := newLookupServer(t, &calls)
+	client := iplocate.NewClient(nil).WithBaseURL(server.URL).WithCache(iplocate.NewMemoryCache(100), time.Hour)
+
+	input := strings.Join([]string{
+		"# hot addresses",
+		"8.8.8.8",
+		"",
+		"1.1.1.1",
+		`{"ip":"9.9.9.9","country_code":"CH"}`,
+		`{"key":"lookup:4.4.4.4","response":{"ip":"4.4.4.4","country_code":"GB"}}`,
+	}, "\n")
+
+	n, err := Prewarm(context.Background(), client, strings.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, 4, n)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	for ip, country := range map[string]string{"8.8.8.8": "US", "1.1.1.1": "US", "9.9.9.9": "CH", "4.4.4.4": "GB"} {
+		result, err := client.Lookup(ip)
+		require.NoError(t, err)
+		assert.Equal(t, country, *result.CountryCode)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "everything is served from the cache")
+}
+
+func TestPrewarm_FailedLookups(t *testing.T) {
+	var calls int32
+	server := newLookupServer(t, &calls)
+	client := iplocate.NewClient(nil).WithBaseURL(server.URL).WithCache(iplocate.NewMemoryCache(100), time.Hour)
+
+	n, err := Prewarm(context.Background(), client, strings.NewReader("8.8.8.8\n192.0.2.1\n"))
+	assert.Equal(t, 1, n)
+	var batchErr *iplocate.BatchError
+	require.True(t, errors.As(err, &batchErr))
+	assert.Contains(t, batchErr.Errors, "192.0.2.1")
+}
+
+func TestPrewarm_InvalidJSON(t *testing.T) {
+	client := iplocate.NewClient(nil).WithCache(iplocate.NewMemoryCache(100), time.Hour)
+
+	_, err := Prewarm(context.Background(), client, strings.NewReader("{not json\n"))
+	assert.ErrorContains(t, err, "line 1")
+}
+
+func TestPrewarm_NoCache(t *testing.T) {
+	_, err := Prewarm(context.Background(), iplocate.NewClient(nil), strings.NewReader("8.8.8.8\n"))
+	assert.ErrorIs(t, err, iplocate.ErrNoCache)
+}
@@ -0,0 +1,213 @@
+// Package sqlitecache provides an iplocate.Cache stored in a SQLite
+// database. Besides the raw cached value, each row holds the looked up IP
+// address, its country and ASN and the full response as JSON, so the cached
+// lookups can be queried with SQL. There is one row per cache key, holding
+// its latest lookup, so this isn't a log of every lookup made:
+//
+//	SELECT country_code, COUNT(*) FROM lookups
+//	WHERE stored_at >= datetime('now', '-7 days')
+//	GROUP BY country_code
+package sqlitecache
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/iplocate/go-iplocate"
+	_ "modernc.org/sqlite" // registers the "sqlite" driver
+)
+
+// schema creates the lookups table. stored_at uses the format of SQLite's
+// datetime() so it can be compared with date functions; expires_at is in
+// Unix nanoseconds, or NULL for entries that don't expire.
+const schema = `
+CREATE TABLE IF NOT EXISTS lookups (
+	key          TEXT PRIMARY KEY,
+	value        BLOB NOT NULL,
+	ip           TEXT,
+	country_code TEXT,
+	asn          TEXT,
+	response     TEXT,
+	stored_at    TEXT NOT NULL,
+	expires_at   INTEGER
+);
+CREATE INDEX IF NOT EXISTS lookups_ip ON lookups (ip);
+CREATE INDEX IF NOT EXISTS lookups_stored_at ON lookups (stored_at);
+`
+
+// timeFormat matches the output of SQLite's datetime()
+const timeFormat = "2006-01-02 15:04:05"
+
+var _ iplocate.Cache = (*Cache)(nil)
+
+// Cache is an iplocate.Cache backed by a SQLite table named lookups.
+// Expired rows are not returned, but kept until Compact is called. It is
+// safe for concurrent use.
+type Cache struct {
+	db    *sql.DB
+	owned bool
+	now   func() time.Time
+
+	// SQLite allows one writer at a time
+	mu sync.Mutex
+}
+
+// Open opens or creates the SQLite database file at path, in WAL mode so
+// lookups can be read while others are written
+func Open(path string) (*Cache, error) {
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cache: %w", err)
+	}
+	c, err := New(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	c.owned = true
+	return c, nil
+}
+
+// New creates a cache in an already opened SQLite database, creating the
+// lookups table if needed. Close does not close db.
+func New(db *sql.DB) (*Cache, error) {
+	if _, err := db.Exec(schema); err != nil {
+		return nil, fmt.Errorf("failed to create cache schema: %w", err)
+	}
+	return &Cache{db: db, now: time.Now}, nil
+}
+
+// DB returns the database, e.g. to run queries over the lookups table
+func (c *Cache) DB() *sql.DB {
+	return c.db
+}
+
+// Get returns the value stored for key. Errors are treated as misses.
+func (c *Cache) Get(key string) ([]byte, bool) {
+	var value []byte
+	err := c.db.QueryRow(
+		`SELECT value FROM lookups WHERE key = ? AND (expires_at IS NULL OR expires_at > ?)`,
+		key, c.now().UnixNano(),
+	).Scan(&value)
+	if err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// Set stores value for key. A ttl of zero means the value doesn't expire.
+// Values written by the client are decoded to fill in the ip, country_code,
+// asn and response columns. Errors are ignored, as for a cache miss.
+func (c *Cache) Set(key string, value []byte, ttl time.Duration) {
+	now := c.now()
+	var expires sql.NullInt64
+	if ttl > 0 {
+		expires = sql.NullInt64{Int64: now.Add(ttl).UnixNano(), Valid: true}
+	}
+
+	var ip, countryCode, asn, response sql.NullString
+	if entry, err := iplocate.DecodeCacheEntry(value); err == nil && entry.Response != nil {
+		ip = nullString(entry.Response.IP)
+		if entry.Response.CountryCode != nil {
+			countryCode = nullString(*entry.Response.CountryCode)
+		}
+		if entry.Response.ASN != nil {
+			asn = nullString(entry.Response.ASN.ASN)
+		}
+		if data, err := json.Marshal(entry.Response); err == nil {
+			response = nullString(string(data))
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.db.Exec(
+		`INSERT INTO lookups (key, value, ip, country_code, asn, response, stored_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET
+			value = excluded.value,
+			ip = excluded.ip,
+			country_code = excluded.country_code,
+			asn = excluded.asn,
+			response = excluded.response,
+			stored_at = excluded.stored_at,
+			expires_at = excluded.expires_at`,
+		key, value, ip, countryCode, asn, response, now.UTC().Format(timeFormat), expires,
+	)
+}
+
+// Delete removes key
+func (c *Cache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.db.Exec(`DELETE FROM lookups WHERE key = ?`, key)
+}
+
+// Range calls fn for each row that has not expired, in key order, until fn
+// returns false
+func (c *Cache) Range(fn func(key string, value []byte) bool) error {
+	rows, err := c.db.Query(
+		`SELECT key, value FROM lookups WHERE expires_at IS NULL OR expires_at > ? ORDER BY key`,
+		c.now().UnixNano(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to read cache: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key string
+		var value []byte
+		if err := rows.Scan(&key, &value); err != nil {
+			return fmt.Errorf("failed to read cache: %w", err)
+		}
+		if !fn(key, value) {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read cache: %w", err)
+	}
+	return nil
+}
+
+// Compact deletes expired rows and returns how many were deleted
+func (c *Cache) Compact() (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result, err := c.db.Exec(`DELETE FROM lookups WHERE expires_at IS NOT NULL AND expires_at <= ?`, c.now().UnixNano())
+	if err != nil {
+		return 0, fmt.Errorf("failed to compact cache: %w", err)
+	}
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to compact cache: %w", err)
+	}
+	return int(removed), nil
+}
+
+// Flush checkpoints the write-ahead log into the database file
+func (c *Cache) Flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := c.db.Exec(`PRAGMA wal_checkpoint(TRUNCATE)`); err != nil {
+		return fmt.Errorf("failed to flush cache: %w", err)
+	}
+	return nil
+}
+
+// Close closes the database if it was opened by Open
+func (c *Cache) Close() error {
+	if !c.owned {
+		return nil
+	}
+	return c.db.Close()
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,172 @@
+package sqlitecache
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/iplocate/go-iplocate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openTestCache(t *testing.T) *Cache {
+	t.Helper()
+	c, err := Open(filepath.Join(t.TempDir(), "cache.sqlite"))
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestCache_GetSetDelete(t *testing.T) {
+	c := openTestCache(t)
+
+	_, ok := c.Get("missing")
+	assert.False(t, ok)
+
+	c.Set("a", []byte("1"), 0)
+	c.Set("a", []byte("2"), 0)
+	value, ok := c.Get("a")
+	require.True(t, ok)
+	assert.Equal(t, "2", string(value))
+
+	c.Delete("a")
+	_, ok = c.Get("a")
+	assert.False(t, ok)
+}
+
+func TestCache_Range(t *testing.T) {
+	c := openTestCache(t)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	c.Set("b", []byte("2"), 0)
+	c.Set("a", []byte("1"), time.Minute)
+	c.Set("expired", []byte("3"), time.Second)
+	now = now.Add(2 * time.Second)
+
+	var keys, values []string
+	require.NoError(t, c.Range(func(key string, value []byte) bool {
+		keys = append(keys, key)
+		values = append(values, string(value))
+		return true
+	}))
+	assert.Equal(t, []string{"a", "b"}, keys)
+	assert.Equal(t, []string{"1", "2"}, values)
+
+	calls := 0
+	require.NoError(t, c.Range(func(key string, value []byte) bool {
+		calls++
+		return false
+	}))
+	assert.Equal(t, 1, calls)
+}
+
+func TestCache_TTLAndCompact(t *testing.T) {
+	c := openTestCache(t)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	c.Set("short", []byte("1"), time.Minute)
+	c.Set("forever", []byte("2"), 0)
+
+	now = now.Add(2 * time.Minute)
+	_, ok := c.Get("short")
+	assert.False(t, ok)
+
+	var rows int
+	require.NoError(t, c.DB().QueryRow(`SELECT COUNT(*) FROM lookups`).Scan(&rows))
+	assert.Equal(t, 2, rows, "expired rows are kept until Compact")
+
+	removed, err := c.Compact()
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	_, ok = c.Get("forever")
+	assert.True(t, ok)
+}
+
+func TestCache_QueryLookups(t *testing.T) {
+	countries := map[string]string{"8.8.8.8": "US", "1.1.1.1": "AU", "9.9.9.9": "US"}
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		ip := strings.TrimPrefix(r.URL.Path, "/lookup/")
+		code := countries[ip]
+		json.NewEncoder(w).Encode(iplocate.LookupResponse{IP: ip, CountryCode: &code, ASN: &iplocate.ASN{ASN: "AS" + code}})
+	}))
+	defer server.Close()
+
+	c := openTestCache(t)
+	client := iplocate.NewClient(nil).WithBaseURL(server.URL).WithCache(c, time.Hour)
+	for ip := range countries {
+		_, err := client.Lookup(ip)
+		require.NoError(t, err)
+	}
+	_, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	rows, err := c.DB().Query(`SELECT country_code, COUNT(*) FROM lookups
+		WHERE stored_at >= datetime('now', '-7 days')
+		GROUP BY country_code ORDER BY country_code`)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var code string
+		var count int
+		require.NoError(t, rows.Scan(&code, &count))
+		counts[code] = count
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, map[string]int{"AU": 1, "US": 2}, counts)
+
+	var asn, ip string
+	require.NoError(t, c.DB().QueryRow(`SELECT asn, json_extract(response, '$.ip') FROM lookups WHERE ip = '1.1.1.1'`).Scan(&asn, &ip))
+	assert.Equal(t, "ASAU", asn)
+	assert.Equal(t, "1.1.1.1", ip)
+}
+
+func TestCache_Concurrent(t *testing.T) {
+	c := openTestCache(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := string(rune('a' + i))
+			for j := 0; j < 20; j++ {
+				c.Set(key, []byte("value"), time.Hour)
+				c.Get(key)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	var rows int
+	require.NoError(t, c.DB().QueryRow(`SELECT COUNT(*) FROM lookups`).Scan(&rows))
+	assert.Equal(t, 8, rows)
+}
+
+func TestCache_Flush(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.sqlite")
+	c, err := Open(path)
+	require.NoError(t, err)
+	defer c.Close()
+
+	c.Set("a", []byte("1"), 0)
+	require.NoError(t, c.Flush())
+
+	wal, err := os.Stat(path + "-wal")
+	require.NoError(t, err)
+	assert.Zero(t, wal.Size(), "the write-ahead log is checkpointed")
+}
@@ -0,0 +1,93 @@
+package iplocate
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryCache_GetSet(t *testing.T) {
+	cache := NewMemoryCache(10)
+
+	_, ok := cache.Get("missing")
+	assert.False(t, ok)
+
+	cache.Set("key", []byte("value"), 0)
+	value, ok := cache.Get("key")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("value"), value)
+
+	cache.Set("key", []byte("updated"), 0)
+	value, _ = cache.Get("key")
+	assert.Equal(t, []byte("updated"), value)
+	assert.Equal(t, 1, cache.Len())
+
+	cache.Delete("key")
+	_, ok = cache.Get("key")
+	assert.False(t, ok)
+}
+
+func TestMemoryCache_Expiry(t *testing.T) {
+	now := time.Now()
+	cache := NewMemoryCache(10)
+	cache.now = func() time.Time { return now }
+
+	cache.Set("short", []byte("1"), time.Minute)
+	cache.Set("forever", []byte("2"), 0)
+
+	now = now.Add(time.Minute)
+	_, ok := cache.Get("short")
+	assert.False(t, ok)
+	_, ok = cache.Get("forever")
+	assert.True(t, ok)
+	assert.Equal(t, 1, cache.Len())
+}
+
+func TestMemoryCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewMemoryCache(3)
+	for i := 0; i < 3; i++ {
+		cache.Set(fmt.Sprint(i), []byte{byte(i)}, 0)
+	}
+
+	// Touch "0" so "1" becomes the least recently used
+	cache.Get("0")
+	cache.Set("3", []byte{3}, 0)
+
+	_, ok := cache.Get("1")
+	assert.False(t, ok)
+	for _, key := range []string{"0", "2", "3"} {
+		_, ok := cache.Get(key)
+		assert.True(t, ok, key)
+	}
+}
+
+func TestNewMemoryCache_DefaultSize(t *testing.T) {
+	assert.Equal(t, DefaultMemoryCacheSize, NewMemoryCache(0).maxEntries)
+}
+
+func TestMemoryCache_Range(t *testing.T) {
+	now := time.Now()
+	cache := NewMemoryCache(10)
+	cache.now = func() time.Time { return now }
+
+	cache.Set("a", []byte("1"), 0)
+	cache.Set("b", []byte("2"), time.Minute)
+	cache.Set("expired", []byte("3"), time.Second)
+	now = now.Add(2 * time.Second)
+
+	got := map[string]string{}
+	assert.NoError(t, cache.Range(func(key string, value []byte) bool {
+		got[key] = string(value)
+		return true
+	}))
+	assert.Equal(t, map[string]string{"a": "1", "b": "2"}, got)
+
+	calls := 0
+	cache.Range(func(key string, value []byte) bool {
+		calls++
+		return false
+	})
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,179 @@
+package iplocate
+
+import (
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Category groups the fields of a LookupResponse that change at a similar
+// pace, so they can be cached for different durations
+type Category string
+
+const (
+	// CategoryGeo is the location data: country, city, coordinates, time
+	// zone, currency and calling code
+	CategoryGeo Category = "geo"
+	// CategoryASN is the network data: ASN, network, company, hosting
+	// provider and abuse contact
+	CategoryASN Category = "asn"
+	// CategoryPrivacy is the threat data in Privacy, such as VPN, proxy and
+	// Tor flags
+	CategoryPrivacy Category = "privacy"
+)
+
+// categories lists every category in a fixed order
+var categories = []Category{CategoryGeo, CategoryASN, CategoryPrivacy}
+
+// categoryFields maps each category to the API fields it covers
+var categoryFields = map[Category][]string{
+	CategoryGeo: {
+		"country", "country_code", "is_eu", "city", "continent", "latitude", "longitude",
+		"time_zone", "postal_code", "subdivision", "currency_code", "calling_code",
+	},
+	CategoryASN:     {"network", "asn", "company", "hosting", "abuse"},
+	CategoryPrivacy: {"privacy"},
+}
+
+// WithCategoryTTL caches the fields of category for ttl instead of the TTL
+// given to WithCache, e.g. to refresh threat flags hourly while keeping
+// country data for a week. When only some categories of a cached lookup
+// have expired, only their fields are requested from the API and merged
+// into the cached result. It requires a cache set with WithCache.
+func (c *Client) WithCategoryTTL(category Category, ttl time.Duration) *Client {
+	if c.categoryTTLs == nil {
+		c.categoryTTLs = make(map[Category]time.Duration)
+	}
+	c.categoryTTLs[category] = ttl
+	return c
+}
+
+// categoryTTL returns how long the fields of category are fresh
+func (c *Client) categoryTTL(category Category) time.Duration {
+	if ttl, ok := c.categoryTTLs[category]; ok {
+		return ttl
+	}
+	return c.cacheTTL
+}
+
+// longestTTL returns the longest time any category is fresh
+func (c *Client) longestTTL() time.Duration {
+	longest := c.cacheTTL
+	for _, ttl := range c.categoryTTLs {
+		if ttl > longest {
+			longest = ttl
+		}
+	}
+	return longest
+}
+
+// requestedCategories returns the categories covered by the fields of a
+// lookup query, or every category if it doesn't limit the fields
+func requestedCategories(query url.Values) []Category {
+	fields := query.Get("fields")
+	if fields == "" {
+		return categories
+	}
+
+	requested := make(map[Category]bool)
+	for _, field := range strings.Split(fields, ",") {
+		requested[fieldCategory(field)] = true
+	}
+	var result []Category
+	for _, category := range categories {
+		if requested[category] {
+			result = append(result, category)
+		}
+	}
+	return result
+}
+
+// fieldCategory returns the category of a top-level API field. Fields
+// outside any category, such as "ip", go with the location data.
+func fieldCategory(field string) Category {
+	for _, category := range categories {
+		for _, f := range categoryFields[category] {
+			if f == field {
+				return category
+			}
+		}
+	}
+	return CategoryGeo
+}
+
+// categoryAge returns how long ago the fields of category were fetched
+func (e *CacheEntry) categoryAge(category Category, now time.Time) time.Duration {
+	fetched := e.StoredAt
+	if refreshed, ok := e.Refreshed[category]; ok && refreshed.After(fetched) {
+		fetched = refreshed
+	}
+	return now.Sub(fetched)
+}
+
+// expiredCategories returns the categories of a lookup query whose cached
+// fields are older than their TTL plus grace
+func (c *Client) expiredCategories(entry *CacheEntry, query url.Values, now time.Time, grace time.Duration) []Category {
+	var expired []Category
+	for _, category := range requestedCategories(query) {
+		if entry.categoryAge(category, now) >= c.categoryTTL(category)+grace {
+			expired = append(expired, category)
+		}
+	}
+	return expired
+}
+
+// partialQuery narrows a lookup query to the fields of the given categories
+func partialQuery(query url.Values, refresh []Category) url.Values {
+	requested := query.Get("fields")
+	var fields []string
+	for _, category := range refresh {
+		for _, field := range categoryFields[category] {
+			if requested == "" || containsField(requested, field) {
+				fields = append(fields, field)
+			}
+		}
+	}
+
+	partial := url.Values{}
+	for k, v := range query {
+		partial[k] = v
+	}
+	partial.Set("fields", strings.Join(fields, ","))
+	return partial
+}
+
+func containsField(fields, field string) bool {
+	for _, f := range strings.Split(fields, ",") {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeCategory copies the fields of category from src to dst
+func mergeCategory(dst, src *LookupResponse, category Category) {
+	switch category {
+	case CategoryGeo:
+		dst.Country = src.Country
+		dst.CountryCode = src.CountryCode
+		dst.IsEU = src.IsEU
+		dst.City = src.City
+		dst.Continent = src.Continent
+		dst.Latitude = src.Latitude
+		dst.Longitude = src.Longitude
+		dst.TimeZone = src.TimeZone
+		dst.PostalCode = src.PostalCode
+		dst.Subdivision = src.Subdivision
+		dst.CurrencyCode = src.CurrencyCode
+		dst.CallingCode = src.CallingCode
+	case CategoryASN:
+		dst.Network = src.Network
+		dst.ASN = src.ASN
+		dst.Company = src.Company
+		dst.Hosting = src.Hosting
+		dst.Abuse = src.Abuse
+	case CategoryPrivacy:
+		dst.Privacy = src.Privacy
+	}
+}
@@ -0,0 +1,119 @@
+package iplocate
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// categoryServer records the fields parameter of each lookup and answers with
+// the requested fields of a response whose privacy flags change on every call
+func categoryServer(t *testing.T) (*httptest.Server, func() []string) {
+	var mu sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.URL.Query().Get("fields"))
+		n := len(requests)
+		mu.Unlock()
+
+		resp := map[string]interface{}{
+			"ip":           "8.8.8.8",
+			"country_code": "US",
+			"asn":          map[string]string{"asn": "AS15169"},
+			"privacy":      map[string]bool{"is_vpn": n%2 == 0},
+		}
+		if fields := r.URL.Query().Get("fields"); fields != "" {
+			for key := range resp {
+				if key != "ip" && !containsField(fields, key) {
+					delete(resp, key)
+				}
+			}
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), requests...)
+	}
+}
+
+func TestWithCategoryTTL_RefreshesExpiredCategories(t *testing.T) {
+	server, requests := categoryServer(t)
+
+	now := time.Now()
+	client := NewClient(nil).WithBaseURL(server.URL).
+		WithCache(NewMemoryCache(10), 24*time.Hour).
+		WithCategoryTTL(CategoryPrivacy, time.Hour)
+	client.now = func() time.Time { return now }
+
+	result, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.False(t, result.Privacy.IsVPN)
+
+	now = now.Add(30 * time.Minute)
+	_, err = client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Len(t, requests(), 1, "every category is fresh")
+
+	now = now.Add(time.Hour)
+	result, err = client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.True(t, result.Privacy.IsVPN, "privacy data is refreshed")
+	assert.Equal(t, "US", *result.CountryCode, "geo data is kept")
+	assert.Equal(t, "AS15169", result.ASN.ASN)
+	assert.Equal(t, []string{"", "privacy"}, requests())
+
+	now = now.Add(30 * time.Minute)
+	_, err = client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Len(t, requests(), 2, "the refreshed category is fresh again")
+
+	now = now.Add(24 * time.Hour)
+	_, err = client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "privacy", ""}, requests(), "a full lookup once every category expired")
+}
+
+func TestWithCategoryTTL_RespectsRequestedFields(t *testing.T) {
+	server, requests := categoryServer(t)
+
+	now := time.Now()
+	client := NewClient(nil).WithBaseURL(server.URL).
+		WithCache(NewMemoryCache(10), 24*time.Hour).
+		WithCategoryTTL(CategoryASN, time.Hour).
+		WithFields("country_code", "asn")
+	client.now = func() time.Time { return now }
+
+	_, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	now = now.Add(2 * time.Hour)
+	result, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "US", *result.CountryCode)
+	assert.Equal(t, []string{"country_code,asn", "asn"}, requests())
+}
+
+func TestRequestedCategories(t *testing.T) {
+	assert.Equal(t, categories, requestedCategories(url.Values{}))
+	assert.Equal(t, []Category{CategoryGeo, CategoryPrivacy}, requestedCategories(url.Values{"fields": {"privacy,country_code"}}))
+	assert.Equal(t, []Category{CategoryGeo}, requestedCategories(url.Values{"fields": {"ip"}}))
+}
+
+func TestPartialQuery(t *testing.T) {
+	query := partialQuery(url.Values{}, []Category{CategoryASN})
+	assert.Equal(t, strings.Join(categoryFields[CategoryASN], ","), query.Get("fields"))
+
+	query = partialQuery(url.Values{"fields": {"country_code,city,asn"}}, []Category{CategoryGeo})
+	assert.Equal(t, "country_code,city", query.Get("fields"))
+}
@@ -0,0 +1,29 @@
+// Package cbor encodes IPLocate lookup results as CBOR (RFC 8949), for
+// compact caching or event streaming. Fields use the names of the JSON
+// encoding and unset fields are left out.
+package cbor
+
+import (
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/iplocate/go-iplocate"
+)
+
+// Marshal encodes resp as CBOR
+func Marshal(resp *iplocate.LookupResponse) ([]byte, error) {
+	data, err := cbor.Marshal(resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode CBOR: %w", err)
+	}
+	return data, nil
+}
+
+// Unmarshal decodes a response encoded with Marshal
+func Unmarshal(data []byte) (*iplocate.LookupResponse, error) {
+	var resp iplocate.LookupResponse
+	if err := cbor.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode CBOR: %w", err)
+	}
+	return &resp, nil
+}
@@ -0,0 +1,141 @@
+package iplocate
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/netip"
+	"slices"
+)
+
+// DefaultCIDRSamples is how many addresses LookupCIDR samples from a network
+const DefaultCIDRSamples = 8
+
+// Samples sets how many addresses LookupCIDR looks up from the network,
+// DefaultCIDRSamples by default
+func Samples(n int) LookupOption {
+	return func(o *lookupOptions) {
+		o.samples = n
+	}
+}
+
+// CIDRResult summarizes the lookups of addresses sampled from a network
+type CIDRResult struct {
+	Prefix netip.Prefix
+	// Samples are the looked up addresses, from the first to the last
+	// address of the network
+	Samples []string
+	BatchResult
+	// Countries counts the successful lookups by country code
+	Countries map[string]int
+	// ASNs counts the successful lookups by ASN
+	ASNs map[string]int
+	// Homogeneous reports whether every successful lookup has the same
+	// country, ASN and privacy flags
+	Homogeneous bool
+}
+
+// LookupCIDR looks up addresses sampled evenly across a network, always
+// including its first and last address, and aggregates the results. Use it
+// to check whether a range about to go into a firewall rule belongs to a
+// single country and operator. Lookups of the samples run like
+// LookupBatch; when some fail, the result holds the others and the error
+// lists the failures.
+func (c *Client) LookupCIDR(ctx context.Context, cidr string, opts ...LookupOption) (*CIDRResult, error) {
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR: %s", cidr)
+	}
+	prefix = prefix.Masked()
+
+	n := c.lookupOptions(opts).samples
+	if n <= 0 {
+		n = DefaultCIDRSamples
+	}
+	samples := samplePrefix(prefix, n)
+
+	batch, err := c.LookupBatch(ctx, samples, opts...)
+	if batch == nil {
+		return nil, err
+	}
+
+	result := &CIDRResult{
+		Prefix:      prefix,
+		Samples:     samples,
+		BatchResult: *batch,
+		Countries:   make(map[string]int),
+		ASNs:        make(map[string]int),
+		Homogeneous: true,
+	}
+	var first *LookupResponse
+	for _, ip := range samples {
+		resp, ok := batch.Responses[ip]
+		if !ok {
+			continue
+		}
+		if code := stringOrEmpty(resp.CountryCode); code != "" {
+			result.Countries[code]++
+		}
+		if resp.ASN != nil && resp.ASN.ASN != "" {
+			result.ASNs[resp.ASN.ASN]++
+		}
+		if first == nil {
+			first = resp
+		} else if !sameOperator(first, resp) {
+			result.Homogeneous = false
+		}
+	}
+	return result, err
+}
+
+// sameOperator reports whether two lookups agree on country, ASN and privacy flags
+func sameOperator(a, b *LookupResponse) bool {
+	var asnA, asnB string
+	if a.ASN != nil {
+		asnA = a.ASN.ASN
+	}
+	if b.ASN != nil {
+		asnB = b.ASN.ASN
+	}
+	return stringOrEmpty(a.CountryCode) == stringOrEmpty(b.CountryCode) && asnA == asnB && a.Privacy == b.Privacy
+}
+
+// samplePrefix returns up to n addresses spread evenly over prefix,
+// including its first and last address
+func samplePrefix(prefix netip.Prefix, n int) []string {
+	first := prefix.Addr()
+	hostBits := first.BitLen() - prefix.Bits()
+
+	size := new(big.Int).Lsh(big.NewInt(1), uint(hostBits))
+	if size.Cmp(big.NewInt(int64(n))) < 0 {
+		n = int(size.Int64())
+	}
+	if n == 1 {
+		return []string{first.String()}
+	}
+
+	start := new(big.Int).SetBytes(first.AsSlice())
+	last := new(big.Int).Sub(size, big.NewInt(1))
+	steps := big.NewInt(int64(n - 1))
+
+	samples := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		// offset = last * i / (n - 1)
+		offset := new(big.Int).Mul(last, big.NewInt(int64(i)))
+		offset.Div(offset, steps)
+		samples = append(samples, addrFromInt(new(big.Int).Add(start, offset), first.Is4()).String())
+	}
+	return slices.Compact(samples)
+}
+
+// addrFromInt converts an integer back to an IPv4 or IPv6 address
+func addrFromInt(v *big.Int, is4 bool) netip.Addr {
+	if is4 {
+		var b [4]byte
+		v.FillBytes(b[:])
+		return netip.AddrFrom4(b)
+	}
+	var b [16]byte
+	v.FillBytes(b[:])
+	return netip.AddrFrom16(b)
+}
@@ -0,0 +1,92 @@
+package iplocate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSamplePrefix(t *testing.T) {
+	tests := []struct {
+		cidr string
+		n    int
+		want []string
+	}{
+		{"10.0.0.0/24", 3, []string{"10.0.0.0", "10.0.0.127", "10.0.0.255"}},
+		{"10.0.0.0/30", 8, []string{"10.0.0.0", "10.0.0.1", "10.0.0.2", "10.0.0.3"}},
+		{"10.0.0.7/32", 8, []string{"10.0.0.7"}},
+		{"2001:db8::/32", 2, []string{"2001:db8::", "2001:db8:ffff:ffff:ffff:ffff:ffff:ffff"}},
+		{"::/0", 3, []string{"::", "7fff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cidr, func(t *testing.T) {
+			assert.Equal(t, tt.want, samplePrefix(netip.MustParsePrefix(tt.cidr), tt.n))
+		})
+	}
+}
+
+// cidrServer answers lookups in 10.0.0.128/25 as DE/AS64501 and the rest as
+// US/AS64500, failing lookups of 10.1.0.255
+func cidrServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := strings.TrimPrefix(r.URL.Path, "/lookup/")
+		if ip == "10.1.0.255" {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error":"Internal error"}`))
+			return
+		}
+		resp := LookupResponse{IP: ip, CountryCode: stringPtr("US"), ASN: &ASN{ASN: "AS64500"}}
+		if addr := netip.MustParseAddr(ip); strings.HasPrefix(ip, "10.0.0.") && addr.As4()[3] >= 128 {
+			resp.CountryCode = stringPtr("DE")
+			resp.ASN = &ASN{ASN: "AS64501"}
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestLookupCIDR(t *testing.T) {
+	client := NewClient(nil).WithBaseURL(cidrServer(t).URL)
+
+	result, err := client.LookupCIDR(context.Background(), "10.0.0.0/26")
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.0/26", result.Prefix.String())
+	assert.Len(t, result.Samples, DefaultCIDRSamples)
+	assert.Equal(t, "10.0.0.0", result.Samples[0])
+	assert.Equal(t, "10.0.0.63", result.Samples[len(result.Samples)-1])
+	assert.True(t, result.Homogeneous)
+	assert.Equal(t, map[string]int{"US": DefaultCIDRSamples}, result.Countries)
+
+	result, err = client.LookupCIDR(context.Background(), "10.0.0.1/24", Samples(4))
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.0/24", result.Prefix.String(), "host bits are masked")
+	assert.Equal(t, []string{"10.0.0.0", "10.0.0.85", "10.0.0.170", "10.0.0.255"}, result.Samples)
+	assert.False(t, result.Homogeneous)
+	assert.Equal(t, map[string]int{"US": 2, "DE": 2}, result.Countries)
+	assert.Equal(t, map[string]int{"AS64500": 2, "AS64501": 2}, result.ASNs)
+}
+
+func TestLookupCIDR_PartialFailure(t *testing.T) {
+	client := NewClient(nil).WithBaseURL(cidrServer(t).URL)
+
+	result, err := client.LookupCIDR(context.Background(), "10.1.0.0/24", Samples(2))
+	var batchErr *BatchError
+	require.ErrorAs(t, err, &batchErr)
+	require.NotNil(t, result)
+	assert.Contains(t, result.Errors, "10.1.0.255")
+	assert.Contains(t, result.Responses, "10.1.0.0")
+	assert.True(t, result.Homogeneous)
+}
+
+func TestLookupCIDR_Invalid(t *testing.T) {
+	_, err := NewClient(nil).LookupCIDR(context.Background(), "10.0.0.0")
+	assert.EqualError(t, err, "invalid CIDR: 10.0.0.0")
+}
@@ -0,0 +1,196 @@
+package iplocate
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"strings"
+	"sync"
+)
+
+// cidrNode is a node of a binary radix tree of prefixes. The path from the
+// root to a node spells the prefix bits; terminal marks an added prefix.
+type cidrNode struct {
+	children [2]*cidrNode
+	terminal bool
+}
+
+// CIDRSet is a set of IP ranges with fast membership tests, for allowlists
+// and denylists checked on every request. Containment takes at most one
+// step per address bit, however many ranges the set holds. It is safe for
+// concurrent use.
+type CIDRSet struct {
+	mu   sync.RWMutex
+	v4   cidrNode
+	v6   cidrNode
+	size int
+}
+
+// NewCIDRSet creates a set of the given CIDR ranges and addresses
+func NewCIDRSet(cidrs ...string) (*CIDRSet, error) {
+	s := &CIDRSet{}
+	for _, cidr := range cidrs {
+		if err := s.Add(cidr); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// LoadCIDRSet reads a set from r, one CIDR range or address per line.
+// Blank lines and text after a "#" are ignored.
+func LoadCIDRSet(r io.Reader) (*CIDRSet, error) {
+	s := &CIDRSet{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		if err := s.Add(text); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read CIDR list: %w", err)
+	}
+	return s, nil
+}
+
+// LoadCIDRSetFile reads a set from the file at path, see LoadCIDRSet
+func LoadCIDRSetFile(path string) (*CIDRSet, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open CIDR list: %w", err)
+	}
+	defer f.Close()
+	return LoadCIDRSet(f)
+}
+
+// Add adds a CIDR range, or a single address
+func (s *CIDRSet) Add(cidr string) error {
+	if !strings.Contains(cidr, "/") {
+		addr, err := netip.ParseAddr(cidr)
+		if err != nil {
+			return fmt.Errorf("invalid IP address or CIDR: %q", cidr)
+		}
+		addr = addr.Unmap()
+		s.AddPrefix(netip.PrefixFrom(addr, addr.BitLen()))
+		return nil
+	}
+
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return fmt.Errorf("invalid IP address or CIDR: %q", cidr)
+	}
+	s.AddPrefix(prefix)
+	return nil
+}
+
+// AddPrefix adds a range. IPv4-mapped IPv6 ranges are added as IPv4 ranges.
+func (s *CIDRSet) AddPrefix(prefix netip.Prefix) {
+	prefix = normalizePrefix(prefix)
+	if !prefix.IsValid() {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	node := s.root(prefix.Addr())
+	bytes := prefix.Addr().AsSlice()
+	for i := 0; i < prefix.Bits(); i++ {
+		if node.terminal {
+			// A wider range already covers this one
+			return
+		}
+		bit := addrBit(bytes, i)
+		if node.children[bit] == nil {
+			node.children[bit] = &cidrNode{}
+		}
+		node = node.children[bit]
+	}
+	if !node.terminal {
+		// The ranges within this one are no longer needed
+		s.size -= node.count()
+		node.terminal = true
+		node.children = [2]*cidrNode{}
+		s.size++
+	}
+}
+
+// count returns the number of ranges in the subtree of n
+func (n *cidrNode) count() int {
+	if n == nil {
+		return 0
+	}
+	if n.terminal {
+		return 1
+	}
+	return n.children[0].count() + n.children[1].count()
+}
+
+// Contains reports whether ip is in any range of the set. Invalid
+// addresses are never contained.
+func (s *CIDRSet) Contains(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	return s.ContainsAddr(addr)
+}
+
+// ContainsAddr reports whether addr is in any range of the set
+func (s *CIDRSet) ContainsAddr(addr netip.Addr) bool {
+	if !addr.IsValid() {
+		return false
+	}
+	addr = addr.Unmap()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	node := s.root(addr)
+	bytes := addr.AsSlice()
+	for i := 0; node != nil; i++ {
+		if node.terminal {
+			return true
+		}
+		if i == addr.BitLen() {
+			return false
+		}
+		node = node.children[addrBit(bytes, i)]
+	}
+	return false
+}
+
+// Len returns the number of ranges in the set. Ranges within a wider range
+// of the set are not counted.
+func (s *CIDRSet) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.size
+}
+
+// root returns the tree for the address family of addr
+func (s *CIDRSet) root(addr netip.Addr) *cidrNode {
+	if addr.Is4() {
+		return &s.v4
+	}
+	return &s.v6
+}
+
+// addrBit returns bit i of an address, counting from the most significant bit
+func addrBit(bytes []byte, i int) int {
+	return int(bytes[i/8]>>(7-i%8)) & 1
+}
+
+// normalizePrefix masks prefix and turns IPv4-mapped IPv6 ranges into IPv4 ranges
+func normalizePrefix(prefix netip.Prefix) netip.Prefix {
+	prefix = prefix.Masked()
+	if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+		prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+	}
+	return prefix
+}
@@ -0,0 +1,87 @@
+package iplocate
+
+import (
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCIDRSet_Contains(t *testing.T) {
+	set, err := NewCIDRSet("10.0.0.0/8", "192.168.1.0/24", "203.0.113.7", "2001:db8::/32", "::ffff:100.64.0.0/106")
+	require.NoError(t, err)
+	assert.Equal(t, 5, set.Len())
+
+	for _, ip := range []string{"10.0.0.1", "10.255.255.255", "192.168.1.200", "203.0.113.7", "2001:db8::1", "::ffff:10.1.2.3", "100.64.0.1"} {
+		assert.True(t, set.Contains(ip), ip)
+	}
+	for _, ip := range []string{"11.0.0.1", "192.168.2.1", "203.0.113.8", "2001:db9::1", "8.8.8.8", "::", "invalid", ""} {
+		assert.False(t, set.Contains(ip), ip)
+	}
+	assert.False(t, set.ContainsAddr(netip.Addr{}))
+
+	_, err = NewCIDRSet("10.0.0.0/33")
+	assert.Error(t, err)
+	_, err = NewCIDRSet("office")
+	assert.Error(t, err)
+}
+
+func TestCIDRSet_Overlapping(t *testing.T) {
+	set, err := NewCIDRSet("10.1.0.0/16", "10.2.2.0/24", "10.1.2.0/24")
+	require.NoError(t, err)
+	assert.Equal(t, 2, set.Len())
+
+	require.NoError(t, set.Add("10.0.0.0/8"))
+	assert.Equal(t, 1, set.Len(), "a wider range replaces the ranges within it")
+	require.NoError(t, set.Add("10.9.0.0/16"))
+	assert.Equal(t, 1, set.Len(), "ranges within a wider range are not added")
+	require.NoError(t, set.Add("10.0.0.0/8"))
+	assert.Equal(t, 1, set.Len())
+	assert.True(t, set.Contains("10.1.2.3"))
+
+	all, err := NewCIDRSet("0.0.0.0/0")
+	require.NoError(t, err)
+	assert.True(t, all.Contains("8.8.8.8"))
+	assert.False(t, all.Contains("2001:db8::1"), "address families are separate")
+}
+
+func TestLoadCIDRSet(t *testing.T) {
+	list := `# office networks
+10.0.0.0/8
+192.168.1.0/24   # VPN
+
+203.0.113.7
+`
+	set, err := LoadCIDRSet(strings.NewReader(list))
+	require.NoError(t, err)
+	assert.Equal(t, 3, set.Len())
+	assert.True(t, set.Contains("192.168.1.1"))
+
+	_, err = LoadCIDRSet(strings.NewReader("10.0.0.0/8\nnot-a-cidr\n"))
+	assert.ErrorContains(t, err, "line 2")
+
+	path := filepath.Join(t.TempDir(), "allowlist.txt")
+	require.NoError(t, os.WriteFile(path, []byte(list), 0o600))
+	set, err = LoadCIDRSetFile(path)
+	require.NoError(t, err)
+	assert.True(t, set.Contains("203.0.113.7"))
+
+	_, err = LoadCIDRSetFile(filepath.Join(t.TempDir(), "missing.txt"))
+	assert.Error(t, err)
+}
+
+func BenchmarkCIDRSet_Contains(b *testing.B) {
+	set := &CIDRSet{}
+	for i := 0; i < 10000; i++ {
+		require.NoError(b, set.Add(fmt.Sprintf("%d.%d.%d.0/24", 1+i%200, (i/200)%256, i%256)))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		set.Contains("93.184.216.34")
+	}
+}
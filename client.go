@@ -3,13 +3,18 @@
 package iplocate
 
 import (
-	"encoding/json"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -18,32 +23,137 @@ const (
 	DefaultBaseURL = "https://iplocate.io/api"
 	// DefaultTimeout is the default HTTP request timeout
 	DefaultTimeout = 30 * time.Second
+	// MaxResponseSize is the largest response body the client reads by
+	// default; see WithMaxResponseBytes
+	MaxResponseSize = 1 << 20
 )
 
+// ErrResponseTooLarge is returned when a response body exceeds the client's
+// maximum response size
+var ErrResponseTooLarge = errors.New("response body too large")
+
 // Client represents an IPLocate API client
 type Client struct {
-	baseURL    string
-	apiKey     string
-	httpClient *http.Client
+	baseURL      string
+	apiKey       string
+	httpClient   *http.Client
+	ownTransport *http.Transport
+
+	keys          *keyRing
+	keyProvider   *providedKeys
+	endpoints     *endpointSet
+	retryPolicy   RetryPolicy
+	maxRetryAfter time.Duration
+	quota         *QuotaTracker
+	local         *localDatabases
+	fields        []string
+	language      string
+	privacyLevel  PrivacyLevel
+	ipHashKey     []byte
+	ipHashOnce    sync.Once
+	history       *History
+	rdapURL       string
+	rdns          *reverseDNS
+	static        *StaticProvider
+	dnsCache      *DNSCache
+	lookupHost    func(ctx context.Context, host string) ([]string, error)
+	ipVersion     IPVersion
+	userAgent     string
+	correlation   []correlationHeader
+	appInfo       []string
+
+	disableCompression bool
+	maxResponseBytes   int64
+	debug              *debugWriter
+	marshal            JSONMarshaler
+	unmarshal          JSONUnmarshaler
+
+	countries     *countryCache
+	countriesOnce sync.Once
+
+	batchConcurrency int
+	batchInterval    time.Duration
+
+	cache        Cache
+	cacheTTL     time.Duration
+	categoryTTLs map[Category]time.Duration
+	negativeTTL  time.Duration
+	maxStaleness time.Duration
+	now          func() time.Time
+	clock        Clock
+	rand         *lockedRand
+
+	sinks []EventSink
+
+	health    *healthMonitor
+	closeOnce sync.Once
 }
 
 // NewClient creates a new IPLocate client with the given HTTP client.
 // If httpClient is nil, a default client with 30 second timeout is used.
+// Otherwise the client works on a copy of httpClient, and options such as
+// WithTimeout or WithProxy leave httpClient and its transport unchanged.
 func NewClient(httpClient *http.Client) *Client {
 	if httpClient == nil {
 		httpClient = &http.Client{
 			Timeout: DefaultTimeout,
 		}
+	} else {
+		copied := *httpClient
+		httpClient = &copied
 	}
 	return &Client{
 		baseURL:    DefaultBaseURL,
 		httpClient: httpClient,
+		now:        time.Now,
+		clock:      systemClock{},
+	}
+}
+
+// Close releases the resources of the client so long-running daemons and
+// tests can shut down cleanly: it stops the health monitor started with
+// StartHealthMonitor, flushes caches that implement Flusher, closes the
+// local databases and closes idle connections. Caches set with WithCache
+// are not closed; they belong to the caller. Close waits for lookups that
+// are reading the local databases. Lookups after Close still work,
+// reopening what they need, but the health monitor is not restarted. Close
+// is safe to call more than once.
+func (c *Client) Close() error {
+	var errs []error
+	c.closeOnce.Do(func() {
+		if c.health != nil {
+			c.health.stop()
+		}
+	})
+
+	if flusher, ok := c.cache.(Flusher); ok {
+		if err := flusher.Flush(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to flush cache: %w", err))
+		}
+	}
+	if c.countries != nil {
+		if flusher, ok := c.countries.cache.(Flusher); ok {
+			if err := flusher.Flush(); err != nil {
+				errs = append(errs, fmt.Errorf("failed to flush country cache: %w", err))
+			}
+		}
+	}
+
+	if c.local != nil {
+		if err := c.local.close(); err != nil {
+			errs = append(errs, err)
+		}
 	}
+
+	c.httpClient.CloseIdleConnections()
+	return errors.Join(errs...)
 }
 
 // WithAPIKey sets the API key for authentication
 func (c *Client) WithAPIKey(apiKey string) *Client {
 	c.apiKey = apiKey
+	c.keys = nil
+	c.keyProvider = nil
 	return c
 }
 
@@ -53,162 +163,563 @@ func (c *Client) WithTimeout(timeout time.Duration) *Client {
 	return c
 }
 
+// WithMaxResponseBytes caps the size of response bodies the client reads,
+// after decompression. Larger responses fail with ErrResponseTooLarge
+// instead of being buffered, which protects memory when the base URL points
+// at something that isn't the API. n <= 0 restores the default of
+// MaxResponseSize.
+func (c *Client) WithMaxResponseBytes(n int64) *Client {
+	c.maxResponseBytes = n
+	return c
+}
+
+// maxResponseSize returns the largest response body the client reads
+func (c *Client) maxResponseSize() int64 {
+	if c.maxResponseBytes > 0 {
+		return c.maxResponseBytes
+	}
+	return MaxResponseSize
+}
+
 // WithBaseURL sets a custom base URL for the API
 func (c *Client) WithBaseURL(baseURL string) *Client {
 	c.baseURL = strings.TrimSuffix(baseURL, "/")
+	c.endpoints = nil
+	return c
+}
+
+// WithUserAgent replaces the User-Agent sent with every request
+func (c *Client) WithUserAgent(userAgent string) *Client {
+	c.userAgent = userAgent
+	return c
+}
+
+// WithAppInfo identifies the application using the client by appending
+// "name/version" to the User-Agent, e.g. "go-iplocate/1.0.0 myapp/2.3.0".
+// It can be called more than once.
+func (c *Client) WithAppInfo(name, version string) *Client {
+	info := name
+	if version != "" {
+		info += "/" + version
+	}
+	c.appInfo = append(c.appInfo, info)
+	return c
+}
+
+// userAgentHeader returns the User-Agent including any application info
+func (c *Client) userAgentHeader() string {
+	userAgent := c.userAgent
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+	if len(c.appInfo) == 0 {
+		return userAgent
+	}
+	return userAgent + " " + strings.Join(c.appInfo, " ")
+}
+
+// WithAutoRetryAfter makes the client honor the Retry-After header of a 429
+// response by waiting and retrying the request once. Up to
+// DefaultRetryJitter of the wait is randomly added to it. The request is only
+// retried if the requested wait is at most maxWait and fits within the
+// context deadline. A maxWait of zero disables retrying.
+func (c *Client) WithAutoRetryAfter(maxWait time.Duration) *Client {
+	c.maxRetryAfter = maxWait
 	return c
 }
 
 // LookupResponse represents the complete response from the IPLocate API
+//
+//easyjson:json
 type LookupResponse struct {
-	IP           string   `json:"ip"`
-	Country      *string  `json:"country"`
-	CountryCode  *string  `json:"country_code"`
-	IsEU         bool     `json:"is_eu"`
-	City         *string  `json:"city"`
-	Continent    *string  `json:"continent"`
-	Latitude     *float64 `json:"latitude"`
-	Longitude    *float64 `json:"longitude"`
-	TimeZone     *string  `json:"time_zone"`
-	PostalCode   *string  `json:"postal_code"`
-	Subdivision  *string  `json:"subdivision"`
-	CurrencyCode *string  `json:"currency_code"`
-	CallingCode  *string  `json:"calling_code"`
-	Network      *string  `json:"network"`
-	ASN          *ASN     `json:"asn"`
-	Privacy      Privacy  `json:"privacy"`
-	Company      *Company `json:"company"`
-	Hosting      *Hosting `json:"hosting"`
-	Abuse        *Abuse   `json:"abuse"`
+	IP string `json:"ip" msgpack:"ip,omitempty" cbor:"ip,omitempty"`
+	// Hostname is the reverse DNS name of the IP. The API doesn't return it;
+	// it is set by WithReverseDNS.
+	Hostname     *string  `json:"hostname,omitempty" msgpack:"hostname,omitempty" cbor:"hostname,omitempty"`
+	Country      *string  `json:"country" msgpack:"country,omitempty" cbor:"country,omitempty"`
+	CountryCode  *string  `json:"country_code" msgpack:"country_code,omitempty" cbor:"country_code,omitempty"`
+	IsEU         bool     `json:"is_eu" msgpack:"is_eu,omitempty" cbor:"is_eu,omitempty"`
+	City         *string  `json:"city" msgpack:"city,omitempty" cbor:"city,omitempty"`
+	Continent    *string  `json:"continent" msgpack:"continent,omitempty" cbor:"continent,omitempty"`
+	Latitude     *float64 `json:"latitude" msgpack:"latitude,omitempty" cbor:"latitude,omitempty"`
+	Longitude    *float64 `json:"longitude" msgpack:"longitude,omitempty" cbor:"longitude,omitempty"`
+	TimeZone     *string  `json:"time_zone" msgpack:"time_zone,omitempty" cbor:"time_zone,omitempty"`
+	PostalCode   *string  `json:"postal_code" msgpack:"postal_code,omitempty" cbor:"postal_code,omitempty"`
+	Subdivision  *string  `json:"subdivision" msgpack:"subdivision,omitempty" cbor:"subdivision,omitempty"`
+	CurrencyCode *string  `json:"currency_code" msgpack:"currency_code,omitempty" cbor:"currency_code,omitempty"`
+	CallingCode  *string  `json:"calling_code" msgpack:"calling_code,omitempty" cbor:"calling_code,omitempty"`
+	Network      *string  `json:"network" msgpack:"network,omitempty" cbor:"network,omitempty"`
+	ASN          *ASN     `json:"asn" msgpack:"asn,omitempty" cbor:"asn,omitempty"`
+	Privacy      Privacy  `json:"privacy" msgpack:"privacy,omitempty" cbor:"privacy,omitempty"`
+	Company      *Company `json:"company" msgpack:"company,omitempty" cbor:"company,omitempty"`
+	Hosting      *Hosting `json:"hosting" msgpack:"hosting,omitempty" cbor:"hosting,omitempty"`
+	Abuse        *Abuse   `json:"abuse" msgpack:"abuse,omitempty" cbor:"abuse,omitempty"`
 }
 
 // ASN represents Autonomous System Number information
 type ASN struct {
-	ASN         string `json:"asn"`
-	Route       string `json:"route"`
-	Netname     string `json:"netname"`
-	Name        string `json:"name"`
-	CountryCode string `json:"country_code"`
-	Domain      string `json:"domain"`
-	Type        string `json:"type"`
-	RIR         string `json:"rir"`
+	ASN         string `json:"asn" msgpack:"asn,omitempty" cbor:"asn,omitempty"`
+	Route       string `json:"route" msgpack:"route,omitempty" cbor:"route,omitempty"`
+	Netname     string `json:"netname" msgpack:"netname,omitempty" cbor:"netname,omitempty"`
+	Name        string `json:"name" msgpack:"name,omitempty" cbor:"name,omitempty"`
+	CountryCode string `json:"country_code" msgpack:"country_code,omitempty" cbor:"country_code,omitempty"`
+	Domain      string `json:"domain" msgpack:"domain,omitempty" cbor:"domain,omitempty"`
+	Type        string `json:"type" msgpack:"type,omitempty" cbor:"type,omitempty"`
+	RIR         string `json:"rir" msgpack:"rir,omitempty" cbor:"rir,omitempty"`
 }
 
 // Privacy represents privacy and threat detection information
 type Privacy struct {
-	IsAbuser      bool `json:"is_abuser"`
-	IsAnonymous   bool `json:"is_anonymous"`
-	IsBogon       bool `json:"is_bogon"`
-	IsHosting     bool `json:"is_hosting"`
-	IsIcloudRelay bool `json:"is_icloud_relay"`
-	IsProxy       bool `json:"is_proxy"`
-	IsTor         bool `json:"is_tor"`
-	IsVPN         bool `json:"is_vpn"`
+	IsAbuser      bool `json:"is_abuser" msgpack:"is_abuser,omitempty" cbor:"is_abuser,omitempty"`
+	IsAnonymous   bool `json:"is_anonymous" msgpack:"is_anonymous,omitempty" cbor:"is_anonymous,omitempty"`
+	IsBogon       bool `json:"is_bogon" msgpack:"is_bogon,omitempty" cbor:"is_bogon,omitempty"`
+	IsHosting     bool `json:"is_hosting" msgpack:"is_hosting,omitempty" cbor:"is_hosting,omitempty"`
+	IsIcloudRelay bool `json:"is_icloud_relay" msgpack:"is_icloud_relay,omitempty" cbor:"is_icloud_relay,omitempty"`
+	IsProxy       bool `json:"is_proxy" msgpack:"is_proxy,omitempty" cbor:"is_proxy,omitempty"`
+	IsTor         bool `json:"is_tor" msgpack:"is_tor,omitempty" cbor:"is_tor,omitempty"`
+	IsVPN         bool `json:"is_vpn" msgpack:"is_vpn,omitempty" cbor:"is_vpn,omitempty"`
 }
 
 // Company represents company information associated with the IP
 type Company struct {
-	Name        string `json:"name"`
-	Domain      string `json:"domain"`
-	CountryCode string `json:"country_code"`
-	Type        string `json:"type"`
+	Name        string `json:"name" msgpack:"name,omitempty" cbor:"name,omitempty"`
+	Domain      string `json:"domain" msgpack:"domain,omitempty" cbor:"domain,omitempty"`
+	CountryCode string `json:"country_code" msgpack:"country_code,omitempty" cbor:"country_code,omitempty"`
+	Type        string `json:"type" msgpack:"type,omitempty" cbor:"type,omitempty"`
 }
 
 // Hosting represents hosting provider information
 type Hosting struct {
-	Provider *string `json:"provider"`
-	Domain   *string `json:"domain"`
-	Network  *string `json:"network"`
-	Region   *string `json:"region"`
-	Service  *string `json:"service"`
+	Provider *string `json:"provider" msgpack:"provider,omitempty" cbor:"provider,omitempty"`
+	Domain   *string `json:"domain" msgpack:"domain,omitempty" cbor:"domain,omitempty"`
+	Network  *string `json:"network" msgpack:"network,omitempty" cbor:"network,omitempty"`
+	Region   *string `json:"region" msgpack:"region,omitempty" cbor:"region,omitempty"`
+	Service  *string `json:"service" msgpack:"service,omitempty" cbor:"service,omitempty"`
 }
 
 // Abuse represents abuse contact information
 type Abuse struct {
-	Address     *string `json:"address"`
-	CountryCode *string `json:"country_code"`
-	Email       *string `json:"email"`
-	Name        *string `json:"name"`
-	Network     *string `json:"network"`
-	Phone       *string `json:"phone"`
+	Address     *string `json:"address" msgpack:"address,omitempty" cbor:"address,omitempty"`
+	CountryCode *string `json:"country_code" msgpack:"country_code,omitempty" cbor:"country_code,omitempty"`
+	Email       *string `json:"email" msgpack:"email,omitempty" cbor:"email,omitempty"`
+	Name        *string `json:"name" msgpack:"name,omitempty" cbor:"name,omitempty"`
+	Network     *string `json:"network" msgpack:"network,omitempty" cbor:"network,omitempty"`
+	Phone       *string `json:"phone" msgpack:"phone,omitempty" cbor:"phone,omitempty"`
 }
 
 // APIError represents an error response from the IPLocate API
+//
+//easyjson:json
 type APIError struct {
-	Message    string `json:"error"`
-	StatusCode int    `json:"-"`
+	Message string `json:"error"`
+	// Code is the machine-readable error code, if the API returned one
+	Code       ErrorCode `json:"code,omitempty"`
+	StatusCode int       `json:"-"`
+	// RetryAfter is the wait requested by the Retry-After header, if any
+	RetryAfter time.Duration `json:"-"`
+	// RequestID identifies the failed request in the API's logs, if the
+	// response carried one; include it in support tickets
+	RequestID string `json:"-"`
+	// ContentType is the Content-Type of the error response
+	ContentType string `json:"-"`
+	// Body is the raw error response, for debugging. Message is sanitized
+	// to be safe to log: it holds the text of the body, truncated and with
+	// HTML, control characters and API keys removed. Body is not, so don't
+	// log it as is.
+	Body []byte `json:"-"`
+
+	// unparsed is set when the body wasn't a JSON error from the API
+	unparsed bool
 }
 
 func (e *APIError) Error() string {
-	return fmt.Sprintf("IPLocate API error (%d): %s", e.StatusCode, e.Message)
+	message := e.Message
+	if e.unparsed {
+		message = fmt.Sprintf("unexpected %s response: %s", e.contentType(), e.Message)
+	}
+	if e.RequestID != "" {
+		return fmt.Sprintf("IPLocate API error (%d): %s (request ID %s)", e.StatusCode, message, e.RequestID)
+	}
+	return fmt.Sprintf("IPLocate API error (%d): %s", e.StatusCode, message)
+}
+
+// contentType returns the media type of the error response, without parameters
+func (e *APIError) contentType() string {
+	mediaType, _, err := mime.ParseMediaType(e.ContentType)
+	if err != nil {
+		return "non-JSON"
+	}
+	return mediaType
 }
 
-// Lookup returns geolocation and threat intelligence data for the specified IP address
+// Lookup returns geolocation and threat intelligence data for the specified
+// IP address, in any of the forms accepted by ParseFlexibleIP
 func (c *Client) Lookup(ip string) (*LookupResponse, error) {
+	return c.LookupContext(context.Background(), ip)
+}
+
+// LookupContext is like Lookup but uses ctx for the request and accepts
+// per-request options
+func (c *Client) LookupContext(ctx context.Context, ip string, opts ...LookupOption) (*LookupResponse, error) {
+	result, _, err := c.lookup(ctx, ip, opts, nil)
+	return result, err
+}
+
+// lookup normalizes and validates ip and looks it up in the local databases in offline mode,
+// or through the cache and the API otherwise. The result is stored in dst if
+// it is not nil. stale reports a cached result served because the API failed.
+// The outcome is reported to the event sinks.
+func (c *Client) lookup(ctx context.Context, ip string, opts []LookupOption, dst *LookupResponse) (result *LookupResponse, stale bool, err error) {
+	if normalized, ok := normalizeIP(ip); ok {
+		ip = normalized
+	}
+
+	var hostname func() *string
+	if c.rdns != nil && net.ParseIP(ip) != nil {
+		hostname = c.rdns.start(ctx, ip)
+	}
+
+	start := c.now()
+	result, stale, err = c.lookupIP(ctx, ip, opts, dst)
+	if hostname != nil && result != nil {
+		result.Hostname = hostname()
+	}
+	c.minimize(result)
+	if len(c.sinks) == 0 {
+		return result, stale, err
+	}
+	c.emit(ctx, LookupEvent{Time: start, IP: ip, Response: result, Err: err, Duration: c.now().Sub(start), Stale: stale})
+	return result, stale, err
+}
+
+// lookupIP does the work of lookup without reporting to the event sinks
+func (c *Client) lookupIP(ctx context.Context, ip string, opts []LookupOption, dst *LookupResponse) (*LookupResponse, bool, error) {
 	// Validate IP address format
 	if parsedIP := net.ParseIP(ip); parsedIP == nil {
-		return nil, fmt.Errorf("invalid IP address: %s", ip)
+		return nil, false, fmt.Errorf("invalid IP address: %s", ip)
+	}
+
+	if c.static != nil {
+		if result, ok := c.static.Match(ip); ok {
+			return into(dst, result), false, nil
+		}
+	}
+
+	if c.offline() {
+		result, err := c.local.lookup(ip)
+		if err != nil {
+			return nil, false, err
+		}
+		return into(dst, result), false, nil
 	}
 
-	endpoint := fmt.Sprintf("%s/lookup/%s", c.baseURL, url.PathEscape(ip))
-	return c.doRequest(endpoint)
+	return c.lookupCached(ctx, ip, c.lookupQuery(opts), dst)
 }
 
 // LookupSelf returns geolocation and threat intelligence data for the client's current IP address
 func (c *Client) LookupSelf() (*LookupResponse, error) {
-	endpoint := fmt.Sprintf("%s/lookup/", c.baseURL)
-	return c.doRequest(endpoint)
+	return c.LookupSelfContext(context.Background())
 }
 
-// doRequest performs the HTTP request to the IPLocate API
-func (c *Client) doRequest(endpoint string) (*LookupResponse, error) {
-	// Parse the endpoint URL to add query parameters
-	parsedURL, err := url.Parse(endpoint)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse endpoint URL: %w", err)
+// LookupSelfContext is like LookupSelf but uses ctx for the request and
+// accepts per-request options
+func (c *Client) LookupSelfContext(ctx context.Context, opts ...LookupOption) (*LookupResponse, error) {
+	var result LookupResponse
+	if _, err := c.doRequest(ctx, apiRequest{path: "/lookup/", query: c.lookupQuery(opts)}, &result); err != nil {
+		return nil, err
+	}
+	c.minimize(&result)
+	return &result, nil
+}
+
+// apiRequest describes a request to the IPLocate API
+type apiRequest struct {
+	// path is relative to the base URL
+	path   string
+	query  url.Values
+	header http.Header
+}
+
+// doRequest performs the HTTP request to the IPLocate API and decodes the
+// JSON response into out. The returned response's body has been consumed.
+// A 304 Not Modified response is returned without decoding.
+func (c *Client) doRequest(ctx context.Context, r apiRequest, out interface{}) (*http.Response, error) {
+	retriedAfter := false
+	retried := false
+	rotations := 0
+	var tried []int
+	for {
+		baseURL, endpointIndex := c.pickEndpoint(tried)
+
+		// Parse the endpoint URL to add query parameters
+		parsedURL, err := url.Parse(baseURL + r.path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse endpoint URL: %w", err)
+		}
+
+		key, keyIndex, err := c.currentKey(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if c.quota != nil {
+			if err := c.quota.Acquire(); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := c.send(ctx, requestURL(parsedURL, r.query, key), r.header)
+
+		// Fail over to the next endpoint on network errors and server errors
+		if c.endpointFailed(ctx, endpointIndex, resp, err) {
+			tried = append(tried, endpointIndex)
+			if len(tried) < len(c.endpoints.urls) {
+				if resp != nil {
+					discard(resp)
+				}
+				continue
+			}
+		}
+		if !retried && c.retrySingle(ctx, resp, err) {
+			if resp != nil {
+				discard(resp)
+			}
+			if err := c.sleep(ctx, c.jitter(retryDelay)); err != nil {
+				return nil, err
+			}
+			retried = true
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// Switch to the next key when this one is rejected or out of quota
+		if c.rotateKey(keyIndex, resp.StatusCode, rotations) {
+			discard(resp)
+			rotations++
+			continue
+		}
+
+		// Honor Retry-After once when rate limited
+		if resp.StatusCode == http.StatusTooManyRequests && !retriedAfter {
+			if wait, ok := c.retryAfterWait(ctx, resp.Header); ok {
+				discard(resp)
+				if err := c.sleep(ctx, wait); err != nil {
+					return nil, err
+				}
+				retriedAfter = true
+				continue
+			}
+		}
+
+		return resp, c.parseResponse(resp, out)
 	}
+}
 
-	// Add API key as query parameter if provided
-	if c.apiKey != "" {
-		query := parsedURL.Query()
-		query.Set("apikey", c.apiKey)
-		parsedURL.RawQuery = query.Encode()
+// requestURL returns the URL with the query parameters and the API key, if provided
+func requestURL(u *url.URL, params url.Values, apiKey string) string {
+	if len(params) == 0 && apiKey == "" {
+		return u.String()
 	}
+	withQuery := *u
+	query := withQuery.Query()
+	for name, values := range params {
+		query[name] = values
+	}
+	if apiKey != "" {
+		query.Set("apikey", apiKey)
+	}
+	withQuery.RawQuery = query.Encode()
+	return withQuery.String()
+}
 
-	req, err := http.NewRequest("GET", parsedURL.String(), nil)
+// send performs a single GET request. The returned response's body is
+// decompressed and capped at the maximum response size; the caller must
+// close it.
+func (c *Client) send(ctx context.Context, rawURL string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("User-Agent", "go-iplocate/1.0.0")
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	c.setCorrelationHeaders(req)
+	req.Header.Set("User-Agent", c.userAgentHeader())
 	req.Header.Set("Accept", "application/json")
+	if !c.disableCompression {
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
 
+	c.dumpRequest(req)
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
+		err = redactURLError(err)
+		c.dumpError(err)
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	limit := c.maxResponseSize()
+	if resp.ContentLength > limit {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to read response body: %w", ErrResponseTooLarge)
+	}
+
+	reader, err := decodedBody(resp)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		resp.Body.Close()
+		return nil, err
+	}
+	resp.Body = &responseBody{
+		Reader:  &cappedReader{r: reader, n: limit},
+		closers: []io.Closer{reader, resp.Body},
+	}
+
+	if c.debug != nil {
+		// Buffer the body so it can be dumped and still be decoded
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		c.dumpResponse(resp, body)
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	return resp, nil
+}
+
+// discard drains and closes a response body so the connection can be reused
+func discard(resp *http.Response) {
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+}
+
+// parseResponse decodes a successful response into out, or returns the API
+// error it carries. It consumes and closes the response body.
+func (c *Client) parseResponse(resp *http.Response, out interface{}) error {
+	defer discard(resp)
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil
 	}
 
 	// Handle non-200 status codes
 	if resp.StatusCode != http.StatusOK {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read response body: %w", err)
+		}
+
 		var apiErr APIError
-		if err := json.Unmarshal(body, &apiErr); err != nil {
-			// If we can't parse the error response, return the raw body
-			return nil, fmt.Errorf("API request failed (%d): %s", resp.StatusCode, string(body))
+		if err := c.unmarshalJSON(body, &apiErr); err != nil {
+			// Not a JSON error, e.g. an HTML page from a proxy or load balancer
+			apiErr = APIError{Message: errorSnippet(body, resp.StatusCode, c.allKeys()), unparsed: true}
+		} else {
+			apiErr.Message = sanitizeErrorMessage(apiErr.Message, c.allKeys())
 		}
 		apiErr.StatusCode = resp.StatusCode
-		return nil, &apiErr
+		apiErr.ContentType = resp.Header.Get("Content-Type")
+		apiErr.Body = body
+		apiErr.RetryAfter, _ = parseRetryAfter(resp.Header.Get("Retry-After"), c.now())
+		apiErr.RequestID = responseRequestID(resp.Header)
+		return &apiErr
 	}
 
-	var result LookupResponse
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	if err := c.decodeJSON(resp.Body, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
 	}
 
-	return &result, nil
+	return nil
+}
+
+// responseBody reads a decoded response body and closes every reader
+// wrapped around the original body
+type responseBody struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (b *responseBody) Close() error {
+	var first error
+	for _, closer := range b.closers {
+		if err := closer.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// cappedReader reads at most n bytes and fails with ErrResponseTooLarge if
+// the underlying reader holds more
+type cappedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *cappedReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		var probe [1]byte
+		n, err := l.r.Read(probe[:])
+		if n > 0 {
+			return 0, ErrResponseTooLarge
+		}
+		return 0, err
+	}
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	return n, err
+}
+
+// retryAfterWait returns how long to wait before retrying a rate limited
+// request, or false if the request should not be retried.
+func (c *Client) retryAfterWait(ctx context.Context, header http.Header) (time.Duration, bool) {
+	if c.maxRetryAfter <= 0 {
+		return 0, false
+	}
+
+	wait, ok := parseRetryAfter(header.Get("Retry-After"), c.now())
+	if !ok || wait > c.maxRetryAfter {
+		return 0, false
+	}
+	wait = c.jitter(wait)
+
+	// Don't start waiting if the context would expire first
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+		return 0, false
+	}
+
+	return wait, true
+}
+
+// parseRetryAfter parses a Retry-After header value given either in seconds
+// or as an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		wait := date.Sub(now)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+
+	return 0, false
 }
@@ -0,0 +1,987 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package iplocate
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjsonC0e5e3f1DecodeGithubComIplocateGoIplocate(in *jlexer.Lexer, out *LookupResponse) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "ip":
+			out.IP = string(in.String())
+		case "hostname":
+			if in.IsNull() {
+				in.Skip()
+				out.Hostname = nil
+			} else {
+				if out.Hostname == nil {
+					out.Hostname = new(string)
+				}
+				*out.Hostname = string(in.String())
+			}
+		case "country":
+			if in.IsNull() {
+				in.Skip()
+				out.Country = nil
+			} else {
+				if out.Country == nil {
+					out.Country = new(string)
+				}
+				*out.Country = string(in.String())
+			}
+		case "country_code":
+			if in.IsNull() {
+				in.Skip()
+				out.CountryCode = nil
+			} else {
+				if out.CountryCode == nil {
+					out.CountryCode = new(string)
+				}
+				*out.CountryCode = string(in.String())
+			}
+		case "is_eu":
+			out.IsEU = bool(in.Bool())
+		case "city":
+			if in.IsNull() {
+				in.Skip()
+				out.City = nil
+			} else {
+				if out.City == nil {
+					out.City = new(string)
+				}
+				*out.City = string(in.String())
+			}
+		case "continent":
+			if in.IsNull() {
+				in.Skip()
+				out.Continent = nil
+			} else {
+				if out.Continent == nil {
+					out.Continent = new(string)
+				}
+				*out.Continent = string(in.String())
+			}
+		case "latitude":
+			if in.IsNull() {
+				in.Skip()
+				out.Latitude = nil
+			} else {
+				if out.Latitude == nil {
+					out.Latitude = new(float64)
+				}
+				*out.Latitude = float64(in.Float64())
+			}
+		case "longitude":
+			if in.IsNull() {
+				in.Skip()
+				out.Longitude = nil
+			} else {
+				if out.Longitude == nil {
+					out.Longitude = new(float64)
+				}
+				*out.Longitude = float64(in.Float64())
+			}
+		case "time_zone":
+			if in.IsNull() {
+				in.Skip()
+				out.TimeZone = nil
+			} else {
+				if out.TimeZone == nil {
+					out.TimeZone = new(string)
+				}
+				*out.TimeZone = string(in.String())
+			}
+		case "postal_code":
+			if in.IsNull() {
+				in.Skip()
+				out.PostalCode = nil
+			} else {
+				if out.PostalCode == nil {
+					out.PostalCode = new(string)
+				}
+				*out.PostalCode = string(in.String())
+			}
+		case "subdivision":
+			if in.IsNull() {
+				in.Skip()
+				out.Subdivision = nil
+			} else {
+				if out.Subdivision == nil {
+					out.Subdivision = new(string)
+				}
+				*out.Subdivision = string(in.String())
+			}
+		case "currency_code":
+			if in.IsNull() {
+				in.Skip()
+				out.CurrencyCode = nil
+			} else {
+				if out.CurrencyCode == nil {
+					out.CurrencyCode = new(string)
+				}
+				*out.CurrencyCode = string(in.String())
+			}
+		case "calling_code":
+			if in.IsNull() {
+				in.Skip()
+				out.CallingCode = nil
+			} else {
+				if out.CallingCode == nil {
+					out.CallingCode = new(string)
+				}
+				*out.CallingCode = string(in.String())
+			}
+		case "network":
+			if in.IsNull() {
+				in.Skip()
+				out.Network = nil
+			} else {
+				if out.Network == nil {
+					out.Network = new(string)
+				}
+				*out.Network = string(in.String())
+			}
+		case "asn":
+			if in.IsNull() {
+				in.Skip()
+				out.ASN = nil
+			} else {
+				if out.ASN == nil {
+					out.ASN = new(ASN)
+				}
+				easyjsonC0e5e3f1DecodeGithubComIplocateGoIplocate1(in, out.ASN)
+			}
+		case "privacy":
+			easyjsonC0e5e3f1DecodeGithubComIplocateGoIplocate2(in, &out.Privacy)
+		case "company":
+			if in.IsNull() {
+				in.Skip()
+				out.Company = nil
+			} else {
+				if out.Company == nil {
+					out.Company = new(Company)
+				}
+				easyjsonC0e5e3f1DecodeGithubComIplocateGoIplocate3(in, out.Company)
+			}
+		case "hosting":
+			if in.IsNull() {
+				in.Skip()
+				out.Hosting = nil
+			} else {
+				if out.Hosting == nil {
+					out.Hosting = new(Hosting)
+				}
+				easyjsonC0e5e3f1DecodeGithubComIplocateGoIplocate4(in, out.Hosting)
+			}
+		case "abuse":
+			if in.IsNull() {
+				in.Skip()
+				out.Abuse = nil
+			} else {
+				if out.Abuse == nil {
+					out.Abuse = new(Abuse)
+				}
+				easyjsonC0e5e3f1DecodeGithubComIplocateGoIplocate5(in, out.Abuse)
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC0e5e3f1EncodeGithubComIplocateGoIplocate(out *jwriter.Writer, in LookupResponse) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"ip\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.IP))
+	}
+	if in.Hostname != nil {
+		const prefix string = ",\"hostname\":"
+		out.RawString(prefix)
+		out.String(string(*in.Hostname))
+	}
+	{
+		const prefix string = ",\"country\":"
+		out.RawString(prefix)
+		if in.Country == nil {
+			out.RawString("null")
+		} else {
+			out.String(string(*in.Country))
+		}
+	}
+	{
+		const prefix string = ",\"country_code\":"
+		out.RawString(prefix)
+		if in.CountryCode == nil {
+			out.RawString("null")
+		} else {
+			out.String(string(*in.CountryCode))
+		}
+	}
+	{
+		const prefix string = ",\"is_eu\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.IsEU))
+	}
+	{
+		const prefix string = ",\"city\":"
+		out.RawString(prefix)
+		if in.City == nil {
+			out.RawString("null")
+		} else {
+			out.String(string(*in.City))
+		}
+	}
+	{
+		const prefix string = ",\"continent\":"
+		out.RawString(prefix)
+		if in.Continent == nil {
+			out.RawString("null")
+		} else {
+			out.String(string(*in.Continent))
+		}
+	}
+	{
+		const prefix string = ",\"latitude\":"
+		out.RawString(prefix)
+		if in.Latitude == nil {
+			out.RawString("null")
+		} else {
+			out.Float64(float64(*in.Latitude))
+		}
+	}
+	{
+		const prefix string = ",\"longitude\":"
+		out.RawString(prefix)
+		if in.Longitude == nil {
+			out.RawString("null")
+		} else {
+			out.Float64(float64(*in.Longitude))
+		}
+	}
+	{
+		const prefix string = ",\"time_zone\":"
+		out.RawString(prefix)
+		if in.TimeZone == nil {
+			out.RawString("null")
+		} else {
+			out.String(string(*in.TimeZone))
+		}
+	}
+	{
+		const prefix string = ",\"postal_code\":"
+		out.RawString(prefix)
+		if in.PostalCode == nil {
+			out.RawString("null")
+		} else {
+			out.String(string(*in.PostalCode))
+		}
+	}
+	{
+		const prefix string = ",\"subdivision\":"
+		out.RawString(prefix)
+		if in.Subdivision == nil {
+			out.RawString("null")
+		} else {
+			out.String(string(*in.Subdivision))
+		}
+	}
+	{
+		const prefix string = ",\"currency_code\":"
+		out.RawString(prefix)
+		if in.CurrencyCode == nil {
+			out.RawString("null")
+		} else {
+			out.String(string(*in.CurrencyCode))
+		}
+	}
+	{
+		const prefix string = ",\"calling_code\":"
+		out.RawString(prefix)
+		if in.CallingCode == nil {
+			out.RawString("null")
+		} else {
+			out.String(string(*in.CallingCode))
+		}
+	}
+	{
+		const prefix string = ",\"network\":"
+		out.RawString(prefix)
+		if in.Network == nil {
+			out.RawString("null")
+		} else {
+			out.String(string(*in.Network))
+		}
+	}
+	{
+		const prefix string = ",\"asn\":"
+		out.RawString(prefix)
+		if in.ASN == nil {
+			out.RawString("null")
+		} else {
+			easyjsonC0e5e3f1EncodeGithubComIplocateGoIplocate1(out, *in.ASN)
+		}
+	}
+	{
+		const prefix string = ",\"privacy\":"
+		out.RawString(prefix)
+		easyjsonC0e5e3f1EncodeGithubComIplocateGoIplocate2(out, in.Privacy)
+	}
+	{
+		const prefix string = ",\"company\":"
+		out.RawString(prefix)
+		if in.Company == nil {
+			out.RawString("null")
+		} else {
+			easyjsonC0e5e3f1EncodeGithubComIplocateGoIplocate3(out, *in.Company)
+		}
+	}
+	{
+		const prefix string = ",\"hosting\":"
+		out.RawString(prefix)
+		if in.Hosting == nil {
+			out.RawString("null")
+		} else {
+			easyjsonC0e5e3f1EncodeGithubComIplocateGoIplocate4(out, *in.Hosting)
+		}
+	}
+	{
+		const prefix string = ",\"abuse\":"
+		out.RawString(prefix)
+		if in.Abuse == nil {
+			out.RawString("null")
+		} else {
+			easyjsonC0e5e3f1EncodeGithubComIplocateGoIplocate5(out, *in.Abuse)
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LookupResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC0e5e3f1EncodeGithubComIplocateGoIplocate(w, v)
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LookupResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC0e5e3f1DecodeGithubComIplocateGoIplocate(l, v)
+}
+func easyjsonC0e5e3f1DecodeGithubComIplocateGoIplocate5(in *jlexer.Lexer, out *Abuse) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "address":
+			if in.IsNull() {
+				in.Skip()
+				out.Address = nil
+			} else {
+				if out.Address == nil {
+					out.Address = new(string)
+				}
+				*out.Address = string(in.String())
+			}
+		case "country_code":
+			if in.IsNull() {
+				in.Skip()
+				out.CountryCode = nil
+			} else {
+				if out.CountryCode == nil {
+					out.CountryCode = new(string)
+				}
+				*out.CountryCode = string(in.String())
+			}
+		case "email":
+			if in.IsNull() {
+				in.Skip()
+				out.Email = nil
+			} else {
+				if out.Email == nil {
+					out.Email = new(string)
+				}
+				*out.Email = string(in.String())
+			}
+		case "name":
+			if in.IsNull() {
+				in.Skip()
+				out.Name = nil
+			} else {
+				if out.Name == nil {
+					out.Name = new(string)
+				}
+				*out.Name = string(in.String())
+			}
+		case "network":
+			if in.IsNull() {
+				in.Skip()
+				out.Network = nil
+			} else {
+				if out.Network == nil {
+					out.Network = new(string)
+				}
+				*out.Network = string(in.String())
+			}
+		case "phone":
+			if in.IsNull() {
+				in.Skip()
+				out.Phone = nil
+			} else {
+				if out.Phone == nil {
+					out.Phone = new(string)
+				}
+				*out.Phone = string(in.String())
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC0e5e3f1EncodeGithubComIplocateGoIplocate5(out *jwriter.Writer, in Abuse) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"address\":"
+		out.RawString(prefix[1:])
+		if in.Address == nil {
+			out.RawString("null")
+		} else {
+			out.String(string(*in.Address))
+		}
+	}
+	{
+		const prefix string = ",\"country_code\":"
+		out.RawString(prefix)
+		if in.CountryCode == nil {
+			out.RawString("null")
+		} else {
+			out.String(string(*in.CountryCode))
+		}
+	}
+	{
+		const prefix string = ",\"email\":"
+		out.RawString(prefix)
+		if in.Email == nil {
+			out.RawString("null")
+		} else {
+			out.String(string(*in.Email))
+		}
+	}
+	{
+		const prefix string = ",\"name\":"
+		out.RawString(prefix)
+		if in.Name == nil {
+			out.RawString("null")
+		} else {
+			out.String(string(*in.Name))
+		}
+	}
+	{
+		const prefix string = ",\"network\":"
+		out.RawString(prefix)
+		if in.Network == nil {
+			out.RawString("null")
+		} else {
+			out.String(string(*in.Network))
+		}
+	}
+	{
+		const prefix string = ",\"phone\":"
+		out.RawString(prefix)
+		if in.Phone == nil {
+			out.RawString("null")
+		} else {
+			out.String(string(*in.Phone))
+		}
+	}
+	out.RawByte('}')
+}
+func easyjsonC0e5e3f1DecodeGithubComIplocateGoIplocate4(in *jlexer.Lexer, out *Hosting) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "provider":
+			if in.IsNull() {
+				in.Skip()
+				out.Provider = nil
+			} else {
+				if out.Provider == nil {
+					out.Provider = new(string)
+				}
+				*out.Provider = string(in.String())
+			}
+		case "domain":
+			if in.IsNull() {
+				in.Skip()
+				out.Domain = nil
+			} else {
+				if out.Domain == nil {
+					out.Domain = new(string)
+				}
+				*out.Domain = string(in.String())
+			}
+		case "network":
+			if in.IsNull() {
+				in.Skip()
+				out.Network = nil
+			} else {
+				if out.Network == nil {
+					out.Network = new(string)
+				}
+				*out.Network = string(in.String())
+			}
+		case "region":
+			if in.IsNull() {
+				in.Skip()
+				out.Region = nil
+			} else {
+				if out.Region == nil {
+					out.Region = new(string)
+				}
+				*out.Region = string(in.String())
+			}
+		case "service":
+			if in.IsNull() {
+				in.Skip()
+				out.Service = nil
+			} else {
+				if out.Service == nil {
+					out.Service = new(string)
+				}
+				*out.Service = string(in.String())
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC0e5e3f1EncodeGithubComIplocateGoIplocate4(out *jwriter.Writer, in Hosting) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"provider\":"
+		out.RawString(prefix[1:])
+		if in.Provider == nil {
+			out.RawString("null")
+		} else {
+			out.String(string(*in.Provider))
+		}
+	}
+	{
+		const prefix string = ",\"domain\":"
+		out.RawString(prefix)
+		if in.Domain == nil {
+			out.RawString("null")
+		} else {
+			out.String(string(*in.Domain))
+		}
+	}
+	{
+		const prefix string = ",\"network\":"
+		out.RawString(prefix)
+		if in.Network == nil {
+			out.RawString("null")
+		} else {
+			out.String(string(*in.Network))
+		}
+	}
+	{
+		const prefix string = ",\"region\":"
+		out.RawString(prefix)
+		if in.Region == nil {
+			out.RawString("null")
+		} else {
+			out.String(string(*in.Region))
+		}
+	}
+	{
+		const prefix string = ",\"service\":"
+		out.RawString(prefix)
+		if in.Service == nil {
+			out.RawString("null")
+		} else {
+			out.String(string(*in.Service))
+		}
+	}
+	out.RawByte('}')
+}
+func easyjsonC0e5e3f1DecodeGithubComIplocateGoIplocate3(in *jlexer.Lexer, out *Company) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "name":
+			out.Name = string(in.String())
+		case "domain":
+			out.Domain = string(in.String())
+		case "country_code":
+			out.CountryCode = string(in.String())
+		case "type":
+			out.Type = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC0e5e3f1EncodeGithubComIplocateGoIplocate3(out *jwriter.Writer, in Company) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"name\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Name))
+	}
+	{
+		const prefix string = ",\"domain\":"
+		out.RawString(prefix)
+		out.String(string(in.Domain))
+	}
+	{
+		const prefix string = ",\"country_code\":"
+		out.RawString(prefix)
+		out.String(string(in.CountryCode))
+	}
+	{
+		const prefix string = ",\"type\":"
+		out.RawString(prefix)
+		out.String(string(in.Type))
+	}
+	out.RawByte('}')
+}
+func easyjsonC0e5e3f1DecodeGithubComIplocateGoIplocate2(in *jlexer.Lexer, out *Privacy) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "is_abuser":
+			out.IsAbuser = bool(in.Bool())
+		case "is_anonymous":
+			out.IsAnonymous = bool(in.Bool())
+		case "is_bogon":
+			out.IsBogon = bool(in.Bool())
+		case "is_hosting":
+			out.IsHosting = bool(in.Bool())
+		case "is_icloud_relay":
+			out.IsIcloudRelay = bool(in.Bool())
+		case "is_proxy":
+			out.IsProxy = bool(in.Bool())
+		case "is_tor":
+			out.IsTor = bool(in.Bool())
+		case "is_vpn":
+			out.IsVPN = bool(in.Bool())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC0e5e3f1EncodeGithubComIplocateGoIplocate2(out *jwriter.Writer, in Privacy) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"is_abuser\":"
+		out.RawString(prefix[1:])
+		out.Bool(bool(in.IsAbuser))
+	}
+	{
+		const prefix string = ",\"is_anonymous\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.IsAnonymous))
+	}
+	{
+		const prefix string = ",\"is_bogon\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.IsBogon))
+	}
+	{
+		const prefix string = ",\"is_hosting\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.IsHosting))
+	}
+	{
+		const prefix string = ",\"is_icloud_relay\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.IsIcloudRelay))
+	}
+	{
+		const prefix string = ",\"is_proxy\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.IsProxy))
+	}
+	{
+		const prefix string = ",\"is_tor\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.IsTor))
+	}
+	{
+		const prefix string = ",\"is_vpn\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.IsVPN))
+	}
+	out.RawByte('}')
+}
+func easyjsonC0e5e3f1DecodeGithubComIplocateGoIplocate1(in *jlexer.Lexer, out *ASN) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "asn":
+			out.ASN = string(in.String())
+		case "route":
+			out.Route = string(in.String())
+		case "netname":
+			out.Netname = string(in.String())
+		case "name":
+			out.Name = string(in.String())
+		case "country_code":
+			out.CountryCode = string(in.String())
+		case "domain":
+			out.Domain = string(in.String())
+		case "type":
+			out.Type = string(in.String())
+		case "rir":
+			out.RIR = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC0e5e3f1EncodeGithubComIplocateGoIplocate1(out *jwriter.Writer, in ASN) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"asn\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.ASN))
+	}
+	{
+		const prefix string = ",\"route\":"
+		out.RawString(prefix)
+		out.String(string(in.Route))
+	}
+	{
+		const prefix string = ",\"netname\":"
+		out.RawString(prefix)
+		out.String(string(in.Netname))
+	}
+	{
+		const prefix string = ",\"name\":"
+		out.RawString(prefix)
+		out.String(string(in.Name))
+	}
+	{
+		const prefix string = ",\"country_code\":"
+		out.RawString(prefix)
+		out.String(string(in.CountryCode))
+	}
+	{
+		const prefix string = ",\"domain\":"
+		out.RawString(prefix)
+		out.String(string(in.Domain))
+	}
+	{
+		const prefix string = ",\"type\":"
+		out.RawString(prefix)
+		out.String(string(in.Type))
+	}
+	{
+		const prefix string = ",\"rir\":"
+		out.RawString(prefix)
+		out.String(string(in.RIR))
+	}
+	out.RawByte('}')
+}
+func easyjsonC0e5e3f1DecodeGithubComIplocateGoIplocate6(in *jlexer.Lexer, out *APIError) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "error":
+			out.Message = string(in.String())
+		case "code":
+			out.Code = ErrorCode(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC0e5e3f1EncodeGithubComIplocateGoIplocate6(out *jwriter.Writer, in APIError) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"error\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Message))
+	}
+	if in.Code != "" {
+		const prefix string = ",\"code\":"
+		out.RawString(prefix)
+		out.String(string(in.Code))
+	}
+	out.RawByte('}')
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v APIError) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC0e5e3f1EncodeGithubComIplocateGoIplocate6(w, v)
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *APIError) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC0e5e3f1DecodeGithubComIplocateGoIplocate6(l, v)
+}
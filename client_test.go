@@ -1,9 +1,15 @@
 package iplocate
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -52,6 +58,28 @@ func TestWithBaseURL(t *testing.T) {
 	assert.Equal(t, "https://api.custom.com", client.baseURL)
 }
 
+func TestUserAgent(t *testing.T) {
+	var userAgents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.Header.Get("User-Agent"))
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8"})
+	}))
+	defer server.Close()
+
+	_, err := NewClient(nil).WithBaseURL(server.URL).WithAppInfo("myapp", "2.3.0").WithAppInfo("plugin", "").Lookup("8.8.8.8")
+	require.NoError(t, err)
+	_, err = NewClient(nil).WithBaseURL(server.URL).WithUserAgent("custom/1.0").Lookup("8.8.8.8")
+	require.NoError(t, err)
+	_, err = NewClient(nil).WithBaseURL(server.URL).WithUserAgent("custom/1.0").WithAppInfo("myapp", "2.3.0").Lookup("8.8.8.8")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		DefaultUserAgent + " myapp/2.3.0 plugin",
+		"custom/1.0",
+		"custom/1.0 myapp/2.3.0",
+	}, userAgents)
+}
+
 func TestChainedConfiguration(t *testing.T) {
 	apiKey := "test-key"
 	timeout := 45 * time.Second
@@ -219,6 +247,256 @@ func TestAPIError_Error(t *testing.T) {
 	assert.Equal(t, expected, err.Error())
 }
 
+func TestLookupContext_Canceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8"})
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	client := NewClient(nil).WithBaseURL(server.URL)
+	_, err := client.LookupContext(ctx, "8.8.8.8")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestAutoRetryAfter_Retries(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Rate limit exceeded"})
+			return
+		}
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8"})
+	}))
+	defer server.Close()
+
+	client := NewClient(nil).WithBaseURL(server.URL).WithAutoRetryAfter(time.Second)
+	result, err := client.Lookup("8.8.8.8")
+
+	require.NoError(t, err)
+	assert.Equal(t, "8.8.8.8", result.IP)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestAutoRetryAfter_RetriesOnce(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Rate limit exceeded"})
+	}))
+	defer server.Close()
+
+	client := NewClient(nil).WithBaseURL(server.URL).WithAutoRetryAfter(time.Second)
+	_, err := client.Lookup("8.8.8.8")
+
+	require.Error(t, err)
+	apiErr, ok := err.(*APIError)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusTooManyRequests, apiErr.StatusCode)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestAutoRetryAfter_WaitTooLong(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Rate limit exceeded"})
+	}))
+	defer server.Close()
+
+	client := NewClient(nil).WithBaseURL(server.URL).WithAutoRetryAfter(time.Second)
+	_, err := client.Lookup("8.8.8.8")
+
+	require.Error(t, err)
+	apiErr, ok := err.(*APIError)
+	require.True(t, ok)
+	assert.Equal(t, 120*time.Second, apiErr.RetryAfter)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestAutoRetryAfter_Disabled(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Rate limit exceeded"})
+	}))
+	defer server.Close()
+
+	client := NewClient(nil).WithBaseURL(server.URL)
+	_, err := client.Lookup("8.8.8.8")
+
+	require.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestAutoRetryAfter_ContextDeadline(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "5")
+		w.WriteHeader(http.StatusTooManyRequests)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Rate limit exceeded"})
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	client := NewClient(nil).WithBaseURL(server.URL).WithAutoRetryAfter(time.Minute)
+	_, err := client.LookupContext(ctx, "8.8.8.8")
+
+	require.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	wait, ok := parseRetryAfter("30", now)
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, wait)
+
+	wait, ok = parseRetryAfter(now.Add(time.Minute).Format(http.TimeFormat), now)
+	assert.True(t, ok)
+	assert.Equal(t, time.Minute, wait)
+
+	_, ok = parseRetryAfter("", now)
+	assert.False(t, ok)
+	_, ok = parseRetryAfter("-1", now)
+	assert.False(t, ok)
+	_, ok = parseRetryAfter("soon", now)
+	assert.False(t, ok)
+}
+
+func TestLookup_ResponseTooLarge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ip":"8.8.8.8","padding":"`))
+		w.Write(bytes.Repeat([]byte("x"), MaxResponseSize))
+		w.Write([]byte(`"}`))
+	}))
+	defer server.Close()
+
+	_, err := NewClient(nil).WithBaseURL(server.URL).Lookup("8.8.8.8")
+	assert.ErrorIs(t, err, ErrResponseTooLarge)
+}
+
+func TestWithMaxResponseBytes(t *testing.T) {
+	body := []byte(`{"ip":"8.8.8.8","country_code":"US"}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	_, err := NewClient(nil).WithBaseURL(server.URL).WithMaxResponseBytes(int64(len(body)) - 1).Lookup("8.8.8.8")
+	assert.ErrorIs(t, err, ErrResponseTooLarge)
+
+	result, err := NewClient(nil).WithBaseURL(server.URL).WithMaxResponseBytes(int64(len(body))).Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "US", *result.CountryCode)
+}
+
+func TestWithMaxResponseBytes_Chunked(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ip":"8.8.8.8","padding":"`))
+		w.(http.Flusher).Flush()
+		w.Write(bytes.Repeat([]byte("x"), 4096))
+		w.Write([]byte(`"}`))
+	}))
+	defer server.Close()
+
+	_, err := NewClient(nil).WithBaseURL(server.URL).WithMaxResponseBytes(1024).Lookup("8.8.8.8")
+	assert.ErrorIs(t, err, ErrResponseTooLarge)
+}
+
+func TestWithMaxResponseBytes_Default(t *testing.T) {
+	client := NewClient(nil)
+	assert.Equal(t, int64(MaxResponseSize), client.maxResponseSize())
+	assert.Equal(t, int64(512), client.WithMaxResponseBytes(512).maxResponseSize())
+	assert.Equal(t, int64(MaxResponseSize), client.WithMaxResponseBytes(0).maxResponseSize())
+}
+
+func TestCappedReader(t *testing.T) {
+	data, err := io.ReadAll(&cappedReader{r: strings.NewReader("hello"), n: 5})
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	_, err = io.ReadAll(&cappedReader{r: strings.NewReader("hello!"), n: 5})
+	assert.ErrorIs(t, err, ErrResponseTooLarge)
+}
+
+// benchmarkBody is a complete lookup response as returned by the API
+func benchmarkBody(tb testing.TB) []byte {
+	body, err := json.Marshal(LookupResponse{
+		IP:           "8.8.8.8",
+		Country:      stringPtr("United States"),
+		CountryCode:  stringPtr("US"),
+		City:         stringPtr("Mountain View"),
+		Continent:    stringPtr("North America"),
+		Latitude:     float64Ptr(37.386),
+		Longitude:    float64Ptr(-122.0838),
+		TimeZone:     stringPtr("America/Los_Angeles"),
+		PostalCode:   stringPtr("94035"),
+		Subdivision:  stringPtr("California"),
+		CurrencyCode: stringPtr("USD"),
+		CallingCode:  stringPtr("1"),
+		Network:      stringPtr("8.8.8.0/24"),
+		ASN:          &ASN{ASN: "AS15169", Route: "8.8.8.0/24", Netname: "GOOGLE", Name: "Google LLC", CountryCode: "US", Domain: "google.com", Type: "hosting", RIR: "ARIN"},
+		Privacy:      Privacy{IsHosting: true},
+		Company:      &Company{Name: "Google LLC", Domain: "google.com", CountryCode: "US", Type: "hosting"},
+		Abuse:        &Abuse{Email: stringPtr("network-abuse@google.com"), Name: stringPtr("Abuse"), Network: stringPtr("8.8.8.0/24")},
+	})
+	require.NoError(tb, err)
+	return body
+}
+
+// BenchmarkParseResponse measures decoding a response body as it is streamed
+func BenchmarkParseResponse(b *testing.B) {
+	body := benchmarkBody(b)
+	client := NewClient(nil)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &responseBody{Reader: &cappedReader{r: bytes.NewReader(body), n: MaxResponseSize}},
+		}
+		var result LookupResponse
+		if err := client.parseResponse(resp, &result); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkParseResponse_ReadAll measures the previous approach of reading
+// the whole body before unmarshaling it, for comparison
+func BenchmarkParseResponse_ReadAll(b *testing.B) {
+	body := benchmarkBody(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		data, err := io.ReadAll(bytes.NewReader(body))
+		if err != nil {
+			b.Fatal(err)
+		}
+		var result LookupResponse
+		if err := json.Unmarshal(data, &result); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 // Helper functions for test data
 func stringPtr(s string) *string {
 	return &s
@@ -227,3 +505,37 @@ func stringPtr(s string) *string {
 func float64Ptr(f float64) *float64 {
 	return &f
 }
+
+// flushCache is a MemoryCache that records flushes
+type flushCache struct {
+	*MemoryCache
+	flushes int
+	err     error
+}
+
+func (f *flushCache) Flush() error {
+	f.flushes++
+	return f.err
+}
+
+func TestClose(t *testing.T) {
+	cache := &flushCache{MemoryCache: NewMemoryCache(10)}
+	client := NewClient(nil).
+		WithCache(cache, time.Hour).
+		WithLocalDatabase(testCountryDatabase(t)).
+		WithOfflineMode(true)
+
+	_, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	require.Len(t, client.local.dbs, 1)
+
+	require.NoError(t, client.Close())
+	assert.Equal(t, 1, cache.flushes)
+	assert.Empty(t, client.local.dbs, "local databases are closed")
+
+	_, err = client.Lookup("8.8.8.8")
+	assert.NoError(t, err, "the client still works after Close")
+
+	cache.err = errors.New("disk full")
+	assert.ErrorContains(t, client.Close(), "failed to flush cache: disk full")
+}
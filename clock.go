@@ -0,0 +1,81 @@
+package iplocate
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// DefaultRetryJitter is the largest fraction of a Retry-After wait that is
+// randomly added to it, so clients rate limited together don't all retry at
+// the same instant
+const DefaultRetryJitter = 0.1
+
+// Clock tells the time and waits on behalf of the client. Tests can inject a
+// fake clock with WithClock to control retries, pacing and cache expiry.
+type Clock interface {
+	Now() time.Time
+	// After returns a channel that receives the current time once d has elapsed
+	After(d time.Duration) <-chan time.Time
+}
+
+// systemClock is the Clock backed by the time package
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// WithClock sets the clock used for Retry-After waits, batch pacing,
+// endpoint cooldowns, cache TTLs and the health monitor interval
+func (c *Client) WithClock(clock Clock) *Client {
+	c.clock = clock
+	c.now = clock.Now
+	return c
+}
+
+// WithRand sets the random number generator used to jitter retry waits.
+// Calls to r are serialized, so it doesn't need to be safe for concurrent use.
+func (c *Client) WithRand(r *rand.Rand) *Client {
+	c.rand = &lockedRand{r: r}
+	return c
+}
+
+// lockedRand serializes access to a rand.Rand
+type lockedRand struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+func (l *lockedRand) float64() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Float64()
+}
+
+// jitter returns d extended by a random fraction of up to DefaultRetryJitter
+func (c *Client) jitter(d time.Duration) time.Duration {
+	f := rand.Float64()
+	if c.rand != nil {
+		f = c.rand.float64()
+	}
+	return d + time.Duration(float64(d)*DefaultRetryJitter*f)
+}
+
+// sleep waits for d on the client's clock or until ctx is done
+func (c *Client) sleep(ctx context.Context, d time.Duration) error {
+	return sleepContext(ctx, c.clock, d)
+}
+
+// sleepContext waits for d on clock or until ctx is done
+func sleepContext(ctx context.Context, clock Clock, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	select {
+	case <-clock.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,159 @@
+package iplocate
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock advances its time by the requested duration instead of waiting
+type fakeClock struct {
+	mu    sync.Mutex
+	now   time.Time
+	waits []time.Duration
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *fakeClock) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+	f.waits = append(f.waits, d)
+
+	ch := make(chan time.Time, 1)
+	ch <- f.now
+	return ch
+}
+
+func (f *fakeClock) advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
+
+func (f *fakeClock) recordedWaits() []time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]time.Duration(nil), f.waits...)
+}
+
+// rateLimitedOnceServer answers the first request with 429 and Retry-After
+func rateLimitedOnceServer(t *testing.T, retryAfter string) *httptest.Server {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.Header().Set("Retry-After", retryAfter)
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8"})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWithClock_RetryAfterWait(t *testing.T) {
+	run := func() time.Duration {
+		clock := newFakeClock()
+		client := NewClient(nil).
+			WithBaseURL(rateLimitedOnceServer(t, "30").URL).
+			WithAutoRetryAfter(time.Minute).
+			WithClock(clock).
+			WithRand(rand.New(rand.NewPCG(1, 2)))
+
+		start := time.Now()
+		_, err := client.Lookup("8.8.8.8")
+		require.NoError(t, err)
+		assert.Less(t, time.Since(start), 5*time.Second)
+
+		waits := clock.recordedWaits()
+		require.Len(t, waits, 1)
+		return waits[0]
+	}
+
+	wait := run()
+	assert.GreaterOrEqual(t, wait, 30*time.Second)
+	assert.LessOrEqual(t, wait, 33*time.Second)
+	assert.Equal(t, wait, run(), "the same seed gives the same jitter")
+}
+
+func TestWithClock_RetryAfterDate(t *testing.T) {
+	clock := newFakeClock()
+	date := clock.Now().Add(20 * time.Second).Format(http.TimeFormat)
+	client := NewClient(nil).
+		WithBaseURL(rateLimitedOnceServer(t, date).URL).
+		WithAutoRetryAfter(time.Minute).
+		WithClock(clock)
+
+	_, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+
+	waits := clock.recordedWaits()
+	require.Len(t, waits, 1)
+	assert.GreaterOrEqual(t, waits[0], 20*time.Second)
+	assert.LessOrEqual(t, waits[0], 22*time.Second)
+}
+
+func TestWithClock_CacheTTL(t *testing.T) {
+	server, full, _ := etagServer(t, "")
+
+	clock := newFakeClock()
+	client := NewClient(nil).WithBaseURL(server.URL).WithCache(NewMemoryCache(10), time.Minute).WithClock(clock)
+
+	_, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	_, err = client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(full))
+
+	clock.advance(2 * time.Minute)
+	_, err = client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(full))
+}
+
+func TestWithClock_BatchInterval(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8"})
+	}))
+	defer server.Close()
+
+	clock := newFakeClock()
+	client := NewClient(nil).WithBaseURL(server.URL).WithBatchInterval(time.Hour).WithClock(clock)
+
+	start := time.Now()
+	for _, result := range client.LookupIter(context.Background(), slices.Values([]string{"8.8.8.8", "1.1.1.1", "9.9.9.9"})) {
+		require.NoError(t, result.Err)
+	}
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Equal(t, []time.Duration{time.Hour, time.Hour}, clock.recordedWaits())
+}
+
+func TestJitter(t *testing.T) {
+	client := NewClient(nil).WithRand(rand.New(rand.NewPCG(3, 4)))
+	for i := 0; i < 100; i++ {
+		wait := client.jitter(10 * time.Second)
+		assert.GreaterOrEqual(t, wait, 10*time.Second)
+		assert.LessOrEqual(t, wait, 11*time.Second)
+	}
+	assert.Zero(t, client.jitter(0))
+}
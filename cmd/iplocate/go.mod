@@ -0,0 +1,26 @@
+module github.com/iplocate/go-iplocate/cmd/iplocate
+
+go 1.23
+
+require (
+	github.com/iplocate/go-iplocate v1.0.1-0.20261014111649-fed04dd274c8
+	github.com/iplocate/go-iplocate/cache/boltcache v0.0.0-20261014111649-fed04dd274c8
+	github.com/stretchr/testify v1.9.0
+	golang.org/x/time v0.5.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/josharian/intern v1.0.0 // indirect
+	github.com/mailru/easyjson v0.7.7 // indirect
+	github.com/oschwald/maxminddb-golang v1.12.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	go.etcd.io/bbolt v1.3.11 // indirect
+	golang.org/x/sys v0.23.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace (
+	github.com/iplocate/go-iplocate => ../..
+	github.com/iplocate/go-iplocate/cache/boltcache => ../../cache/boltcache
+)
@@ -0,0 +1,123 @@
+// Command iplocate looks up IP addresses with the IPLocate API, and serves
+// a caching lookup proxy that internal applications can share.
+//
+// The client is configured from IPLOCATE_* environment variables, such as
+// IPLOCATE_API_KEY; see iplocate.NewFromEnv.
+//
+// Usage:
+//
+//	iplocate lookup [-fields country_code,asn] <ip>...
+//	iplocate serve [-addr :8080] [-cache-ttl 1h] [-rate 50]
+//	iplocate version
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/iplocate/go-iplocate"
+)
+
+const usage = `Usage: iplocate <command> [flags]
+
+Commands:
+  lookup   look up IP addresses and print the results as JSON
+  serve    run an HTTP server exposing /lookup/{ip} with a shared cache
+  version  print the version
+
+The client is configured from IPLOCATE_* environment variables, such as
+IPLOCATE_API_KEY. Run "iplocate <command> -h" for the flags of a command.
+`
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command in args and returns the exit code
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	var err error
+	switch args[0] {
+	case "lookup":
+		err = runLookup(ctx, args[1:], stdout, stderr)
+	case "serve":
+		err = runServe(ctx, args[1:], stderr)
+	case "version":
+		fmt.Fprintln(stdout, "iplocate", iplocate.Version)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	var usageErr usageError
+	if errors.As(err, &usageErr) {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "iplocate:", err)
+		return 1
+	}
+	return 0
+}
+
+// usageError reports invalid command line arguments
+type usageError string
+
+func (e usageError) Error() string {
+	return string(e)
+}
+
+// runLookup looks up the addresses in args and prints one JSON result per line
+func runLookup(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("lookup", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	fields := flags.String("fields", "", "comma-separated top-level fields to return")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return usageError("usage: iplocate lookup [-fields f1,f2] <ip>...")
+	}
+
+	client, err := iplocate.NewFromEnv()
+	if err != nil {
+		return err
+	}
+
+	var opts []iplocate.LookupOption
+	if *fields != "" {
+		opts = append(opts, iplocate.Fields(strings.Split(*fields, ",")...))
+	}
+
+	enc := json.NewEncoder(stdout)
+	for _, ip := range flags.Args() {
+		result, err := client.LookupContext(ctx, ip, opts...)
+		if err != nil {
+			return fmt.Errorf("%s: %w", ip, err)
+		}
+		if err := enc.Encode(result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/iplocate/go-iplocate"
+	"github.com/stretchr/testify/assert"
+)
+
+// newAPI serves lookups with country US, returning 404 for 192.0.2.1, and
+// points the client configured from the environment at it
+func newAPI(t *testing.T, calls *int32) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		ip := strings.TrimPrefix(r.URL.Path, "/lookup/")
+		if ip == "192.0.2.1" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"Not found"}`))
+			return
+		}
+		country := "US"
+		json.NewEncoder(w).Encode(iplocate.LookupResponse{IP: ip, CountryCode: &country})
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv(iplocate.EnvBaseURL, server.URL)
+	t.Setenv(iplocate.EnvAPIKey, "test-key")
+}
+
+func runCommand(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun_Lookup(t *testing.T) {
+	var calls int32
+	newAPI(t, &calls)
+
+	code, stdout, _ := runCommand("lookup", "8.8.8.8", "1.1.1.1")
+	assert.Equal(t, 0, code)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[1], `"ip":"1.1.1.1"`)
+
+	code, _, stderr := runCommand("lookup", "192.0.2.1")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "192.0.2.1: IPLocate API error (404)")
+}
+
+func TestRun_Usage(t *testing.T) {
+	code, _, stderr := runCommand()
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "Usage: iplocate")
+
+	code, _, stderr = runCommand("frobnicate")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, `unknown command "frobnicate"`)
+
+	code, _, _ = runCommand("lookup")
+	assert.Equal(t, 2, code)
+
+	code, _, _ = runCommand("lookup", "-h")
+	assert.Equal(t, 0, code)
+
+	code, stdout, _ := runCommand("version")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "iplocate "+iplocate.Version+"\n", stdout)
+}
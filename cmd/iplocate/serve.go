@@ -0,0 +1,291 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iplocate/go-iplocate"
+	"github.com/iplocate/go-iplocate/cache/boltcache"
+	"golang.org/x/time/rate"
+)
+
+// serveConfig holds the flags of the serve command
+type serveConfig struct {
+	addr      string
+	cacheSize int
+	cacheTTL  time.Duration
+	cacheFile string
+	rate      float64
+	burst     int
+}
+
+// runServe runs the lookup proxy until ctx is done
+func runServe(ctx context.Context, args []string, stderr io.Writer) (err error) {
+	var cfg serveConfig
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(&cfg.addr, "addr", ":8080", "address to listen on")
+	flags.IntVar(&cfg.cacheSize, "cache-size", iplocate.DefaultMemoryCacheSize, "maximum number of cached lookups")
+	flags.DurationVar(&cfg.cacheTTL, "cache-ttl", time.Hour, "how long lookups are cached")
+	flags.StringVar(&cfg.cacheFile, "cache-file", "", "keep the cache in this file instead of in memory")
+	flags.Float64Var(&cfg.rate, "rate", 0, "maximum lookups per second across all callers, 0 for no limit")
+	flags.IntVar(&cfg.burst, "burst", 0, "maximum burst of lookups over -rate, defaults to -rate")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return usageError("usage: iplocate serve [flags]")
+	}
+
+	client, err := iplocate.NewFromEnv()
+	if err != nil {
+		return err
+	}
+
+	var cacheLen func() int
+	var closeCache func() error
+	if cfg.cacheFile != "" {
+		diskCache, err := boltcache.Open(cfg.cacheFile)
+		if err != nil {
+			client.Close()
+			return err
+		}
+		diskCache.WithMaxEntries(cfg.cacheSize)
+		diskCache.StartCompaction(cfg.cacheTTL, nil)
+		client.WithCache(diskCache, cfg.cacheTTL)
+		cacheLen = diskCache.Len
+		closeCache = diskCache.Close
+	} else {
+		memoryCache := iplocate.NewMemoryCache(cfg.cacheSize)
+		client.WithCache(memoryCache, cfg.cacheTTL)
+		cacheLen = memoryCache.Len
+	}
+
+	// Closing the client flushes the cache, so it has to happen first
+	defer func() {
+		closeErr := client.Close()
+		if closeCache != nil {
+			closeErr = errors.Join(closeErr, closeCache())
+		}
+		if err == nil {
+			err = closeErr
+		}
+	}()
+
+	srv := newServer(client, newLimiter(cfg.rate, cfg.burst), cacheLen)
+
+	httpServer := &http.Server{
+		Addr:              cfg.addr,
+		Handler:           srv,
+		ReadHeaderTimeout: 10 * time.Second,
+		ErrorLog:          log.New(stderr, "", log.LstdFlags),
+	}
+	listener, err := net.Listen("tcp", cfg.addr)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stderr, "iplocate: serving lookups on %s\n", listener.Addr())
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- httpServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return httpServer.Shutdown(shutdownCtx)
+	}
+}
+
+// server answers /lookup/{ip} with the client, and exposes /healthz and
+// /metrics
+type server struct {
+	client   *iplocate.Client
+	limiter  *rate.Limiter
+	cacheLen func() int
+	mux      *http.ServeMux
+	metrics  metrics
+}
+
+// newServer creates a server for client. limiter may be nil.
+func newServer(client *iplocate.Client, limiter *rate.Limiter, cacheLen func() int) *server {
+	s := &server{client: client, limiter: limiter, cacheLen: cacheLen, mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /lookup/{ip}", s.handleLookup)
+	s.mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok\n"))
+	})
+	s.mux.HandleFunc("GET /metrics", s.handleMetrics)
+	return s
+}
+
+// newLimiter returns a limiter allowing perSecond lookups with bursts of
+// burst, or nil if perSecond is zero
+func newLimiter(perSecond float64, burst int) *rate.Limiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = int(perSecond)
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return rate.NewLimiter(rate.Limit(perSecond), burst)
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// handleLookup looks up the IP address in the path. The fields query
+// parameter is passed on like in the API.
+func (s *server) handleLookup(w http.ResponseWriter, r *http.Request) {
+	if s.limiter != nil && !s.limiter.Allow() {
+		s.metrics.rateLimited()
+		s.writeError(w, http.StatusTooManyRequests, "rate limit exceeded")
+		return
+	}
+
+	var opts []iplocate.LookupOption
+	if fields := r.URL.Query().Get("fields"); fields != "" {
+		opts = append(opts, iplocate.Fields(strings.Split(fields, ",")...))
+	}
+
+	ip := r.PathValue("ip")
+	if _, err := iplocate.ParseFlexibleIP(ip); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid IP address")
+		return
+	}
+
+	start := time.Now()
+	result, err := s.client.LookupContext(r.Context(), ip, opts...)
+	s.metrics.observe(time.Since(start))
+	if err != nil {
+		s.writeError(w, errorStatus(err), errorMessage(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	s.metrics.count(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
+func (s *server) writeError(w http.ResponseWriter, status int, message string) {
+	s.metrics.count(status)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+// errorStatus returns the status code a failed lookup is answered with
+func errorStatus(err error) int {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout
+	}
+	if errors.Is(err, iplocate.ErrQuotaExceeded) {
+		return http.StatusTooManyRequests
+	}
+	var apiErr *iplocate.APIError
+	if !errors.As(err, &apiErr) {
+		return http.StatusBadGateway
+	}
+	switch {
+	case apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden:
+		// The proxy's credentials are wrong, not the caller's
+		return http.StatusBadGateway
+	case apiErr.StatusCode >= http.StatusInternalServerError:
+		return http.StatusBadGateway
+	default:
+		return apiErr.StatusCode
+	}
+}
+
+// errorMessage returns the message of an API error, or the error itself
+func errorMessage(err error) string {
+	var apiErr *iplocate.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Message
+	}
+	return err.Error()
+}
+
+// handleMetrics writes the metrics in the Prometheus text format
+func (s *server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	s.metrics.write(w, s.cacheLen())
+}
+
+// metrics counts the requests served
+type metrics struct {
+	mu          sync.Mutex
+	requests    map[int]uint64
+	limited     uint64
+	lookups     uint64
+	lookupTotal time.Duration
+}
+
+func (m *metrics) count(status int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.requests == nil {
+		m.requests = make(map[int]uint64)
+	}
+	m.requests[status]++
+}
+
+func (m *metrics) rateLimited() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.limited++
+}
+
+func (m *metrics) observe(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lookups++
+	m.lookupTotal += d
+}
+
+func (m *metrics) write(w io.Writer, cacheEntries int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fmt.Fprintln(w, "# HELP iplocate_serve_requests_total Lookup requests served, by status code.")
+	fmt.Fprintln(w, "# TYPE iplocate_serve_requests_total counter")
+	codes := make([]int, 0, len(m.requests))
+	for code := range m.requests {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	for _, code := range codes {
+		fmt.Fprintf(w, "iplocate_serve_requests_total{code=\"%d\"} %d\n", code, m.requests[code])
+	}
+
+	fmt.Fprintln(w, "# HELP iplocate_serve_rate_limited_total Lookup requests refused by the rate limit.")
+	fmt.Fprintln(w, "# TYPE iplocate_serve_rate_limited_total counter")
+	fmt.Fprintf(w, "iplocate_serve_rate_limited_total %d\n", m.limited)
+
+	fmt.Fprintln(w, "# HELP iplocate_serve_lookup_duration_seconds Time spent looking up addresses, including cache hits.")
+	fmt.Fprintln(w, "# TYPE iplocate_serve_lookup_duration_seconds summary")
+	fmt.Fprintf(w, "iplocate_serve_lookup_duration_seconds_sum %g\n", m.lookupTotal.Seconds())
+	fmt.Fprintf(w, "iplocate_serve_lookup_duration_seconds_count %d\n", m.lookups)
+
+	fmt.Fprintln(w, "# HELP iplocate_serve_cache_entries Entries in the lookup cache.")
+	fmt.Fprintln(w, "# TYPE iplocate_serve_cache_entries gauge")
+	fmt.Fprintf(w, "iplocate_serve_cache_entries %d\n", cacheEntries)
+}
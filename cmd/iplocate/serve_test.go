@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/iplocate/go-iplocate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer serves the lookup proxy in front of a test API
+func newTestServer(t *testing.T, perSecond float64, burst int) (*httptest.Server, *int32) {
+	t.Helper()
+	var calls int32
+	newAPI(t, &calls)
+	client, err := iplocate.NewFromEnv()
+	require.NoError(t, err)
+	cache := iplocate.NewMemoryCache(100)
+	client.WithCache(cache, time.Hour)
+
+	server := httptest.NewServer(newServer(client, newLimiter(perSecond, burst), cache.Len))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func get(t *testing.T, url string) (int, string) {
+	t.Helper()
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(body)
+}
+
+func TestServer_Lookup(t *testing.T) {
+	server, calls := newTestServer(t, 0, 0)
+
+	for i := 0; i < 3; i++ {
+		status, body := get(t, server.URL+"/lookup/8.8.8.8")
+		assert.Equal(t, http.StatusOK, status)
+		var result iplocate.LookupResponse
+		require.NoError(t, json.Unmarshal([]byte(body), &result))
+		assert.Equal(t, "US", *result.CountryCode)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls), "callers share the cache")
+
+	status, _ := get(t, server.URL+"/lookup/134744072")
+	assert.Equal(t, http.StatusOK, status, "the forms the client accepts are accepted")
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+
+	status, body := get(t, server.URL+"/lookup/192.0.2.1")
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.JSONEq(t, `{"error":"Not found"}`, body)
+
+	status, _ = get(t, server.URL+"/lookup/not-an-ip")
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, body = get(t, server.URL+"/healthz")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "ok\n", body)
+}
+
+func TestServer_RateLimit(t *testing.T) {
+	server, _ := newTestServer(t, 0.001, 2)
+
+	for i := 0; i < 2; i++ {
+		status, _ := get(t, server.URL+"/lookup/8.8.8.8")
+		assert.Equal(t, http.StatusOK, status)
+	}
+	status, _ := get(t, server.URL+"/lookup/8.8.8.8")
+	assert.Equal(t, http.StatusTooManyRequests, status)
+
+	_, metrics := get(t, server.URL+"/metrics")
+	assert.Contains(t, metrics, `iplocate_serve_requests_total{code="200"} 2`)
+	assert.Contains(t, metrics, `iplocate_serve_requests_total{code="429"} 1`)
+	assert.Contains(t, metrics, "iplocate_serve_rate_limited_total 1")
+	assert.Contains(t, metrics, "iplocate_serve_lookup_duration_seconds_count 2")
+	assert.Contains(t, metrics, "iplocate_serve_cache_entries 1")
+}
+
+func TestErrorStatus(t *testing.T) {
+	assert.Equal(t, http.StatusGatewayTimeout, errorStatus(context.DeadlineExceeded))
+	assert.Equal(t, http.StatusTooManyRequests, errorStatus(iplocate.ErrQuotaExceeded))
+	assert.Equal(t, http.StatusBadGateway, errorStatus(&iplocate.APIError{StatusCode: http.StatusForbidden}))
+	assert.Equal(t, http.StatusBadGateway, errorStatus(&iplocate.APIError{StatusCode: http.StatusServiceUnavailable}))
+	assert.Equal(t, http.StatusTooManyRequests, errorStatus(&iplocate.APIError{StatusCode: http.StatusTooManyRequests}))
+	assert.Equal(t, http.StatusBadGateway, errorStatus(&net.OpError{Op: "dial"}))
+}
+
+func TestRunServe(t *testing.T) {
+	var calls int32
+	newAPI(t, &calls)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- runServe(ctx, []string{"-addr", addr, "-cache-file", t.TempDir() + "/cache.db"}, io.Discard)
+	}()
+
+	require.Eventually(t, func() bool {
+		resp, err := http.Get("http://" + addr + "/lookup/8.8.8.8")
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not shut down")
+	}
+}
@@ -0,0 +1,81 @@
+package iplocate
+
+//go:generate easyjson -no_std_marshalers client.go lookupcache.go
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/mailru/easyjson"
+)
+
+// JSONMarshaler encodes v as JSON, like json.Marshal
+type JSONMarshaler func(v interface{}) ([]byte, error)
+
+// JSONUnmarshaler decodes JSON data into v, like json.Unmarshal
+type JSONUnmarshaler func(data []byte, v interface{}) error
+
+// WithJSONCodec replaces encoding/json for decoding API responses and for
+// encoding cache entries, e.g. with jsoniter or sonic:
+//
+//	client.WithJSONCodec(sonic.Marshal, sonic.Unmarshal)
+//
+// Either function may be nil to keep the encoding/json default.
+func (c *Client) WithJSONCodec(marshal JSONMarshaler, unmarshal JSONUnmarshaler) *Client {
+	c.marshal = marshal
+	c.unmarshal = unmarshal
+	return c
+}
+
+// WithGeneratedJSONCodec decodes responses and encodes cache entries with
+// the easyjson marshalers generated for LookupResponse, APIError and
+// CacheEntry, which roughly halves the time spent decoding in bulk
+// pipelines. Other types still go through encoding/json.
+func (c *Client) WithGeneratedJSONCodec() *Client {
+	return c.WithJSONCodec(generatedMarshal, generatedUnmarshal)
+}
+
+// generatedMarshal encodes v with its generated marshaler, if it has one
+func generatedMarshal(v interface{}) ([]byte, error) {
+	if m, ok := v.(easyjson.Marshaler); ok {
+		return easyjson.Marshal(m)
+	}
+	return json.Marshal(v)
+}
+
+// generatedUnmarshal decodes data with the generated unmarshaler of v, if it has one
+func generatedUnmarshal(data []byte, v interface{}) error {
+	if u, ok := v.(easyjson.Unmarshaler); ok {
+		return easyjson.Unmarshal(data, u)
+	}
+	return json.Unmarshal(data, v)
+}
+
+// marshalJSON encodes v with the configured codec
+func (c *Client) marshalJSON(v interface{}) ([]byte, error) {
+	if c.marshal != nil {
+		return c.marshal(v)
+	}
+	return json.Marshal(v)
+}
+
+// unmarshalJSON decodes data with the configured codec
+func (c *Client) unmarshalJSON(data []byte, v interface{}) error {
+	if c.unmarshal != nil {
+		return c.unmarshal(data, v)
+	}
+	return json.Unmarshal(data, v)
+}
+
+// decodeJSON decodes a JSON document read from r. The default codec streams
+// from r; a custom codec gets the whole document.
+func (c *Client) decodeJSON(r io.Reader, v interface{}) error {
+	if c.unmarshal == nil {
+		return json.NewDecoder(r).Decode(v)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return c.unmarshal(data, v)
+}
@@ -0,0 +1,160 @@
+package iplocate
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithJSONCodec(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8", CountryCode: stringPtr("US")})
+	}))
+	defer server.Close()
+
+	var marshals, unmarshals int
+	marshal := func(v interface{}) ([]byte, error) {
+		marshals++
+		return json.Marshal(v)
+	}
+	unmarshal := func(data []byte, v interface{}) error {
+		unmarshals++
+		return json.Unmarshal(data, v)
+	}
+
+	client := NewClient(nil).
+		WithBaseURL(server.URL).
+		WithCache(NewMemoryCache(10), time.Hour).
+		WithJSONCodec(marshal, unmarshal)
+
+	result, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "US", *result.CountryCode)
+	assert.Equal(t, 1, unmarshals, "response decoded with the custom codec")
+	assert.Equal(t, 1, marshals, "cache entry encoded with the custom codec")
+
+	_, err = client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, 2, unmarshals, "cache entry decoded with the custom codec")
+}
+
+func TestWithJSONCodec_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"Invalid IP address"}`))
+	}))
+	defer server.Close()
+
+	var unmarshals int
+	client := NewClient(nil).WithBaseURL(server.URL).WithJSONCodec(nil, func(data []byte, v interface{}) error {
+		unmarshals++
+		return json.Unmarshal(data, v)
+	})
+
+	_, err := client.Lookup("8.8.8.8")
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "Invalid IP address", apiErr.Message)
+	assert.Equal(t, 1, unmarshals)
+}
+
+func TestWithGeneratedJSONCodec(t *testing.T) {
+	body := benchmarkBody(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	cache := NewMemoryCache(10)
+	client := NewClient(nil).WithBaseURL(server.URL).WithCache(cache, time.Hour).WithGeneratedJSONCodec()
+
+	result, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	var want LookupResponse
+	require.NoError(t, json.Unmarshal(body, &want))
+	assert.Equal(t, &want, result, "generated and encoding/json decoding agree")
+
+	cached, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, result, cached, "cache entries round trip")
+
+	var apiErr *APIError
+	err = client.parseResponse(&http.Response{
+		StatusCode: http.StatusForbidden,
+		Body:       io.NopCloser(strings.NewReader(`{"error": "Invalid API key"}`)),
+	}, nil)
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "Invalid API key", apiErr.Message)
+}
+
+func TestGeneratedMarshal(t *testing.T) {
+	resp := LookupResponse{IP: "8.8.8.8", CountryCode: stringPtr("US"), ASN: &ASN{ASN: "AS15169"}, Privacy: Privacy{IsHosting: true}}
+	generated, err := generatedMarshal(resp)
+	require.NoError(t, err)
+	standard, err := json.Marshal(resp)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(standard), string(generated))
+
+	// Types without generated marshalers use encoding/json
+	data, err := generatedMarshal(map[string]int{"a": 1})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"a": 1}`, string(data))
+	var m map[string]int
+	require.NoError(t, generatedUnmarshal(data, &m))
+	assert.Equal(t, 1, m["a"])
+}
+
+func BenchmarkDecode_EncodingJSON(b *testing.B) {
+	body := benchmarkBody(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var result LookupResponse
+		if err := json.Unmarshal(body, &result); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecode_Generated(b *testing.B) {
+	body := benchmarkBody(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var result LookupResponse
+		if err := generatedUnmarshal(body, &result); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncode_EncodingJSON(b *testing.B) {
+	entry := CacheEntry{StoredAt: time.Now()}
+	require.NoError(b, json.Unmarshal(benchmarkBody(b), &entry.Response))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := json.Marshal(entry); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncode_Generated(b *testing.B) {
+	entry := CacheEntry{StoredAt: time.Now()}
+	require.NoError(b, json.Unmarshal(benchmarkBody(b), &entry.Response))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := generatedMarshal(entry); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
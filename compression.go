@@ -0,0 +1,32 @@
+package iplocate
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// WithCompression controls whether responses are requested gzip compressed.
+// Compression is enabled by default. The client decompresses responses
+// itself, so it works with any http.RoundTripper, not only the default
+// transport.
+func (c *Client) WithCompression(enabled bool) *Client {
+	c.disableCompression = !enabled
+	return c
+}
+
+// decodedBody returns a reader for the response body that undoes any gzip
+// content encoding
+func decodedBody(resp *http.Response) (io.ReadCloser, error) {
+	if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return io.NopCloser(resp.Body), nil
+	}
+
+	reader, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress response body: %w", err)
+	}
+	return reader, nil
+}
@@ -0,0 +1,87 @@
+package iplocate
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gzipServer compresses its response if the client accepts gzip
+func gzipServer(t *testing.T, acceptEncoding *string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*acceptEncoding = r.Header.Get("Accept-Encoding")
+		w.Header().Set("Content-Type", "application/json")
+
+		if *acceptEncoding != "gzip" {
+			json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8"})
+			return
+		}
+
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		json.NewEncoder(gz).Encode(LookupResponse{IP: "8.8.8.8"})
+		gz.Close()
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// plainTransport is a RoundTripper that, unlike http.DefaultTransport,
+// never decompresses responses itself
+type plainTransport struct{}
+
+func (plainTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return (&http.Transport{DisableCompression: true}).RoundTrip(req)
+}
+
+func TestCompression_Default(t *testing.T) {
+	var acceptEncoding string
+	server := gzipServer(t, &acceptEncoding)
+
+	client := NewClient(nil).WithBaseURL(server.URL)
+	result, err := client.Lookup("8.8.8.8")
+
+	require.NoError(t, err)
+	assert.Equal(t, "gzip", acceptEncoding)
+	assert.Equal(t, "8.8.8.8", result.IP)
+}
+
+func TestCompression_CustomTransport(t *testing.T) {
+	var acceptEncoding string
+	server := gzipServer(t, &acceptEncoding)
+
+	client := NewClient(&http.Client{Transport: plainTransport{}}).WithBaseURL(server.URL)
+	result, err := client.Lookup("8.8.8.8")
+
+	require.NoError(t, err)
+	assert.Equal(t, "gzip", acceptEncoding)
+	assert.Equal(t, "8.8.8.8", result.IP)
+}
+
+func TestCompression_Disabled(t *testing.T) {
+	var acceptEncoding string
+	server := gzipServer(t, &acceptEncoding)
+
+	client := NewClient(&http.Client{Transport: plainTransport{}}).WithBaseURL(server.URL).WithCompression(false)
+	result, err := client.Lookup("8.8.8.8")
+
+	require.NoError(t, err)
+	assert.Empty(t, acceptEncoding)
+	assert.Equal(t, "8.8.8.8", result.IP)
+}
+
+func TestCompression_InvalidBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write([]byte("not gzip"))
+	}))
+	defer server.Close()
+
+	_, err := NewClient(nil).WithBaseURL(server.URL).Lookup("8.8.8.8")
+	assert.ErrorContains(t, err, "failed to decompress")
+}
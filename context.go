@@ -0,0 +1,18 @@
+package iplocate
+
+import "context"
+
+type responseKey struct{}
+
+// NewContext returns a copy of ctx carrying resp. The contrib middlewares
+// and interceptors store their lookup results this way, so handlers and
+// application code can read them with FromContext whatever the framework.
+func NewContext(ctx context.Context, resp *LookupResponse) context.Context {
+	return context.WithValue(ctx, responseKey{}, resp)
+}
+
+// FromContext returns the lookup result stored in ctx with NewContext
+func FromContext(ctx context.Context) (*LookupResponse, bool) {
+	resp, ok := ctx.Value(responseKey{}).(*LookupResponse)
+	return resp, ok && resp != nil
+}
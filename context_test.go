@@ -0,0 +1,21 @@
+package iplocate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewContext(t *testing.T) {
+	_, ok := FromContext(context.Background())
+	assert.False(t, ok)
+
+	resp := &LookupResponse{IP: "8.8.8.8"}
+	got, ok := FromContext(NewContext(context.Background(), resp))
+	assert.True(t, ok)
+	assert.Same(t, resp, got)
+
+	_, ok = FromContext(NewContext(context.Background(), nil))
+	assert.False(t, ok)
+}
@@ -0,0 +1,150 @@
+// Package iplocateecho provides Echo middleware that looks up the client IP
+// address of each request with IPLocate and stores the result in the
+// echo.Context, where handlers read it with Get, and in the request context,
+// where code further down reads it with iplocate.FromContext.
+//
+//	e := echo.New()
+//	e.Use(iplocateecho.New(client, iplocateecho.SkipPaths("/healthz")))
+//	e.GET("/", func(c echo.Context) error {
+//		if result, ok := iplocateecho.Get(c); ok && result.CountryCode != nil {
+//			return c.String(http.StatusOK, "Hello from "+*result.CountryCode)
+//		}
+//		return c.String(http.StatusOK, "Hello")
+//	})
+//
+// Lookups go through the client, so every route and middleware instance
+// using the same client shares its cache. With WithPolicy, the middleware
+// also blocks requests that a policy.Policy denies, e.g. to geo-block
+// countries.
+package iplocateecho
+
+import (
+	"net/http"
+
+	"github.com/iplocate/go-iplocate"
+	"github.com/iplocate/go-iplocate/policy"
+	"github.com/labstack/echo/v4"
+)
+
+// contextKey is the echo.Context key the lookup result is stored under
+const contextKey = "github.com/iplocate/go-iplocate/contrib/echo"
+
+// Option customizes the middleware
+type Option func(*config)
+
+type config struct {
+	skipPaths  map[string]bool
+	skip       func(echo.Context) bool
+	clientIP   func(echo.Context) string
+	onError    func(echo.Context, error) error
+	lookupOpts []iplocate.LookupOption
+	policy     *policy.Policy
+	onBlock    func(echo.Context, policy.Decision) error
+}
+
+// SkipPaths skips the lookup for requests to the given paths. A path matches
+// either the request path, e.g. "/healthz", or the route pattern it was
+// registered with, e.g. "/users/:id".
+func SkipPaths(paths ...string) Option {
+	return func(c *config) {
+		for _, path := range paths {
+			c.skipPaths[path] = true
+		}
+	}
+}
+
+// Skip skips the lookup for requests for which fn returns true
+func Skip(fn func(echo.Context) bool) Option {
+	return func(c *config) {
+		c.skip = fn
+	}
+}
+
+// WithClientIP sets how the IP address to look up is found. By default it
+// is echo.Context.RealIP, which honors the echo.IPExtractor of the server.
+func WithClientIP(fn func(echo.Context) string) Option {
+	return func(c *config) {
+		c.clientIP = fn
+	}
+}
+
+// WithErrorHandler sets a callback for failed lookups. Returning an error
+// ends the request with it; returning nil continues without a result. By
+// default errors are ignored.
+func WithErrorHandler(fn func(echo.Context, error) error) Option {
+	return func(c *config) {
+		c.onError = fn
+	}
+}
+
+// WithPolicy evaluates p against each lookup result and blocks requests
+// that it doesn't allow. Requests whose lookup fails are not blocked.
+func WithPolicy(p *policy.Policy) Option {
+	return func(c *config) {
+		c.policy = p
+	}
+}
+
+// WithBlockHandler sets how requests blocked by the policy are answered,
+// for Deny and Challenge decisions alike. By default they get a 403
+// Forbidden echo.HTTPError.
+func WithBlockHandler(fn func(echo.Context, policy.Decision) error) Option {
+	return func(c *config) {
+		c.onBlock = fn
+	}
+}
+
+// WithLookupOptions sets the options used for every lookup
+func WithLookupOptions(opts ...iplocate.LookupOption) Option {
+	return func(c *config) {
+		c.lookupOpts = opts
+	}
+}
+
+// New returns middleware that looks up the client IP address of each
+// request with client
+func New(client *iplocate.Client, opts ...Option) echo.MiddlewareFunc {
+	cfg := &config{
+		skipPaths: make(map[string]bool),
+		clientIP:  echo.Context.RealIP,
+		onBlock: func(echo.Context, policy.Decision) error {
+			return echo.NewHTTPError(http.StatusForbidden)
+		},
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if cfg.skipPaths[c.Request().URL.Path] || cfg.skipPaths[c.Path()] || (cfg.skip != nil && cfg.skip(c)) {
+				return next(c)
+			}
+
+			result, err := client.LookupContext(c.Request().Context(), cfg.clientIP(c), cfg.lookupOpts...)
+			if err != nil {
+				if cfg.onError != nil {
+					if err := cfg.onError(c, err); err != nil {
+						return err
+					}
+				}
+				return next(c)
+			}
+
+			c.Set(contextKey, result)
+			c.SetRequest(c.Request().WithContext(iplocate.NewContext(c.Request().Context(), result)))
+			if cfg.policy != nil {
+				if decision := cfg.policy.Decide(result); decision.Action != policy.Allow {
+					return cfg.onBlock(c, decision)
+				}
+			}
+			return next(c)
+		}
+	}
+}
+
+// Get returns the lookup result the middleware stored for the request
+func Get(c echo.Context) (*iplocate.LookupResponse, bool) {
+	result, ok := c.Get(contextKey).(*iplocate.LookupResponse)
+	return result, ok && result != nil
+}
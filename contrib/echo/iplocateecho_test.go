@@ -0,0 +1,132 @@
+package iplocateecho
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/iplocate/go-iplocate"
+	"github.com/iplocate/go-iplocate/policy"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient returns a client whose API answers every lookup with
+// country US, failing lookups of 192.0.2.1
+func newTestClient(t *testing.T, calls *int32) *iplocate.Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		ip := strings.TrimPrefix(r.URL.Path, "/lookup/")
+		if ip == "192.0.2.1" {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error":"Internal error"}`))
+			return
+		}
+		country := "US"
+		json.NewEncoder(w).Encode(iplocate.LookupResponse{IP: ip, CountryCode: &country})
+	}))
+	t.Cleanup(server.Close)
+	return iplocate.NewClient(nil).WithBaseURL(server.URL).WithCache(iplocate.NewMemoryCache(100), time.Hour)
+}
+
+// serve sends a request from remoteAddr to e and returns the recorder
+func serve(e *echo.Echo, path, remoteAddr string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = remoteAddr + ":1234"
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func countryHandler(c echo.Context) error {
+	result, ok := Get(c)
+	if !ok {
+		return c.String(http.StatusOK, "unknown")
+	}
+	return c.String(http.StatusOK, *result.CountryCode)
+}
+
+func newEcho(mw echo.MiddlewareFunc) *echo.Echo {
+	e := echo.New()
+	e.Use(mw)
+	e.GET("/", countryHandler)
+	e.GET("/healthz", countryHandler)
+	e.GET("/users/:id", countryHandler)
+	return e
+}
+
+func TestNew(t *testing.T) {
+	var calls int32
+	e := newEcho(New(newTestClient(t, &calls)))
+
+	for i := 0; i < 2; i++ {
+		rec := serve(e, "/", "8.8.8.8")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "US", rec.Body.String())
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "lookups share the client's cache")
+}
+
+func TestNew_RequestContext(t *testing.T) {
+	var calls int32
+	e := echo.New()
+	e.Use(New(newTestClient(t, &calls)))
+	e.GET("/", func(c echo.Context) error {
+		result, ok := iplocate.FromContext(c.Request().Context())
+		require.True(t, ok)
+		return c.String(http.StatusOK, *result.CountryCode)
+	})
+
+	rec := serve(e, "/", "8.8.8.8")
+	assert.Equal(t, "US", rec.Body.String())
+}
+
+func TestNew_SkipPaths(t *testing.T) {
+	var calls int32
+	e := newEcho(New(newTestClient(t, &calls), SkipPaths("/healthz", "/users/:id")))
+
+	assert.Equal(t, "unknown", serve(e, "/healthz", "8.8.8.8").Body.String())
+	assert.Equal(t, "unknown", serve(e, "/users/42", "8.8.8.8").Body.String())
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+}
+
+func TestNew_Errors(t *testing.T) {
+	var calls int32
+	client := newTestClient(t, &calls)
+
+	rec := serve(newEcho(New(client)), "/", "192.0.2.1")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "unknown", rec.Body.String(), "errors are ignored by default")
+
+	e := newEcho(New(client, WithErrorHandler(func(c echo.Context, err error) error {
+		var apiErr *iplocate.APIError
+		require.True(t, errors.As(err, &apiErr))
+		return echo.NewHTTPError(http.StatusServiceUnavailable)
+	})))
+	assert.Equal(t, http.StatusServiceUnavailable, serve(e, "/", "192.0.2.1").Code)
+}
+
+func TestNew_Policy(t *testing.T) {
+	var calls int32
+	client := newTestClient(t, &calls)
+	p, err := policy.New(policy.Allow, policy.Rule{Name: "block-us", Action: policy.Deny, Countries: []string{"US"}})
+	require.NoError(t, err)
+
+	e := newEcho(New(client, WithPolicy(p)))
+	assert.Equal(t, http.StatusForbidden, serve(e, "/", "8.8.8.8").Code)
+	assert.Equal(t, http.StatusOK, serve(e, "/", "192.0.2.1").Code, "failed lookups are not blocked")
+
+	e = newEcho(New(client, WithPolicy(p), WithBlockHandler(func(c echo.Context, decision policy.Decision) error {
+		return c.JSON(http.StatusUnavailableForLegalReasons, map[string]string{"error": decision.Rule.Name})
+	})))
+	rec := serve(e, "/", "8.8.8.8")
+	assert.Equal(t, http.StatusUnavailableForLegalReasons, rec.Code)
+	assert.JSONEq(t, `{"error":"block-us"}`, rec.Body.String())
+}
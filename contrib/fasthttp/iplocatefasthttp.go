@@ -0,0 +1,192 @@
+// Package iplocatefasthttp provides an http.RoundTripper that sends IPLocate
+// API requests with fasthttp instead of net/http, for enrichment services
+// doing so many lookups that net/http's per-request allocations show up in
+// profiles:
+//
+//	client := iplocate.NewClient(&http.Client{
+//		Transport: iplocatefasthttp.New(),
+//		Timeout:   iplocate.DefaultTimeout,
+//	})
+//
+// Client options that configure the default transport, such as WithProxy,
+// WithTLSConfig or WithTransportTuning, have no effect on this transport;
+// configure the fasthttp.Client passed to WithClient instead.
+package iplocatefasthttp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/iplocate/go-iplocate"
+	"github.com/valyala/fasthttp"
+)
+
+// Option customizes the transport
+type Option func(*config)
+
+type config struct {
+	client           *fasthttp.Client
+	maxConnsPerHost  int
+	maxResponseBytes int64
+}
+
+// WithClient sets the fasthttp client requests are sent with, replacing
+// the default one
+func WithClient(client *fasthttp.Client) Option {
+	return func(c *config) {
+		c.client = client
+	}
+}
+
+// WithMaxConnsPerHost sets how many connections the default client opens
+// to the API, fasthttp.DefaultMaxConnsPerHost by default
+func WithMaxConnsPerHost(n int) Option {
+	return func(c *config) {
+		c.maxConnsPerHost = n
+	}
+}
+
+// WithMaxResponseBytes sets the largest response body the default client
+// reads, iplocate.MaxResponseSize by default. Set it to the limit given to
+// the iplocate.Client's WithMaxResponseBytes when raising that limit, since
+// fasthttp reads whole bodies before the client sees them. Larger responses
+// fail with iplocate.ErrResponseTooLarge.
+func WithMaxResponseBytes(n int64) Option {
+	return func(c *config) {
+		c.maxResponseBytes = n
+	}
+}
+
+// Transport is an http.RoundTripper backed by a fasthttp.Client. It is safe
+// for concurrent use.
+type Transport struct {
+	client *fasthttp.Client
+}
+
+// New creates a transport. By default, it dials IPv4 and IPv6 and reads
+// responses of up to iplocate.MaxResponseSize.
+func New(opts ...Option) *Transport {
+	cfg := &config{maxResponseBytes: iplocate.MaxResponseSize}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.maxResponseBytes <= 0 {
+		cfg.maxResponseBytes = iplocate.MaxResponseSize
+	}
+	if cfg.client == nil {
+		cfg.client = &fasthttp.Client{
+			MaxConnsPerHost:          cfg.maxConnsPerHost,
+			MaxResponseBodySize:      int(cfg.maxResponseBytes),
+			DialDualStack:            true,
+			NoDefaultUserAgentHeader: true,
+			DisablePathNormalizing:   true,
+		}
+	}
+	return &Transport{client: cfg.client}
+}
+
+// RoundTrip sends req with fasthttp. The request's context deadline bounds
+// the request; when the context is canceled, RoundTrip returns at once and
+// the request finishes in the background.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	freq := fasthttp.AcquireRequest()
+	fresp := fasthttp.AcquireResponse()
+	release := func() {
+		fasthttp.ReleaseRequest(freq)
+		fasthttp.ReleaseResponse(fresp)
+	}
+
+	if err := copyRequest(freq, req); err != nil {
+		release()
+		return nil, err
+	}
+
+	ctx := req.Context()
+	done := make(chan error, 1)
+	go func() {
+		if deadline, ok := ctx.Deadline(); ok {
+			done <- t.client.DoDeadline(freq, fresp, deadline)
+		} else {
+			done <- t.client.Do(freq, fresp)
+		}
+	}()
+
+	select {
+	case err := <-done:
+		defer release()
+		if err != nil {
+			// fasthttp's timer may fire just before the context's
+			if _, ok := ctx.Deadline(); ok && errors.Is(err, fasthttp.ErrTimeout) {
+				return nil, context.DeadlineExceeded
+			}
+			if errors.Is(err, fasthttp.ErrBodyTooLarge) {
+				return nil, iplocate.ErrResponseTooLarge
+			}
+			return nil, err
+		}
+		return toResponse(fresp, req), nil
+	case <-ctx.Done():
+		go func() {
+			<-done
+			release()
+		}()
+		return nil, ctx.Err()
+	}
+}
+
+// CloseIdleConnections closes the connections the transport keeps open. It
+// is called by http.Client.CloseIdleConnections and iplocate.Client.Close.
+func (t *Transport) CloseIdleConnections() {
+	t.client.CloseIdleConnections()
+}
+
+// copyRequest sets up a fasthttp request from req
+func copyRequest(dst *fasthttp.Request, req *http.Request) error {
+	dst.Header.SetMethod(req.Method)
+	dst.SetRequestURI(req.URL.String())
+	if req.Host != "" {
+		dst.Header.SetHost(req.Host)
+	}
+	for name, values := range req.Header {
+		for _, value := range values {
+			dst.Header.Add(name, value)
+		}
+	}
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read request body: %w", err)
+		}
+		dst.SetBody(body)
+	}
+	return nil
+}
+
+// toResponse converts a fasthttp response to a net/http response. The body
+// is copied, as fasthttp reuses its buffer once the response is released.
+func toResponse(src *fasthttp.Response, req *http.Request) *http.Response {
+	header := make(http.Header)
+	src.Header.VisitAll(func(key, value []byte) {
+		header.Add(string(key), string(value))
+	})
+	body := bytes.Clone(src.Body())
+
+	status := src.StatusCode()
+	return &http.Response{
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
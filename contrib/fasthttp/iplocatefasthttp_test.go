@@ -0,0 +1,98 @@
+package iplocatefasthttp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iplocate/go-iplocate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestTransport_Lookup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/lookup/8.8.8.8", r.URL.Path)
+		assert.Equal(t, "test-key", r.URL.Query().Get("apikey"))
+		assert.Equal(t, iplocate.DefaultUserAgent, r.Header.Get("User-Agent"))
+		assert.Equal(t, "application/json", r.Header.Get("Accept"))
+		w.Header().Set("X-Request-Id", "req-1")
+		country := "US"
+		json.NewEncoder(w).Encode(iplocate.LookupResponse{IP: "8.8.8.8", CountryCode: &country})
+	}))
+	defer server.Close()
+
+	client := iplocate.NewClient(&http.Client{Transport: New()}).WithBaseURL(server.URL).WithAPIKey("test-key")
+	defer client.Close()
+
+	result, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "US", *result.CountryCode)
+}
+
+func TestTransport_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid API key"})
+	}))
+	defer server.Close()
+
+	client := iplocate.NewClient(&http.Client{Transport: New(WithMaxConnsPerHost(4))}).WithBaseURL(server.URL)
+	_, err := client.Lookup("8.8.8.8")
+	var apiErr *iplocate.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusForbidden, apiErr.StatusCode)
+	assert.Equal(t, "Invalid API key", apiErr.Message)
+}
+
+func TestTransport_Context(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := iplocate.NewClient(&http.Client{Transport: New(WithClient(&fasthttp.Client{}))}).WithBaseURL(server.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.LookupContext(ctx, "8.8.8.8")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 2*time.Second)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	_, err = client.LookupContext(ctx, "8.8.8.8")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestWithMaxResponseBytes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(iplocate.LookupResponse{IP: "8.8.8.8", Hostname: stringPtr(strings.Repeat("x", 2*iplocate.MaxResponseSize))})
+	}))
+	defer server.Close()
+
+	client := iplocate.NewClient(&http.Client{Transport: New()}).WithBaseURL(server.URL)
+	_, err := client.Lookup("8.8.8.8")
+	assert.ErrorIs(t, err, iplocate.ErrResponseTooLarge)
+
+	const limit = 4 * iplocate.MaxResponseSize
+	client = iplocate.NewClient(&http.Client{Transport: New(WithMaxResponseBytes(limit))}).WithBaseURL(server.URL).WithMaxResponseBytes(limit)
+	result, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "8.8.8.8", result.IP)
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
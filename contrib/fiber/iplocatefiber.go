@@ -0,0 +1,146 @@
+// Package iplocatefiber provides Fiber middleware that looks up the client
+// IP address of each request with IPLocate and stores the result in the
+// request's locals, where handlers read it with Get, and in the user
+// context, where code further down reads it with iplocate.FromContext.
+//
+//	app := fiber.New()
+//	app.Use(iplocatefiber.New(client, iplocatefiber.SkipPaths("/healthz")))
+//	app.Get("/", func(c *fiber.Ctx) error {
+//		if result, ok := iplocatefiber.Get(c); ok && result.CountryCode != nil {
+//			return c.SendString("Hello from " + *result.CountryCode)
+//		}
+//		return c.SendString("Hello")
+//	})
+//
+// Lookups go through the client, so every route and middleware instance
+// using the same client shares its cache. With WithPolicy, the middleware
+// also blocks requests that a policy.Policy denies, e.g. to geo-block
+// countries.
+package iplocatefiber
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/iplocate/go-iplocate"
+	"github.com/iplocate/go-iplocate/policy"
+)
+
+// localsKey is the locals key the lookup result is stored under
+const localsKey = "github.com/iplocate/go-iplocate/contrib/fiber"
+
+// Option customizes the middleware
+type Option func(*config)
+
+type config struct {
+	skipPaths  map[string]bool
+	skip       func(*fiber.Ctx) bool
+	clientIP   func(*fiber.Ctx) string
+	onError    func(*fiber.Ctx, error) error
+	lookupOpts []iplocate.LookupOption
+	policy     *policy.Policy
+	onBlock    func(*fiber.Ctx, policy.Decision) error
+}
+
+// SkipPaths skips the lookup for requests to the given paths, e.g.
+// "/healthz". Middleware added with Use runs before the route is matched,
+// so route patterns such as "/users/:id" don't match; use Skip for those.
+func SkipPaths(paths ...string) Option {
+	return func(c *config) {
+		for _, path := range paths {
+			c.skipPaths[path] = true
+		}
+	}
+}
+
+// Skip skips the lookup for requests for which fn returns true
+func Skip(fn func(*fiber.Ctx) bool) Option {
+	return func(c *config) {
+		c.skip = fn
+	}
+}
+
+// WithClientIP sets how the IP address to look up is found. By default it
+// is fiber.Ctx.IP, which honors the ProxyHeader of the app's config.
+func WithClientIP(fn func(*fiber.Ctx) string) Option {
+	return func(c *config) {
+		c.clientIP = fn
+	}
+}
+
+// WithErrorHandler sets a callback for failed lookups. Returning an error
+// ends the request with it; returning nil continues without a result. By
+// default errors are ignored.
+func WithErrorHandler(fn func(*fiber.Ctx, error) error) Option {
+	return func(c *config) {
+		c.onError = fn
+	}
+}
+
+// WithPolicy evaluates p against each lookup result and blocks requests
+// that it doesn't allow. Requests whose lookup fails are not blocked.
+func WithPolicy(p *policy.Policy) Option {
+	return func(c *config) {
+		c.policy = p
+	}
+}
+
+// WithBlockHandler sets how requests blocked by the policy are answered,
+// for Deny and Challenge decisions alike. By default they get a 403
+// Forbidden fiber.Error.
+func WithBlockHandler(fn func(*fiber.Ctx, policy.Decision) error) Option {
+	return func(c *config) {
+		c.onBlock = fn
+	}
+}
+
+// WithLookupOptions sets the options used for every lookup
+func WithLookupOptions(opts ...iplocate.LookupOption) Option {
+	return func(c *config) {
+		c.lookupOpts = opts
+	}
+}
+
+// New returns middleware that looks up the client IP address of each
+// request with client
+func New(client *iplocate.Client, opts ...Option) fiber.Handler {
+	cfg := &config{
+		skipPaths: make(map[string]bool),
+		clientIP:  (*fiber.Ctx).IP,
+		onBlock: func(*fiber.Ctx, policy.Decision) error {
+			return fiber.ErrForbidden
+		},
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(c *fiber.Ctx) error {
+		if cfg.skipPaths[c.Path()] || (cfg.skip != nil && cfg.skip(c)) {
+			return c.Next()
+		}
+
+		result, err := client.LookupContext(c.UserContext(), cfg.clientIP(c), cfg.lookupOpts...)
+		if err != nil {
+			if cfg.onError != nil {
+				if err := cfg.onError(c, err); err != nil {
+					return err
+				}
+			}
+			return c.Next()
+		}
+
+		c.Locals(localsKey, result)
+		c.SetUserContext(iplocate.NewContext(c.UserContext(), result))
+		if cfg.policy != nil {
+			if decision := cfg.policy.Decide(result); decision.Action != policy.Allow {
+				return cfg.onBlock(c, decision)
+			}
+		}
+		return c.Next()
+	}
+}
+
+// Get returns the lookup result the middleware stored for the request
+func Get(c *fiber.Ctx) (*iplocate.LookupResponse, bool) {
+	result, ok := c.Locals(localsKey).(*iplocate.LookupResponse)
+	return result, ok && result != nil
+}
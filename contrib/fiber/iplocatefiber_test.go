@@ -0,0 +1,146 @@
+package iplocatefiber
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iplocate/go-iplocate"
+	"github.com/iplocate/go-iplocate/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient returns a client whose API answers every lookup with
+// country US, failing lookups of 192.0.2.1
+func newTestClient(t *testing.T, calls *int32) *iplocate.Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		ip := strings.TrimPrefix(r.URL.Path, "/lookup/")
+		if ip == "192.0.2.1" {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error":"Internal error"}`))
+			return
+		}
+		country := "US"
+		json.NewEncoder(w).Encode(iplocate.LookupResponse{IP: ip, CountryCode: &country})
+	}))
+	t.Cleanup(server.Close)
+	return iplocate.NewClient(nil).WithBaseURL(server.URL).WithCache(iplocate.NewMemoryCache(100), time.Hour)
+}
+
+func countryHandler(c *fiber.Ctx) error {
+	result, ok := Get(c)
+	if !ok {
+		return c.SendString("unknown")
+	}
+	return c.SendString(*result.CountryCode)
+}
+
+// newApp returns an app that takes the client IP from the X-Real-IP header
+func newApp(mw fiber.Handler) *fiber.App {
+	app := fiber.New(fiber.Config{ProxyHeader: "X-Real-IP"})
+	app.Use(mw)
+	app.Get("/", countryHandler)
+	app.Get("/healthz", countryHandler)
+	app.Get("/users/:id", countryHandler)
+	return app
+}
+
+// serve sends a request from ip to app and returns the status and body
+func serve(t *testing.T, app *fiber.App, path, ip string) (int, string) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("X-Real-IP", ip)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(body)
+}
+
+func TestNew(t *testing.T) {
+	var calls int32
+	app := newApp(New(newTestClient(t, &calls)))
+
+	for i := 0; i < 2; i++ {
+		status, body := serve(t, app, "/", "8.8.8.8")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "US", body)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "lookups share the client's cache")
+}
+
+func TestNew_UserContext(t *testing.T) {
+	var calls int32
+	app := fiber.New(fiber.Config{ProxyHeader: "X-Real-IP"})
+	app.Use(New(newTestClient(t, &calls)))
+	app.Get("/", func(c *fiber.Ctx) error {
+		result, ok := iplocate.FromContext(c.UserContext())
+		require.True(t, ok)
+		return c.SendString(*result.CountryCode)
+	})
+
+	_, body := serve(t, app, "/", "8.8.8.8")
+	assert.Equal(t, "US", body)
+}
+
+func TestNew_SkipPaths(t *testing.T) {
+	var calls int32
+	app := newApp(New(newTestClient(t, &calls), SkipPaths("/healthz"), Skip(func(c *fiber.Ctx) bool {
+		return strings.HasPrefix(c.Path(), "/users/")
+	})))
+
+	_, body := serve(t, app, "/healthz", "8.8.8.8")
+	assert.Equal(t, "unknown", body)
+	_, body = serve(t, app, "/users/42", "8.8.8.8")
+	assert.Equal(t, "unknown", body)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+}
+
+func TestNew_Errors(t *testing.T) {
+	var calls int32
+	client := newTestClient(t, &calls)
+
+	status, body := serve(t, newApp(New(client)), "/", "192.0.2.1")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "unknown", body, "errors are ignored by default")
+
+	app := newApp(New(client, WithErrorHandler(func(c *fiber.Ctx, err error) error {
+		var apiErr *iplocate.APIError
+		if !errors.As(err, &apiErr) {
+			return err
+		}
+		return fiber.ErrServiceUnavailable
+	})))
+	status, _ = serve(t, app, "/", "192.0.2.1")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+}
+
+func TestNew_Policy(t *testing.T) {
+	var calls int32
+	client := newTestClient(t, &calls)
+	p, err := policy.New(policy.Allow, policy.Rule{Name: "block-us", Action: policy.Deny, Countries: []string{"US"}})
+	require.NoError(t, err)
+
+	status, _ := serve(t, newApp(New(client, WithPolicy(p))), "/", "8.8.8.8")
+	assert.Equal(t, http.StatusForbidden, status)
+	status, _ = serve(t, newApp(New(client, WithPolicy(p))), "/", "192.0.2.1")
+	assert.Equal(t, http.StatusOK, status, "failed lookups are not blocked")
+
+	app := newApp(New(client, WithPolicy(p), WithBlockHandler(func(c *fiber.Ctx, decision policy.Decision) error {
+		return c.Status(http.StatusUnavailableForLegalReasons).JSON(fiber.Map{"error": decision.Rule.Name})
+	})))
+	status, body := serve(t, app, "/", "8.8.8.8")
+	assert.Equal(t, http.StatusUnavailableForLegalReasons, status)
+	assert.JSONEq(t, `{"error":"block-us"}`, body)
+}
@@ -0,0 +1,162 @@
+// Package iplocategin provides Gin middleware that looks up the client IP
+// address of each request with IPLocate and stores the result in the
+// gin.Context, where handlers read it with Get, and in the request context,
+// where code further down reads it with iplocate.FromContext.
+//
+//	client := iplocate.NewClient(nil).
+//		WithAPIKey(apiKey).
+//		WithCache(iplocate.NewMemoryCache(10000), time.Hour)
+//
+//	router := gin.Default()
+//	router.Use(iplocategin.New(client, iplocategin.SkipPaths("/healthz")))
+//	router.GET("/", func(c *gin.Context) {
+//		if result, ok := iplocategin.Get(c); ok && result.CountryCode != nil {
+//			c.String(http.StatusOK, "Hello from %s", *result.CountryCode)
+//		}
+//	})
+//
+// Lookups go through the client, so every route and middleware instance
+// using the same client shares its cache. Give the client a cache with
+// WithCache to avoid an API call per request.
+//
+// With WithPolicy, the middleware also blocks requests that a policy.Policy
+// denies, e.g. to geo-block countries.
+package iplocategin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iplocate/go-iplocate"
+	"github.com/iplocate/go-iplocate/policy"
+)
+
+// contextKey is the gin.Context key the lookup result is stored under
+const contextKey = "github.com/iplocate/go-iplocate/contrib/gin"
+
+// Option customizes the middleware
+type Option func(*config)
+
+type config struct {
+	skipPaths  map[string]bool
+	skip       func(*gin.Context) bool
+	clientIP   func(*gin.Context) string
+	onError    func(*gin.Context, error)
+	lookupOpts []iplocate.LookupOption
+	policy     *policy.Policy
+	onBlock    func(*gin.Context, policy.Decision)
+}
+
+// SkipPaths skips the lookup for requests to the given paths. A path matches
+// either the request path, e.g. "/healthz", or the route pattern it was
+// registered with, e.g. "/users/:id".
+func SkipPaths(paths ...string) Option {
+	return func(c *config) {
+		for _, path := range paths {
+			c.skipPaths[path] = true
+		}
+	}
+}
+
+// Skip skips the lookup for requests for which fn returns true
+func Skip(fn func(*gin.Context) bool) Option {
+	return func(c *config) {
+		c.skip = fn
+	}
+}
+
+// WithClientIP sets how the IP address to look up is found. By default it
+// is gin.Context.ClientIP, which honors the engine's trusted proxies.
+func WithClientIP(fn func(*gin.Context) string) Option {
+	return func(c *config) {
+		c.clientIP = fn
+	}
+}
+
+// WithErrorHandler sets a callback for failed lookups. It may abort the
+// request; otherwise the request continues without a result. By default
+// errors are ignored.
+func WithErrorHandler(fn func(*gin.Context, error)) Option {
+	return func(c *config) {
+		c.onError = fn
+	}
+}
+
+// WithPolicy evaluates p against each lookup result and blocks requests
+// that it doesn't allow. Requests whose lookup fails are not blocked.
+func WithPolicy(p *policy.Policy) Option {
+	return func(c *config) {
+		c.policy = p
+	}
+}
+
+// WithBlockHandler sets how requests blocked by the policy are answered,
+// for Deny and Challenge decisions alike. The handler must abort the
+// request to block it. By default requests are aborted with 403 Forbidden.
+func WithBlockHandler(fn func(*gin.Context, policy.Decision)) Option {
+	return func(c *config) {
+		c.onBlock = fn
+	}
+}
+
+// WithLookupOptions sets the options used for every lookup
+func WithLookupOptions(opts ...iplocate.LookupOption) Option {
+	return func(c *config) {
+		c.lookupOpts = opts
+	}
+}
+
+// New returns middleware that looks up the client IP address of each
+// request with client
+func New(client *iplocate.Client, opts ...Option) gin.HandlerFunc {
+	cfg := &config{
+		skipPaths: make(map[string]bool),
+		clientIP:  (*gin.Context).ClientIP,
+		onBlock: func(c *gin.Context, _ policy.Decision) {
+			c.AbortWithStatus(http.StatusForbidden)
+		},
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(c *gin.Context) {
+		if cfg.skipPaths[c.Request.URL.Path] || cfg.skipPaths[c.FullPath()] || (cfg.skip != nil && cfg.skip(c)) {
+			c.Next()
+			return
+		}
+
+		result, err := client.LookupContext(c.Request.Context(), cfg.clientIP(c), cfg.lookupOpts...)
+		if err != nil {
+			if cfg.onError != nil {
+				cfg.onError(c, err)
+			}
+			if !c.IsAborted() {
+				c.Next()
+			}
+			return
+		}
+
+		c.Set(contextKey, result)
+		c.Request = c.Request.WithContext(iplocate.NewContext(c.Request.Context(), result))
+		if cfg.policy != nil {
+			if decision := cfg.policy.Decide(result); decision.Action != policy.Allow {
+				cfg.onBlock(c, decision)
+				if c.IsAborted() {
+					return
+				}
+			}
+		}
+		c.Next()
+	}
+}
+
+// Get returns the lookup result the middleware stored for the request
+func Get(c *gin.Context) (*iplocate.LookupResponse, bool) {
+	value, ok := c.Get(contextKey)
+	if !ok {
+		return nil, false
+	}
+	result, ok := value.(*iplocate.LookupResponse)
+	return result, ok && result != nil
+}
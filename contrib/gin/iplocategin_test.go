@@ -0,0 +1,179 @@
+package iplocategin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iplocate/go-iplocate"
+	"github.com/iplocate/go-iplocate/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// newTestClient returns a client whose API answers every lookup with
+// country US, failing lookups of 192.0.2.1
+func newTestClient(t *testing.T, calls *int32) *iplocate.Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		ip := strings.TrimPrefix(r.URL.Path, "/lookup/")
+		if ip == "192.0.2.1" {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error":"Internal error"}`))
+			return
+		}
+		country := "US"
+		json.NewEncoder(w).Encode(iplocate.LookupResponse{IP: ip, CountryCode: &country})
+	}))
+	t.Cleanup(server.Close)
+	return iplocate.NewClient(nil).WithBaseURL(server.URL).WithCache(iplocate.NewMemoryCache(100), time.Hour)
+}
+
+// serve sends a request from remoteAddr to router and returns the recorder
+func serve(router *gin.Engine, path, remoteAddr string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = remoteAddr + ":1234"
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func countryHandler(c *gin.Context) {
+	result, ok := Get(c)
+	if !ok {
+		c.String(http.StatusOK, "unknown")
+		return
+	}
+	c.String(http.StatusOK, *result.CountryCode)
+}
+
+func TestNew(t *testing.T) {
+	var calls int32
+	router := gin.New()
+	router.Use(New(newTestClient(t, &calls)))
+	router.GET("/", countryHandler)
+
+	for i := 0; i < 2; i++ {
+		rec := serve(router, "/", "8.8.8.8")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "US", rec.Body.String())
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "lookups share the client's cache")
+}
+
+func TestNew_RequestContext(t *testing.T) {
+	var calls int32
+	router := gin.New()
+	router.Use(New(newTestClient(t, &calls)))
+	router.GET("/", func(c *gin.Context) {
+		result, ok := iplocate.FromContext(c.Request.Context())
+		require.True(t, ok)
+		c.String(http.StatusOK, *result.CountryCode)
+	})
+
+	rec := serve(router, "/", "8.8.8.8")
+	assert.Equal(t, "US", rec.Body.String())
+}
+
+func TestNew_SkipPaths(t *testing.T) {
+	var calls int32
+	router := gin.New()
+	router.Use(New(newTestClient(t, &calls), SkipPaths("/healthz", "/users/:id"), Skip(func(c *gin.Context) bool {
+		return c.GetHeader("X-Internal") != ""
+	})))
+	router.GET("/healthz", countryHandler)
+	router.GET("/users/:id", countryHandler)
+	router.GET("/", countryHandler)
+
+	assert.Equal(t, "unknown", serve(router, "/healthz", "8.8.8.8").Body.String())
+	assert.Equal(t, "unknown", serve(router, "/users/42", "8.8.8.8").Body.String())
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "8.8.8.8:1234"
+	req.Header.Set("X-Internal", "1")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, "unknown", rec.Body.String())
+
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+}
+
+func TestNew_Errors(t *testing.T) {
+	var calls int32
+	client := newTestClient(t, &calls)
+
+	router := gin.New()
+	router.Use(New(client))
+	router.GET("/", countryHandler)
+	rec := serve(router, "/", "192.0.2.1")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "unknown", rec.Body.String(), "errors are ignored by default")
+
+	var lookupErr error
+	router = gin.New()
+	router.Use(New(client, WithErrorHandler(func(c *gin.Context, err error) {
+		lookupErr = err
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+	})))
+	router.GET("/", countryHandler)
+	rec = serve(router, "/", "192.0.2.1")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	var apiErr *iplocate.APIError
+	require.True(t, errors.As(lookupErr, &apiErr))
+	assert.Equal(t, http.StatusInternalServerError, apiErr.StatusCode)
+}
+
+func TestNew_ClientIP(t *testing.T) {
+	var calls int32
+	router := gin.New()
+	router.Use(New(newTestClient(t, &calls), WithClientIP(func(c *gin.Context) string {
+		return c.GetHeader("X-Test-IP")
+	})))
+	router.GET("/", func(c *gin.Context) {
+		result, ok := Get(c)
+		require.True(t, ok)
+		c.String(http.StatusOK, result.IP)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Test-IP", "1.1.1.1")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, "1.1.1.1", rec.Body.String())
+}
+
+func TestNew_Policy(t *testing.T) {
+	var calls int32
+	client := newTestClient(t, &calls)
+	p, err := policy.New(policy.Allow, policy.Rule{Name: "block-us", Action: policy.Deny, Countries: []string{"US"}})
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(New(client, WithPolicy(p)))
+	router.GET("/", countryHandler)
+	assert.Equal(t, http.StatusForbidden, serve(router, "/", "8.8.8.8").Code)
+	assert.Equal(t, http.StatusOK, serve(router, "/", "192.0.2.1").Code, "failed lookups are not blocked")
+
+	var blocked policy.Decision
+	router = gin.New()
+	router.Use(New(client, WithPolicy(p), WithBlockHandler(func(c *gin.Context, decision policy.Decision) {
+		blocked = decision
+		c.AbortWithStatusJSON(http.StatusUnavailableForLegalReasons, gin.H{"error": decision.Rule.Name})
+	})))
+	router.GET("/", countryHandler)
+	rec := serve(router, "/", "8.8.8.8")
+	assert.Equal(t, http.StatusUnavailableForLegalReasons, rec.Code)
+	assert.Equal(t, policy.Deny, blocked.Action)
+	assert.JSONEq(t, `{"error":"block-us"}`, rec.Body.String())
+}
@@ -0,0 +1,208 @@
+// Package iplocategrpc provides gRPC server interceptors that look up the
+// address of the calling peer with IPLocate and add the result to the RPC
+// context, where handlers read it with FromContext or iplocate.FromContext.
+//
+//	server := grpc.NewServer(
+//		grpc.UnaryInterceptor(iplocategrpc.UnaryServerInterceptor(client)),
+//		grpc.StreamInterceptor(iplocategrpc.StreamServerInterceptor(client)),
+//	)
+//
+// Lookups go through the client, so give it a cache with WithCache to avoid
+// an API call per RPC. Behind a load balancer or proxy, list its addresses
+// with WithTrustedProxies so the original client address is taken from the
+// x-forwarded-for metadata it sets.
+package iplocategrpc
+
+import (
+	"context"
+	"net"
+	"net/netip"
+
+	"github.com/iplocate/go-iplocate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// DefaultForwardedKeys are the metadata keys read for the original client
+// address when the peer is a trusted proxy
+var DefaultForwardedKeys = []string{"x-forwarded-for", "x-real-ip"}
+
+// Option customizes the interceptors
+type Option func(*config)
+
+type config struct {
+	trusted       *iplocate.CIDRSet
+	forwardedKeys []string
+	skipMethods   map[string]bool
+	onError       func(context.Context, error) error
+	lookupOpts    []iplocate.LookupOption
+}
+
+// WithTrustedProxies sets the networks of proxies whose forwarded metadata
+// is honored. Without trusted proxies, the peer address is always used.
+func WithTrustedProxies(networks ...netip.Prefix) Option {
+	return func(c *config) {
+		c.trusted = &iplocate.CIDRSet{}
+		for _, network := range networks {
+			c.trusted.AddPrefix(network)
+		}
+	}
+}
+
+// WithForwardedKeys sets the metadata keys read, in order, for the original
+// client address, replacing DefaultForwardedKeys. Values may hold a comma
+// separated chain of addresses, as in x-forwarded-for.
+func WithForwardedKeys(keys ...string) Option {
+	return func(c *config) {
+		c.forwardedKeys = keys
+	}
+}
+
+// SkipMethods skips the lookup for the given full method names, e.g.
+// "/grpc.health.v1.Health/Check"
+func SkipMethods(methods ...string) Option {
+	return func(c *config) {
+		for _, method := range methods {
+			c.skipMethods[method] = true
+		}
+	}
+}
+
+// WithErrorHandler sets a callback for failed lookups. Returning an error
+// fails the RPC with it; returning nil continues without a result. By
+// default errors are ignored.
+func WithErrorHandler(fn func(context.Context, error) error) Option {
+	return func(c *config) {
+		c.onError = fn
+	}
+}
+
+// WithLookupOptions sets the options used for every lookup
+func WithLookupOptions(opts ...iplocate.LookupOption) Option {
+	return func(c *config) {
+		c.lookupOpts = opts
+	}
+}
+
+func newConfig(opts []Option) *config {
+	cfg := &config{
+		forwardedKeys: DefaultForwardedKeys,
+		skipMethods:   make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// UnaryServerInterceptor returns an interceptor that looks up the peer of
+// each unary RPC with client
+func UnaryServerInterceptor(client *iplocate.Client, opts ...Option) grpc.UnaryServerInterceptor {
+	cfg := newConfig(opts)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := cfg.enrich(ctx, client, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns an interceptor that looks up the peer of
+// each streaming RPC with client
+func StreamServerInterceptor(client *iplocate.Client, opts ...Option) grpc.StreamServerInterceptor {
+	cfg := newConfig(opts)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := cfg.enrich(ss.Context(), client, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// FromContext returns the lookup result an interceptor added to ctx. It is
+// the same as iplocate.FromContext.
+func FromContext(ctx context.Context) (*iplocate.LookupResponse, bool) {
+	return iplocate.FromContext(ctx)
+}
+
+// serverStream overrides the context of a stream
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// enrich looks up the client address of an RPC and adds the result to ctx
+func (c *config) enrich(ctx context.Context, client *iplocate.Client, method string) (context.Context, error) {
+	if c.skipMethods[method] {
+		return ctx, nil
+	}
+	ip, ok := c.clientIP(ctx)
+	if !ok {
+		return ctx, nil
+	}
+
+	result, err := client.LookupContext(ctx, ip.String(), c.lookupOpts...)
+	if err != nil {
+		if c.onError != nil {
+			if err := c.onError(ctx, err); err != nil {
+				return ctx, err
+			}
+		}
+		return ctx, nil
+	}
+	return iplocate.NewContext(ctx, result), nil
+}
+
+// clientIP returns the address of the caller: the peer address, or if the
+// peer is a trusted proxy, the client of the forwarded chain as returned by
+// iplocate.ForwardedChain.Client. It returns false if the chain has an
+// invalid hop before the client.
+func (c *config) clientIP(ctx context.Context) (netip.Addr, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return netip.Addr{}, false
+	}
+	ip, ok := parseAddr(p.Addr)
+	if !ok || !c.isTrusted(ip) {
+		return ip, ok
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, key := range c.forwardedKeys {
+		values := md.Get(key)
+		if len(values) == 0 {
+			continue
+		}
+		hop, ok := iplocate.ParseForwardedChain(ip.String(), values, c.trusted).Client()
+		return hop.Addr, ok
+	}
+	return ip, true
+}
+
+func (c *config) isTrusted(ip netip.Addr) bool {
+	return c.trusted != nil && c.trusted.ContainsAddr(ip)
+}
+
+// parseAddr returns the IP address of a peer address
+func parseAddr(addr net.Addr) (netip.Addr, bool) {
+	if tcp, ok := addr.(*net.TCPAddr); ok {
+		ip, ok := netip.AddrFromSlice(tcp.IP)
+		return ip.Unmap(), ok
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		host = addr.String()
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return ip.Unmap(), true
+}
@@ -0,0 +1,183 @@
+package iplocategrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/iplocate/go-iplocate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// newTestClient returns a client whose API answers every lookup with
+// country US, failing lookups of 192.0.2.1
+func newTestClient(t *testing.T, calls *int32) *iplocate.Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		ip := strings.TrimPrefix(r.URL.Path, "/lookup/")
+		if ip == "192.0.2.1" {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error":"Internal error"}`))
+			return
+		}
+		country := "US"
+		json.NewEncoder(w).Encode(iplocate.LookupResponse{IP: ip, CountryCode: &country})
+	}))
+	t.Cleanup(server.Close)
+	return iplocate.NewClient(nil).WithBaseURL(server.URL).WithCache(iplocate.NewMemoryCache(100), time.Hour)
+}
+
+// peerContext returns a context for an RPC from addr with the given metadata
+func peerContext(addr string, kv ...string) context.Context {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(addr), Port: 50000}})
+	if len(kv) > 0 {
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(kv...))
+	}
+	return ctx
+}
+
+// callUnary runs a unary RPC through interceptor and returns the looked up IP
+func callUnary(t *testing.T, interceptor grpc.UnaryServerInterceptor, ctx context.Context, method string) (string, error) {
+	t.Helper()
+	resp, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		result, ok := FromContext(ctx)
+		if !ok {
+			return "", nil
+		}
+		return result.IP, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return resp.(string), nil
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	var calls int32
+	interceptor := UnaryServerInterceptor(newTestClient(t, &calls), SkipMethods("/grpc.health.v1.Health/Check"))
+
+	ip, err := callUnary(t, interceptor, peerContext("8.8.8.8"), "/test.Service/Method")
+	require.NoError(t, err)
+	assert.Equal(t, "8.8.8.8", ip)
+
+	ip, err = callUnary(t, interceptor, peerContext("8.8.8.8"), "/grpc.health.v1.Health/Check")
+	require.NoError(t, err)
+	assert.Empty(t, ip)
+
+	ip, err = callUnary(t, interceptor, context.Background(), "/test.Service/Method")
+	require.NoError(t, err)
+	assert.Empty(t, ip, "RPCs without a peer are not looked up")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestUnaryServerInterceptor_SharedContext(t *testing.T) {
+	var calls int32
+	interceptor := UnaryServerInterceptor(newTestClient(t, &calls))
+
+	_, err := interceptor(peerContext("8.8.8.8"), nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		result, ok := iplocate.FromContext(ctx)
+		require.True(t, ok)
+		assert.Equal(t, "8.8.8.8", result.IP)
+		return nil, nil
+	})
+	require.NoError(t, err)
+}
+
+func TestUnaryServerInterceptor_TrustedProxies(t *testing.T) {
+	var calls int32
+	client := newTestClient(t, &calls)
+	proxies := WithTrustedProxies(netip.MustParsePrefix("10.0.0.0/8"))
+
+	// Forwarded metadata is ignored without trusted proxies
+	ip, err := callUnary(t, UnaryServerInterceptor(client), peerContext("10.0.0.1", "x-forwarded-for", "8.8.8.8"), "/m")
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", ip)
+
+	ip, err = callUnary(t, UnaryServerInterceptor(client, proxies), peerContext("10.0.0.1", "x-forwarded-for", "1.2.3.4, 8.8.8.8, 10.0.0.2"), "/m")
+	require.NoError(t, err)
+	assert.Equal(t, "8.8.8.8", ip, "the nearest untrusted hop is the client")
+
+	ip, err = callUnary(t, UnaryServerInterceptor(client, proxies), peerContext("9.9.9.9", "x-forwarded-for", "8.8.8.8"), "/m")
+	require.NoError(t, err)
+	assert.Equal(t, "9.9.9.9", ip, "untrusted peers can't forward addresses")
+
+	ip, err = callUnary(t, UnaryServerInterceptor(client, proxies, WithForwardedKeys("x-client-ip")), peerContext("10.0.0.1", "x-client-ip", "1.1.1.1"), "/m")
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.1.1", ip)
+
+	callsBefore := atomic.LoadInt32(&calls)
+	ip, err = callUnary(t, UnaryServerInterceptor(client, proxies), peerContext("10.0.0.1", "x-forwarded-for", "8.8.8.8, unknown, 10.0.0.2"), "/m")
+	require.NoError(t, err)
+	assert.Empty(t, ip, "an invalid hop before the client leaves the client unknown")
+	assert.Equal(t, callsBefore, atomic.LoadInt32(&calls))
+}
+
+func TestUnaryServerInterceptor_Errors(t *testing.T) {
+	var calls int32
+	client := newTestClient(t, &calls)
+
+	ip, err := callUnary(t, UnaryServerInterceptor(client), peerContext("192.0.2.1"), "/m")
+	require.NoError(t, err, "errors are ignored by default")
+	assert.Empty(t, ip)
+
+	interceptor := UnaryServerInterceptor(client, WithErrorHandler(func(ctx context.Context, err error) error {
+		var apiErr *iplocate.APIError
+		require.True(t, errors.As(err, &apiErr))
+		return status.Error(codes.Unavailable, "lookup failed")
+	}))
+	_, err = callUnary(t, interceptor, peerContext("192.0.2.1"), "/m")
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+// testStream is a ServerStream with a fixed context
+type testStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	var calls int32
+	interceptor := StreamServerInterceptor(newTestClient(t, &calls))
+
+	var ip string
+	err := interceptor(nil, &testStream{ctx: peerContext("8.8.8.8")}, &grpc.StreamServerInfo{FullMethod: "/m"}, func(srv interface{}, ss grpc.ServerStream) error {
+		result, ok := FromContext(ss.Context())
+		require.True(t, ok)
+		ip = result.IP
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "8.8.8.8", ip)
+}
+
+func TestParseAddr(t *testing.T) {
+	ip, ok := parseAddr(&net.TCPAddr{IP: net.ParseIP("::ffff:8.8.8.8")})
+	require.True(t, ok)
+	assert.Equal(t, "8.8.8.8", ip.String())
+
+	ip, ok = parseAddr(&net.UDPAddr{IP: net.ParseIP("2001:db8::1"), Port: 53})
+	require.True(t, ok)
+	assert.Equal(t, "2001:db8::1", ip.String())
+
+	_, ok = parseAddr(&net.UnixAddr{Name: "/tmp/grpc.sock", Net: "unix"})
+	assert.False(t, ok)
+}
@@ -0,0 +1,150 @@
+// Package iplocatehttp3 provides an http.RoundTripper that sends IPLocate
+// API requests over HTTP/3 (QUIC), which saves a round trip per connection
+// and copes better with lossy links:
+//
+//	client := iplocate.NewClient(&http.Client{
+//		Transport: iplocatehttp3.New(),
+//		Timeout:   iplocate.DefaultTimeout,
+//	})
+//
+// If a QUIC connection can't be established, e.g. because a firewall
+// blocks UDP, the request is sent over a TCP fallback transport instead,
+// and HTTP/3 isn't tried again for five minutes. Requests going through a
+// proxy always use TCP. Client options that configure the transport, such
+// as WithTLSConfig or WithProxy, configure the client's copy of the fallback
+// transport, and its TLS settings apply to both protocols.
+package iplocatehttp3
+
+import (
+	"crypto/tls"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+)
+
+const (
+	// DefaultHandshakeTimeout bounds the QUIC handshake, after which
+	// requests fall back to TCP
+	DefaultHandshakeTimeout = 3 * time.Second
+	// retryInterval is how long requests go over TCP after HTTP/3 failed
+	retryInterval = 5 * time.Minute
+)
+
+// Option customizes the transport
+type Option func(*Transport)
+
+// WithFallback sets the TCP transport used when HTTP/3 is unavailable,
+// replacing a copy of http.DefaultTransport
+func WithFallback(t *http.Transport) Option {
+	return func(tr *Transport) {
+		tr.fallback = t
+	}
+}
+
+// WithHandshakeTimeout sets how long to wait for the QUIC handshake before
+// falling back to TCP, DefaultHandshakeTimeout by default
+func WithHandshakeTimeout(d time.Duration) Option {
+	return func(tr *Transport) {
+		tr.handshakeTimeout = d
+	}
+}
+
+// Transport sends requests over HTTP/3, falling back to TCP when QUIC is
+// blocked or the endpoint doesn't speak it. It is safe for concurrent use.
+type Transport struct {
+	fallback         *http.Transport
+	handshakeTimeout time.Duration
+
+	once sync.Once
+	h3   *http3.Transport
+
+	mu          sync.Mutex
+	brokenUntil time.Time
+}
+
+// New creates a transport
+func New(opts ...Option) *Transport {
+	t := &Transport{handshakeTimeout: DefaultHandshakeTimeout}
+	for _, opt := range opts {
+		opt(t)
+	}
+	if t.fallback == nil {
+		t.fallback = http.DefaultTransport.(*http.Transport).Clone()
+	}
+	return t
+}
+
+// FallbackTransport returns the TCP transport, which the transport options
+// of iplocate.Client configure
+func (t *Transport) FallbackTransport() *http.Transport {
+	return t.fallback
+}
+
+// CloneWithFallback returns a transport with the same settings that falls
+// back to fallback. iplocate.Client uses it to configure its own copy of
+// the transport.
+func (t *Transport) CloneWithFallback(fallback *http.Transport) http.RoundTripper {
+	return &Transport{fallback: fallback, handshakeTimeout: t.handshakeTimeout}
+}
+
+// RoundTrip sends req over HTTP/3, or over TCP if HTTP/3 is unavailable
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "https" || t.proxied(req) || t.broken() {
+		return t.fallback.RoundTrip(req)
+	}
+
+	t.once.Do(t.init)
+	resp, err := t.h3.RoundTrip(req)
+	if err == nil {
+		return resp, nil
+	}
+	if req.Context().Err() != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	t.brokenUntil = time.Now().Add(retryInterval)
+	t.mu.Unlock()
+	return t.fallback.RoundTrip(req)
+}
+
+// CloseIdleConnections closes the idle connections of both transports. It
+// is called by http.Client.CloseIdleConnections and iplocate.Client.Close.
+func (t *Transport) CloseIdleConnections() {
+	t.once.Do(t.init)
+	t.h3.CloseIdleConnections()
+	t.fallback.CloseIdleConnections()
+}
+
+// init creates the HTTP/3 transport with the TLS settings of the fallback,
+// which are final by the time the first request is sent
+func (t *Transport) init() {
+	var tlsConfig *tls.Config
+	if t.fallback.TLSClientConfig != nil {
+		tlsConfig = t.fallback.TLSClientConfig.Clone()
+	}
+	t.h3 = &http3.Transport{
+		TLSClientConfig: tlsConfig,
+		QUICConfig:      &quic.Config{HandshakeIdleTimeout: t.handshakeTimeout},
+	}
+}
+
+// proxied reports whether the fallback transport would send req through a
+// proxy, which HTTP/3 can't do
+func (t *Transport) proxied(req *http.Request) bool {
+	if t.fallback.Proxy == nil {
+		return false
+	}
+	proxyURL, err := t.fallback.Proxy(req)
+	return err != nil || proxyURL != nil
+}
+
+// broken reports whether HTTP/3 failed recently
+func (t *Transport) broken() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return time.Now().Before(t.brokenUntil)
+}
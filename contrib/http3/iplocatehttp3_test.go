@@ -0,0 +1,119 @@
+package iplocatehttp3
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/iplocate/go-iplocate"
+	"github.com/quic-go/quic-go/http3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// protoServer serves lookups over TLS and records the protocol of each request
+func protoServer(t *testing.T) (*httptest.Server, func() []string) {
+	var mu sync.Mutex
+	var protos []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		protos = append(protos, r.Proto)
+		mu.Unlock()
+		json.NewEncoder(w).Encode(iplocate.LookupResponse{IP: "8.8.8.8"})
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), protos...)
+	}
+}
+
+func serverRoots(server *httptest.Server) *x509.CertPool {
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	return roots
+}
+
+func TestTransport(t *testing.T) {
+	server, protos := protoServer(t)
+
+	// Serve HTTP/3 on the UDP port matching the TCP server's port
+	_, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	conn, err := net.ListenPacket("udp", "127.0.0.1:"+port)
+	if err != nil {
+		t.Skipf("UDP port %s unavailable: %v", port, err)
+	}
+	h3 := &http3.Server{
+		Handler:   server.Config.Handler,
+		TLSConfig: http3.ConfigureTLSConfig(&tls.Config{Certificates: server.TLS.Certificates}),
+	}
+	go h3.Serve(conn)
+	defer h3.Close()
+
+	transport := New()
+	client := iplocate.NewClient(&http.Client{Transport: transport}).
+		WithBaseURL(server.URL).
+		WithTLSConfig(&tls.Config{RootCAs: serverRoots(server)})
+	defer client.Close()
+
+	_, err = client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"HTTP/3.0"}, protos())
+	if config := transport.FallbackTransport().TLSClientConfig; config != nil {
+		assert.Nil(t, config.RootCAs, "transport options configure a copy of the fallback")
+	}
+}
+
+func TestTransport_FallsBackToTCP(t *testing.T) {
+	server, protos := protoServer(t)
+
+	client := iplocate.NewClient(&http.Client{Transport: New(WithHandshakeTimeout(100 * time.Millisecond))}).
+		WithBaseURL(server.URL).
+		WithTLSConfig(&tls.Config{RootCAs: serverRoots(server)})
+
+	_, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, err = client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), 100*time.Millisecond, "HTTP/3 isn't retried right away")
+	assert.Equal(t, []string{"HTTP/1.1", "HTTP/1.1"}, protos())
+}
+
+func TestTransport_Proxy(t *testing.T) {
+	req, err := http.NewRequest("GET", "https://iplocate.io/api/lookup/8.8.8.8", nil)
+	require.NoError(t, err)
+
+	assert.False(t, New().proxied(req))
+
+	proxyURL, err := url.Parse("http://proxy.example.com:3128")
+	require.NoError(t, err)
+	transport := New(WithFallback(&http.Transport{Proxy: http.ProxyURL(proxyURL)}))
+	assert.True(t, transport.proxied(req), "proxied requests use TCP")
+}
+
+func TestTransport_CloneWithFallback(t *testing.T) {
+	transport := New(WithHandshakeTimeout(time.Second))
+	fallback := &http.Transport{}
+
+	clone, ok := transport.CloneWithFallback(fallback).(*Transport)
+	require.True(t, ok)
+	assert.Same(t, fallback, clone.FallbackTransport())
+	assert.Equal(t, time.Second, clone.handshakeTimeout)
+	assert.NotSame(t, fallback, transport.FallbackTransport())
+}
+
+func TestWithFallback(t *testing.T) {
+	fallback := &http.Transport{}
+	assert.Same(t, fallback, New(WithFallback(fallback)).FallbackTransport())
+}
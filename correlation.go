@@ -0,0 +1,67 @@
+package iplocate
+
+import (
+	"context"
+	"net/http"
+)
+
+// DefaultRequestIDHeader is the header WithRequestIDHeader sends the request ID in
+const DefaultRequestIDHeader = "X-Request-ID"
+
+// responseRequestIDHeaders are the response headers APIError.RequestID is read from, in order
+var responseRequestIDHeaders = []string{"X-Request-ID", "X-Correlation-ID", "CF-Ray"}
+
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a copy of ctx carrying the request ID, e.g.
+// the ID of the incoming request that triggered a lookup
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID stored with ContextWithRequestID,
+// or an empty string
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// correlationHeader is a header whose value is taken from the request context
+type correlationHeader struct {
+	name  string
+	value func(context.Context) string
+}
+
+// WithCorrelationHeader sets the header name on every API request to the
+// value returned for the request's context. The header is left out when
+// value returns an empty string.
+func (c *Client) WithCorrelationHeader(name string, value func(ctx context.Context) string) *Client {
+	c.correlation = append(c.correlation, correlationHeader{name: name, value: value})
+	return c
+}
+
+// WithRequestIDHeader sends the request ID stored in the context with
+// ContextWithRequestID as X-Request-ID, so API calls can be correlated
+// with the requests that caused them
+func (c *Client) WithRequestIDHeader() *Client {
+	return c.WithCorrelationHeader(DefaultRequestIDHeader, RequestIDFromContext)
+}
+
+// setCorrelationHeaders adds the configured correlation headers to req
+func (c *Client) setCorrelationHeaders(req *http.Request) {
+	for _, h := range c.correlation {
+		if value := h.value(req.Context()); value != "" {
+			req.Header.Set(h.name, value)
+		}
+	}
+}
+
+// responseRequestID returns the request ID the API returned, if any
+func responseRequestID(header http.Header) string {
+	for _, name := range responseRequestIDHeaders {
+		if id := header.Get(name); id != "" {
+			return id
+		}
+	}
+	return ""
+}
@@ -0,0 +1,77 @@
+package iplocate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRequestIDHeader(t *testing.T) {
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get("X-Request-ID"))
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8"})
+	}))
+	defer server.Close()
+
+	client := NewClient(nil).WithBaseURL(server.URL).WithRequestIDHeader()
+
+	_, err := client.LookupContext(ContextWithRequestID(context.Background(), "req-123"), "8.8.8.8")
+	require.NoError(t, err)
+	_, err = client.LookupContext(context.Background(), "8.8.8.8")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"req-123", ""}, got)
+}
+
+func TestWithCorrelationHeader(t *testing.T) {
+	type traceKey struct{}
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("Traceparent")
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8"})
+	}))
+	defer server.Close()
+
+	client := NewClient(nil).WithBaseURL(server.URL).
+		WithCorrelationHeader("Traceparent", func(ctx context.Context) string {
+			trace, _ := ctx.Value(traceKey{}).(string)
+			return trace
+		})
+
+	ctx := context.WithValue(context.Background(), traceKey{}, "00-trace-span-01")
+	_, err := client.LookupContext(ctx, "8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "00-trace-span-01", got)
+}
+
+func TestAPIError_RequestID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-ID", "api-456")
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error":"Invalid API key"}`))
+	}))
+	defer server.Close()
+
+	_, err := NewClient(nil).WithBaseURL(server.URL).Lookup("8.8.8.8")
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "api-456", apiErr.RequestID)
+	assert.Equal(t, "IPLocate API error (403): Invalid API key (request ID api-456)", err.Error())
+}
+
+func TestResponseRequestID(t *testing.T) {
+	header := http.Header{}
+	assert.Empty(t, responseRequestID(header))
+
+	header.Set("CF-Ray", "ray-1")
+	assert.Equal(t, "ray-1", responseRequestID(header))
+
+	header.Set("X-Request-ID", "req-1")
+	assert.Equal(t, "req-1", responseRequestID(header))
+}
@@ -0,0 +1,136 @@
+// Package countries is an offline table of country metadata keyed by the
+// ISO 3166-1 alpha-2 codes the IPLocate API returns: localized names, flag
+// emoji, continents and currency symbols, for rendering friendly output
+// without another dependency.
+//
+//	if c, ok := countries.Lookup(*resp.CountryCode); ok {
+//		fmt.Println(c.Flag(), c.LocalizedName("de"), c.CurrencySymbol())
+//	}
+package countries
+
+import "strings"
+
+// Languages are the languages of localized names, in addition to English
+var Languages = []string{"en", "de", "es", "fr"}
+
+// names holds a country or continent name in each of Languages
+type names [4]string
+
+// localized returns the name in lang, falling back to English. Regional
+// variants such as "de-AT" or "fr_CA" use their base language.
+func (n names) localized(lang string) string {
+	lang = strings.ToLower(lang)
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+	for i, l := range Languages {
+		if l == lang {
+			return n[i]
+		}
+	}
+	return n[0]
+}
+
+// Country is the metadata of a country or territory
+type Country struct {
+	// Code is the ISO 3166-1 alpha-2 code, e.g. "DE"
+	Code string
+	// Continent is the continent code: AF, AN, AS, EU, NA, OC or SA
+	Continent string
+	// Currency is the ISO 4217 code of the main currency, e.g. "EUR". It is
+	// empty for Antarctica.
+	Currency string
+	names    names
+}
+
+// Name returns the English name of the country
+func (c Country) Name() string {
+	return c.names[0]
+}
+
+// LocalizedName returns the name of the country in lang, e.g. "de" or
+// "de-AT". Languages without a translation fall back to English.
+func (c Country) LocalizedName(lang string) string {
+	return c.names.localized(lang)
+}
+
+// Flag returns the flag emoji of the country
+func (c Country) Flag() string {
+	return Flag(c.Code)
+}
+
+// ContinentName returns the name of the continent in lang
+func (c Country) ContinentName(lang string) string {
+	return ContinentName(c.Continent, lang)
+}
+
+// CurrencySymbol returns the symbol of the main currency, e.g. "€"
+func (c Country) CurrencySymbol() string {
+	return CurrencySymbol(c.Currency)
+}
+
+var byCode = func() map[string]Country {
+	m := make(map[string]Country, len(countries))
+	for _, c := range countries {
+		m[c.Code] = c
+	}
+	return m
+}()
+
+// Lookup returns the metadata of a country by its ISO 3166-1 alpha-2 code,
+// compared case-insensitively
+func Lookup(code string) (Country, bool) {
+	c, ok := byCode[strings.ToUpper(strings.TrimSpace(code))]
+	return c, ok
+}
+
+// All returns every country, ordered by code
+func All() []Country {
+	return append([]Country(nil), countries...)
+}
+
+// Flag returns the flag emoji of a two letter country code, made of the
+// matching regional indicator symbols, or an empty string for other input
+func Flag(code string) string {
+	if len(code) != 2 {
+		return ""
+	}
+	var b strings.Builder
+	for _, r := range strings.ToUpper(code) {
+		if r < 'A' || r > 'Z' {
+			return ""
+		}
+		b.WriteRune(0x1F1E6 + r - 'A')
+	}
+	return b.String()
+}
+
+var continents = map[string]names{
+	"AF": {"Africa", "Afrika", "África", "Afrique"},
+	"AN": {"Antarctica", "Antarktis", "Antártida", "Antarctique"},
+	"AS": {"Asia", "Asien", "Asia", "Asie"},
+	"EU": {"Europe", "Europa", "Europa", "Europe"},
+	"NA": {"North America", "Nordamerika", "América del Norte", "Amérique du Nord"},
+	"OC": {"Oceania", "Ozeanien", "Oceanía", "Océanie"},
+	"SA": {"South America", "Südamerika", "América del Sur", "Amérique du Sud"},
+}
+
+// ContinentName returns the name of a continent code in lang, falling back
+// to English, or an empty string for unknown codes
+func ContinentName(code, lang string) string {
+	n, ok := continents[strings.ToUpper(code)]
+	if !ok {
+		return ""
+	}
+	return n.localized(lang)
+}
+
+// CurrencySymbol returns the symbol of an ISO 4217 currency code, e.g. "¥"
+// for "JPY". Unknown currencies return the code.
+func CurrencySymbol(currency string) string {
+	currency = strings.ToUpper(currency)
+	if symbol, ok := currencySymbols[currency]; ok {
+		return symbol
+	}
+	return currency
+}
@@ -0,0 +1,69 @@
+package countries
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookup(t *testing.T) {
+	c, ok := Lookup("de")
+	require.True(t, ok)
+	assert.Equal(t, "DE", c.Code)
+	assert.Equal(t, "Germany", c.Name())
+	assert.Equal(t, "Deutschland", c.LocalizedName("de"))
+	assert.Equal(t, "Deutschland", c.LocalizedName("de-AT"))
+	assert.Equal(t, "Allemagne", c.LocalizedName("fr_CA"))
+	assert.Equal(t, "Germany", c.LocalizedName("ja"))
+	assert.Equal(t, "🇩🇪", c.Flag())
+	assert.Equal(t, "Europa", c.ContinentName("es"))
+	assert.Equal(t, "€", c.CurrencySymbol())
+
+	_, ok = Lookup("ZZ")
+	assert.False(t, ok)
+}
+
+func TestAll(t *testing.T) {
+	all := All()
+	assert.Len(t, all, 250)
+	for i, c := range all {
+		if i > 0 {
+			assert.Less(t, all[i-1].Code, c.Code)
+		}
+		assert.NotEmpty(t, ContinentName(c.Continent, "en"), c.Code)
+		if c.Currency != "" {
+			_, ok := currencySymbols[c.Currency]
+			assert.True(t, ok, "no symbol for %s of %s", c.Currency, c.Code)
+		}
+		for _, lang := range Languages {
+			assert.NotEmpty(t, c.LocalizedName(lang), c.Code)
+		}
+	}
+}
+
+func TestFlag(t *testing.T) {
+	assert.Equal(t, "🇺🇸", Flag("US"))
+	assert.Equal(t, "🇯🇵", Flag("jp"))
+	assert.Empty(t, Flag("USA"))
+	assert.Empty(t, Flag("1A"))
+}
+
+func TestCurrencySymbol(t *testing.T) {
+	assert.Equal(t, "¥", CurrencySymbol("jpy"))
+	assert.Equal(t, "R$", CurrencySymbol("BRL"))
+	assert.Equal(t, "XYZ", CurrencySymbol("XYZ"))
+
+	for code, currency := range map[string]string{"BG": "EUR", "CW": "XCG", "SX": "XCG"} {
+		c, ok := Lookup(code)
+		require.True(t, ok)
+		assert.Equal(t, currency, c.Currency, code)
+	}
+	assert.Equal(t, "Cg", CurrencySymbol("XCG"))
+}
+
+func TestContinentName(t *testing.T) {
+	assert.Equal(t, "Nordamerika", ContinentName("NA", "de"))
+	assert.Equal(t, "South America", ContinentName("sa", ""))
+	assert.Empty(t, ContinentName("XX", "en"))
+}
@@ -0,0 +1,31 @@
+package countries
+
+// currencySymbols maps ISO 4217 codes to their local symbols
+var currencySymbols = map[string]string{
+	"AED": "د.إ", "AFN": "؋", "ALL": "L", "AMD": "֏", "ANG": "ƒ", "AOA": "Kz",
+	"ARS": "$", "AUD": "$", "AWG": "ƒ", "AZN": "₼", "BAM": "KM", "BBD": "$",
+	"BDT": "৳", "BGN": "лв", "BHD": ".د.ب", "BIF": "FBu", "BMD": "$", "BND": "$",
+	"BOB": "Bs", "BRL": "R$", "BSD": "$", "BTN": "Nu.", "BWP": "P", "BYN": "Br",
+	"BZD": "$", "CAD": "$", "CDF": "FC", "CHF": "CHF", "CLP": "$", "CNY": "¥", "COP": "$",
+	"CRC": "₡", "CUP": "$", "CVE": "$", "CZK": "Kč", "DJF": "Fdj", "DKK": "kr",
+	"DOP": "$", "DZD": "د.ج", "EGP": "£", "ERN": "Nfk", "ETB": "Br", "EUR": "€",
+	"FJD": "$", "FKP": "£", "GBP": "£", "GEL": "₾", "GHS": "₵", "GIP": "£",
+	"GMD": "D", "GNF": "FG", "GTQ": "Q", "GYD": "$", "HKD": "$", "HNL": "L",
+	"HTG": "G", "HUF": "Ft", "IDR": "Rp", "ILS": "₪", "INR": "₹", "IQD": "ع.د",
+	"IRR": "﷼", "ISK": "kr", "JMD": "$", "JOD": "د.ا", "JPY": "¥", "KES": "KSh",
+	"KGS": "с", "KHR": "៛", "KMF": "CF", "KPW": "₩", "KRW": "₩", "KWD": "د.ك",
+	"KYD": "$", "KZT": "₸", "LAK": "₭", "LBP": "ل.ل", "LKR": "Rs", "LRD": "$",
+	"LSL": "L", "LYD": "ل.د", "MAD": "د.م.", "MDL": "L", "MGA": "Ar", "MKD": "ден",
+	"MMK": "K", "MNT": "₮", "MOP": "MOP$", "MRU": "UM", "MUR": "₨", "MVR": "Rf",
+	"MWK": "MK", "MXN": "$", "MYR": "RM", "MZN": "MT", "NAD": "$", "NGN": "₦",
+	"NIO": "C$", "NOK": "kr", "NPR": "₨", "NZD": "$", "OMR": "ر.ع.", "PAB": "B/.",
+	"PEN": "S/", "PGK": "K", "PHP": "₱", "PKR": "₨", "PLN": "zł", "PYG": "₲",
+	"QAR": "ر.ق", "RON": "lei", "RSD": "дин.", "RUB": "₽", "RWF": "FRw", "SAR": "ر.س",
+	"SBD": "$", "SCR": "₨", "SDG": "ج.س.", "SEK": "kr", "SGD": "$", "SHP": "£",
+	"SLE": "Le", "SOS": "Sh", "SRD": "$", "SSP": "£", "STN": "Db", "SYP": "£",
+	"SZL": "L", "THB": "฿", "TJS": "SM", "TMT": "m", "TND": "د.ت", "TOP": "T$",
+	"TRY": "₺", "TTD": "$", "TWD": "$", "TZS": "TSh", "UAH": "₴", "UGX": "USh",
+	"USD": "$", "UYU": "$", "UZS": "soʻm", "VES": "Bs.", "VND": "₫", "VUV": "VT",
+	"WST": "T", "XAF": "FCFA", "XCD": "$", "XCG": "Cg", "XOF": "CFA", "XPF": "₣", "YER": "﷼",
+	"ZAR": "R", "ZMW": "ZK",
+}
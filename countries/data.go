@@ -0,0 +1,256 @@
+package countries
+
+// countries lists every ISO 3166-1 country, plus Kosovo (XK), with its
+// continent, main currency and names in English, German, Spanish and French
+var countries = []Country{
+	{"AD", "EU", "EUR", names{"Andorra", "Andorra", "Andorra", "Andorre"}},
+	{"AE", "AS", "AED", names{"United Arab Emirates", "Vereinigte Arabische Emirate", "Emiratos Árabes Unidos", "Émirats arabes unis"}},
+	{"AF", "AS", "AFN", names{"Afghanistan", "Afghanistan", "Afganistán", "Afghanistan"}},
+	{"AG", "NA", "XCD", names{"Antigua and Barbuda", "Antigua und Barbuda", "Antigua y Barbuda", "Antigua-et-Barbuda"}},
+	{"AI", "NA", "XCD", names{"Anguilla", "Anguilla", "Anguila", "Anguilla"}},
+	{"AL", "EU", "ALL", names{"Albania", "Albanien", "Albania", "Albanie"}},
+	{"AM", "AS", "AMD", names{"Armenia", "Armenien", "Armenia", "Arménie"}},
+	{"AO", "AF", "AOA", names{"Angola", "Angola", "Angola", "Angola"}},
+	{"AQ", "AN", "", names{"Antarctica", "Antarktis", "Antártida", "Antarctique"}},
+	{"AR", "SA", "ARS", names{"Argentina", "Argentinien", "Argentina", "Argentine"}},
+	{"AS", "OC", "USD", names{"American Samoa", "Amerikanisch-Samoa", "Samoa Americana", "Samoa américaines"}},
+	{"AT", "EU", "EUR", names{"Austria", "Österreich", "Austria", "Autriche"}},
+	{"AU", "OC", "AUD", names{"Australia", "Australien", "Australia", "Australie"}},
+	{"AW", "NA", "AWG", names{"Aruba", "Aruba", "Aruba", "Aruba"}},
+	{"AX", "EU", "EUR", names{"Åland Islands", "Ålandinseln", "Islas Åland", "Îles Åland"}},
+	{"AZ", "AS", "AZN", names{"Azerbaijan", "Aserbaidschan", "Azerbaiyán", "Azerbaïdjan"}},
+	{"BA", "EU", "BAM", names{"Bosnia and Herzegovina", "Bosnien und Herzegowina", "Bosnia y Herzegovina", "Bosnie-Herzégovine"}},
+	{"BB", "NA", "BBD", names{"Barbados", "Barbados", "Barbados", "Barbade"}},
+	{"BD", "AS", "BDT", names{"Bangladesh", "Bangladesch", "Bangladés", "Bangladesh"}},
+	{"BE", "EU", "EUR", names{"Belgium", "Belgien", "Bélgica", "Belgique"}},
+	{"BF", "AF", "XOF", names{"Burkina Faso", "Burkina Faso", "Burkina Faso", "Burkina Faso"}},
+	{"BG", "EU", "EUR", names{"Bulgaria", "Bulgarien", "Bulgaria", "Bulgarie"}},
+	{"BH", "AS", "BHD", names{"Bahrain", "Bahrain", "Baréin", "Bahreïn"}},
+	{"BI", "AF", "BIF", names{"Burundi", "Burundi", "Burundi", "Burundi"}},
+	{"BJ", "AF", "XOF", names{"Benin", "Benin", "Benín", "Bénin"}},
+	{"BL", "NA", "EUR", names{"Saint Barthélemy", "St. Barthélemy", "San Bartolomé", "Saint-Barthélemy"}},
+	{"BM", "NA", "BMD", names{"Bermuda", "Bermuda", "Bermudas", "Bermudes"}},
+	{"BN", "AS", "BND", names{"Brunei", "Brunei", "Brunéi", "Brunei"}},
+	{"BO", "SA", "BOB", names{"Bolivia", "Bolivien", "Bolivia", "Bolivie"}},
+	{"BQ", "NA", "USD", names{"Caribbean Netherlands", "Karibische Niederlande", "Caribe neerlandés", "Pays-Bas caribéens"}},
+	{"BR", "SA", "BRL", names{"Brazil", "Brasilien", "Brasil", "Brésil"}},
+	{"BS", "NA", "BSD", names{"Bahamas", "Bahamas", "Bahamas", "Bahamas"}},
+	{"BT", "AS", "BTN", names{"Bhutan", "Bhutan", "Bután", "Bhoutan"}},
+	{"BV", "AN", "NOK", names{"Bouvet Island", "Bouvetinsel", "Isla Bouvet", "Île Bouvet"}},
+	{"BW", "AF", "BWP", names{"Botswana", "Botsuana", "Botsuana", "Botswana"}},
+	{"BY", "EU", "BYN", names{"Belarus", "Belarus", "Bielorrusia", "Biélorussie"}},
+	{"BZ", "NA", "BZD", names{"Belize", "Belize", "Belice", "Belize"}},
+	{"CA", "NA", "CAD", names{"Canada", "Kanada", "Canadá", "Canada"}},
+	{"CC", "AS", "AUD", names{"Cocos (Keeling) Islands", "Kokosinseln", "Islas Cocos", "Îles Cocos"}},
+	{"CD", "AF", "CDF", names{"DR Congo", "Demokratische Republik Kongo", "República Democrática del Congo", "République démocratique du Congo"}},
+	{"CF", "AF", "XAF", names{"Central African Republic", "Zentralafrikanische Republik", "República Centroafricana", "République centrafricaine"}},
+	{"CG", "AF", "XAF", names{"Republic of the Congo", "Republik Kongo", "República del Congo", "République du Congo"}},
+	{"CH", "EU", "CHF", names{"Switzerland", "Schweiz", "Suiza", "Suisse"}},
+	{"CI", "AF", "XOF", names{"Côte d'Ivoire", "Côte d'Ivoire", "Costa de Marfil", "Côte d'Ivoire"}},
+	{"CK", "OC", "NZD", names{"Cook Islands", "Cookinseln", "Islas Cook", "Îles Cook"}},
+	{"CL", "SA", "CLP", names{"Chile", "Chile", "Chile", "Chili"}},
+	{"CM", "AF", "XAF", names{"Cameroon", "Kamerun", "Camerún", "Cameroun"}},
+	{"CN", "AS", "CNY", names{"China", "China", "China", "Chine"}},
+	{"CO", "SA", "COP", names{"Colombia", "Kolumbien", "Colombia", "Colombie"}},
+	{"CR", "NA", "CRC", names{"Costa Rica", "Costa Rica", "Costa Rica", "Costa Rica"}},
+	{"CU", "NA", "CUP", names{"Cuba", "Kuba", "Cuba", "Cuba"}},
+	{"CV", "AF", "CVE", names{"Cape Verde", "Kap Verde", "Cabo Verde", "Cap-Vert"}},
+	{"CW", "NA", "XCG", names{"Curaçao", "Curaçao", "Curazao", "Curaçao"}},
+	{"CX", "AS", "AUD", names{"Christmas Island", "Weihnachtsinsel", "Isla de Navidad", "Île Christmas"}},
+	{"CY", "EU", "EUR", names{"Cyprus", "Zypern", "Chipre", "Chypre"}},
+	{"CZ", "EU", "CZK", names{"Czechia", "Tschechien", "Chequia", "Tchéquie"}},
+	{"DE", "EU", "EUR", names{"Germany", "Deutschland", "Alemania", "Allemagne"}},
+	{"DJ", "AF", "DJF", names{"Djibouti", "Dschibuti", "Yibuti", "Djibouti"}},
+	{"DK", "EU", "DKK", names{"Denmark", "Dänemark", "Dinamarca", "Danemark"}},
+	{"DM", "NA", "XCD", names{"Dominica", "Dominica", "Dominica", "Dominique"}},
+	{"DO", "NA", "DOP", names{"Dominican Republic", "Dominikanische Republik", "República Dominicana", "République dominicaine"}},
+	{"DZ", "AF", "DZD", names{"Algeria", "Algerien", "Argelia", "Algérie"}},
+	{"EC", "SA", "USD", names{"Ecuador", "Ecuador", "Ecuador", "Équateur"}},
+	{"EE", "EU", "EUR", names{"Estonia", "Estland", "Estonia", "Estonie"}},
+	{"EG", "AF", "EGP", names{"Egypt", "Ägypten", "Egipto", "Égypte"}},
+	{"EH", "AF", "MAD", names{"Western Sahara", "Westsahara", "Sáhara Occidental", "Sahara occidental"}},
+	{"ER", "AF", "ERN", names{"Eritrea", "Eritrea", "Eritrea", "Érythrée"}},
+	{"ES", "EU", "EUR", names{"Spain", "Spanien", "España", "Espagne"}},
+	{"ET", "AF", "ETB", names{"Ethiopia", "Äthiopien", "Etiopía", "Éthiopie"}},
+	{"FI", "EU", "EUR", names{"Finland", "Finnland", "Finlandia", "Finlande"}},
+	{"FJ", "OC", "FJD", names{"Fiji", "Fidschi", "Fiyi", "Fidji"}},
+	{"FK", "SA", "FKP", names{"Falkland Islands", "Falklandinseln", "Islas Malvinas", "Îles Malouines"}},
+	{"FM", "OC", "USD", names{"Micronesia", "Mikronesien", "Micronesia", "Micronésie"}},
+	{"FO", "EU", "DKK", names{"Faroe Islands", "Färöer", "Islas Feroe", "Îles Féroé"}},
+	{"FR", "EU", "EUR", names{"France", "Frankreich", "Francia", "France"}},
+	{"GA", "AF", "XAF", names{"Gabon", "Gabun", "Gabón", "Gabon"}},
+	{"GB", "EU", "GBP", names{"United Kingdom", "Vereinigtes Königreich", "Reino Unido", "Royaume-Uni"}},
+	{"GD", "NA", "XCD", names{"Grenada", "Grenada", "Granada", "Grenade"}},
+	{"GE", "AS", "GEL", names{"Georgia", "Georgien", "Georgia", "Géorgie"}},
+	{"GF", "SA", "EUR", names{"French Guiana", "Französisch-Guayana", "Guayana Francesa", "Guyane française"}},
+	{"GG", "EU", "GBP", names{"Guernsey", "Guernsey", "Guernsey", "Guernesey"}},
+	{"GH", "AF", "GHS", names{"Ghana", "Ghana", "Ghana", "Ghana"}},
+	{"GI", "EU", "GIP", names{"Gibraltar", "Gibraltar", "Gibraltar", "Gibraltar"}},
+	{"GL", "NA", "DKK", names{"Greenland", "Grönland", "Groenlandia", "Groenland"}},
+	{"GM", "AF", "GMD", names{"Gambia", "Gambia", "Gambia", "Gambie"}},
+	{"GN", "AF", "GNF", names{"Guinea", "Guinea", "Guinea", "Guinée"}},
+	{"GP", "NA", "EUR", names{"Guadeloupe", "Guadeloupe", "Guadalupe", "Guadeloupe"}},
+	{"GQ", "AF", "XAF", names{"Equatorial Guinea", "Äquatorialguinea", "Guinea Ecuatorial", "Guinée équatoriale"}},
+	{"GR", "EU", "EUR", names{"Greece", "Griechenland", "Grecia", "Grèce"}},
+	{"GS", "AN", "GBP", names{"South Georgia and the South Sandwich Islands", "Südgeorgien und die Südlichen Sandwichinseln", "Islas Georgias del Sur y Sandwich del Sur", "Géorgie du Sud-et-les Îles Sandwich du Sud"}},
+	{"GT", "NA", "GTQ", names{"Guatemala", "Guatemala", "Guatemala", "Guatemala"}},
+	{"GU", "OC", "USD", names{"Guam", "Guam", "Guam", "Guam"}},
+	{"GW", "AF", "XOF", names{"Guinea-Bissau", "Guinea-Bissau", "Guinea-Bisáu", "Guinée-Bissau"}},
+	{"GY", "SA", "GYD", names{"Guyana", "Guyana", "Guyana", "Guyana"}},
+	{"HK", "AS", "HKD", names{"Hong Kong", "Hongkong", "Hong Kong", "Hong Kong"}},
+	{"HM", "AN", "AUD", names{"Heard Island and McDonald Islands", "Heard und McDonaldinseln", "Islas Heard y McDonald", "Îles Heard-et-MacDonald"}},
+	{"HN", "NA", "HNL", names{"Honduras", "Honduras", "Honduras", "Honduras"}},
+	{"HR", "EU", "EUR", names{"Croatia", "Kroatien", "Croacia", "Croatie"}},
+	{"HT", "NA", "HTG", names{"Haiti", "Haiti", "Haití", "Haïti"}},
+	{"HU", "EU", "HUF", names{"Hungary", "Ungarn", "Hungría", "Hongrie"}},
+	{"ID", "AS", "IDR", names{"Indonesia", "Indonesien", "Indonesia", "Indonésie"}},
+	{"IE", "EU", "EUR", names{"Ireland", "Irland", "Irlanda", "Irlande"}},
+	{"IL", "AS", "ILS", names{"Israel", "Israel", "Israel", "Israël"}},
+	{"IM", "EU", "GBP", names{"Isle of Man", "Isle of Man", "Isla de Man", "Île de Man"}},
+	{"IN", "AS", "INR", names{"India", "Indien", "India", "Inde"}},
+	{"IO", "AS", "USD", names{"British Indian Ocean Territory", "Britisches Territorium im Indischen Ozean", "Territorio Británico del Océano Índico", "Territoire britannique de l'océan Indien"}},
+	{"IQ", "AS", "IQD", names{"Iraq", "Irak", "Irak", "Irak"}},
+	{"IR", "AS", "IRR", names{"Iran", "Iran", "Irán", "Iran"}},
+	{"IS", "EU", "ISK", names{"Iceland", "Island", "Islandia", "Islande"}},
+	{"IT", "EU", "EUR", names{"Italy", "Italien", "Italia", "Italie"}},
+	{"JE", "EU", "GBP", names{"Jersey", "Jersey", "Jersey", "Jersey"}},
+	{"JM", "NA", "JMD", names{"Jamaica", "Jamaika", "Jamaica", "Jamaïque"}},
+	{"JO", "AS", "JOD", names{"Jordan", "Jordanien", "Jordania", "Jordanie"}},
+	{"JP", "AS", "JPY", names{"Japan", "Japan", "Japón", "Japon"}},
+	{"KE", "AF", "KES", names{"Kenya", "Kenia", "Kenia", "Kenya"}},
+	{"KG", "AS", "KGS", names{"Kyrgyzstan", "Kirgisistan", "Kirguistán", "Kirghizistan"}},
+	{"KH", "AS", "KHR", names{"Cambodia", "Kambodscha", "Camboya", "Cambodge"}},
+	{"KI", "OC", "AUD", names{"Kiribati", "Kiribati", "Kiribati", "Kiribati"}},
+	{"KM", "AF", "KMF", names{"Comoros", "Komoren", "Comoras", "Comores"}},
+	{"KN", "NA", "XCD", names{"Saint Kitts and Nevis", "St. Kitts und Nevis", "San Cristóbal y Nieves", "Saint-Christophe-et-Niévès"}},
+	{"KP", "AS", "KPW", names{"North Korea", "Nordkorea", "Corea del Norte", "Corée du Nord"}},
+	{"KR", "AS", "KRW", names{"South Korea", "Südkorea", "Corea del Sur", "Corée du Sud"}},
+	{"KW", "AS", "KWD", names{"Kuwait", "Kuwait", "Kuwait", "Koweït"}},
+	{"KY", "NA", "KYD", names{"Cayman Islands", "Kaimaninseln", "Islas Caimán", "Îles Caïmans"}},
+	{"KZ", "AS", "KZT", names{"Kazakhstan", "Kasachstan", "Kazajistán", "Kazakhstan"}},
+	{"LA", "AS", "LAK", names{"Laos", "Laos", "Laos", "Laos"}},
+	{"LB", "AS", "LBP", names{"Lebanon", "Libanon", "Líbano", "Liban"}},
+	{"LC", "NA", "XCD", names{"Saint Lucia", "St. Lucia", "Santa Lucía", "Sainte-Lucie"}},
+	{"LI", "EU", "CHF", names{"Liechtenstein", "Liechtenstein", "Liechtenstein", "Liechtenstein"}},
+	{"LK", "AS", "LKR", names{"Sri Lanka", "Sri Lanka", "Sri Lanka", "Sri Lanka"}},
+	{"LR", "AF", "LRD", names{"Liberia", "Liberia", "Liberia", "Libéria"}},
+	{"LS", "AF", "LSL", names{"Lesotho", "Lesotho", "Lesoto", "Lesotho"}},
+	{"LT", "EU", "EUR", names{"Lithuania", "Litauen", "Lituania", "Lituanie"}},
+	{"LU", "EU", "EUR", names{"Luxembourg", "Luxemburg", "Luxemburgo", "Luxembourg"}},
+	{"LV", "EU", "EUR", names{"Latvia", "Lettland", "Letonia", "Lettonie"}},
+	{"LY", "AF", "LYD", names{"Libya", "Libyen", "Libia", "Libye"}},
+	{"MA", "AF", "MAD", names{"Morocco", "Marokko", "Marruecos", "Maroc"}},
+	{"MC", "EU", "EUR", names{"Monaco", "Monaco", "Mónaco", "Monaco"}},
+	{"MD", "EU", "MDL", names{"Moldova", "Moldau", "Moldavia", "Moldavie"}},
+	{"ME", "EU", "EUR", names{"Montenegro", "Montenegro", "Montenegro", "Monténégro"}},
+	{"MF", "NA", "EUR", names{"Saint Martin", "St. Martin", "San Martín", "Saint-Martin"}},
+	{"MG", "AF", "MGA", names{"Madagascar", "Madagaskar", "Madagascar", "Madagascar"}},
+	{"MH", "OC", "USD", names{"Marshall Islands", "Marshallinseln", "Islas Marshall", "Îles Marshall"}},
+	{"MK", "EU", "MKD", names{"North Macedonia", "Nordmazedonien", "Macedonia del Norte", "Macédoine du Nord"}},
+	{"ML", "AF", "XOF", names{"Mali", "Mali", "Mali", "Mali"}},
+	{"MM", "AS", "MMK", names{"Myanmar", "Myanmar", "Myanmar", "Myanmar"}},
+	{"MN", "AS", "MNT", names{"Mongolia", "Mongolei", "Mongolia", "Mongolie"}},
+	{"MO", "AS", "MOP", names{"Macao", "Macau", "Macao", "Macao"}},
+	{"MP", "OC", "USD", names{"Northern Mariana Islands", "Nördliche Marianen", "Islas Marianas del Norte", "Îles Mariannes du Nord"}},
+	{"MQ", "NA", "EUR", names{"Martinique", "Martinique", "Martinica", "Martinique"}},
+	{"MR", "AF", "MRU", names{"Mauritania", "Mauretanien", "Mauritania", "Mauritanie"}},
+	{"MS", "NA", "XCD", names{"Montserrat", "Montserrat", "Montserrat", "Montserrat"}},
+	{"MT", "EU", "EUR", names{"Malta", "Malta", "Malta", "Malte"}},
+	{"MU", "AF", "MUR", names{"Mauritius", "Mauritius", "Mauricio", "Maurice"}},
+	{"MV", "AS", "MVR", names{"Maldives", "Malediven", "Maldivas", "Maldives"}},
+	{"MW", "AF", "MWK", names{"Malawi", "Malawi", "Malaui", "Malawi"}},
+	{"MX", "NA", "MXN", names{"Mexico", "Mexiko", "México", "Mexique"}},
+	{"MY", "AS", "MYR", names{"Malaysia", "Malaysia", "Malasia", "Malaisie"}},
+	{"MZ", "AF", "MZN", names{"Mozambique", "Mosambik", "Mozambique", "Mozambique"}},
+	{"NA", "AF", "NAD", names{"Namibia", "Namibia", "Namibia", "Namibie"}},
+	{"NC", "OC", "XPF", names{"New Caledonia", "Neukaledonien", "Nueva Caledonia", "Nouvelle-Calédonie"}},
+	{"NE", "AF", "XOF", names{"Niger", "Niger", "Níger", "Niger"}},
+	{"NF", "OC", "AUD", names{"Norfolk Island", "Norfolkinsel", "Isla Norfolk", "Île Norfolk"}},
+	{"NG", "AF", "NGN", names{"Nigeria", "Nigeria", "Nigeria", "Nigeria"}},
+	{"NI", "NA", "NIO", names{"Nicaragua", "Nicaragua", "Nicaragua", "Nicaragua"}},
+	{"NL", "EU", "EUR", names{"Netherlands", "Niederlande", "Países Bajos", "Pays-Bas"}},
+	{"NO", "EU", "NOK", names{"Norway", "Norwegen", "Noruega", "Norvège"}},
+	{"NP", "AS", "NPR", names{"Nepal", "Nepal", "Nepal", "Népal"}},
+	{"NR", "OC", "AUD", names{"Nauru", "Nauru", "Nauru", "Nauru"}},
+	{"NU", "OC", "NZD", names{"Niue", "Niue", "Niue", "Niue"}},
+	{"NZ", "OC", "NZD", names{"New Zealand", "Neuseeland", "Nueva Zelanda", "Nouvelle-Zélande"}},
+	{"OM", "AS", "OMR", names{"Oman", "Oman", "Omán", "Oman"}},
+	{"PA", "NA", "PAB", names{"Panama", "Panama", "Panamá", "Panama"}},
+	{"PE", "SA", "PEN", names{"Peru", "Peru", "Perú", "Pérou"}},
+	{"PF", "OC", "XPF", names{"French Polynesia", "Französisch-Polynesien", "Polinesia Francesa", "Polynésie française"}},
+	{"PG", "OC", "PGK", names{"Papua New Guinea", "Papua-Neuguinea", "Papúa Nueva Guinea", "Papouasie-Nouvelle-Guinée"}},
+	{"PH", "AS", "PHP", names{"Philippines", "Philippinen", "Filipinas", "Philippines"}},
+	{"PK", "AS", "PKR", names{"Pakistan", "Pakistan", "Pakistán", "Pakistan"}},
+	{"PL", "EU", "PLN", names{"Poland", "Polen", "Polonia", "Pologne"}},
+	{"PM", "NA", "EUR", names{"Saint Pierre and Miquelon", "St. Pierre und Miquelon", "San Pedro y Miquelón", "Saint-Pierre-et-Miquelon"}},
+	{"PN", "OC", "NZD", names{"Pitcairn Islands", "Pitcairninseln", "Islas Pitcairn", "Îles Pitcairn"}},
+	{"PR", "NA", "USD", names{"Puerto Rico", "Puerto Rico", "Puerto Rico", "Porto Rico"}},
+	{"PS", "AS", "ILS", names{"Palestine", "Palästina", "Palestina", "Palestine"}},
+	{"PT", "EU", "EUR", names{"Portugal", "Portugal", "Portugal", "Portugal"}},
+	{"PW", "OC", "USD", names{"Palau", "Palau", "Palaos", "Palaos"}},
+	{"PY", "SA", "PYG", names{"Paraguay", "Paraguay", "Paraguay", "Paraguay"}},
+	{"QA", "AS", "QAR", names{"Qatar", "Katar", "Catar", "Qatar"}},
+	{"RE", "AF", "EUR", names{"Réunion", "Réunion", "Reunión", "La Réunion"}},
+	{"RO", "EU", "RON", names{"Romania", "Rumänien", "Rumania", "Roumanie"}},
+	{"RS", "EU", "RSD", names{"Serbia", "Serbien", "Serbia", "Serbie"}},
+	{"RU", "EU", "RUB", names{"Russia", "Russland", "Rusia", "Russie"}},
+	{"RW", "AF", "RWF", names{"Rwanda", "Ruanda", "Ruanda", "Rwanda"}},
+	{"SA", "AS", "SAR", names{"Saudi Arabia", "Saudi-Arabien", "Arabia Saudí", "Arabie saoudite"}},
+	{"SB", "OC", "SBD", names{"Solomon Islands", "Salomonen", "Islas Salomón", "Îles Salomon"}},
+	{"SC", "AF", "SCR", names{"Seychelles", "Seychellen", "Seychelles", "Seychelles"}},
+	{"SD", "AF", "SDG", names{"Sudan", "Sudan", "Sudán", "Soudan"}},
+	{"SE", "EU", "SEK", names{"Sweden", "Schweden", "Suecia", "Suède"}},
+	{"SG", "AS", "SGD", names{"Singapore", "Singapur", "Singapur", "Singapour"}},
+	{"SH", "AF", "SHP", names{"Saint Helena", "St. Helena", "Santa Elena", "Sainte-Hélène"}},
+	{"SI", "EU", "EUR", names{"Slovenia", "Slowenien", "Eslovenia", "Slovénie"}},
+	{"SJ", "EU", "NOK", names{"Svalbard and Jan Mayen", "Spitzbergen und Jan Mayen", "Svalbard y Jan Mayen", "Svalbard et Jan Mayen"}},
+	{"SK", "EU", "EUR", names{"Slovakia", "Slowakei", "Eslovaquia", "Slovaquie"}},
+	{"SL", "AF", "SLE", names{"Sierra Leone", "Sierra Leone", "Sierra Leona", "Sierra Leone"}},
+	{"SM", "EU", "EUR", names{"San Marino", "San Marino", "San Marino", "Saint-Marin"}},
+	{"SN", "AF", "XOF", names{"Senegal", "Senegal", "Senegal", "Sénégal"}},
+	{"SO", "AF", "SOS", names{"Somalia", "Somalia", "Somalia", "Somalie"}},
+	{"SR", "SA", "SRD", names{"Suriname", "Suriname", "Surinam", "Suriname"}},
+	{"SS", "AF", "SSP", names{"South Sudan", "Südsudan", "Sudán del Sur", "Soudan du Sud"}},
+	{"ST", "AF", "STN", names{"São Tomé and Príncipe", "São Tomé und Príncipe", "Santo Tomé y Príncipe", "Sao Tomé-et-Principe"}},
+	{"SV", "NA", "USD", names{"El Salvador", "El Salvador", "El Salvador", "Salvador"}},
+	{"SX", "NA", "XCG", names{"Sint Maarten", "Sint Maarten", "Sint Maarten", "Saint-Martin (partie néerlandaise)"}},
+	{"SY", "AS", "SYP", names{"Syria", "Syrien", "Siria", "Syrie"}},
+	{"SZ", "AF", "SZL", names{"Eswatini", "Eswatini", "Esuatini", "Eswatini"}},
+	{"TC", "NA", "USD", names{"Turks and Caicos Islands", "Turks- und Caicosinseln", "Islas Turcas y Caicos", "Îles Turques-et-Caïques"}},
+	{"TD", "AF", "XAF", names{"Chad", "Tschad", "Chad", "Tchad"}},
+	{"TF", "AN", "EUR", names{"French Southern Territories", "Französische Süd- und Antarktisgebiete", "Territorios Australes Franceses", "Terres australes françaises"}},
+	{"TG", "AF", "XOF", names{"Togo", "Togo", "Togo", "Togo"}},
+	{"TH", "AS", "THB", names{"Thailand", "Thailand", "Tailandia", "Thaïlande"}},
+	{"TJ", "AS", "TJS", names{"Tajikistan", "Tadschikistan", "Tayikistán", "Tadjikistan"}},
+	{"TK", "OC", "NZD", names{"Tokelau", "Tokelau", "Tokelau", "Tokelau"}},
+	{"TL", "AS", "USD", names{"Timor-Leste", "Timor-Leste", "Timor Oriental", "Timor oriental"}},
+	{"TM", "AS", "TMT", names{"Turkmenistan", "Turkmenistan", "Turkmenistán", "Turkménistan"}},
+	{"TN", "AF", "TND", names{"Tunisia", "Tunesien", "Túnez", "Tunisie"}},
+	{"TO", "OC", "TOP", names{"Tonga", "Tonga", "Tonga", "Tonga"}},
+	{"TR", "AS", "TRY", names{"Turkey", "Türkei", "Turquía", "Turquie"}},
+	{"TT", "NA", "TTD", names{"Trinidad and Tobago", "Trinidad und Tobago", "Trinidad y Tobago", "Trinité-et-Tobago"}},
+	{"TV", "OC", "AUD", names{"Tuvalu", "Tuvalu", "Tuvalu", "Tuvalu"}},
+	{"TW", "AS", "TWD", names{"Taiwan", "Taiwan", "Taiwán", "Taïwan"}},
+	{"TZ", "AF", "TZS", names{"Tanzania", "Tansania", "Tanzania", "Tanzanie"}},
+	{"UA", "EU", "UAH", names{"Ukraine", "Ukraine", "Ucrania", "Ukraine"}},
+	{"UG", "AF", "UGX", names{"Uganda", "Uganda", "Uganda", "Ouganda"}},
+	{"UM", "OC", "USD", names{"U.S. Outlying Islands", "Amerikanische Überseeinseln", "Islas menores alejadas de EE. UU.", "Îles mineures éloignées des États-Unis"}},
+	{"US", "NA", "USD", names{"United States", "Vereinigte Staaten", "Estados Unidos", "États-Unis"}},
+	{"UY", "SA", "UYU", names{"Uruguay", "Uruguay", "Uruguay", "Uruguay"}},
+	{"UZ", "AS", "UZS", names{"Uzbekistan", "Usbekistan", "Uzbekistán", "Ouzbékistan"}},
+	{"VA", "EU", "EUR", names{"Vatican City", "Vatikanstadt", "Ciudad del Vaticano", "Cité du Vatican"}},
+	{"VC", "NA", "XCD", names{"Saint Vincent and the Grenadines", "St. Vincent und die Grenadinen", "San Vicente y las Granadinas", "Saint-Vincent-et-les-Grenadines"}},
+	{"VE", "SA", "VES", names{"Venezuela", "Venezuela", "Venezuela", "Venezuela"}},
+	{"VG", "NA", "USD", names{"British Virgin Islands", "Britische Jungferninseln", "Islas Vírgenes Británicas", "Îles Vierges britanniques"}},
+	{"VI", "NA", "USD", names{"U.S. Virgin Islands", "Amerikanische Jungferninseln", "Islas Vírgenes de EE. UU.", "Îles Vierges des États-Unis"}},
+	{"VN", "AS", "VND", names{"Vietnam", "Vietnam", "Vietnam", "Viêt Nam"}},
+	{"VU", "OC", "VUV", names{"Vanuatu", "Vanuatu", "Vanuatu", "Vanuatu"}},
+	{"WF", "OC", "XPF", names{"Wallis and Futuna", "Wallis und Futuna", "Wallis y Futuna", "Wallis-et-Futuna"}},
+	{"WS", "OC", "WST", names{"Samoa", "Samoa", "Samoa", "Samoa"}},
+	{"XK", "EU", "EUR", names{"Kosovo", "Kosovo", "Kosovo", "Kosovo"}},
+	{"YE", "AS", "YER", names{"Yemen", "Jemen", "Yemen", "Yémen"}},
+	{"YT", "AF", "EUR", names{"Mayotte", "Mayotte", "Mayotte", "Mayotte"}},
+	{"ZA", "AF", "ZAR", names{"South Africa", "Südafrika", "Sudáfrica", "Afrique du Sud"}},
+	{"ZM", "AF", "ZMW", names{"Zambia", "Sambia", "Zambia", "Zambie"}},
+	{"ZW", "AF", "USD", names{"Zimbabwe", "Simbabwe", "Zimbabue", "Zimbabwe"}},
+}
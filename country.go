@@ -0,0 +1,68 @@
+package iplocate
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DefaultCountryCacheTTL is how long LookupCountry caches a country code.
+// Country assignments rarely change, so the cache is kept for a long time.
+const DefaultCountryCacheTTL = 24 * time.Hour
+
+// countryCache is the cache used by LookupCountry
+type countryCache struct {
+	cache Cache
+	ttl   time.Duration
+}
+
+// WithCountryCache replaces the cache used by LookupCountry. By default it
+// uses its own MemoryCache with DefaultCountryCacheTTL. A nil cache disables
+// caching of country codes.
+func (c *Client) WithCountryCache(cache Cache, ttl time.Duration) *Client {
+	c.countries = &countryCache{cache: cache, ttl: ttl}
+	return c
+}
+
+// LookupCountry returns the ISO 3166-1 alpha-2 country code of ip, or an
+// empty string if the API has no country for it. Only the country code is
+// requested from the API, and results are cached.
+func (c *Client) LookupCountry(ctx context.Context, ip string) (string, error) {
+	normalized, ok := normalizeIP(ip)
+	if !ok {
+		return "", fmt.Errorf("invalid IP address: %s", ip)
+	}
+	ip = normalized
+
+	key := "country:" + ip
+	countries := c.countryCache()
+	if countries.cache != nil {
+		if code, ok := countries.cache.Get(key); ok {
+			return string(code), nil
+		}
+	}
+
+	result, err := c.LookupContext(ctx, ip, Fields("country_code"))
+	if err != nil {
+		return "", err
+	}
+
+	var code string
+	if result.CountryCode != nil {
+		code = *result.CountryCode
+	}
+	if countries.cache != nil {
+		countries.cache.Set(key, []byte(code), countries.ttl)
+	}
+	return code, nil
+}
+
+// countryCache returns the country cache, creating the default one on first use
+func (c *Client) countryCache() *countryCache {
+	c.countriesOnce.Do(func() {
+		if c.countries == nil {
+			c.countries = &countryCache{cache: NewMemoryCache(0), ttl: DefaultCountryCacheTTL}
+		}
+	})
+	return c.countries
+}
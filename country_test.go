@@ -0,0 +1,95 @@
+package iplocate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupCountry(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		assert.Equal(t, "country_code", r.URL.Query().Get("fields"))
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8", CountryCode: stringPtr("US")})
+	}))
+	defer server.Close()
+
+	client := NewClient(nil).WithBaseURL(server.URL)
+
+	code, err := client.LookupCountry(context.Background(), "8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "US", code)
+
+	code, err = client.LookupCountry(context.Background(), "8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "US", code)
+	assert.Equal(t, 1, calls)
+}
+
+func TestLookupCountry_NoCountry(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		json.NewEncoder(w).Encode(LookupResponse{IP: "10.0.0.1"})
+	}))
+	defer server.Close()
+
+	client := NewClient(nil).WithBaseURL(server.URL)
+
+	for i := 0; i < 2; i++ {
+		code, err := client.LookupCountry(context.Background(), "10.0.0.1")
+		require.NoError(t, err)
+		assert.Empty(t, code)
+	}
+	assert.Equal(t, 1, calls)
+}
+
+func TestLookupCountry_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Rate limit exceeded"})
+	}))
+	defer server.Close()
+
+	client := NewClient(nil).WithBaseURL(server.URL)
+
+	_, err := client.LookupCountry(context.Background(), "invalid-ip")
+	assert.ErrorContains(t, err, "invalid IP address")
+
+	_, err = client.LookupCountry(context.Background(), "8.8.8.8")
+	_, ok := err.(*APIError)
+	assert.True(t, ok)
+}
+
+func TestWithCountryCache(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8", CountryCode: stringPtr("US")})
+	}))
+	defer server.Close()
+
+	cache := NewMemoryCache(10)
+	client := NewClient(nil).WithBaseURL(server.URL).WithCountryCache(cache, time.Hour)
+
+	_, err := client.LookupCountry(context.Background(), "8.8.8.8")
+	require.NoError(t, err)
+	value, ok := cache.Get("country:8.8.8.8")
+	assert.True(t, ok)
+	assert.Equal(t, "US", string(value))
+
+	// Without a cache every call reaches the API
+	client.WithCountryCache(nil, 0)
+	_, err = client.LookupCountry(context.Background(), "8.8.8.8")
+	require.NoError(t, err)
+	_, err = client.LookupCountry(context.Background(), "8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}
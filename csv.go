@@ -0,0 +1,217 @@
+package iplocate
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// csvColumn extracts one CSV column from a response
+type csvColumn struct {
+	name  string
+	value func(r *LookupResponse) string
+}
+
+// csvColumns are the columns in the order of DefaultCSVColumns. Nested
+// fields are named by their JSON path, e.g. "asn.name".
+var csvColumns = []csvColumn{
+	{"ip", func(r *LookupResponse) string { return r.IP }},
+	{"hostname", func(r *LookupResponse) string { return stringOrEmpty(r.Hostname) }},
+	{"country", func(r *LookupResponse) string { return stringOrEmpty(r.Country) }},
+	{"country_code", func(r *LookupResponse) string { return stringOrEmpty(r.CountryCode) }},
+	{"is_eu", func(r *LookupResponse) string { return strconv.FormatBool(r.IsEU) }},
+	{"city", func(r *LookupResponse) string { return stringOrEmpty(r.City) }},
+	{"continent", func(r *LookupResponse) string { return stringOrEmpty(r.Continent) }},
+	{"latitude", func(r *LookupResponse) string { return csvFloat(r.Latitude) }},
+	{"longitude", func(r *LookupResponse) string { return csvFloat(r.Longitude) }},
+	{"time_zone", func(r *LookupResponse) string { return stringOrEmpty(r.TimeZone) }},
+	{"postal_code", func(r *LookupResponse) string { return stringOrEmpty(r.PostalCode) }},
+	{"subdivision", func(r *LookupResponse) string { return stringOrEmpty(r.Subdivision) }},
+	{"currency_code", func(r *LookupResponse) string { return stringOrEmpty(r.CurrencyCode) }},
+	{"calling_code", func(r *LookupResponse) string { return stringOrEmpty(r.CallingCode) }},
+	{"network", func(r *LookupResponse) string { return stringOrEmpty(r.Network) }},
+	{"asn.asn", asnColumn(func(a *ASN) string { return a.ASN })},
+	{"asn.route", asnColumn(func(a *ASN) string { return a.Route })},
+	{"asn.netname", asnColumn(func(a *ASN) string { return a.Netname })},
+	{"asn.name", asnColumn(func(a *ASN) string { return a.Name })},
+	{"asn.country_code", asnColumn(func(a *ASN) string { return a.CountryCode })},
+	{"asn.domain", asnColumn(func(a *ASN) string { return a.Domain })},
+	{"asn.type", asnColumn(func(a *ASN) string { return a.Type })},
+	{"asn.rir", asnColumn(func(a *ASN) string { return a.RIR })},
+	{"privacy.is_abuser", privacyColumn(func(p Privacy) bool { return p.IsAbuser })},
+	{"privacy.is_anonymous", privacyColumn(func(p Privacy) bool { return p.IsAnonymous })},
+	{"privacy.is_bogon", privacyColumn(func(p Privacy) bool { return p.IsBogon })},
+	{"privacy.is_hosting", privacyColumn(func(p Privacy) bool { return p.IsHosting })},
+	{"privacy.is_icloud_relay", privacyColumn(func(p Privacy) bool { return p.IsIcloudRelay })},
+	{"privacy.is_proxy", privacyColumn(func(p Privacy) bool { return p.IsProxy })},
+	{"privacy.is_tor", privacyColumn(func(p Privacy) bool { return p.IsTor })},
+	{"privacy.is_vpn", privacyColumn(func(p Privacy) bool { return p.IsVPN })},
+	{"company.name", companyColumn(func(c *Company) string { return c.Name })},
+	{"company.domain", companyColumn(func(c *Company) string { return c.Domain })},
+	{"company.country_code", companyColumn(func(c *Company) string { return c.CountryCode })},
+	{"company.type", companyColumn(func(c *Company) string { return c.Type })},
+	{"hosting.provider", hostingColumn(func(h *Hosting) *string { return h.Provider })},
+	{"hosting.domain", hostingColumn(func(h *Hosting) *string { return h.Domain })},
+	{"hosting.network", hostingColumn(func(h *Hosting) *string { return h.Network })},
+	{"hosting.region", hostingColumn(func(h *Hosting) *string { return h.Region })},
+	{"hosting.service", hostingColumn(func(h *Hosting) *string { return h.Service })},
+	{"abuse.address", abuseColumn(func(a *Abuse) *string { return a.Address })},
+	{"abuse.country_code", abuseColumn(func(a *Abuse) *string { return a.CountryCode })},
+	{"abuse.email", abuseColumn(func(a *Abuse) *string { return a.Email })},
+	{"abuse.name", abuseColumn(func(a *Abuse) *string { return a.Name })},
+	{"abuse.network", abuseColumn(func(a *Abuse) *string { return a.Network })},
+	{"abuse.phone", abuseColumn(func(a *Abuse) *string { return a.Phone })},
+}
+
+// DefaultCSVColumns are every CSV column, in the order used when no columns are given
+var DefaultCSVColumns = func() []string {
+	names := make([]string, len(csvColumns))
+	for i, column := range csvColumns {
+		names[i] = column.name
+	}
+	return names
+}()
+
+var csvColumnsByName = func() map[string]csvColumn {
+	byName := make(map[string]csvColumn, len(csvColumns))
+	for _, column := range csvColumns {
+		byName[column.name] = column
+	}
+	return byName
+}()
+
+// CSVHeader returns the header row for columns, or for DefaultCSVColumns
+// if none are given
+func (r *LookupResponse) CSVHeader(columns ...string) []string {
+	if len(columns) == 0 {
+		columns = DefaultCSVColumns
+	}
+	return append([]string(nil), columns...)
+}
+
+// CSVRecord returns the response as a CSV row with the given columns, or
+// DefaultCSVColumns if none are given. Missing values and unknown columns
+// are empty; booleans are "true" or "false".
+func (r *LookupResponse) CSVRecord(columns ...string) []string {
+	if len(columns) == 0 {
+		columns = DefaultCSVColumns
+	}
+	record := make([]string, len(columns))
+	for i, name := range columns {
+		if column, ok := csvColumnsByName[name]; ok {
+			record[i] = column.value(r)
+		}
+	}
+	return record
+}
+
+// CSVWriter writes responses as CSV rows, preceded by a header row
+type CSVWriter struct {
+	w           *csv.Writer
+	columns     []string
+	wroteHeader bool
+}
+
+// NewCSVWriter returns a writer of the given columns to w, or of
+// DefaultCSVColumns if none are given
+func NewCSVWriter(w io.Writer, columns ...string) *CSVWriter {
+	if len(columns) == 0 {
+		columns = DefaultCSVColumns
+	}
+	return &CSVWriter{w: csv.NewWriter(w), columns: columns}
+}
+
+// Write writes resp as a row, writing the header row first if it hasn't
+// been written yet. It fails if a column is unknown.
+func (w *CSVWriter) Write(resp *LookupResponse) error {
+	if !w.wroteHeader {
+		if err := w.WriteHeader(); err != nil {
+			return err
+		}
+	}
+	return w.w.Write(resp.CSVRecord(w.columns...))
+}
+
+// WriteHeader writes the header row. Write calls it automatically; call it
+// directly to produce a header for an empty result set.
+func (w *CSVWriter) WriteHeader() error {
+	for _, name := range w.columns {
+		if _, ok := csvColumnsByName[name]; !ok {
+			return fmt.Errorf("unknown CSV column: %q", name)
+		}
+	}
+	w.wroteHeader = true
+	return w.w.Write(w.columns)
+}
+
+// WriteAll writes every response and flushes the output
+func (w *CSVWriter) WriteAll(resps []*LookupResponse) error {
+	for _, resp := range resps {
+		if err := w.Write(resp); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// Flush writes any buffered rows to the underlying writer
+func (w *CSVWriter) Flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// stringOrEmpty dereferences s, or returns an empty string if s is nil
+func stringOrEmpty(s *string) string {
+	v, _ := value(s)
+	return v
+}
+
+func csvFloat(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f, 'f', -1, 64)
+}
+
+func asnColumn(field func(*ASN) string) func(*LookupResponse) string {
+	return func(r *LookupResponse) string {
+		if r.ASN == nil {
+			return ""
+		}
+		return field(r.ASN)
+	}
+}
+
+func privacyColumn(field func(Privacy) bool) func(*LookupResponse) string {
+	return func(r *LookupResponse) string {
+		return strconv.FormatBool(field(r.Privacy))
+	}
+}
+
+func companyColumn(field func(*Company) string) func(*LookupResponse) string {
+	return func(r *LookupResponse) string {
+		if r.Company == nil {
+			return ""
+		}
+		return field(r.Company)
+	}
+}
+
+func hostingColumn(field func(*Hosting) *string) func(*LookupResponse) string {
+	return func(r *LookupResponse) string {
+		if r.Hosting == nil {
+			return ""
+		}
+		return stringOrEmpty(field(r.Hosting))
+	}
+}
+
+func abuseColumn(field func(*Abuse) *string) func(*LookupResponse) string {
+	return func(r *LookupResponse) string {
+		if r.Abuse == nil {
+			return ""
+		}
+		return stringOrEmpty(field(r.Abuse))
+	}
+}
@@ -0,0 +1,59 @@
+package iplocate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSVRecord(t *testing.T) {
+	resp := &LookupResponse{
+		IP:          "8.8.8.8",
+		Hostname:    stringPtr("dns.google"),
+		CountryCode: stringPtr("US"),
+		Latitude:    float64Ptr(37.386),
+		ASN:         &ASN{ASN: "AS15169", Name: "Google LLC"},
+		Privacy:     Privacy{IsHosting: true},
+		Hosting:     &Hosting{Provider: stringPtr("Google Cloud")},
+	}
+
+	columns := []string{"ip", "hostname", "country_code", "city", "latitude", "asn.name", "privacy.is_hosting", "hosting.provider", "abuse.email", "unknown"}
+	assert.Equal(t, columns, resp.CSVHeader(columns...))
+	assert.Equal(t, []string{"8.8.8.8", "dns.google", "US", "", "37.386", "Google LLC", "true", "Google Cloud", "", ""}, resp.CSVRecord(columns...))
+}
+
+func TestCSVRecord_DefaultColumns(t *testing.T) {
+	resp := &LookupResponse{IP: "8.8.8.8"}
+	assert.Equal(t, DefaultCSVColumns, resp.CSVHeader())
+	record := resp.CSVRecord()
+	require.Len(t, record, len(DefaultCSVColumns))
+	assert.Equal(t, "8.8.8.8", record[0])
+}
+
+func TestCSVWriter(t *testing.T) {
+	var buf strings.Builder
+	w := NewCSVWriter(&buf, "ip", "country", "asn.asn")
+
+	err := w.WriteAll([]*LookupResponse{
+		{IP: "8.8.8.8", Country: stringPtr("United States"), ASN: &ASN{ASN: "AS15169"}},
+		{IP: "1.1.1.1", Country: stringPtr("Australia, Oceania")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ip,country,asn.asn\n8.8.8.8,United States,AS15169\n1.1.1.1,\"Australia, Oceania\",\n", buf.String())
+}
+
+func TestCSVWriter_UnknownColumn(t *testing.T) {
+	var buf strings.Builder
+	err := NewCSVWriter(&buf, "ip", "nope").Write(&LookupResponse{IP: "8.8.8.8"})
+	assert.EqualError(t, err, `unknown CSV column: "nope"`)
+}
+
+func TestCSVWriter_HeaderOnly(t *testing.T) {
+	var buf strings.Builder
+	w := NewCSVWriter(&buf, "ip")
+	require.NoError(t, w.WriteHeader())
+	require.NoError(t, w.Flush())
+	assert.Equal(t, "ip\n", buf.String())
+}
@@ -0,0 +1,405 @@
+// Package db downloads and serves IPLocate database files. A Manager keeps
+// local copies of the databases up to date, verifies their checksums and
+// swaps in new versions without interrupting lookups.
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iplocate/go-iplocate"
+)
+
+const (
+	// DefaultDownloadURL is the base URL database files are downloaded from
+	DefaultDownloadURL = "https://iplocate.io/download"
+	// DefaultUpdateInterval is how often a started Manager checks for updates
+	DefaultUpdateInterval = 24 * time.Hour
+)
+
+// Database editions
+const (
+	Country = "ip-to-country"
+	ASN     = "ip-to-asn"
+)
+
+// ErrChecksumMismatch is returned when a downloaded file doesn't match its published checksum
+var ErrChecksumMismatch = errors.New("database checksum mismatch")
+
+// Manager downloads database editions into a directory and serves lookups
+// from them. It is safe for concurrent use.
+type Manager struct {
+	apiKey      string
+	dir         string
+	editions    []string
+	downloadURL string
+	httpClient  *http.Client
+	interval    time.Duration
+	onError     func(error)
+
+	mu  sync.RWMutex
+	dbs map[string]*iplocate.Database
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewManager creates a manager that keeps the given editions in dir,
+// downloading them with apiKey. Without editions, Country is used.
+func NewManager(apiKey, dir string, editions ...string) *Manager {
+	if len(editions) == 0 {
+		editions = []string{Country}
+	}
+	return &Manager{
+		apiKey:      apiKey,
+		dir:         dir,
+		editions:    editions,
+		downloadURL: DefaultDownloadURL,
+		httpClient:  &http.Client{Timeout: 10 * time.Minute},
+		interval:    DefaultUpdateInterval,
+		dbs:         make(map[string]*iplocate.Database),
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for downloads
+func (m *Manager) WithHTTPClient(httpClient *http.Client) *Manager {
+	m.httpClient = httpClient
+	return m
+}
+
+// WithDownloadURL sets a custom base URL to download database files from
+func (m *Manager) WithDownloadURL(downloadURL string) *Manager {
+	m.downloadURL = strings.TrimSuffix(downloadURL, "/")
+	return m
+}
+
+// WithUpdateInterval sets how often a started manager checks for updates
+func (m *Manager) WithUpdateInterval(interval time.Duration) *Manager {
+	m.interval = interval
+	return m
+}
+
+// WithErrorHandler sets a callback for errors from background updates
+func (m *Manager) WithErrorHandler(fn func(error)) *Manager {
+	m.onError = fn
+	return m
+}
+
+// Open loads the editions already present in the directory and downloads
+// the ones that are missing
+func (m *Manager) Open(ctx context.Context) error {
+	for _, edition := range m.editions {
+		path := m.path(edition)
+		if _, err := os.Stat(path); err == nil {
+			if err := m.swap(edition, path); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := m.update(ctx, edition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Update downloads any edition whose published checksum differs from the
+// local copy, verifies it and swaps it in
+func (m *Manager) Update(ctx context.Context) error {
+	var errs []error
+	for _, edition := range m.editions {
+		if err := m.update(ctx, edition); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Start checks for updates in the background every update interval until
+// Close is called. Errors are passed to the error handler. Start must be
+// called at most once.
+func (m *Manager) Start() {
+	m.stop = make(chan struct{})
+	m.done = make(chan struct{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-m.stop
+		cancel()
+	}()
+
+	go func() {
+		defer close(m.done)
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-m.stop:
+				return
+			case <-ticker.C:
+				if err := m.Update(ctx); err != nil && m.onError != nil {
+					m.onError(err)
+				}
+			}
+		}
+	}()
+}
+
+// Close stops background updates and closes the open databases
+func (m *Manager) Close() error {
+	m.stopOnce.Do(func() {
+		if m.stop != nil {
+			close(m.stop)
+			<-m.done
+		}
+	})
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var errs []error
+	for edition, db := range m.dbs {
+		if err := db.Close(); err != nil {
+			errs = append(errs, err)
+		}
+		delete(m.dbs, edition)
+	}
+	return errors.Join(errs...)
+}
+
+// Lookup returns the data the databases hold for ip, with the same
+// signature as the API client's Lookup. Editions are merged in the order
+// they were given to NewManager.
+func (m *Manager) Lookup(ip string) (*iplocate.LookupResponse, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result *iplocate.LookupResponse
+	for _, edition := range m.editions {
+		db, ok := m.dbs[edition]
+		if !ok {
+			continue
+		}
+		record, err := db.Lookup(ip)
+		if errors.Is(err, iplocate.ErrNotInDatabase) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if result == nil {
+			result = record
+		} else {
+			result.Fill(record)
+		}
+	}
+
+	if result == nil {
+		if len(m.dbs) == 0 {
+			return nil, errors.New("no database loaded")
+		}
+		return nil, iplocate.ErrNotInDatabase
+	}
+	return result, nil
+}
+
+// LookupContext is like Lookup. The context is accepted for symmetry with
+// the API client; local lookups don't block.
+func (m *Manager) LookupContext(ctx context.Context, ip string) (*iplocate.LookupResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return m.Lookup(ip)
+}
+
+// path returns where an edition is stored
+func (m *Manager) path(edition string) string {
+	return filepath.Join(m.dir, edition+".mmdb")
+}
+
+// update downloads an edition if its checksum changed and swaps it in
+func (m *Manager) update(ctx context.Context, edition string) error {
+	want, err := m.fetchChecksum(ctx, edition)
+	if err != nil {
+		return fmt.Errorf("%s: %w", edition, err)
+	}
+
+	path := m.path(edition)
+	if have, err := fileChecksum(path); err == nil && have == want {
+		m.mu.RLock()
+		_, loaded := m.dbs[edition]
+		m.mu.RUnlock()
+		if loaded {
+			return nil
+		}
+		return m.swap(edition, path)
+	}
+
+	tmp, err := m.download(ctx, edition)
+	if err != nil {
+		return fmt.Errorf("%s: %w", edition, err)
+	}
+	defer os.Remove(tmp)
+
+	have, err := fileChecksum(tmp)
+	if err != nil {
+		return fmt.Errorf("%s: %w", edition, err)
+	}
+	if have != want {
+		return fmt.Errorf("%s: %w", edition, ErrChecksumMismatch)
+	}
+
+	// Make sure the file is a readable database before replacing the old one
+	check, err := iplocate.OpenDatabase(tmp)
+	if err != nil {
+		return fmt.Errorf("%s: %w", edition, err)
+	}
+	check.Close()
+
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("%s: failed to install database: %w", edition, err)
+	}
+	return m.swap(edition, path)
+}
+
+// swap opens the file at path and replaces the edition's open database
+func (m *Manager) swap(edition, path string) error {
+	db, err := iplocate.OpenDatabase(path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", edition, err)
+	}
+
+	m.mu.Lock()
+	old := m.dbs[edition]
+	m.dbs[edition] = db
+	m.mu.Unlock()
+
+	// Lookups hold the read lock, so none can be using the old database now
+	if old != nil {
+		old.Close()
+	}
+	return nil
+}
+
+// fileURL returns the download URL for an edition's file with the given suffix
+func (m *Manager) fileURL(edition, suffix string) string {
+	query := url.Values{}
+	if m.apiKey != "" {
+		query.Set("apikey", m.apiKey)
+	}
+	u := fmt.Sprintf("%s/%s.mmdb%s", m.downloadURL, url.PathEscape(edition), suffix)
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+// fetchChecksum returns the published SHA-256 of an edition
+func (m *Manager) fetchChecksum(ctx context.Context, edition string) (string, error) {
+	body, err := m.get(ctx, m.fileURL(edition, ".sha256"))
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(body, 1024))
+	if err != nil {
+		return "", fmt.Errorf("failed to read checksum: %w", err)
+	}
+
+	// Accept both a bare digest and "<digest>  <filename>"
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 || len(fields[0]) != sha256.Size*2 {
+		return "", fmt.Errorf("invalid checksum: %q", strings.TrimSpace(string(data)))
+	}
+	return strings.ToLower(fields[0]), nil
+}
+
+// download saves an edition to a temporary file in the directory
+func (m *Manager) download(ctx context.Context, edition string) (string, error) {
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	body, err := m.get(ctx, m.fileURL(edition, ""))
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	f, err := os.CreateTemp(m.dir, edition+"-*.mmdb.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to create file: %w", err)
+	}
+	if _, err := io.Copy(f, body); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to download database: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write database: %w", err)
+	}
+	return f.Name(), nil
+}
+
+// get performs a GET request and returns the body of a 200 response
+func (m *Manager) get(ctx context.Context, rawURL string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", iplocate.DefaultUserAgent)
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", redactURLError(err))
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("download failed (%d)", resp.StatusCode)
+	}
+	return resp.Body, nil
+}
+
+// redactURLError strips the query, which holds the API key, from the URL of
+// a transport error, whose text includes the request URL
+func redactURLError(err error) error {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return err
+	}
+	if u, parseErr := url.Parse(urlErr.URL); parseErr == nil {
+		u.RawQuery = ""
+		urlErr.URL = u.String()
+	}
+	return err
+}
+
+// fileChecksum returns the hex SHA-256 of the file at path
+func fileChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,180 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/iplocate/go-iplocate"
+	"github.com/maxmind/mmdbwriter"
+	"github.com/maxmind/mmdbwriter/mmdbtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildDatabase returns an MMDB file mapping 8.8.8.0/24 to the country code
+func buildDatabase(t *testing.T, countryCode string) []byte {
+	t.Helper()
+
+	writer, err := mmdbwriter.New(mmdbwriter.Options{DatabaseType: Country, RecordSize: 24})
+	require.NoError(t, err)
+	_, network, err := net.ParseCIDR("8.8.8.0/24")
+	require.NoError(t, err)
+	require.NoError(t, writer.Insert(network, mmdbtype.Map{
+		"country_code":   mmdbtype.String(countryCode),
+		"continent_code": mmdbtype.String("NA"),
+	}))
+
+	var buf bytes.Buffer
+	_, err = writer.WriteTo(&buf)
+	require.NoError(t, err)
+	return buf.Bytes()
+}
+
+// downloadServer serves a database file and its checksum
+type downloadServer struct {
+	*httptest.Server
+	mu        sync.Mutex
+	data      []byte
+	checksum  string
+	downloads int32
+}
+
+func newDownloadServer(t *testing.T, data []byte) *downloadServer {
+	s := &downloadServer{}
+	s.set(data)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-key", r.URL.Query().Get("apikey"))
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		switch r.URL.Path {
+		case "/ip-to-country.mmdb.sha256":
+			w.Write([]byte(s.checksum + "  ip-to-country.mmdb\n"))
+		case "/ip-to-country.mmdb":
+			atomic.AddInt32(&s.downloads, 1)
+			w.Write(s.data)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *downloadServer) set(data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sum := sha256.Sum256(data)
+	s.data = data
+	s.checksum = hex.EncodeToString(sum[:])
+}
+
+func TestManager_OpenDownloads(t *testing.T) {
+	server := newDownloadServer(t, buildDatabase(t, "US"))
+	dir := t.TempDir()
+
+	m := NewManager("test-key", dir).WithDownloadURL(server.URL)
+	require.NoError(t, m.Open(context.Background()))
+	defer m.Close()
+
+	result, err := m.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "US", *result.CountryCode)
+	assert.FileExists(t, filepath.Join(dir, "ip-to-country.mmdb"))
+
+	_, err = m.Lookup("1.1.1.1")
+	assert.ErrorIs(t, err, iplocate.ErrNotInDatabase)
+}
+
+func TestManager_OpenUsesExistingFile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ip-to-country.mmdb"), buildDatabase(t, "CA"), 0o644))
+
+	m := NewManager("test-key", dir).WithDownloadURL("http://127.0.0.1:0")
+	require.NoError(t, m.Open(context.Background()))
+	defer m.Close()
+
+	result, err := m.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "CA", *result.CountryCode)
+}
+
+func TestManager_UpdateSwapsDatabase(t *testing.T) {
+	server := newDownloadServer(t, buildDatabase(t, "US"))
+
+	m := NewManager("test-key", t.TempDir()).WithDownloadURL(server.URL)
+	require.NoError(t, m.Open(context.Background()))
+	defer m.Close()
+
+	// Unchanged checksum: nothing is downloaded
+	require.NoError(t, m.Update(context.Background()))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&server.downloads))
+
+	server.set(buildDatabase(t, "MX"))
+	require.NoError(t, m.Update(context.Background()))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&server.downloads))
+
+	result, err := m.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "MX", *result.CountryCode)
+}
+
+func TestManager_ChecksumMismatch(t *testing.T) {
+	server := newDownloadServer(t, buildDatabase(t, "US"))
+	server.mu.Lock()
+	server.checksum = hex.EncodeToString(make([]byte, sha256.Size))
+	server.mu.Unlock()
+
+	dir := t.TempDir()
+	m := NewManager("test-key", dir).WithDownloadURL(server.URL)
+	err := m.Open(context.Background())
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+	assert.NoFileExists(t, filepath.Join(dir, "ip-to-country.mmdb"))
+
+	_, err = m.Lookup("8.8.8.8")
+	assert.Error(t, err)
+
+	err = m.Update(context.Background())
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+}
+
+func TestManager_TransportErrorRedacted(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	m := NewManager("test-key", t.TempDir()).WithDownloadURL(server.URL)
+	err := m.Update(context.Background())
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "test-key")
+	assert.Contains(t, err.Error(), "ip-to-country.mmdb.sha256")
+}
+
+func TestManager_StartUpdatesInBackground(t *testing.T) {
+	server := newDownloadServer(t, buildDatabase(t, "US"))
+
+	m := NewManager("test-key", t.TempDir()).
+		WithDownloadURL(server.URL).
+		WithUpdateInterval(10 * time.Millisecond)
+	require.NoError(t, m.Open(context.Background()))
+
+	server.set(buildDatabase(t, "BR"))
+	m.Start()
+
+	assert.Eventually(t, func() bool {
+		result, err := m.Lookup("8.8.8.8")
+		return err == nil && *result.CountryCode == "BR"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, m.Close())
+}
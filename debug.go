@@ -0,0 +1,144 @@
+package iplocate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// redacted replaces API keys in debug output
+const redacted = "[REDACTED]"
+
+// debugWriter serializes wire dumps written to w
+type debugWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// WithDebug writes every request and response, including headers and
+// bodies, to w for troubleshooting. API keys are redacted, and with
+// PrivacyStrict IP addresses are replaced by their HashIP. Pass nil to stop
+// dumping.
+func (c *Client) WithDebug(w io.Writer) *Client {
+	if w == nil {
+		c.debug = nil
+		return c
+	}
+	c.debug = &debugWriter{w: w}
+	return c
+}
+
+// dumpRequest writes the request line and headers
+func (c *Client) dumpRequest(req *http.Request) {
+	if c.debug == nil {
+		return
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "> %s %s\n", req.Method, redactURL(req.URL))
+	writeHeaders(&buf, "> ", req.Header)
+	buf.WriteString(">\n")
+	c.writeDebug(buf.Bytes())
+}
+
+// dumpResponse writes the status line, headers and decoded body
+func (c *Client) dumpResponse(resp *http.Response, body []byte) {
+	if c.debug == nil {
+		return
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "< %s %s\n", resp.Proto, resp.Status)
+	writeHeaders(&buf, "< ", resp.Header)
+	buf.WriteString("<\n")
+	if len(body) > 0 {
+		buf.Write(body)
+		if body[len(body)-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+	}
+	c.writeDebug(buf.Bytes())
+}
+
+// dumpError writes a transport error
+func (c *Client) dumpError(err error) {
+	if c.debug == nil {
+		return
+	}
+	c.writeDebug([]byte(fmt.Sprintf("! %v\n", err)))
+}
+
+// writeDebug writes a dump with all configured API keys redacted
+func (c *Client) writeDebug(data []byte) {
+	text := string(data)
+	for _, key := range c.allKeys() {
+		text = strings.ReplaceAll(text, key, redacted)
+	}
+	if c.hashIPs() {
+		text = c.hashIPsInText(text)
+	}
+
+	c.debug.mu.Lock()
+	defer c.debug.mu.Unlock()
+	io.WriteString(c.debug.w, text)
+}
+
+// allKeys returns every configured API key
+func (c *Client) allKeys() []string {
+	if c.keyProvider != nil {
+		return c.keyProvider.keys()
+	}
+	if c.keys != nil {
+		return c.keys.keys
+	}
+	if c.apiKey != "" {
+		return []string{c.apiKey}
+	}
+	return nil
+}
+
+// redactURL returns the URL with the apikey query parameter redacted
+func redactURL(u *url.URL) string {
+	query := u.Query()
+	if _, ok := query["apikey"]; !ok {
+		return u.String()
+	}
+	query.Set("apikey", redacted)
+	redactedURL := *u
+	redactedURL.RawQuery = query.Encode()
+	return redactedURL.String()
+}
+
+// redactURLError redacts the apikey query parameter from the URL of a
+// transport error, whose text includes the request URL
+func redactURLError(err error) error {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return err
+	}
+	if u, parseErr := url.Parse(urlErr.URL); parseErr == nil {
+		urlErr.URL = redactURL(u)
+	}
+	return err
+}
+
+// writeHeaders writes headers sorted by name, one per line
+func writeHeaders(buf *bytes.Buffer, prefix string, header http.Header) {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, value := range header[name] {
+			fmt.Fprintf(buf, "%s%s: %s\n", prefix, name, value)
+		}
+	}
+}
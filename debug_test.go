@@ -0,0 +1,64 @@
+package iplocate
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithDebug(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Echo-Key", r.URL.Query().Get("apikey"))
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8", CountryCode: stringPtr("US")})
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	client := NewClient(nil).WithBaseURL(server.URL).WithAPIKey("secret-key").WithDebug(&buf)
+
+	_, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+
+	dump := buf.String()
+	assert.NotContains(t, dump, "secret-key")
+	assert.Contains(t, dump, "> GET "+server.URL+"/lookup/8.8.8.8?apikey=%5BREDACTED%5D")
+	assert.Contains(t, dump, "> User-Agent: go-iplocate/1.0.0")
+	assert.Contains(t, dump, "< HTTP/1.1 200 OK")
+	assert.Contains(t, dump, "< Content-Type: application/json")
+	assert.Contains(t, dump, "< X-Echo-Key: [REDACTED]")
+	assert.Contains(t, dump, `"country_code":"US"`)
+}
+
+func TestWithDebug_TransportError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	serverURL := server.URL
+	server.Close()
+
+	var buf bytes.Buffer
+	client := NewClient(nil).WithBaseURL(serverURL).WithAPIKeys("key-1", "key-2").WithDebug(&buf)
+
+	_, err := client.Lookup("8.8.8.8")
+	require.Error(t, err)
+	assert.Contains(t, buf.String(), "! ")
+	assert.NotContains(t, buf.String(), "key-1")
+}
+
+func TestWithDebug_Disabled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8"})
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	client := NewClient(nil).WithBaseURL(server.URL).WithDebug(&buf).WithDebug(nil)
+
+	_, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Empty(t, buf.String())
+}
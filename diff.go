@@ -0,0 +1,53 @@
+package iplocate
+
+import "strings"
+
+// FieldChange is a field whose value differs between two lookups
+type FieldChange struct {
+	// Field is the JSON path of the field, as in DefaultCSVColumns, e.g.
+	// "country_code" or "privacy.is_vpn"
+	Field string
+	// Category is the category of the field
+	Category Category
+	// Old and New are the values, formatted as in CSVRecord; an empty
+	// string means the field was missing
+	Old, New string
+}
+
+// String formats the change as "field: old -> new"
+func (c FieldChange) String() string {
+	return c.Field + ": " + c.Old + " -> " + c.New
+}
+
+// Diff compares two lookups of the same IP, such as a cached result and a
+// fresh one, and returns the fields that changed in the order of
+// DefaultCSVColumns. Monitoring jobs can alert on the changes that matter
+// to them, e.g. a new country or a privacy flag being set. The ip field
+// isn't compared, and a nil response is treated as one without data.
+func Diff(old, new *LookupResponse) []FieldChange {
+	if old == nil {
+		old = &LookupResponse{}
+	}
+	if new == nil {
+		new = &LookupResponse{}
+	}
+
+	var changes []FieldChange
+	for _, column := range csvColumns {
+		if column.name == "ip" {
+			continue
+		}
+		oldValue, newValue := column.value(old), column.value(new)
+		if oldValue == newValue {
+			continue
+		}
+		field, _, _ := strings.Cut(column.name, ".")
+		changes = append(changes, FieldChange{
+			Field:    column.name,
+			Category: fieldCategory(field),
+			Old:      oldValue,
+			New:      newValue,
+		})
+	}
+	return changes
+}
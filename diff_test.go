@@ -0,0 +1,49 @@
+package iplocate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	old := &LookupResponse{
+		IP:          "203.0.113.7",
+		CountryCode: stringPtr("US"),
+		City:        stringPtr("Seattle"),
+		ASN:         &ASN{ASN: "AS64500", Name: "Example Hosting"},
+	}
+	new := &LookupResponse{
+		IP:          "203.0.113.7",
+		Hostname:    stringPtr("vpn.example.net"),
+		CountryCode: stringPtr("NL"),
+		City:        stringPtr("Seattle"),
+		ASN:         &ASN{ASN: "AS64501", Name: "Example Hosting"},
+		Privacy:     Privacy{IsVPN: true},
+	}
+
+	changes := Diff(old, new)
+	assert.Equal(t, []FieldChange{
+		{Field: "hostname", Category: CategoryGeo, Old: "", New: "vpn.example.net"},
+		{Field: "country_code", Category: CategoryGeo, Old: "US", New: "NL"},
+		{Field: "asn.asn", Category: CategoryASN, Old: "AS64500", New: "AS64501"},
+		{Field: "privacy.is_vpn", Category: CategoryPrivacy, Old: "false", New: "true"},
+	}, changes)
+	assert.Equal(t, "country_code: US -> NL", changes[1].String())
+
+	assert.Empty(t, Diff(old, old))
+	assert.Empty(t, Diff(nil, nil))
+}
+
+func TestDiff_MissingFields(t *testing.T) {
+	resp := &LookupResponse{IP: "203.0.113.7", City: stringPtr("Paris"), Latitude: float64Ptr(48.8566)}
+
+	assert.Equal(t, []FieldChange{
+		{Field: "city", Category: CategoryGeo, Old: "", New: "Paris"},
+		{Field: "latitude", Category: CategoryGeo, Old: "", New: "48.8566"},
+	}, Diff(nil, resp))
+	assert.Equal(t, []FieldChange{
+		{Field: "city", Category: CategoryGeo, Old: "Paris", New: ""},
+		{Field: "latitude", Category: CategoryGeo, Old: "48.8566", New: ""},
+	}, Diff(resp, &LookupResponse{IP: "198.51.100.1"}), "the IP isn't compared")
+}
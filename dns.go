@@ -0,0 +1,230 @@
+package iplocate
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDNSCacheTTL is how long a DNSCache keeps resolved addresses
+const DefaultDNSCacheTTL = 5 * time.Minute
+
+// DNSCache caches the addresses of the hosts a client connects to, so bulk
+// lookups don't resolve the API hostname for every new connection. If
+// resolving fails after an entry has expired, the expired addresses are used
+// so a resolver hiccup doesn't stall requests. It is safe for concurrent use.
+type DNSCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]dnsEntry
+
+	lookupHost func(ctx context.Context, host string) ([]string, error)
+	now        func() time.Time
+}
+
+type dnsEntry struct {
+	addrs   []string
+	expires time.Time
+}
+
+// NewDNSCache creates a cache keeping addresses for ttl, regardless of the
+// TTL of the DNS records. If ttl is zero or negative, DefaultDNSCacheTTL is
+// used.
+func NewDNSCache(ttl time.Duration) *DNSCache {
+	if ttl <= 0 {
+		ttl = DefaultDNSCacheTTL
+	}
+	return &DNSCache{
+		ttl:        ttl,
+		entries:    make(map[string]dnsEntry),
+		lookupHost: net.DefaultResolver.LookupHost,
+		now:        time.Now,
+	}
+}
+
+// Flush removes all cached addresses
+func (d *DNSCache) Flush() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.entries = make(map[string]dnsEntry)
+}
+
+// LookupHost returns the addresses of host, resolving it if the cached
+// addresses have expired
+func (d *DNSCache) LookupHost(ctx context.Context, host string) ([]string, error) {
+	if net.ParseIP(host) != nil {
+		return []string{host}, nil
+	}
+
+	d.mu.Lock()
+	entry, ok := d.entries[host]
+	d.mu.Unlock()
+	if ok && d.now().Before(entry.expires) {
+		return entry.addrs, nil
+	}
+
+	addrs, err := d.lookupHost(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		if ok {
+			return entry.addrs, nil
+		}
+		if err == nil {
+			err = &net.DNSError{Err: "no addresses found", Name: host, IsNotFound: true}
+		}
+		return nil, err
+	}
+
+	d.mu.Lock()
+	d.entries[host] = dnsEntry{addrs: addrs, expires: d.now().Add(d.ttl)}
+	d.mu.Unlock()
+	return addrs, nil
+}
+
+// fallbackDelay is how long a dial waits for the preferred address family
+// before racing the other one, as net.Dialer does by default
+const fallbackDelay = 300 * time.Millisecond
+
+// dialFunc opens a connection, like net.Dialer.DialContext
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// dialContext returns a dial function that connects to the cached addresses.
+// Like net.Dialer for hostnames, it tries the addresses of the family of the
+// first address in turn, and races the other family after fallbackDelay
+// (Happy Eyeballs, RFC 6555), unless the dialer's FallbackDelay is negative.
+func (d *DNSCache) dialContext(dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		addrs, err := d.LookupHost(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+
+		delay := dialer.FallbackDelay
+		if delay == 0 {
+			delay = fallbackDelay
+		}
+		return dialHappyEyeballs(ctx, dialer.DialContext, network, addrs, port, delay)
+	}
+}
+
+// dialHappyEyeballs connects to one of addrs, racing the address families.
+// A negative delay tries every address in turn.
+func dialHappyEyeballs(ctx context.Context, dial dialFunc, network string, addrs []string, port string, delay time.Duration) (net.Conn, error) {
+	primaries, fallbacks := partitionAddrs(addrs, network)
+	if len(primaries) == 0 {
+		return nil, &net.AddrError{Err: "no suitable address found", Addr: strings.Join(addrs, ",")}
+	}
+	if len(fallbacks) == 0 || delay < 0 {
+		return dialSerial(ctx, dial, network, append(primaries, fallbacks...), port)
+	}
+
+	type dialResult struct {
+		conn net.Conn
+		err  error
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan dialResult, 2)
+	start := func(addrs []string) {
+		go func() {
+			conn, err := dialSerial(ctx, dial, network, addrs, port)
+			results <- dialResult{conn, err}
+		}()
+	}
+
+	start(primaries)
+	pending, fallbackStarted := 1, false
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	var firstErr error
+	for {
+		select {
+		case <-timer.C:
+			if !fallbackStarted {
+				fallbackStarted = true
+				pending++
+				start(fallbacks)
+			}
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				// Close the connection of a dial finishing after the winner
+				go func(n int) {
+					for ; n > 0; n-- {
+						if r := <-results; r.conn != nil {
+							r.conn.Close()
+						}
+					}
+				}(pending)
+				return r.conn, nil
+			}
+			if firstErr == nil {
+				firstErr = r.err
+			}
+			if !fallbackStarted && ctx.Err() == nil {
+				fallbackStarted = true
+				pending++
+				start(fallbacks)
+				continue
+			}
+			if pending == 0 {
+				return nil, firstErr
+			}
+		}
+	}
+}
+
+// dialSerial tries each address in turn
+func dialSerial(ctx context.Context, dial dialFunc, network string, addrs []string, port string) (net.Conn, error) {
+	var firstErr error
+	for _, ip := range addrs {
+		conn, err := dial(ctx, network, net.JoinHostPort(ip, port))
+		if err == nil {
+			return conn, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, firstErr
+}
+
+// partitionAddrs splits addrs into those of the family of the first
+// address and the others, leaving out the family network excludes
+func partitionAddrs(addrs []string, network string) (primaries, fallbacks []string) {
+	var primaryIs4 bool
+	for _, addr := range addrs {
+		ip, err := netip.ParseAddr(addr)
+		is4 := err == nil && ip.Unmap().Is4()
+		if (network == "tcp4" && !is4) || (network == "tcp6" && is4) {
+			continue
+		}
+		if len(primaries) == 0 {
+			primaryIs4 = is4
+		}
+		if is4 == primaryIs4 {
+			primaries = append(primaries, addr)
+		} else {
+			fallbacks = append(fallbacks, addr)
+		}
+	}
+	return primaries, fallbacks
+}
+
+// WithDNSCache resolves hostnames through cache when opening connections.
+// Like WithTLSConfig, it requires an *http.Transport.
+func (c *Client) WithDNSCache(cache *DNSCache) *Client {
+	c.dnsCache = cache
+	c.configureDialer()
+	return c
+}
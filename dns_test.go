@@ -0,0 +1,186 @@
+package iplocate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeResolver counts lookups and returns fixed addresses or an error
+type fakeResolver struct {
+	addrs []string
+	err   error
+	calls int
+}
+
+func (f *fakeResolver) lookupHost(ctx context.Context, host string) ([]string, error) {
+	f.calls++
+	return f.addrs, f.err
+}
+
+func newTestDNSCache(resolver *fakeResolver, now *time.Time) *DNSCache {
+	cache := NewDNSCache(time.Minute)
+	cache.lookupHost = resolver.lookupHost
+	cache.now = func() time.Time { return *now }
+	return cache
+}
+
+func TestDNSCache_TTL(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	resolver := &fakeResolver{addrs: []string{"192.0.2.1"}}
+	cache := newTestDNSCache(resolver, &now)
+	ctx := context.Background()
+
+	addrs, err := cache.LookupHost(ctx, "iplocate.io")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"192.0.2.1"}, addrs)
+
+	_, err = cache.LookupHost(ctx, "iplocate.io")
+	require.NoError(t, err)
+	assert.Equal(t, 1, resolver.calls)
+
+	now = now.Add(time.Minute)
+	resolver.addrs = []string{"192.0.2.2"}
+	addrs, err = cache.LookupHost(ctx, "iplocate.io")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"192.0.2.2"}, addrs)
+	assert.Equal(t, 2, resolver.calls)
+}
+
+func TestDNSCache_Flush(t *testing.T) {
+	now := time.Now()
+	resolver := &fakeResolver{addrs: []string{"192.0.2.1"}}
+	cache := newTestDNSCache(resolver, &now)
+
+	_, err := cache.LookupHost(context.Background(), "iplocate.io")
+	require.NoError(t, err)
+	cache.Flush()
+	_, err = cache.LookupHost(context.Background(), "iplocate.io")
+	require.NoError(t, err)
+	assert.Equal(t, 2, resolver.calls)
+}
+
+func TestDNSCache_StaleOnError(t *testing.T) {
+	now := time.Now()
+	resolver := &fakeResolver{addrs: []string{"192.0.2.1"}}
+	cache := newTestDNSCache(resolver, &now)
+	ctx := context.Background()
+
+	_, err := cache.LookupHost(ctx, "iplocate.io")
+	require.NoError(t, err)
+
+	now = now.Add(time.Hour)
+	resolver.err = errors.New("resolver unavailable")
+	addrs, err := cache.LookupHost(ctx, "iplocate.io")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"192.0.2.1"}, addrs)
+
+	_, err = cache.LookupHost(ctx, "other.example.com")
+	assert.EqualError(t, err, "resolver unavailable")
+}
+
+func TestDNSCache_IPLiteral(t *testing.T) {
+	now := time.Now()
+	resolver := &fakeResolver{}
+	cache := newTestDNSCache(resolver, &now)
+
+	addrs, err := cache.LookupHost(context.Background(), "127.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"127.0.0.1"}, addrs)
+	assert.Zero(t, resolver.calls)
+}
+
+func TestWithDNSCache(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8"})
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	_, port, err := net.SplitHostPort(serverURL.Host)
+	require.NoError(t, err)
+
+	now := time.Now()
+	resolver := &fakeResolver{addrs: []string{"127.0.0.1"}}
+	cache := newTestDNSCache(resolver, &now)
+
+	client := NewClient(nil).
+		WithBaseURL("http://api.iplocate.test:" + port).
+		WithTimeout(5 * time.Second).
+		WithDNSCache(cache)
+	client.httpClient.Transport.(*http.Transport).DisableKeepAlives = true
+
+	for i := 0; i < 3; i++ {
+		result, err := client.Lookup("8.8.8.8")
+		require.NoError(t, err)
+		assert.Equal(t, "8.8.8.8", result.IP)
+	}
+	assert.Equal(t, 1, resolver.calls)
+}
+
+// blackholeDial never connects to IPv6 addresses, like a network with broken
+// IPv6, and connects to IPv4 addresses at once
+func blackholeDial(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, _, _ := net.SplitHostPort(addr)
+	if strings.Contains(host, ":") {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	client, server := net.Pipe()
+	server.Close()
+	return client, nil
+}
+
+func TestDialHappyEyeballs(t *testing.T) {
+	start := time.Now()
+	conn, err := dialHappyEyeballs(context.Background(), blackholeDial, "tcp", []string{"2001:db8::1", "2001:db8::2", "192.0.2.1"}, "443", 50*time.Millisecond)
+	require.NoError(t, err)
+	conn.Close()
+	assert.Less(t, time.Since(start), time.Second, "IPv4 is raced after the fallback delay")
+}
+
+func TestDialHappyEyeballs_PrimaryFails(t *testing.T) {
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if strings.HasPrefix(addr, "[") {
+			return nil, errors.New("connection refused")
+		}
+		return blackholeDial(ctx, network, addr)
+	}
+	start := time.Now()
+	conn, err := dialHappyEyeballs(context.Background(), dial, "tcp", []string{"2001:db8::1", "192.0.2.1"}, "443", time.Hour)
+	require.NoError(t, err)
+	conn.Close()
+	assert.Less(t, time.Since(start), time.Second, "the fallback starts as soon as the primaries fail")
+}
+
+func TestDialHappyEyeballs_Errors(t *testing.T) {
+	refuse := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return nil, errors.New("connection refused to " + addr)
+	}
+	_, err := dialHappyEyeballs(context.Background(), refuse, "tcp", []string{"2001:db8::1", "192.0.2.1"}, "443", time.Hour)
+	assert.EqualError(t, err, "connection refused to [2001:db8::1]:443")
+
+	_, err = dialHappyEyeballs(context.Background(), refuse, "tcp4", []string{"2001:db8::1"}, "443", time.Millisecond)
+	assert.ErrorContains(t, err, "no suitable address")
+}
+
+func TestPartitionAddrs(t *testing.T) {
+	primaries, fallbacks := partitionAddrs([]string{"2001:db8::1", "192.0.2.1", "2001:db8::2", "192.0.2.2"}, "tcp")
+	assert.Equal(t, []string{"2001:db8::1", "2001:db8::2"}, primaries)
+	assert.Equal(t, []string{"192.0.2.1", "192.0.2.2"}, fallbacks)
+
+	primaries, fallbacks = partitionAddrs([]string{"2001:db8::1", "192.0.2.1"}, "tcp4")
+	assert.Equal(t, []string{"192.0.2.1"}, primaries)
+	assert.Empty(t, fallbacks)
+}
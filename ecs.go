@@ -0,0 +1,108 @@
+package iplocate
+
+import (
+	"net"
+	"strconv"
+)
+
+// ECS holds the Elastic Common Schema (ECS) fields for a lookup. Marshaled
+// to JSON it nests as geo.*, as.* and threat.*, so it can be embedded in
+// a document under e.g. source or client.
+type ECS struct {
+	Geo    *ECSGeo    `json:"geo,omitempty"`
+	AS     *ECSAS     `json:"as,omitempty"`
+	Threat *ECSThreat `json:"threat,omitempty"`
+	// Tags lists the privacy flags set on the IP; see Privacy.Flags
+	Tags []string `json:"tags,omitempty"`
+}
+
+// ECSGeo is the ECS geo field set
+type ECSGeo struct {
+	CityName       string       `json:"city_name,omitempty"`
+	ContinentName  string       `json:"continent_name,omitempty"`
+	CountryISOCode string       `json:"country_iso_code,omitempty"`
+	CountryName    string       `json:"country_name,omitempty"`
+	Location       *ECSGeoPoint `json:"location,omitempty"`
+	PostalCode     string       `json:"postal_code,omitempty"`
+	RegionName     string       `json:"region_name,omitempty"`
+	Timezone       string       `json:"timezone,omitempty"`
+}
+
+// ECSGeoPoint is an ECS geo_point
+type ECSGeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// ECSAS is the ECS as field set
+type ECSAS struct {
+	Number       int64             `json:"number,omitempty"`
+	Organization ECSASOrganization `json:"organization"`
+}
+
+// ECSASOrganization is the organization of an ECS autonomous system
+type ECSASOrganization struct {
+	Name string `json:"name,omitempty"`
+}
+
+// ECSThreat is the ECS threat field set, filled in for IPs with a privacy
+// or abuse flag
+type ECSThreat struct {
+	Indicator ECSThreatIndicator `json:"indicator"`
+}
+
+// ECSThreatIndicator describes the IP as an ECS threat indicator
+type ECSThreatIndicator struct {
+	IP       string `json:"ip"`
+	Type     string `json:"type"`
+	Provider string `json:"provider"`
+}
+
+// ecsProvider is the threat.indicator.provider of ECS threat fields
+const ecsProvider = "IPLocate.io"
+
+// ToECS maps the response to Elastic Common Schema fields, so lookups can
+// be indexed alongside the geo and as fields Elasticsearch and Kibana
+// dashboards already use. Field sets without data are left nil.
+func (r *LookupResponse) ToECS() ECS {
+	var ecs ECS
+
+	geo := ECSGeo{
+		CityName:       stringOrEmpty(r.City),
+		ContinentName:  stringOrEmpty(r.Continent),
+		CountryISOCode: stringOrEmpty(r.CountryCode),
+		CountryName:    stringOrEmpty(r.Country),
+		PostalCode:     stringOrEmpty(r.PostalCode),
+		RegionName:     stringOrEmpty(r.Subdivision),
+		Timezone:       stringOrEmpty(r.TimeZone),
+	}
+	if lat, lon, ok := r.Coordinates(); ok {
+		geo.Location = &ECSGeoPoint{Lat: lat, Lon: lon}
+	}
+	if geo != (ECSGeo{}) {
+		ecs.Geo = &geo
+	}
+
+	if r.ASN != nil && (r.ASN.ASN != "" || r.ASN.Name != "") {
+		number, _ := strconv.ParseInt(normalizeASN(r.ASN.ASN), 10, 64)
+		ecs.AS = &ECSAS{Number: number, Organization: ECSASOrganization{Name: r.ASN.Name}}
+	}
+
+	ecs.Tags = r.Privacy.Flags()
+	if len(ecs.Tags) > 0 {
+		ecs.Threat = &ECSThreat{Indicator: ECSThreatIndicator{
+			IP:       r.IP,
+			Type:     ecsIndicatorType(r.IP),
+			Provider: ecsProvider,
+		}}
+	}
+	return ecs
+}
+
+// ecsIndicatorType returns the ECS threat.indicator.type of an IP address
+func ecsIndicatorType(ip string) string {
+	if parsed := net.ParseIP(ip); parsed != nil && parsed.To4() == nil {
+		return "ipv6-addr"
+	}
+	return "ipv4-addr"
+}
@@ -0,0 +1,57 @@
+package iplocate
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToECS(t *testing.T) {
+	resp := &LookupResponse{
+		IP:          "2001:db8::1",
+		Country:     stringPtr("Germany"),
+		CountryCode: stringPtr("DE"),
+		City:        stringPtr("Berlin"),
+		Continent:   stringPtr("Europe"),
+		Latitude:    float64Ptr(52.52),
+		Longitude:   float64Ptr(13.405),
+		Subdivision: stringPtr("Berlin"),
+		TimeZone:    stringPtr("Europe/Berlin"),
+		ASN:         &ASN{ASN: "AS3320", Name: "Deutsche Telekom AG"},
+		Privacy:     Privacy{IsVPN: true, IsAnonymous: true},
+	}
+
+	data, err := json.Marshal(resp.ToECS())
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"geo": {
+			"city_name": "Berlin",
+			"continent_name": "Europe",
+			"country_iso_code": "DE",
+			"country_name": "Germany",
+			"location": {"lat": 52.52, "lon": 13.405},
+			"region_name": "Berlin",
+			"timezone": "Europe/Berlin"
+		},
+		"as": {"number": 3320, "organization": {"name": "Deutsche Telekom AG"}},
+		"threat": {"indicator": {"ip": "2001:db8::1", "type": "ipv6-addr", "provider": "IPLocate.io"}},
+		"tags": ["anonymous", "vpn"]
+	}`, string(data))
+}
+
+func TestToECS_Empty(t *testing.T) {
+	ecs := (&LookupResponse{IP: "8.8.8.8"}).ToECS()
+	assert.Nil(t, ecs.Geo)
+	assert.Nil(t, ecs.AS)
+	assert.Nil(t, ecs.Threat)
+	assert.Empty(t, ecs.Tags)
+}
+
+func TestToECS_IPv4Threat(t *testing.T) {
+	ecs := (&LookupResponse{IP: "185.220.101.1", Privacy: Privacy{IsTor: true}}).ToECS()
+	require.NotNil(t, ecs.Threat)
+	assert.Equal(t, "ipv4-addr", ecs.Threat.Indicator.Type)
+	assert.Equal(t, []string{"tor"}, ecs.Tags)
+}
@@ -0,0 +1,173 @@
+package iplocate
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultEndpointCooldown is how long an endpoint is skipped after it fails
+const DefaultEndpointCooldown = 30 * time.Second
+
+// retryDelay is the wait before a single base URL is retried
+const retryDelay = 500 * time.Millisecond
+
+// endpointSet tracks the health of a prioritized list of base URLs
+type endpointSet struct {
+	mu        sync.Mutex
+	urls      []string
+	downUntil []time.Time
+	cooldown  time.Duration
+	now       func() time.Time
+}
+
+// pick returns the highest priority endpoint that is healthy and not in
+// tried. If every remaining endpoint is down, the highest priority one that
+// has not been tried is returned so requests still get a chance.
+func (s *endpointSet) pick(tried []int) (string, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	fallback := -1
+	for i := range s.urls {
+		if containsInt(tried, i) {
+			continue
+		}
+		if !now.Before(s.downUntil[i]) {
+			return s.urls[i], i
+		}
+		if fallback < 0 {
+			fallback = i
+		}
+	}
+	if fallback < 0 {
+		fallback = 0
+	}
+	return s.urls[fallback], fallback
+}
+
+// markDown skips the endpoint until the cooldown has passed
+func (s *endpointSet) markDown(i int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.downUntil[i] = s.now().Add(s.cooldown)
+}
+
+// markUp records a successful request to the endpoint
+func (s *endpointSet) markUp(i int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.downUntil[i] = time.Time{}
+}
+
+// WithEndpoints configures a primary base URL and fallbacks tried in order.
+// An endpoint that returns a network error or a 5xx response is skipped for
+// DefaultEndpointCooldown, and the request is retried against the next one.
+// The primary is used again as soon as its cooldown expires.
+func (c *Client) WithEndpoints(primary string, fallbacks ...string) *Client {
+	urls := []string{strings.TrimSuffix(primary, "/")}
+	for _, fallback := range fallbacks {
+		urls = append(urls, strings.TrimSuffix(fallback, "/"))
+	}
+
+	c.baseURL = urls[0]
+	c.endpoints = nil
+	if len(urls) > 1 {
+		c.endpoints = &endpointSet{
+			urls:      urls,
+			downUntil: make([]time.Time, len(urls)),
+			cooldown:  DefaultEndpointCooldown,
+			now:       func() time.Time { return c.now() },
+		}
+	}
+	return c
+}
+
+// WithEndpointCooldown sets how long a failing endpoint configured with
+// WithEndpoints is skipped
+func (c *Client) WithEndpointCooldown(cooldown time.Duration) *Client {
+	if c.endpoints != nil {
+		c.endpoints.mu.Lock()
+		c.endpoints.cooldown = cooldown
+		c.endpoints.mu.Unlock()
+	}
+	return c
+}
+
+// RetryPolicy reports whether a failed attempt should be retried against the
+// next endpoint. resp is nil when err is set.
+type RetryPolicy func(resp *http.Response, err error) bool
+
+// DefaultRetryPolicy retries network errors and 5xx responses
+func DefaultRetryPolicy(resp *http.Response, err error) bool {
+	return err != nil || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// WithRetryPolicy sets which attempts fail over to the next endpoint
+// configured with WithEndpoints and mark the endpoint as down, instead of
+// DefaultRetryPolicy. With a single base URL, which isn't retried by
+// default, attempts the policy accepts are retried once against the same
+// URL after about half a second. For example, a 502 from a corporate proxy
+// can be returned right away rather than retried:
+//
+//	client.WithRetryPolicy(func(resp *http.Response, err error) bool {
+//		return err != nil || resp.StatusCode == http.StatusServiceUnavailable
+//	})
+//
+// Requests whose context is done are never retried.
+func (c *Client) WithRetryPolicy(policy RetryPolicy) *Client {
+	c.retryPolicy = policy
+	return c
+}
+
+// pickEndpoint returns the base URL for the next attempt and its index, or
+// -1 when a single base URL is configured
+func (c *Client) pickEndpoint(tried []int) (string, int) {
+	if c.endpoints == nil {
+		return c.baseURL, -1
+	}
+	return c.endpoints.pick(tried)
+}
+
+// endpointFailed updates the health of the endpoint after an attempt and
+// reports whether the attempt should be retried against another endpoint
+func (c *Client) endpointFailed(ctx context.Context, index int, resp *http.Response, err error) bool {
+	if c.endpoints == nil {
+		return false
+	}
+	// The caller gave up; the endpoint is not to blame
+	if ctx.Err() != nil {
+		return false
+	}
+	retry := c.retryPolicy
+	if retry == nil {
+		retry = DefaultRetryPolicy
+	}
+	if retry(resp, err) {
+		c.endpoints.markDown(index)
+		return true
+	}
+	c.endpoints.markUp(index)
+	return false
+}
+
+// retrySingle reports whether an attempt against the single base URL should
+// be retried, which only a policy set with WithRetryPolicy asks for
+func (c *Client) retrySingle(ctx context.Context, resp *http.Response, err error) bool {
+	if c.endpoints != nil || c.retryPolicy == nil || ctx.Err() != nil {
+		return false
+	}
+	return c.retryPolicy(resp, err)
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,196 @@
+package iplocate
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func countingServer(t *testing.T, status int) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(map[string]string{"error": http.StatusText(status)})
+			return
+		}
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8"})
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestWithEndpoints_FailsOverOnServerError(t *testing.T) {
+	primary, primaryCalls := countingServer(t, http.StatusServiceUnavailable)
+	fallback, fallbackCalls := countingServer(t, http.StatusOK)
+
+	client := NewClient(nil).WithEndpoints(primary.URL, fallback.URL)
+
+	result, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "8.8.8.8", result.IP)
+
+	// The primary is skipped while it cools down
+	_, err = client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(primaryCalls))
+	assert.Equal(t, int32(2), atomic.LoadInt32(fallbackCalls))
+}
+
+func TestWithEndpoints_FailsOverOnNetworkError(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	downURL := down.URL
+	down.Close()
+	fallback, fallbackCalls := countingServer(t, http.StatusOK)
+
+	client := NewClient(nil).WithEndpoints(downURL, fallback.URL)
+
+	_, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(fallbackCalls))
+}
+
+func TestWithEndpoints_PrimaryRecovers(t *testing.T) {
+	primary, primaryCalls := countingServer(t, http.StatusBadGateway)
+	fallback, _ := countingServer(t, http.StatusOK)
+
+	client := NewClient(nil).WithEndpoints(primary.URL, fallback.URL)
+	now := time.Now()
+	client.endpoints.now = func() time.Time { return now }
+
+	_, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+
+	now = now.Add(DefaultEndpointCooldown)
+	_, err = client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(primaryCalls))
+}
+
+func TestWithEndpoints_AllDown(t *testing.T) {
+	primary, primaryCalls := countingServer(t, http.StatusInternalServerError)
+	fallback, fallbackCalls := countingServer(t, http.StatusInternalServerError)
+
+	client := NewClient(nil).WithEndpoints(primary.URL, fallback.URL)
+
+	_, err := client.Lookup("8.8.8.8")
+	require.Error(t, err)
+	apiErr, ok := err.(*APIError)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusInternalServerError, apiErr.StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(primaryCalls))
+	assert.Equal(t, int32(1), atomic.LoadInt32(fallbackCalls))
+
+	// With every endpoint down, requests still go to the primary first
+	_, err = client.Lookup("8.8.8.8")
+	require.Error(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(primaryCalls))
+}
+
+func TestWithEndpoints_NoFailoverOnClientError(t *testing.T) {
+	primary, _ := countingServer(t, http.StatusBadRequest)
+	fallback, fallbackCalls := countingServer(t, http.StatusOK)
+
+	client := NewClient(nil).WithEndpoints(primary.URL, fallback.URL)
+
+	_, err := client.Lookup("8.8.8.8")
+	require.Error(t, err)
+	assert.Equal(t, int32(0), atomic.LoadInt32(fallbackCalls))
+}
+
+func TestWithEndpoints_Configuration(t *testing.T) {
+	client := NewClient(nil).WithEndpoints("https://primary.example/", "https://fallback.example")
+	assert.Equal(t, "https://primary.example", client.baseURL)
+	assert.Equal(t, []string{"https://primary.example", "https://fallback.example"}, client.endpoints.urls)
+
+	client.WithEndpointCooldown(time.Minute)
+	assert.Equal(t, time.Minute, client.endpoints.cooldown)
+
+	// WithBaseURL replaces the endpoint list
+	client.WithBaseURL("https://other.example")
+	assert.Nil(t, client.endpoints)
+}
+
+func TestWithRetryPolicy(t *testing.T) {
+	primary, primaryCalls := countingServer(t, http.StatusBadGateway)
+	fallback, fallbackCalls := countingServer(t, http.StatusOK)
+
+	client := NewClient(nil).WithEndpoints(primary.URL, fallback.URL).
+		WithRetryPolicy(func(resp *http.Response, err error) bool {
+			return err != nil || resp.StatusCode == http.StatusServiceUnavailable
+		})
+
+	_, err := client.Lookup("8.8.8.8")
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(primaryCalls))
+	assert.Zero(t, atomic.LoadInt32(fallbackCalls))
+}
+
+func TestWithRetryPolicy_RetriesClientErrors(t *testing.T) {
+	primary, _ := countingServer(t, http.StatusForbidden)
+	fallback, fallbackCalls := countingServer(t, http.StatusOK)
+
+	client := NewClient(nil).WithEndpoints(primary.URL, fallback.URL).
+		WithRetryPolicy(func(resp *http.Response, err error) bool {
+			return DefaultRetryPolicy(resp, err) || resp.StatusCode == http.StatusForbidden
+		})
+
+	_, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(fallbackCalls))
+}
+
+func TestWithRetryPolicy_SingleBaseURL(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8"})
+	}))
+	defer server.Close()
+
+	clock := newFakeClock()
+	client := NewClient(nil).WithBaseURL(server.URL).WithClock(clock).WithRetryPolicy(DefaultRetryPolicy)
+
+	_, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	require.Len(t, clock.recordedWaits(), 1)
+	assert.GreaterOrEqual(t, clock.recordedWaits()[0], retryDelay)
+}
+
+func TestWithRetryPolicy_SingleBaseURLRetriesOnce(t *testing.T) {
+	server, calls := countingServer(t, http.StatusBadGateway)
+	client := NewClient(nil).WithBaseURL(server.URL).WithClock(newFakeClock()).WithRetryPolicy(DefaultRetryPolicy)
+
+	_, err := client.Lookup("8.8.8.8")
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+	assert.Equal(t, int32(2), atomic.LoadInt32(calls))
+}
+
+func TestWithRetryPolicy_SingleBaseURLDefault(t *testing.T) {
+	server, calls := countingServer(t, http.StatusBadGateway)
+	_, err := NewClient(nil).WithBaseURL(server.URL).Lookup("8.8.8.8")
+	require.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls), "without a policy, a single base URL isn't retried")
+}
+
+func TestDefaultRetryPolicy(t *testing.T) {
+	assert.True(t, DefaultRetryPolicy(nil, assert.AnError))
+	assert.True(t, DefaultRetryPolicy(&http.Response{StatusCode: http.StatusBadGateway}, nil))
+	assert.False(t, DefaultRetryPolicy(&http.Response{StatusCode: http.StatusTooManyRequests}, nil))
+	assert.False(t, DefaultRetryPolicy(&http.Response{StatusCode: http.StatusOK}, nil))
+}
@@ -0,0 +1,141 @@
+package iplocate
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// DefaultEnrichBatchSize is how many rows EnrichCSV looks up at a time
+const DefaultEnrichBatchSize = 500
+
+// EnrichCSV copies a CSV file with a header row from r to w, appending the
+// given CSV columns (see DefaultCSVColumns), looked up for the IP address
+// in ipColumn, to every row. Without fields, every column but "ip" is
+// appended. Rows are looked up concurrently, DefaultEnrichBatchSize at a
+// time, and written in their original order; only the API fields behind
+// the columns are requested.
+//
+// Rows whose lookup fails, including rows without a valid IP address, get
+// empty columns. Their errors are returned as a *BatchError once the whole
+// file has been written. Read, write and context errors stop the copy.
+func (c *Client) EnrichCSV(ctx context.Context, r io.Reader, w io.Writer, ipColumn string, fields []string) error {
+	if len(fields) == 0 {
+		fields = DefaultCSVColumns[1:]
+	}
+	var apiFields []string
+	for _, name := range fields {
+		if _, ok := csvColumnsByName[name]; !ok {
+			return fmt.Errorf("unknown CSV column: %q", name)
+		}
+		field, _, _ := strings.Cut(name, ".")
+		if !slices.Contains(apiFields, field) {
+			apiFields = append(apiFields, field)
+		}
+	}
+
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	column := -1
+	for i, name := range header {
+		if name == ipColumn {
+			column = i
+			break
+		}
+	}
+	if column < 0 {
+		return fmt.Errorf("CSV has no %q column", ipColumn)
+	}
+	// Rows may have a varying number of fields; short rows get no IP
+	reader.FieldsPerRecord = -1
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(append(header, fields...)); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	failed := make(map[string]error)
+	rows := make([][]string, 0, DefaultEnrichBatchSize)
+	flush := func() error {
+		if err := c.enrichRows(ctx, writer, rows, column, fields, apiFields, failed); err != nil {
+			return err
+		}
+		rows = rows[:0]
+		return nil
+	}
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read CSV: %w", err)
+		}
+		rows = append(rows, row)
+		if len(rows) == DefaultEnrichBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	if len(failed) > 0 {
+		return &BatchError{Errors: failed}
+	}
+	return nil
+}
+
+// enrichRows looks up the IPs of rows and writes them with the columns
+// appended, recording failed lookups in failed
+func (c *Client) enrichRows(ctx context.Context, writer *csv.Writer, rows [][]string, column int, fields, apiFields []string, failed map[string]error) error {
+	var ips []string
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		if ip := rowIP(row, column); !seen[ip] {
+			seen[ip] = true
+			ips = append(ips, ip)
+		}
+	}
+
+	batch, _ := c.LookupBatch(ctx, ips, Fields(apiFields...))
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	for ip, err := range batch.Errors {
+		failed[ip] = err
+	}
+
+	empty := make([]string, len(fields))
+	for _, row := range rows {
+		extra := empty
+		if resp, ok := batch.Responses[rowIP(row, column)]; ok {
+			extra = resp.CSVRecord(fields...)
+		}
+		if err := writer.Write(append(row, extra...)); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+	return nil
+}
+
+// rowIP returns the IP address in column of row, or an empty string for short rows
+func rowIP(row []string, column int) string {
+	if column >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[column])
+}
@@ -0,0 +1,98 @@
+package iplocate
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func enrichServer(t *testing.T, fields *[]string) *httptest.Server {
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		*fields = append(*fields, r.URL.Query().Get("fields"))
+		mu.Unlock()
+		ip := strings.TrimPrefix(r.URL.Path, "/lookup/")
+		country := "US"
+		if strings.HasPrefix(ip, "1.") {
+			country = "AU"
+		}
+		json.NewEncoder(w).Encode(LookupResponse{IP: ip, CountryCode: &country, ASN: &ASN{ASN: "AS" + ip[:1]}})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestEnrichCSV(t *testing.T) {
+	var fields []string
+	client := NewClient(nil).WithBaseURL(enrichServer(t, &fields).URL)
+
+	input := "user,addr\nalice,8.8.8.8\nbob, 1.1.1.1\ncarol,8.8.8.8\n"
+	var out bytes.Buffer
+	err := client.EnrichCSV(context.Background(), strings.NewReader(input), &out, "addr", []string{"country_code", "asn.asn"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "user,addr,country_code,asn.asn\nalice,8.8.8.8,US,AS8\nbob,\" 1.1.1.1\",AU,AS1\ncarol,8.8.8.8,US,AS8\n", out.String())
+	assert.Len(t, fields, 2, "duplicate IPs are looked up once")
+	assert.Equal(t, "country_code,asn", fields[0])
+}
+
+func TestEnrichCSV_FailedRows(t *testing.T) {
+	var fields []string
+	client := NewClient(nil).WithBaseURL(enrichServer(t, &fields).URL)
+
+	input := "ip,note\n8.8.8.8,ok\nnot-an-ip,bad\n\n"
+	var out bytes.Buffer
+	err := client.EnrichCSV(context.Background(), strings.NewReader(input), &out, "ip", []string{"country_code"})
+
+	var batchErr *BatchError
+	require.True(t, errors.As(err, &batchErr))
+	assert.Contains(t, batchErr.Errors, "not-an-ip")
+	assert.Equal(t, "ip,note,country_code\n8.8.8.8,ok,US\nnot-an-ip,bad,\n", out.String())
+}
+
+func TestEnrichCSV_ManyRows(t *testing.T) {
+	var fields []string
+	client := NewClient(nil).WithBaseURL(enrichServer(t, &fields).URL)
+
+	var input strings.Builder
+	input.WriteString("ip\n")
+	for i := 0; i < DefaultEnrichBatchSize+10; i++ {
+		fmt.Fprintf(&input, "8.8.%d.%d\n", i/256, i%256)
+	}
+	var out bytes.Buffer
+	require.NoError(t, client.EnrichCSV(context.Background(), strings.NewReader(input.String()), &out, "ip", nil))
+
+	records, err := csv.NewReader(&out).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, DefaultEnrichBatchSize+11)
+	assert.Equal(t, append([]string{"ip"}, DefaultCSVColumns[1:]...), records[0])
+	last := records[len(records)-1]
+	assert.Equal(t, fmt.Sprintf("8.8.%d.%d", (DefaultEnrichBatchSize+9)/256, (DefaultEnrichBatchSize+9)%256), last[0])
+	assert.Equal(t, "US", last[3], "rows after the first batch are enriched")
+}
+
+func TestEnrichCSV_Errors(t *testing.T) {
+	client := NewClient(nil)
+	ctx := context.Background()
+
+	err := client.EnrichCSV(ctx, strings.NewReader("ip\n"), &bytes.Buffer{}, "ip", []string{"nope"})
+	assert.EqualError(t, err, `unknown CSV column: "nope"`)
+
+	err = client.EnrichCSV(ctx, strings.NewReader("addr\n"), &bytes.Buffer{}, "ip", nil)
+	assert.EqualError(t, err, `CSV has no "ip" column`)
+
+	err = client.EnrichCSV(ctx, strings.NewReader(""), &bytes.Buffer{}, "ip", nil)
+	assert.ErrorContains(t, err, "failed to read CSV header")
+}
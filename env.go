@@ -0,0 +1,90 @@
+package iplocate
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables read by NewFromEnv
+const (
+	// EnvAPIKey holds the API key, or several comma-separated keys to rotate between
+	EnvAPIKey = "IPLOCATE_API_KEY"
+	// EnvBaseURL overrides the API base URL
+	EnvBaseURL = "IPLOCATE_BASE_URL"
+	// EnvTimeout is the request timeout, e.g. "10s" or a number of seconds
+	EnvTimeout = "IPLOCATE_TIMEOUT"
+	// EnvCacheTTL enables an in-memory lookup cache with the given TTL
+	EnvCacheTTL = "IPLOCATE_CACHE_TTL"
+	// EnvCacheSize is the maximum number of entries of the in-memory cache
+	EnvCacheSize = "IPLOCATE_CACHE_SIZE"
+	// EnvRetryAfter is the longest Retry-After wait to honor on 429 responses
+	EnvRetryAfter = "IPLOCATE_RETRY_AFTER"
+)
+
+// NewFromEnv creates a client configured from IPLOCATE_* environment
+// variables. Unset variables keep the defaults of NewClient; an invalid
+// value is an error.
+func NewFromEnv() (*Client, error) {
+	client := NewClient(nil)
+
+	if value := os.Getenv(EnvAPIKey); value != "" {
+		keys := strings.Split(value, ",")
+		for i := range keys {
+			keys[i] = strings.TrimSpace(keys[i])
+		}
+		client.WithAPIKeys(keys...)
+	}
+
+	if value := os.Getenv(EnvBaseURL); value != "" {
+		client.WithBaseURL(value)
+	}
+
+	if value := os.Getenv(EnvTimeout); value != "" {
+		timeout, err := parseEnvDuration(EnvTimeout, value)
+		if err != nil {
+			return nil, err
+		}
+		client.WithTimeout(timeout)
+	}
+
+	if value := os.Getenv(EnvRetryAfter); value != "" {
+		maxWait, err := parseEnvDuration(EnvRetryAfter, value)
+		if err != nil {
+			return nil, err
+		}
+		client.WithAutoRetryAfter(maxWait)
+	}
+
+	if value := os.Getenv(EnvCacheTTL); value != "" {
+		ttl, err := parseEnvDuration(EnvCacheTTL, value)
+		if err != nil {
+			return nil, err
+		}
+
+		size := DefaultMemoryCacheSize
+		if value := os.Getenv(EnvCacheSize); value != "" {
+			size, err = strconv.Atoi(value)
+			if err != nil || size <= 0 {
+				return nil, fmt.Errorf("invalid %s: %q", EnvCacheSize, value)
+			}
+		}
+		client.WithCache(NewMemoryCache(size), ttl)
+	}
+
+	return client, nil
+}
+
+// parseEnvDuration parses a Go duration such as "1m30s" or a plain number of seconds
+func parseEnvDuration(name, value string) (time.Duration, error) {
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 {
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid %s: %q", name, value)
+	}
+	return d, nil
+}
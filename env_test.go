@@ -0,0 +1,71 @@
+package iplocate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFromEnv_Defaults(t *testing.T) {
+	for _, name := range []string{EnvAPIKey, EnvBaseURL, EnvTimeout, EnvCacheTTL, EnvCacheSize, EnvRetryAfter} {
+		t.Setenv(name, "")
+	}
+
+	client, err := NewFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultBaseURL, client.baseURL)
+	assert.Equal(t, DefaultTimeout, client.httpClient.Timeout)
+	assert.Empty(t, client.apiKey)
+	assert.Nil(t, client.cache)
+}
+
+func TestNewFromEnv(t *testing.T) {
+	t.Setenv(EnvAPIKey, "test-key")
+	t.Setenv(EnvBaseURL, "https://api.example.com/")
+	t.Setenv(EnvTimeout, "5s")
+	t.Setenv(EnvCacheTTL, "1h")
+	t.Setenv(EnvCacheSize, "50")
+	t.Setenv(EnvRetryAfter, "10")
+
+	client, err := NewFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "test-key", client.apiKey)
+	assert.Equal(t, "https://api.example.com", client.baseURL)
+	assert.Equal(t, 5*time.Second, client.httpClient.Timeout)
+	assert.Equal(t, time.Hour, client.cacheTTL)
+	assert.Equal(t, 50, client.cache.(*MemoryCache).maxEntries)
+	assert.Equal(t, 10*time.Second, client.maxRetryAfter)
+}
+
+func TestNewFromEnv_MultipleKeys(t *testing.T) {
+	t.Setenv(EnvAPIKey, "key-1, key-2")
+
+	client, err := NewFromEnv()
+	require.NoError(t, err)
+	require.NotNil(t, client.keys)
+	assert.Equal(t, []string{"key-1", "key-2"}, client.keys.keys)
+}
+
+func TestNewFromEnv_Invalid(t *testing.T) {
+	tests := map[string]string{
+		EnvTimeout:    "soon",
+		EnvRetryAfter: "-1s",
+		EnvCacheTTL:   "forever",
+	}
+	for name, value := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, value)
+			_, err := NewFromEnv()
+			assert.ErrorContains(t, err, name)
+		})
+	}
+
+	t.Run(EnvCacheSize, func(t *testing.T) {
+		t.Setenv(EnvCacheTTL, "1m")
+		t.Setenv(EnvCacheSize, "0")
+		_, err := NewFromEnv()
+		assert.ErrorContains(t, err, EnvCacheSize)
+	})
+}
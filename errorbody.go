@@ -0,0 +1,64 @@
+package iplocate
+
+import (
+	"html"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxErrorSnippet is the longest error message kept in APIError.Message,
+// so error strings stay short enough to log
+const maxErrorSnippet = 200
+
+var (
+	// htmlHiddenElements matches elements whose content isn't shown
+	htmlHiddenElements = regexp.MustCompile(`(?is)<(script|style|head)\b.*?</(script|style|head)\s*>|<!--.*?-->`)
+	// htmlTags matches HTML tags, doctypes and unterminated tags
+	htmlTags = regexp.MustCompile(`<[!/?]?[a-zA-Z][^>]*(>|$)`)
+	// apikeyParams matches API keys echoed back in a URL
+	apikeyParams = regexp.MustCompile(`(?i)(apikey=)[^&\s"'<>]+`)
+)
+
+// errorSnippet returns the error message of a non-JSON error body, such as
+// an HTML page from a proxy, or the status text if the body has no text
+func errorSnippet(body []byte, statusCode int, keys []string) string {
+	text := htmlHiddenElements.ReplaceAllString(string(body), " ")
+	text = html.UnescapeString(htmlTags.ReplaceAllString(text, " "))
+	if snippet := sanitizeErrorMessage(text, keys); snippet != "" {
+		return snippet
+	}
+	return http.StatusText(statusCode)
+}
+
+// sanitizeErrorMessage makes an error message from the API or a proxy safe
+// to log: API keys are redacted, control characters and runs of whitespace
+// become a single space, and the message is truncated to maxErrorSnippet
+// bytes
+func sanitizeErrorMessage(message string, keys []string) string {
+	for _, key := range keys {
+		message = strings.ReplaceAll(message, key, redacted)
+	}
+	message = apikeyParams.ReplaceAllString(message, "${1}"+redacted)
+	message = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == utf8.RuneError {
+			return ' '
+		}
+		return r
+	}, message)
+	return truncateMessage(strings.Join(strings.Fields(message), " "))
+}
+
+// truncateMessage cuts message to maxErrorSnippet bytes at a rune boundary
+func truncateMessage(message string) string {
+	if len(message) <= maxErrorSnippet {
+		return message
+	}
+	cut := maxErrorSnippet
+	for cut > 0 && !utf8.RuneStart(message[cut]) {
+		cut--
+	}
+	return message[:cut] + "..."
+}
@@ -0,0 +1,131 @@
+package iplocate
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const badGatewayPage = `<html>
+<head><title>502 Bad Gateway</title></head>
+<body>
+<center><h1>502 Bad Gateway</h1></center>
+</body>
+</html>`
+
+func TestLookup_HTMLErrorPage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte(badGatewayPage))
+	}))
+	defer server.Close()
+
+	_, err := NewClient(nil).WithBaseURL(server.URL).Lookup("8.8.8.8")
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+	assert.Equal(t, "text/html; charset=utf-8", apiErr.ContentType)
+	assert.Equal(t, badGatewayPage, string(apiErr.Body))
+	assert.Equal(t, "502 Bad Gateway", apiErr.Message)
+	assert.Equal(t, "IPLocate API error (502): unexpected text/html response: 502 Bad Gateway", err.Error())
+}
+
+func TestLookup_JSONErrorBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"Not found"}`))
+	}))
+	defer server.Close()
+
+	_, err := NewClient(nil).WithBaseURL(server.URL).Lookup("8.8.8.8")
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "Not found", apiErr.Message)
+	assert.Equal(t, `{"error":"Not found"}`, string(apiErr.Body))
+	assert.Equal(t, "IPLocate API error (404): Not found", err.Error())
+}
+
+func TestLookup_EmptyErrorBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	_, err := NewClient(nil).WithBaseURL(server.URL).Lookup("8.8.8.8")
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "Service Unavailable", apiErr.Message)
+	assert.Equal(t, "IPLocate API error (503): unexpected non-JSON response: Service Unavailable", err.Error())
+}
+
+func TestErrorPages_NotTreatedAsAPIAnswers(t *testing.T) {
+	err := &APIError{StatusCode: http.StatusNotFound, Message: "Not Found", unparsed: true}
+	assert.False(t, negativeCacheable(err), "a proxy's 404 says nothing about the IP")
+	assert.True(t, shouldUseLocalDatabase(err))
+
+	err.unparsed = false
+	assert.True(t, negativeCacheable(err))
+	assert.False(t, shouldUseLocalDatabase(err))
+}
+
+func TestLookup_ErrorBodySanitized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("{\"error\":\"Invalid key secret-key\\u001b[31m\\nfor " + strings.Repeat("x", 500) + "\"}"))
+	}))
+	defer server.Close()
+
+	_, err := NewClient(nil).WithAPIKey("secret-key").WithBaseURL(server.URL).Lookup("8.8.8.8")
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.True(t, strings.HasPrefix(apiErr.Message, "Invalid key [REDACTED] [31m for xxx"))
+	assert.True(t, strings.HasSuffix(apiErr.Message, "..."))
+	assert.Len(t, apiErr.Message, maxErrorSnippet+len("..."))
+	assert.NotContains(t, err.Error(), "secret-key")
+}
+
+func TestLookup_TransportErrorRedacted(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	serverURL := server.URL
+	server.Close()
+
+	_, err := NewClient(nil).WithAPIKey("secret-key").WithBaseURL(serverURL).Lookup("8.8.8.8")
+	require.Error(t, err)
+	var urlErr *url.Error
+	require.True(t, errors.As(err, &urlErr))
+	assert.NotContains(t, err.Error(), "secret-key")
+	assert.Contains(t, err.Error(), "apikey=%5BREDACTED%5D")
+}
+
+func TestErrorSnippet(t *testing.T) {
+	assert.Equal(t, "Bad Gateway", errorSnippet(nil, http.StatusBadGateway, nil))
+	assert.Equal(t, "Bad Gateway", errorSnippet([]byte("<html><body></body></html>"), http.StatusBadGateway, nil))
+	assert.Equal(t, "upstream timed out", errorSnippet([]byte("  upstream\n\ttimed out\n"), http.StatusGatewayTimeout, nil))
+
+	page := `<!DOCTYPE html><html><head><title>Error</title><style>h1 { color: red }</style></head>
+<body><script>track("error")</script><!-- edge-7 --><h1>Access &amp; denied</h1>
+<p>Request to /api/lookup/8.8.8.8?apikey=abc123&amp;lang=en was blocked</p></body></html>`
+	assert.Equal(t, "Access & denied Request to /api/lookup/8.8.8.8?apikey=[REDACTED]&lang=en was blocked", errorSnippet([]byte(page), http.StatusForbidden, nil))
+	assert.Equal(t, "Access denied", errorSnippet([]byte("<h1>Access denied</h1><p"), http.StatusForbidden, nil))
+
+	snippet := errorSnippet([]byte(strings.Repeat("é", maxErrorSnippet)), http.StatusBadGateway, nil)
+	assert.True(t, strings.HasSuffix(snippet, "..."))
+	assert.LessOrEqual(t, len(snippet), maxErrorSnippet+len("..."))
+	assert.True(t, strings.HasPrefix(snippet, "éé"))
+	assert.NotContains(t, snippet, "\uFFFD")
+}
+
+func TestSanitizeErrorMessage(t *testing.T) {
+	assert.Equal(t, "Invalid API key", sanitizeErrorMessage("Invalid API key", []string{"key-1"}))
+	assert.Equal(t, "key [REDACTED] is invalid", sanitizeErrorMessage("key key-1 is invalid", []string{"key-1"}))
+	assert.Equal(t, "line one line two", sanitizeErrorMessage("line one\r\n\x00line two\x7f", nil))
+	assert.Equal(t, "bad byte", sanitizeErrorMessage("bad\xffbyte", nil))
+}
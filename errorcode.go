@@ -0,0 +1,72 @@
+package iplocate
+
+import (
+	"net"
+	"net/http"
+)
+
+// ErrorCode is the machine-readable code of an API error, for handling
+// errors beyond their HTTP status. Codes the client doesn't know are kept
+// as returned by the API.
+type ErrorCode string
+
+const (
+	// ErrorCodeInvalidIP is returned for a malformed IP address
+	ErrorCodeInvalidIP ErrorCode = "invalid_ip"
+	// ErrorCodeQuotaExceeded is returned when the account has used up its
+	// requests
+	ErrorCodeQuotaExceeded ErrorCode = "quota_exceeded"
+	// ErrorCodeUnauthorized is returned for a missing or invalid API key
+	ErrorCodeUnauthorized ErrorCode = "unauthorized"
+)
+
+// Known reports whether c is one of the ErrorCode constants
+func (c ErrorCode) Known() bool {
+	switch c {
+	case ErrorCodeInvalidIP, ErrorCodeQuotaExceeded, ErrorCodeUnauthorized:
+		return true
+	}
+	return false
+}
+
+// APIError implements net.Error, so retry libraries and callers checking for
+// temporary or timeout errors handle it without knowing this package
+var _ net.Error = (*APIError)(nil)
+
+// IsRateLimit reports whether the request was rejected for exceeding a rate
+// limit or the account's quota
+func (e *APIError) IsRateLimit() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.Code == ErrorCodeQuotaExceeded
+}
+
+// IsAuth reports whether the API key is missing, invalid or not allowed to
+// make the request
+func (e *APIError) IsAuth() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden || e.Code == ErrorCodeUnauthorized
+}
+
+// IsTemporary reports whether the same request may succeed later: server
+// errors, request timeouts and rate limits. An exhausted quota isn't
+// temporary, since it only resets at midnight UTC.
+func (e *APIError) IsTemporary() bool {
+	if e.Code == ErrorCodeQuotaExceeded {
+		return false
+	}
+	switch e.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return true
+	case http.StatusNotImplemented, http.StatusHTTPVersionNotSupported:
+		return false
+	}
+	return e.StatusCode >= http.StatusInternalServerError
+}
+
+// Temporary is IsTemporary, for the net.Error interface
+func (e *APIError) Temporary() bool {
+	return e.IsTemporary()
+}
+
+// Timeout reports whether the API or a gateway in front of it timed out
+func (e *APIError) Timeout() bool {
+	return e.StatusCode == http.StatusRequestTimeout || e.StatusCode == http.StatusGatewayTimeout
+}
@@ -0,0 +1,99 @@
+package iplocate
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIError_Code(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		code   ErrorCode
+		known  bool
+	}{
+		{"invalid IP", http.StatusBadRequest, `{"error":"Invalid IP address","code":"invalid_ip"}`, ErrorCodeInvalidIP, true},
+		{"quota", http.StatusTooManyRequests, `{"error":"Quota exceeded","code":"quota_exceeded"}`, ErrorCodeQuotaExceeded, true},
+		{"unauthorized", http.StatusForbidden, `{"error":"Invalid API key","code":"unauthorized"}`, ErrorCodeUnauthorized, true},
+		{"unknown code", http.StatusBadRequest, `{"error":"Bad field","code":"invalid_field"}`, "invalid_field", false},
+		{"no code", http.StatusNotFound, `{"error":"Not found"}`, "", false},
+		{"HTML page", http.StatusBadGateway, `<h1>Bad Gateway</h1>`, "", false},
+	}
+
+	for _, tt := range tests {
+		for _, generated := range []bool{false, true} {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+
+			client := NewClient(nil).WithBaseURL(server.URL)
+			if generated {
+				client.WithGeneratedJSONCodec()
+			}
+			_, err := client.Lookup("8.8.8.8")
+			server.Close()
+
+			var apiErr *APIError
+			require.True(t, errors.As(err, &apiErr), tt.name)
+			assert.Equal(t, tt.code, apiErr.Code, tt.name)
+			assert.Equal(t, tt.known, apiErr.Code.Known(), tt.name)
+			assert.Equal(t, tt.status, apiErr.StatusCode, tt.name)
+		}
+	}
+}
+
+func TestAPIError_StatusHelpers(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       *APIError
+		rateLimit bool
+		auth      bool
+		temporary bool
+		timeout   bool
+	}{
+		{"bad request", &APIError{StatusCode: http.StatusBadRequest, Code: ErrorCodeInvalidIP}, false, false, false, false},
+		{"unauthorized", &APIError{StatusCode: http.StatusUnauthorized}, false, true, false, false},
+		{"forbidden", &APIError{StatusCode: http.StatusForbidden}, false, true, false, false},
+		{"unauthorized code", &APIError{StatusCode: http.StatusBadRequest, Code: ErrorCodeUnauthorized}, false, true, false, false},
+		{"request timeout", &APIError{StatusCode: http.StatusRequestTimeout}, false, false, true, true},
+		{"rate limited", &APIError{StatusCode: http.StatusTooManyRequests}, true, false, true, false},
+		{"quota exceeded", &APIError{StatusCode: http.StatusTooManyRequests, Code: ErrorCodeQuotaExceeded}, true, false, false, false},
+		{"server error", &APIError{StatusCode: http.StatusInternalServerError}, false, false, true, false},
+		{"not implemented", &APIError{StatusCode: http.StatusNotImplemented}, false, false, false, false},
+		{"bad gateway", &APIError{StatusCode: http.StatusBadGateway, unparsed: true}, false, false, true, false},
+		{"gateway timeout", &APIError{StatusCode: http.StatusGatewayTimeout}, false, false, true, true},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.rateLimit, tt.err.IsRateLimit(), tt.name)
+		assert.Equal(t, tt.auth, tt.err.IsAuth(), tt.name)
+		assert.Equal(t, tt.temporary, tt.err.IsTemporary(), tt.name)
+		assert.Equal(t, tt.temporary, tt.err.Temporary(), tt.name)
+		assert.Equal(t, tt.timeout, tt.err.Timeout(), tt.name)
+	}
+}
+
+func TestAPIError_NetError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"error":"Try again later"}`))
+	}))
+	defer server.Close()
+
+	_, err := NewClient(nil).WithBaseURL(server.URL).Lookup("8.8.8.8")
+	var netErr net.Error
+	require.True(t, errors.As(err, &netErr))
+	assert.False(t, netErr.Timeout())
+
+	var temporary interface{ Temporary() bool }
+	require.True(t, errors.As(err, &temporary))
+	assert.True(t, temporary.Temporary())
+}
@@ -0,0 +1,51 @@
+package iplocate
+
+import (
+	"context"
+	"time"
+)
+
+// LookupEvent describes a finished lookup of an IP address
+type LookupEvent struct {
+	// Time is when the lookup started
+	Time time.Time
+	IP   string
+	// Response is nil if the lookup failed. It may be reused once Emit
+	// returns when the lookup was made with LookupInto; copy it to keep it.
+	Response *LookupResponse
+	Err      error
+	Duration time.Duration
+	// Stale is set when Response is an expired cache entry served because
+	// the API failed
+	Stale bool
+}
+
+// EventSink receives an event for every lookup the client finishes,
+// including lookups answered from the cache or a local database. Emit is
+// called from the goroutine that made the lookup, so it should not block.
+type EventSink interface {
+	Emit(ctx context.Context, event LookupEvent)
+}
+
+// EventSinkFunc adapts a function to the EventSink interface
+type EventSinkFunc func(ctx context.Context, event LookupEvent)
+
+// Emit calls f(ctx, event)
+func (f EventSinkFunc) Emit(ctx context.Context, event LookupEvent) {
+	f(ctx, event)
+}
+
+// WithEventSink adds a sink that receives an event for every lookup.
+// Call it once per sink; sinks are called in the order they were added.
+func (c *Client) WithEventSink(sink EventSink) *Client {
+	c.sinks = append(c.sinks, sink)
+	return c
+}
+
+// emit sends event to every sink
+func (c *Client) emit(ctx context.Context, event LookupEvent) {
+	event = c.privateEvent(event)
+	for _, sink := range c.sinks {
+		sink.Emit(ctx, event)
+	}
+}
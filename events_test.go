@@ -0,0 +1,67 @@
+package iplocate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithEventSink(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8", CountryCode: stringPtr("US")})
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	var events []LookupEvent
+	client := NewClient(nil).WithBaseURL(server.URL).
+		WithCache(NewMemoryCache(10), time.Hour).
+		WithEventSink(EventSinkFunc(func(ctx context.Context, event LookupEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, event)
+		}))
+
+	_, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	_, err = client.Lookup("8.8.8.8")
+	require.NoError(t, err, "cached lookups are reported too")
+	_, err = client.Lookup("not-an-ip")
+	require.Error(t, err)
+
+	require.Len(t, events, 3)
+	assert.Equal(t, "8.8.8.8", events[0].IP)
+	require.NotNil(t, events[0].Response)
+	assert.Equal(t, "US", *events[0].Response.CountryCode)
+	assert.NoError(t, events[0].Err)
+	assert.False(t, events[0].Time.IsZero())
+	assert.GreaterOrEqual(t, events[0].Duration, time.Duration(0))
+
+	assert.Equal(t, "not-an-ip", events[2].IP)
+	assert.Nil(t, events[2].Response)
+	assert.Error(t, events[2].Err)
+}
+
+func TestWithEventSink_Order(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8"})
+	}))
+	defer server.Close()
+
+	var calls []string
+	sink := func(name string) EventSink {
+		return EventSinkFunc(func(ctx context.Context, event LookupEvent) { calls = append(calls, name) })
+	}
+	client := NewClient(nil).WithBaseURL(server.URL).WithEventSink(sink("first")).WithEventSink(sink("second"))
+
+	_, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first", "second"}, calls)
+}
@@ -66,8 +66,8 @@ func main() {
 	printSection("Geographic Coordinates")
 	printFloatField("Latitude", result.Latitude)
 	printFloatField("Longitude", result.Longitude)
-	if result.Latitude != nil && result.Longitude != nil {
-		fmt.Printf("Google Maps: https://maps.google.com/?q=%.4f,%.4f\n", *result.Latitude, *result.Longitude)
+	if link := result.MapLink(iplocate.MapGoogle); link != "" {
+		fmt.Printf("Google Maps: %s\n", link)
 	}
 
 	printSection("Network Information")
@@ -0,0 +1,304 @@
+package iplocate
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+)
+
+// errMalformedJSON is returned by DecodeCountry for documents it can't scan
+var errMalformedJSON = errors.New("failed to decode response: malformed JSON")
+
+// maxSkipDepth caps how deeply nested a skipped value can be, so a hostile
+// document can't exhaust the stack
+const maxSkipDepth = 10000
+
+// countryCodes holds every two letter code, so decoding a country code
+// doesn't allocate a string
+var countryCodes = func() (codes [26 * 26]string) {
+	for i := range codes {
+		codes[i] = string([]byte{byte('A' + i/26), byte('A' + i%26)})
+	}
+	return codes
+}()
+
+// DecodeCountry extracts the country code from a JSON lookup response
+// without decoding the rest, for services that only branch on the country.
+// It returns an empty string if the response has no country. It doesn't
+// allocate for well-formed responses.
+func DecodeCountry(data []byte) (string, error) {
+	code, _, err := decodeCountryASN(data, false)
+	return code, err
+}
+
+// DecodeCountryASN is like DecodeCountry, and also extracts the number of
+// the ASN, or 0 if the response has none
+func DecodeCountryASN(data []byte) (countryCode string, asn uint32, err error) {
+	return decodeCountryASN(data, true)
+}
+
+// decodeCountryASN scans the top level object for country_code and, if
+// wantASN is set, asn.asn, skipping every other value
+func decodeCountryASN(data []byte, wantASN bool) (string, uint32, error) {
+	s := scanner{data: data}
+	var code string
+	var asn uint32
+	foundCode, foundASN := false, !wantASN
+
+	err := s.object(func(key []byte) error {
+		switch {
+		case string(key) == "country_code":
+			value, err := s.stringValue()
+			if err != nil {
+				return err
+			}
+			code, foundCode = internCountryCode(value), true
+		case wantASN && string(key) == "asn":
+			number, err := s.asnObject()
+			if err != nil {
+				return err
+			}
+			asn, foundASN = number, true
+		default:
+			if err := s.skip(); err != nil {
+				return err
+			}
+		}
+		if foundCode && foundASN {
+			return errDone
+		}
+		return nil
+	})
+	if err != nil && err != errDone {
+		return "", 0, err
+	}
+	return code, asn, nil
+}
+
+// errDone stops a scan once the wanted fields are found
+var errDone = errors.New("done")
+
+// internCountryCode returns value as a string, without allocating for
+// two letter codes
+func internCountryCode(value []byte) string {
+	if len(value) == 2 && value[0] >= 'A' && value[0] <= 'Z' && value[1] >= 'A' && value[1] <= 'Z' {
+		return countryCodes[int(value[0]-'A')*26+int(value[1]-'A')]
+	}
+	return string(value)
+}
+
+// scanner reads just enough JSON to find fields without building values
+type scanner struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+func (s *scanner) space() {
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case ' ', '\t', '\n', '\r':
+			s.pos++
+		default:
+			return
+		}
+	}
+}
+
+// peek returns the next non-space byte, or 0 at the end
+func (s *scanner) peek() byte {
+	s.space()
+	if s.pos >= len(s.data) {
+		return 0
+	}
+	return s.data[s.pos]
+}
+
+func (s *scanner) expect(b byte) error {
+	if s.peek() != b {
+		return errMalformedJSON
+	}
+	s.pos++
+	return nil
+}
+
+// object calls field for each key of an object, with the scanner before its value
+func (s *scanner) object(field func(key []byte) error) error {
+	if err := s.expect('{'); err != nil {
+		return err
+	}
+	if s.peek() == '}' {
+		s.pos++
+		return nil
+	}
+	for {
+		key, err := s.rawString()
+		if err != nil {
+			return err
+		}
+		if err := s.expect(':'); err != nil {
+			return err
+		}
+		if err := field(key); err != nil {
+			return err
+		}
+		switch s.peek() {
+		case ',':
+			s.pos++
+		case '}':
+			s.pos++
+			return nil
+		default:
+			return errMalformedJSON
+		}
+	}
+}
+
+// rawString returns the contents of a string without unescaping them
+func (s *scanner) rawString() ([]byte, error) {
+	if err := s.expect('"'); err != nil {
+		return nil, err
+	}
+	start := s.pos
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case '\\':
+			s.pos += 2
+		case '"':
+			s.pos++
+			return s.data[start : s.pos-1], nil
+		default:
+			s.pos++
+		}
+	}
+	return nil, errMalformedJSON
+}
+
+// stringValue returns a string value, unescaped, or nil for null
+func (s *scanner) stringValue() ([]byte, error) {
+	if s.peek() == 'n' {
+		return nil, s.literal("null")
+	}
+	start := s.pos
+	raw, err := s.rawString()
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range raw {
+		if b == '\\' {
+			var value string
+			if err := json.Unmarshal(s.data[start:s.pos], &value); err != nil {
+				return nil, errMalformedJSON
+			}
+			return []byte(value), nil
+		}
+	}
+	return raw, nil
+}
+
+// asnObject returns the number of the asn field of an ASN object, or 0 for null
+func (s *scanner) asnObject() (uint32, error) {
+	if s.peek() == 'n' {
+		return 0, s.literal("null")
+	}
+	var asn uint32
+	err := s.object(func(key []byte) error {
+		if string(key) != "asn" {
+			return s.skip()
+		}
+		value, err := s.stringValue()
+		if err != nil {
+			return err
+		}
+		if len(value) > 2 && (value[0] == 'A' || value[0] == 'a') && (value[1] == 'S' || value[1] == 's') {
+			value = value[2:]
+		}
+		asn = parseASNNumber(value)
+		return nil
+	})
+	return asn, err
+}
+
+// parseASNNumber parses the digits of an AS number, or returns 0
+func parseASNNumber(digits []byte) uint32 {
+	if len(digits) == 0 || len(digits) > 10 {
+		return 0
+	}
+	var n uint64
+	for _, b := range digits {
+		if b < '0' || b > '9' {
+			return 0
+		}
+		n = n*10 + uint64(b-'0')
+	}
+	if n > math.MaxUint32 {
+		return 0
+	}
+	return uint32(n)
+}
+
+func (s *scanner) literal(lit string) error {
+	s.space()
+	if len(s.data)-s.pos < len(lit) || string(s.data[s.pos:s.pos+len(lit)]) != lit {
+		return errMalformedJSON
+	}
+	s.pos += len(lit)
+	return nil
+}
+
+// skip moves past the next value
+func (s *scanner) skip() error {
+	s.depth++
+	defer func() { s.depth-- }()
+	if s.depth > maxSkipDepth {
+		return errMalformedJSON
+	}
+
+	switch s.peek() {
+	case '"':
+		_, err := s.rawString()
+		return err
+	case '{':
+		return s.object(func([]byte) error { return s.skip() })
+	case '[':
+		s.pos++
+		if s.peek() == ']' {
+			s.pos++
+			return nil
+		}
+		for {
+			if err := s.skip(); err != nil {
+				return err
+			}
+			switch s.peek() {
+			case ',':
+				s.pos++
+			case ']':
+				s.pos++
+				return nil
+			default:
+				return errMalformedJSON
+			}
+		}
+	case 't':
+		return s.literal("true")
+	case 'f':
+		return s.literal("false")
+	case 'n':
+		return s.literal("null")
+	default:
+		start := s.pos
+		for s.pos < len(s.data) {
+			b := s.data[s.pos]
+			if (b >= '0' && b <= '9') || b == '-' || b == '+' || b == '.' || b == 'e' || b == 'E' {
+				s.pos++
+				continue
+			}
+			break
+		}
+		if s.pos == start {
+			return errMalformedJSON
+		}
+		return nil
+	}
+}
@@ -0,0 +1,89 @@
+package iplocate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeCountry(t *testing.T) {
+	code, err := DecodeCountry(benchmarkBody(t))
+	require.NoError(t, err)
+	assert.Equal(t, "US", code)
+
+	code, asn, err := DecodeCountryASN(benchmarkBody(t))
+	require.NoError(t, err)
+	assert.Equal(t, "US", code)
+	assert.Equal(t, uint32(15169), asn)
+
+	tests := []struct {
+		name string
+		body string
+		code string
+		asn  uint32
+	}{
+		{"fields in any order", `{"asn": {"route": "1.0.0.0/24", "asn": "AS13335"}, "ip": "1.1.1.1", "country_code": "AU"}`, "AU", 13335},
+		{"skipped values", `{"ip": "1.1.1.1", "a": [1, -2.5e3, true, false, null, {"b": ["\"}"]}], "country_code": "AU", "asn": null}`, "AU", 0},
+		{"null country", `{"country_code": null, "asn": {"asn": "15169"}}`, "", 15169},
+		{"missing fields", `{"ip": "1.1.1.1"}`, "", 0},
+		{"empty object", ` { } `, "", 0},
+		{"escaped code", `{"country_code": "\u0055S"}`, "US", 0},
+		{"invalid ASN", `{"asn": {"asn": "AS99999999999"}}`, "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, asn, err := DecodeCountryASN([]byte(tt.body))
+			require.NoError(t, err)
+			assert.Equal(t, tt.code, code)
+			assert.Equal(t, tt.asn, asn)
+		})
+	}
+
+	for _, body := range []string{``, `[]`, `{"country_code": "US"`, `{"country_code" "US"}`, `{"a": tru}`, `{"a": "unterminated}`, `<html>`} {
+		_, _, err := DecodeCountryASN([]byte(body))
+		assert.Error(t, err, body)
+	}
+}
+
+func TestDecodeCountry_StopsEarly(t *testing.T) {
+	// The malformed tail is never read once the country is found
+	code, err := DecodeCountry([]byte(`{"country_code": "DE", "city": `))
+	require.NoError(t, err)
+	assert.Equal(t, "DE", code)
+}
+
+func TestDecodeCountry_DeepNesting(t *testing.T) {
+	nested := func(depth int) []byte {
+		return []byte(`{"a": ` + strings.Repeat("[", depth) + strings.Repeat("]", depth) + `, "country_code": "US"}`)
+	}
+
+	code, err := DecodeCountry(nested(maxSkipDepth - 1))
+	require.NoError(t, err)
+	assert.Equal(t, "US", code)
+
+	_, err = DecodeCountry(nested(1 << 20))
+	assert.ErrorIs(t, err, errMalformedJSON)
+}
+
+func TestDecodeCountry_Allocations(t *testing.T) {
+	body := benchmarkBody(t)
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, _, err := DecodeCountryASN(body); err != nil {
+			t.Fatal(err)
+		}
+	})
+	assert.Zero(t, allocs)
+}
+
+func BenchmarkDecodeCountryASN(b *testing.B) {
+	body := benchmarkBody(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := DecodeCountryASN(body); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package iplocate
+
+// FlatResponse is a value-typed view of a LookupResponse. Optional fields are
+// plain values paired with a Has* flag that reports whether the API returned
+// them, which is easier to use from templates and to serialize downstream.
+type FlatResponse struct {
+	IP              string      `json:"ip"`
+	Hostname        string      `json:"hostname"`
+	HasHostname     bool        `json:"has_hostname"`
+	Country         string      `json:"country"`
+	HasCountry      bool        `json:"has_country"`
+	CountryCode     string      `json:"country_code"`
+	HasCountryCode  bool        `json:"has_country_code"`
+	IsEU            bool        `json:"is_eu"`
+	City            string      `json:"city"`
+	HasCity         bool        `json:"has_city"`
+	Continent       string      `json:"continent"`
+	HasContinent    bool        `json:"has_continent"`
+	Latitude        float64     `json:"latitude"`
+	HasLatitude     bool        `json:"has_latitude"`
+	Longitude       float64     `json:"longitude"`
+	HasLongitude    bool        `json:"has_longitude"`
+	TimeZone        string      `json:"time_zone"`
+	HasTimeZone     bool        `json:"has_time_zone"`
+	PostalCode      string      `json:"postal_code"`
+	HasPostalCode   bool        `json:"has_postal_code"`
+	Subdivision     string      `json:"subdivision"`
+	HasSubdivision  bool        `json:"has_subdivision"`
+	CurrencyCode    string      `json:"currency_code"`
+	HasCurrencyCode bool        `json:"has_currency_code"`
+	CallingCode     string      `json:"calling_code"`
+	HasCallingCode  bool        `json:"has_calling_code"`
+	Network         string      `json:"network"`
+	HasNetwork      bool        `json:"has_network"`
+	ASN             ASN         `json:"asn"`
+	HasASN          bool        `json:"has_asn"`
+	Privacy         Privacy     `json:"privacy"`
+	Company         Company     `json:"company"`
+	HasCompany      bool        `json:"has_company"`
+	Hosting         FlatHosting `json:"hosting"`
+	HasHosting      bool        `json:"has_hosting"`
+	Abuse           FlatAbuse   `json:"abuse"`
+	HasAbuse        bool        `json:"has_abuse"`
+}
+
+// FlatHosting is the value-typed form of Hosting. Missing fields are empty.
+type FlatHosting struct {
+	Provider string `json:"provider"`
+	Domain   string `json:"domain"`
+	Network  string `json:"network"`
+	Region   string `json:"region"`
+	Service  string `json:"service"`
+}
+
+// FlatAbuse is the value-typed form of Abuse. Missing fields are empty.
+type FlatAbuse struct {
+	Address     string `json:"address"`
+	CountryCode string `json:"country_code"`
+	Email       string `json:"email"`
+	Name        string `json:"name"`
+	Network     string `json:"network"`
+	Phone       string `json:"phone"`
+}
+
+// Flat returns a value-typed copy of the response
+func (r *LookupResponse) Flat() FlatResponse {
+	f := FlatResponse{
+		IP:      r.IP,
+		IsEU:    r.IsEU,
+		Privacy: r.Privacy,
+	}
+	f.Hostname, f.HasHostname = value(r.Hostname)
+	f.Country, f.HasCountry = value(r.Country)
+	f.CountryCode, f.HasCountryCode = value(r.CountryCode)
+	f.City, f.HasCity = value(r.City)
+	f.Continent, f.HasContinent = value(r.Continent)
+	f.Latitude, f.HasLatitude = value(r.Latitude)
+	f.Longitude, f.HasLongitude = value(r.Longitude)
+	f.TimeZone, f.HasTimeZone = value(r.TimeZone)
+	f.PostalCode, f.HasPostalCode = value(r.PostalCode)
+	f.Subdivision, f.HasSubdivision = value(r.Subdivision)
+	f.CurrencyCode, f.HasCurrencyCode = value(r.CurrencyCode)
+	f.CallingCode, f.HasCallingCode = value(r.CallingCode)
+	f.Network, f.HasNetwork = value(r.Network)
+	f.ASN, f.HasASN = value(r.ASN)
+	f.Company, f.HasCompany = value(r.Company)
+
+	if r.Hosting != nil {
+		f.HasHosting = true
+		f.Hosting.Provider, _ = value(r.Hosting.Provider)
+		f.Hosting.Domain, _ = value(r.Hosting.Domain)
+		f.Hosting.Network, _ = value(r.Hosting.Network)
+		f.Hosting.Region, _ = value(r.Hosting.Region)
+		f.Hosting.Service, _ = value(r.Hosting.Service)
+	}
+
+	if r.Abuse != nil {
+		f.HasAbuse = true
+		f.Abuse.Address, _ = value(r.Abuse.Address)
+		f.Abuse.CountryCode, _ = value(r.Abuse.CountryCode)
+		f.Abuse.Email, _ = value(r.Abuse.Email)
+		f.Abuse.Name, _ = value(r.Abuse.Name)
+		f.Abuse.Network, _ = value(r.Abuse.Network)
+		f.Abuse.Phone, _ = value(r.Abuse.Phone)
+	}
+
+	return f
+}
+
+// value dereferences p, reporting whether it was set
+func value[T any](p *T) (T, bool) {
+	if p == nil {
+		var zero T
+		return zero, false
+	}
+	return *p, true
+}
@@ -0,0 +1,47 @@
+package iplocate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlat(t *testing.T) {
+	resp := &LookupResponse{
+		IP:          "8.8.8.8",
+		Hostname:    stringPtr("dns.google"),
+		Country:     stringPtr("United States"),
+		CountryCode: stringPtr("US"),
+		Latitude:    float64Ptr(37.386),
+		ASN:         &ASN{ASN: "AS15169", Name: "Google LLC"},
+		Privacy:     Privacy{IsHosting: true},
+		Hosting:     &Hosting{Provider: stringPtr("Google Cloud")},
+	}
+
+	flat := resp.Flat()
+	assert.Equal(t, "8.8.8.8", flat.IP)
+	assert.Equal(t, "dns.google", flat.Hostname)
+	assert.True(t, flat.HasHostname)
+	assert.Equal(t, "United States", flat.Country)
+	assert.True(t, flat.HasCountry)
+	assert.Equal(t, "US", flat.CountryCode)
+	assert.True(t, flat.HasCountryCode)
+	assert.Equal(t, 37.386, flat.Latitude)
+	assert.True(t, flat.HasLatitude)
+	assert.False(t, flat.HasLongitude)
+	assert.Empty(t, flat.City)
+	assert.False(t, flat.HasCity)
+	assert.True(t, flat.HasASN)
+	assert.Equal(t, "Google LLC", flat.ASN.Name)
+	assert.True(t, flat.Privacy.IsHosting)
+	assert.False(t, flat.HasCompany)
+	assert.True(t, flat.HasHosting)
+	assert.Equal(t, "Google Cloud", flat.Hosting.Provider)
+	assert.Empty(t, flat.Hosting.Region)
+	assert.False(t, flat.HasAbuse)
+}
+
+func TestFlat_Empty(t *testing.T) {
+	flat := (&LookupResponse{IP: "203.0.113.1"}).Flat()
+	assert.Equal(t, FlatResponse{IP: "203.0.113.1"}, flat)
+}
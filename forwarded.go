@@ -0,0 +1,152 @@
+package iplocate
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// ErrNoPublicHop is returned by ForwardedChain.Lookup when the client of a
+// request isn't a public IP address
+var ErrNoPublicHop = errors.New("no public client address in forwarded chain")
+
+// cgnat is the shared address space of carrier-grade NAT (RFC 6598)
+var cgnat = netip.MustParsePrefix("100.64.0.0/10")
+
+// HopKind classifies a hop of a forwarded chain
+type HopKind int
+
+const (
+	// HopInvalid is an entry that isn't an IP address, such as "unknown"
+	HopInvalid HopKind = iota
+	// HopPublic is a publicly routable address
+	HopPublic
+	// HopPrivate is a private, loopback, link-local, CGNAT or unspecified
+	// address
+	HopPrivate
+	// HopTrusted is an address of a trusted proxy
+	HopTrusted
+)
+
+// String returns the lowercase name of the kind
+func (k HopKind) String() string {
+	switch k {
+	case HopPublic:
+		return "public"
+	case HopPrivate:
+		return "private"
+	case HopTrusted:
+		return "trusted"
+	default:
+		return "invalid"
+	}
+}
+
+// Hop is an entry of a forwarded chain
+type Hop struct {
+	// Raw is the entry as it appeared in the chain
+	Raw string
+	// Addr is the address of the hop; it is the zero Addr for HopInvalid
+	Addr netip.Addr
+	Kind HopKind
+}
+
+// ForwardedChain is the chain of addresses a request went through, parsed
+// from X-Forwarded-For headers. Its Hops are in header order: the address
+// claimed as the original client first, and the peer that connected to the
+// server last.
+//
+// Taking the first entry of X-Forwarded-For as the client is a common
+// security bug, since clients can send the header themselves. Client walks
+// the chain from the peer instead and stops at the first hop that isn't a
+// trusted proxy; entries before it can be forged.
+type ForwardedChain struct {
+	Hops []Hop
+}
+
+// ParseForwardedChain parses the X-Forwarded-For values of a request
+// followed by the address of the peer that sent it, as in
+// http.Request.RemoteAddr. Entries may carry a port. Hops in trusted, which
+// may be nil, are classified as HopTrusted.
+func ParseForwardedChain(remoteAddr string, forwardedFor []string, trusted *CIDRSet) *ForwardedChain {
+	chain := &ForwardedChain{}
+	for _, value := range forwardedFor {
+		for _, entry := range strings.Split(value, ",") {
+			if entry = strings.TrimSpace(entry); entry != "" {
+				chain.Hops = append(chain.Hops, parseHop(entry, trusted))
+			}
+		}
+	}
+	if remoteAddr != "" {
+		chain.Hops = append(chain.Hops, parseHop(remoteAddr, trusted))
+	}
+	return chain
+}
+
+// ForwardedChainFromRequest parses the forwarded chain of r from its
+// X-Forwarded-For headers and RemoteAddr
+func ForwardedChainFromRequest(r *http.Request, trusted *CIDRSet) *ForwardedChain {
+	return ParseForwardedChain(r.RemoteAddr, r.Header.Values("X-Forwarded-For"), trusted)
+}
+
+// Client returns the hop that sent the request: walking from the peer, the
+// first hop that isn't a trusted proxy, or the first hop of the chain if
+// they all are. It returns false if the chain is empty or that hop isn't an
+// IP address.
+func (c *ForwardedChain) Client() (Hop, bool) {
+	for i := len(c.Hops) - 1; i >= 0; i-- {
+		hop := c.Hops[i]
+		switch {
+		case hop.Kind == HopInvalid:
+			return Hop{}, false
+		case hop.Kind != HopTrusted || i == 0:
+			return hop, true
+		}
+	}
+	return Hop{}, false
+}
+
+// PublicClient returns the client of the request if it is a public address
+func (c *ForwardedChain) PublicClient() (Hop, bool) {
+	hop, ok := c.Client()
+	if !ok || hop.Kind != HopPublic {
+		return Hop{}, false
+	}
+	return hop, true
+}
+
+// Lookup looks up the public client of the request with client. It fails
+// with ErrNoPublicHop if the client is a private address or unknown.
+func (c *ForwardedChain) Lookup(ctx context.Context, client *Client, opts ...LookupOption) (*LookupResponse, error) {
+	hop, ok := c.PublicClient()
+	if !ok {
+		return nil, ErrNoPublicHop
+	}
+	return client.LookupContext(ctx, hop.Addr.String(), opts...)
+}
+
+// parseHop parses and classifies an entry of a forwarded chain
+func parseHop(entry string, trusted *CIDRSet) Hop {
+	hop := Hop{Raw: entry}
+	addr, err := netip.ParseAddr(strings.Trim(entry, `"`))
+	if err != nil {
+		addrPort, err := netip.ParseAddrPort(strings.Trim(entry, `"`))
+		if err != nil {
+			return hop
+		}
+		addr = addrPort.Addr()
+	}
+	hop.Addr = addr.WithZone("").Unmap()
+
+	switch a := hop.Addr; {
+	case trusted != nil && trusted.ContainsAddr(a):
+		hop.Kind = HopTrusted
+	case a.IsPrivate(), a.IsLoopback(), a.IsLinkLocalUnicast(), a.IsUnspecified(), cgnat.Contains(a):
+		hop.Kind = HopPrivate
+	default:
+		hop.Kind = HopPublic
+	}
+	return hop
+}
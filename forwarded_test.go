@@ -0,0 +1,94 @@
+package iplocate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func trustedProxies(t *testing.T, cidrs ...string) *CIDRSet {
+	set, err := NewCIDRSet(cidrs...)
+	require.NoError(t, err)
+	return set
+}
+
+func TestParseForwardedChain(t *testing.T) {
+	trusted := trustedProxies(t, "10.0.0.0/8", "2001:db8:ffff::/48")
+	chain := ParseForwardedChain("10.0.0.2:51234", []string{
+		`203.0.113.7, "192.168.1.20"`,
+		"unknown,  ,198.51.100.9:8080, [2001:db8:ffff::1]:443",
+		"::ffff:100.64.1.1, 10.0.0.1",
+	}, trusted)
+
+	var kinds []string
+	var addrs []string
+	for _, hop := range chain.Hops {
+		kinds = append(kinds, hop.Kind.String())
+		addrs = append(addrs, hop.Addr.String())
+	}
+	assert.Equal(t, []string{"public", "private", "invalid", "public", "trusted", "private", "trusted", "trusted"}, kinds)
+	assert.Equal(t, []string{"203.0.113.7", "192.168.1.20", "invalid IP", "198.51.100.9", "2001:db8:ffff::1", "100.64.1.1", "10.0.0.1", "10.0.0.2"}, addrs)
+	assert.Equal(t, "unknown", chain.Hops[2].Raw)
+	assert.Equal(t, "[2001:db8:ffff::1]:443", chain.Hops[4].Raw)
+}
+
+func TestForwardedChain_Client(t *testing.T) {
+	trusted := trustedProxies(t, "10.0.0.0/8")
+	tests := []struct {
+		name       string
+		remoteAddr string
+		xff        []string
+		trusted    *CIDRSet
+		client     string
+		public     bool
+	}{
+		{"no proxy", "203.0.113.7:1234", nil, trusted, "203.0.113.7", true},
+		{"spoofed header from untrusted peer", "203.0.113.7:1234", []string{"198.51.100.1"}, trusted, "203.0.113.7", true},
+		{"no trusted proxies", "10.0.0.1:1234", []string{"198.51.100.1"}, nil, "10.0.0.1", false},
+		{"behind trusted proxies", "10.0.0.1:1234", []string{"6.6.6.6, 198.51.100.1, 10.0.0.5"}, trusted, "198.51.100.1", true},
+		{"private client behind proxy", "10.0.0.1:1234", []string{"192.168.1.20"}, trusted, "192.168.1.20", false},
+		{"only trusted hops", "10.0.0.1:1234", []string{"10.0.0.9"}, trusted, "10.0.0.9", false},
+	}
+	for _, tt := range tests {
+		chain := ParseForwardedChain(tt.remoteAddr, tt.xff, tt.trusted)
+		hop, ok := chain.Client()
+		require.True(t, ok, tt.name)
+		assert.Equal(t, tt.client, hop.Addr.String(), tt.name)
+
+		_, ok = chain.PublicClient()
+		assert.Equal(t, tt.public, ok, tt.name)
+	}
+
+	_, ok := ParseForwardedChain("10.0.0.1:1234", []string{"198.51.100.1, unknown"}, trusted).Client()
+	assert.False(t, ok, "the hop forwarded by the proxy isn't an address")
+	_, ok = ParseForwardedChain("", nil, trusted).Client()
+	assert.False(t, ok)
+}
+
+func TestForwardedChain_Lookup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(LookupResponse{IP: strings.TrimPrefix(r.URL.Path, "/lookup/")})
+	}))
+	defer server.Close()
+	client := NewClient(nil).WithBaseURL(server.URL)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Add("X-Forwarded-For", "6.6.6.6")
+	req.Header.Add("X-Forwarded-For", "198.51.100.1")
+	chain := ForwardedChainFromRequest(req, trustedProxies(t, "10.0.0.0/8"))
+
+	result, err := chain.Lookup(context.Background(), client)
+	require.NoError(t, err)
+	assert.Equal(t, "198.51.100.1", result.IP)
+
+	req.Header.Set("X-Forwarded-For", "192.168.1.20")
+	_, err = ForwardedChainFromRequest(req, trustedProxies(t, "10.0.0.0/8")).Lookup(context.Background(), client)
+	assert.ErrorIs(t, err, ErrNoPublicHop)
+}
@@ -0,0 +1,157 @@
+package grpcserver
+
+import (
+	"github.com/iplocate/go-iplocate"
+	iplocatev1 "github.com/iplocate/go-iplocate/proto/iplocate/v1"
+)
+
+// ToProto converts a lookup response to its protobuf form
+func ToProto(r *iplocate.LookupResponse) *iplocatev1.LookupResponse {
+	if r == nil {
+		return nil
+	}
+	resp := &iplocatev1.LookupResponse{
+		Ip:           r.IP,
+		Hostname:     r.Hostname,
+		Country:      r.Country,
+		CountryCode:  r.CountryCode,
+		IsEu:         r.IsEU,
+		City:         r.City,
+		Continent:    r.Continent,
+		Latitude:     r.Latitude,
+		Longitude:    r.Longitude,
+		TimeZone:     r.TimeZone,
+		PostalCode:   r.PostalCode,
+		Subdivision:  r.Subdivision,
+		CurrencyCode: r.CurrencyCode,
+		CallingCode:  r.CallingCode,
+		Network:      r.Network,
+		Privacy: &iplocatev1.Privacy{
+			IsAbuser:      r.Privacy.IsAbuser,
+			IsAnonymous:   r.Privacy.IsAnonymous,
+			IsBogon:       r.Privacy.IsBogon,
+			IsHosting:     r.Privacy.IsHosting,
+			IsIcloudRelay: r.Privacy.IsIcloudRelay,
+			IsProxy:       r.Privacy.IsProxy,
+			IsTor:         r.Privacy.IsTor,
+			IsVpn:         r.Privacy.IsVPN,
+		},
+	}
+	if r.ASN != nil {
+		resp.Asn = &iplocatev1.ASN{
+			Asn:         r.ASN.ASN,
+			Route:       r.ASN.Route,
+			Netname:     r.ASN.Netname,
+			Name:        r.ASN.Name,
+			CountryCode: r.ASN.CountryCode,
+			Domain:      r.ASN.Domain,
+			Type:        r.ASN.Type,
+			Rir:         r.ASN.RIR,
+		}
+	}
+	if r.Company != nil {
+		resp.Company = &iplocatev1.Company{
+			Name:        r.Company.Name,
+			Domain:      r.Company.Domain,
+			CountryCode: r.Company.CountryCode,
+			Type:        r.Company.Type,
+		}
+	}
+	if r.Hosting != nil {
+		resp.Hosting = &iplocatev1.Hosting{
+			Provider: r.Hosting.Provider,
+			Domain:   r.Hosting.Domain,
+			Network:  r.Hosting.Network,
+			Region:   r.Hosting.Region,
+			Service:  r.Hosting.Service,
+		}
+	}
+	if r.Abuse != nil {
+		resp.Abuse = &iplocatev1.Abuse{
+			Address:     r.Abuse.Address,
+			CountryCode: r.Abuse.CountryCode,
+			Email:       r.Abuse.Email,
+			Name:        r.Abuse.Name,
+			Network:     r.Abuse.Network,
+			Phone:       r.Abuse.Phone,
+		}
+	}
+	return resp
+}
+
+// FromProto converts the protobuf form of a lookup response back, e.g. in
+// Go clients of the service
+func FromProto(p *iplocatev1.LookupResponse) *iplocate.LookupResponse {
+	if p == nil {
+		return nil
+	}
+	resp := &iplocate.LookupResponse{
+		IP:           p.GetIp(),
+		Hostname:     p.Hostname,
+		Country:      p.Country,
+		CountryCode:  p.CountryCode,
+		IsEU:         p.GetIsEu(),
+		City:         p.City,
+		Continent:    p.Continent,
+		Latitude:     p.Latitude,
+		Longitude:    p.Longitude,
+		TimeZone:     p.TimeZone,
+		PostalCode:   p.PostalCode,
+		Subdivision:  p.Subdivision,
+		CurrencyCode: p.CurrencyCode,
+		CallingCode:  p.CallingCode,
+		Network:      p.Network,
+	}
+	if privacy := p.GetPrivacy(); privacy != nil {
+		resp.Privacy = iplocate.Privacy{
+			IsAbuser:      privacy.GetIsAbuser(),
+			IsAnonymous:   privacy.GetIsAnonymous(),
+			IsBogon:       privacy.GetIsBogon(),
+			IsHosting:     privacy.GetIsHosting(),
+			IsIcloudRelay: privacy.GetIsIcloudRelay(),
+			IsProxy:       privacy.GetIsProxy(),
+			IsTor:         privacy.GetIsTor(),
+			IsVPN:         privacy.GetIsVpn(),
+		}
+	}
+	if asn := p.GetAsn(); asn != nil {
+		resp.ASN = &iplocate.ASN{
+			ASN:         asn.GetAsn(),
+			Route:       asn.GetRoute(),
+			Netname:     asn.GetNetname(),
+			Name:        asn.GetName(),
+			CountryCode: asn.GetCountryCode(),
+			Domain:      asn.GetDomain(),
+			Type:        asn.GetType(),
+			RIR:         asn.GetRir(),
+		}
+	}
+	if company := p.GetCompany(); company != nil {
+		resp.Company = &iplocate.Company{
+			Name:        company.GetName(),
+			Domain:      company.GetDomain(),
+			CountryCode: company.GetCountryCode(),
+			Type:        company.GetType(),
+		}
+	}
+	if hosting := p.GetHosting(); hosting != nil {
+		resp.Hosting = &iplocate.Hosting{
+			Provider: hosting.Provider,
+			Domain:   hosting.Domain,
+			Network:  hosting.Network,
+			Region:   hosting.Region,
+			Service:  hosting.Service,
+		}
+	}
+	if abuse := p.GetAbuse(); abuse != nil {
+		resp.Abuse = &iplocate.Abuse{
+			Address:     abuse.Address,
+			CountryCode: abuse.CountryCode,
+			Email:       abuse.Email,
+			Name:        abuse.Name,
+			Network:     abuse.Network,
+			Phone:       abuse.Phone,
+		}
+	}
+	return resp
+}
@@ -0,0 +1,35 @@
+package grpcserver
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/iplocate/go-iplocate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProtoRoundTrip(t *testing.T) {
+	data := `{
+		"ip": "8.8.8.8", "hostname": "dns.google", "country": "United States", "country_code": "US", "is_eu": false,
+		"city": "Mountain View", "continent": "North America", "latitude": 37.4, "longitude": -122.1,
+		"time_zone": "America/Los_Angeles", "postal_code": "94043", "subdivision": "California",
+		"currency_code": "USD", "calling_code": "1", "network": "8.8.8.0/24",
+		"asn": {"asn": "AS15169", "route": "8.8.8.0/24", "netname": "GOOGLE", "name": "Google LLC",
+			"country_code": "US", "domain": "google.com", "type": "hosting", "rir": "ARIN"},
+		"privacy": {"is_hosting": true, "is_vpn": true},
+		"company": {"name": "Google LLC", "domain": "google.com", "country_code": "US", "type": "hosting"},
+		"hosting": {"provider": "Google", "domain": "google.com"},
+		"abuse": {"email": "network-abuse@google.com", "name": "Abuse"}
+	}`
+	var want iplocate.LookupResponse
+	require.NoError(t, json.Unmarshal([]byte(data), &want))
+
+	assert.Equal(t, "dns.google", ToProto(&want).GetHostname())
+	assert.Equal(t, &want, FromProto(ToProto(&want)))
+
+	minimal := &iplocate.LookupResponse{IP: "1.1.1.1"}
+	assert.Equal(t, minimal, FromProto(ToProto(minimal)))
+	assert.Nil(t, ToProto(nil))
+	assert.Nil(t, FromProto(nil))
+}
@@ -0,0 +1,158 @@
+// Package grpcserver implements the iplocate.v1.LookupService defined in
+// proto/iplocate/v1/lookup.proto on top of an iplocate.Client, so services
+// written in any language can share one API key, cache and rate limit
+// through an internal gRPC service.
+//
+//	client := iplocate.NewClient(nil).
+//		WithAPIKey(apiKey).
+//		WithCache(iplocate.NewMemoryCache(100000), time.Hour)
+//
+//	server := grpc.NewServer()
+//	grpcserver.New(client).WithRateLimit(50, 100).Register(server)
+//	server.Serve(listener)
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/iplocate/go-iplocate"
+	iplocatev1 "github.com/iplocate/go-iplocate/proto/iplocate/v1"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultMaxBatchSize is the largest number of IP addresses a BatchLookup
+// call may contain unless configured otherwise with WithMaxBatchSize
+const DefaultMaxBatchSize = 1000
+
+var _ iplocatev1.LookupServiceServer = (*Server)(nil)
+
+// Server serves lookups with an iplocate.Client. Lookups go through the
+// client, so its cache, quota tracker and local database fallback apply.
+type Server struct {
+	iplocatev1.UnimplementedLookupServiceServer
+
+	client       *iplocate.Client
+	limiter      *rate.Limiter
+	maxBatchSize int
+}
+
+// New creates a server that looks up addresses with client
+func New(client *iplocate.Client) *Server {
+	return &Server{client: client, maxBatchSize: DefaultMaxBatchSize}
+}
+
+// WithRateLimit limits the lookups served to limit per second, with bursts
+// of up to burst, across all callers. Each address of a batch counts as a
+// lookup, whether it is cached or not, so batches larger than burst are
+// always refused. Calls over the limit fail with codes.ResourceExhausted.
+func (s *Server) WithRateLimit(limit rate.Limit, burst int) *Server {
+	s.limiter = rate.NewLimiter(limit, burst)
+	return s
+}
+
+// WithMaxBatchSize sets the largest number of IP addresses a BatchLookup
+// call may contain
+func (s *Server) WithMaxBatchSize(n int) *Server {
+	if n <= 0 {
+		n = DefaultMaxBatchSize
+	}
+	s.maxBatchSize = n
+	return s
+}
+
+// Register registers the service with a gRPC server
+func (s *Server) Register(registrar grpc.ServiceRegistrar) {
+	iplocatev1.RegisterLookupServiceServer(registrar, s)
+}
+
+// Lookup returns the data for one IP address
+func (s *Server) Lookup(ctx context.Context, req *iplocatev1.LookupRequest) (*iplocatev1.LookupResponse, error) {
+	if _, err := iplocate.ParseFlexibleIP(req.GetIp()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid IP address: %q", req.GetIp())
+	}
+	if err := s.allow(1); err != nil {
+		return nil, err
+	}
+
+	result, err := s.client.LookupContext(ctx, req.GetIp(), lookupOptions(req.GetFields())...)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return ToProto(result), nil
+}
+
+// BatchLookup returns the data for several IP addresses
+func (s *Server) BatchLookup(ctx context.Context, req *iplocatev1.BatchLookupRequest) (*iplocatev1.BatchLookupResponse, error) {
+	if len(req.GetIps()) > s.maxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "batch of %d addresses exceeds the maximum of %d", len(req.GetIps()), s.maxBatchSize)
+	}
+	if err := s.allow(len(req.GetIps())); err != nil {
+		return nil, err
+	}
+
+	batch, _ := s.client.LookupBatch(ctx, req.GetIps(), lookupOptions(req.GetFields())...)
+	if err := ctx.Err(); err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+
+	resp := &iplocatev1.BatchLookupResponse{
+		Results: make(map[string]*iplocatev1.LookupResponse, len(batch.Responses)),
+		Errors:  make(map[string]string, len(batch.Errors)),
+	}
+	for ip, result := range batch.Responses {
+		resp.Results[ip] = ToProto(result)
+	}
+	for ip, err := range batch.Errors {
+		resp.Errors[ip] = err.Error()
+	}
+	return resp, nil
+}
+
+// allow takes n lookups from the rate limit
+func (s *Server) allow(n int) error {
+	if s.limiter != nil && !s.limiter.AllowN(time.Now(), n) {
+		return status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+	return nil
+}
+
+func lookupOptions(fields []string) []iplocate.LookupOption {
+	if len(fields) == 0 {
+		return nil
+	}
+	return []iplocate.LookupOption{iplocate.Fields(fields...)}
+}
+
+// toStatus converts a lookup error to a gRPC status error
+func toStatus(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+	if errors.Is(err, iplocate.ErrQuotaExceeded) {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+
+	var apiErr *iplocate.APIError
+	if !errors.As(err, &apiErr) {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	switch {
+	case apiErr.StatusCode == http.StatusNotFound:
+		return status.Error(codes.NotFound, apiErr.Message)
+	case apiErr.StatusCode == http.StatusTooManyRequests:
+		return status.Error(codes.ResourceExhausted, apiErr.Message)
+	case apiErr.StatusCode >= http.StatusInternalServerError:
+		return status.Error(codes.Unavailable, apiErr.Message)
+	case apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden:
+		// The caller can't fix the service's credentials
+		return status.Error(codes.Internal, apiErr.Message)
+	default:
+		return status.Error(codes.InvalidArgument, apiErr.Message)
+	}
+}
@@ -0,0 +1,146 @@
+package grpcserver
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/iplocate/go-iplocate"
+	iplocatev1 "github.com/iplocate/go-iplocate/proto/iplocate/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestClient returns a client whose API answers every lookup with
+// country US, returning 404 for 192.0.2.1 and 500 for 192.0.2.2
+func newTestClient(t *testing.T, calls *int32) *iplocate.Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		ip := strings.TrimPrefix(r.URL.Path, "/lookup/")
+		switch ip {
+		case "192.0.2.1":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"Not found"}`))
+			return
+		case "192.0.2.2":
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error":"Internal error"}`))
+			return
+		}
+		country := "US"
+		json.NewEncoder(w).Encode(iplocate.LookupResponse{IP: ip, CountryCode: &country, ASN: &iplocate.ASN{ASN: "AS15169"}})
+	}))
+	t.Cleanup(server.Close)
+	return iplocate.NewClient(nil).WithBaseURL(server.URL).WithCache(iplocate.NewMemoryCache(100), time.Hour)
+}
+
+// dial serves s over an in-memory connection and returns a client for it
+func dial(t *testing.T, s *Server) iplocatev1.LookupServiceClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	s.Register(server)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return iplocatev1.NewLookupServiceClient(conn)
+}
+
+func TestServer_Lookup(t *testing.T) {
+	var calls int32
+	client := dial(t, New(newTestClient(t, &calls)))
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Lookup(ctx, &iplocatev1.LookupRequest{Ip: "8.8.8.8"})
+		require.NoError(t, err)
+		assert.Equal(t, "8.8.8.8", resp.GetIp())
+		assert.Equal(t, "US", resp.GetCountryCode())
+		assert.Equal(t, "AS15169", resp.GetAsn().GetAsn())
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "lookups go through the client's cache")
+
+	resp, err := client.Lookup(ctx, &iplocatev1.LookupRequest{Ip: "0x08080808"})
+	require.NoError(t, err, "the forms the client accepts are accepted")
+	assert.Equal(t, "8.8.8.8", resp.GetIp())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestServer_LookupErrors(t *testing.T) {
+	var calls int32
+	client := dial(t, New(newTestClient(t, &calls)))
+	ctx := context.Background()
+
+	_, err := client.Lookup(ctx, &iplocatev1.LookupRequest{Ip: "not-an-ip"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.Lookup(ctx, &iplocatev1.LookupRequest{Ip: "192.0.2.1"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.Lookup(ctx, &iplocatev1.LookupRequest{Ip: "192.0.2.2"})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestServer_BatchLookup(t *testing.T) {
+	var calls int32
+	client := dial(t, New(newTestClient(t, &calls)).WithMaxBatchSize(3))
+	ctx := context.Background()
+
+	resp, err := client.BatchLookup(ctx, &iplocatev1.BatchLookupRequest{Ips: []string{"8.8.8.8", "1.1.1.1", "192.0.2.1"}})
+	require.NoError(t, err)
+	assert.Len(t, resp.GetResults(), 2)
+	assert.Equal(t, "US", resp.GetResults()["1.1.1.1"].GetCountryCode())
+	assert.Contains(t, resp.GetErrors()["192.0.2.1"], "Not found")
+
+	_, err = client.BatchLookup(ctx, &iplocatev1.BatchLookupRequest{Ips: []string{"1.1.1.1", "1.1.1.2", "1.1.1.3", "1.1.1.4"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestServer_RateLimit(t *testing.T) {
+	var calls int32
+	client := dial(t, New(newTestClient(t, &calls)).WithRateLimit(0.001, 2))
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err := client.Lookup(ctx, &iplocatev1.LookupRequest{Ip: "8.8.8.8"})
+		require.NoError(t, err)
+	}
+	_, err := client.Lookup(ctx, &iplocatev1.LookupRequest{Ip: "8.8.8.8"})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestToStatus(t *testing.T) {
+	cases := map[error]codes.Code{
+		context.DeadlineExceeded:                        codes.DeadlineExceeded,
+		iplocate.ErrQuotaExceeded:                       codes.ResourceExhausted,
+		&iplocate.APIError{StatusCode: 429}:             codes.ResourceExhausted,
+		&iplocate.APIError{StatusCode: 403}:             codes.Internal,
+		&iplocate.APIError{StatusCode: 400}:             codes.InvalidArgument,
+		&iplocate.APIError{StatusCode: 503}:             codes.Unavailable,
+		&net.OpError{Op: "dial", Err: context.Canceled}: codes.Canceled,
+		&net.OpError{Op: "dial", Err: net.ErrClosed}:    codes.Unavailable,
+	}
+	for err, code := range cases {
+		assert.Equal(t, code, status.Code(toStatus(err)), "%v", err)
+	}
+}
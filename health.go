@@ -0,0 +1,145 @@
+package iplocate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// PingResult reports the outcome of Ping
+type PingResult struct {
+	// Reachable reports whether the API answered, even with an error status
+	Reachable bool
+	// Latency is how long the request took
+	Latency time.Duration
+}
+
+// Ping sends a minimal request to the API, the account usage endpoint, and
+// reports whether the API could be reached and how long it took. The error
+// is nil only if the API answered successfully, so Ping fails with an
+// *APIError for an invalid API key. It is meant for readiness probes of
+// services that depend on IPLocate. Pings don't count against the quota
+// tracker and don't rotate keys or fail over to other endpoints.
+func (c *Client) Ping(ctx context.Context) (PingResult, error) {
+	start := c.now()
+	err := c.ping(ctx)
+	result := PingResult{Latency: c.now().Sub(start)}
+
+	var apiErr *APIError
+	result.Reachable = err == nil || (errors.As(err, &apiErr) && !apiErr.unparsed)
+	return result, err
+}
+
+// ping sends a single request to the usage endpoint with the current key and
+// endpoint, bypassing the quota, key rotation and failover of doRequest
+func (c *Client) ping(ctx context.Context) error {
+	baseURL, _ := c.pickEndpoint(nil)
+	parsedURL, err := url.Parse(baseURL + usagePath)
+	if err != nil {
+		return fmt.Errorf("failed to parse endpoint URL: %w", err)
+	}
+
+	key, _, err := c.currentKey(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.send(ctx, requestURL(parsedURL, nil, key), nil)
+	if err != nil {
+		return err
+	}
+	var usage Usage
+	return c.parseResponse(resp, &usage)
+}
+
+// DefaultHealthCheckInterval is the ping interval of StartHealthMonitor when
+// none is given
+const DefaultHealthCheckInterval = 30 * time.Second
+
+// healthMonitor pings the API in the background and tracks its availability
+type healthMonitor struct {
+	mu        sync.Mutex
+	available bool
+	lastErr   error
+	interval  time.Duration
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// StartHealthMonitor pings the API right away and then every interval
+// until Close is called, tracking whether it is available for Available.
+// onChange, which may be nil, is called from the monitor goroutine when
+// availability changes, with the error of the failed ping when it becomes
+// unavailable, so dependent services can switch to a degraded mode before
+// lookups fail. If interval is zero or negative, DefaultHealthCheckInterval
+// is used. StartHealthMonitor must be called at most once.
+func (c *Client) StartHealthMonitor(interval time.Duration, onChange func(available bool, err error)) {
+	if interval <= 0 {
+		interval = DefaultHealthCheckInterval
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &healthMonitor{available: true, interval: interval, cancel: cancel, done: make(chan struct{})}
+	c.health = m
+
+	go func() {
+		defer close(m.done)
+		for {
+			_, err := c.Ping(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			if m.update(err) && onChange != nil {
+				onChange(err == nil, err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-c.clock.After(interval):
+			}
+		}
+	}()
+}
+
+// update records the outcome of a ping and reports whether availability changed
+func (m *healthMonitor) update(err error) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	available := err == nil
+	changed := available != m.available
+	m.available = available
+	m.lastErr = err
+	return changed
+}
+
+// stop stops the monitor and waits for it to exit
+func (m *healthMonitor) stop() {
+	m.cancel()
+	<-m.done
+}
+
+// Available reports whether the last ping of the health monitor succeeded.
+// It is true before the first ping completes and when no monitor runs.
+func (c *Client) Available() bool {
+	if c.health == nil {
+		return true
+	}
+	c.health.mu.Lock()
+	defer c.health.mu.Unlock()
+	return c.health.available
+}
+
+// HealthError returns the error of the last ping of the health monitor, or
+// nil if it succeeded
+func (c *Client) HealthError() error {
+	if c.health == nil {
+		return nil
+	}
+	c.health.mu.Lock()
+	defer c.health.mu.Unlock()
+	return c.health.lastErr
+}
@@ -0,0 +1,176 @@
+package iplocate
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, usagePath, r.URL.Path)
+		time.Sleep(5 * time.Millisecond)
+		w.Write([]byte(`{"plan":"free","daily_limit":1000,"requests_used":1}`))
+	}))
+	defer server.Close()
+
+	result, err := NewClient(nil).WithBaseURL(server.URL).Ping(context.Background())
+	require.NoError(t, err)
+	assert.True(t, result.Reachable)
+	assert.GreaterOrEqual(t, result.Latency, 5*time.Millisecond)
+}
+
+func TestPing_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":"Invalid API key"}`))
+	}))
+	defer server.Close()
+
+	result, err := NewClient(nil).WithBaseURL(server.URL).Ping(context.Background())
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+	assert.True(t, result.Reachable, "the API answered")
+}
+
+func TestPing_Unreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	result, err := NewClient(nil).WithBaseURL(server.URL).Ping(context.Background())
+	assert.Error(t, err)
+	assert.False(t, result.Reachable)
+}
+
+func TestPing_BypassesQuotaAndKeyRotation(t *testing.T) {
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.URL.Query().Get("apikey"))
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":"Invalid API key"}`))
+	}))
+	defer server.Close()
+
+	quota := NewQuotaTracker(1)
+	client := NewClient(nil).WithBaseURL(server.URL).WithAPIKeys("first", "second").WithQuotaTracker(quota)
+	for i := 0; i < 3; i++ {
+		_, err := client.Ping(context.Background())
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, []string{"first", "first", "first"}, keys)
+	usage, err := quota.Usage()
+	require.NoError(t, err)
+	assert.Zero(t, usage.Used)
+}
+
+// healthChange records a call of a health monitor callback
+type healthChange struct {
+	available bool
+	err       error
+}
+
+func TestStartHealthMonitor(t *testing.T) {
+	var failing atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error":"Maintenance"}`))
+			return
+		}
+		w.Write([]byte(`{"plan":"free"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(nil).WithBaseURL(server.URL)
+	assert.True(t, client.Available(), "available without a monitor")
+
+	changes := make(chan healthChange, 10)
+	client.StartHealthMonitor(5*time.Millisecond, func(available bool, err error) {
+		changes <- healthChange{available, err}
+	})
+	defer client.Close()
+
+	failing.Store(true)
+	change := <-changes
+	assert.False(t, change.available)
+	var apiErr *APIError
+	require.ErrorAs(t, change.err, &apiErr)
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+	assert.False(t, client.Available())
+	assert.Error(t, client.HealthError())
+
+	failing.Store(false)
+	change = <-changes
+	assert.True(t, change.available)
+	assert.NoError(t, change.err)
+	assert.True(t, client.Available())
+	assert.NoError(t, client.HealthError())
+}
+
+func TestStartHealthMonitor_DefaultInterval(t *testing.T) {
+	var pings atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pings.Add(1)
+		w.Write([]byte(`{"plan":"free"}`))
+	}))
+	defer server.Close()
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		client := NewClient(nil).WithBaseURL(server.URL)
+		assert.NotPanics(t, func() { client.StartHealthMonitor(interval, nil) })
+		assert.Equal(t, DefaultHealthCheckInterval, client.health.interval)
+		require.NoError(t, client.Close())
+	}
+}
+
+func TestStartHealthMonitor_UsesClock(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"plan":"free"}`))
+	}))
+	defer server.Close()
+
+	clock := newFakeClock()
+	client := NewClient(nil).WithBaseURL(server.URL).WithClock(clock)
+	client.StartHealthMonitor(time.Hour, nil)
+	assert.Eventually(t, func() bool { return len(clock.recordedWaits()) >= 3 }, 5*time.Second, time.Millisecond)
+	require.NoError(t, client.Close())
+
+	for _, wait := range clock.recordedWaits() {
+		assert.Equal(t, time.Hour, wait)
+	}
+}
+
+func TestClose_StopsHealthMonitor(t *testing.T) {
+	var pings atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pings.Add(1)
+		w.Write([]byte(`{"plan":"free"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(nil).WithBaseURL(server.URL)
+	client.StartHealthMonitor(time.Millisecond, nil)
+	require.Eventually(t, func() bool { return pings.Load() >= 2 }, time.Second, time.Millisecond)
+
+	require.NoError(t, client.Close())
+	require.NoError(t, client.Close())
+	select {
+	case <-client.health.done:
+	default:
+		t.Fatal("monitor still running after Close")
+	}
+
+	// A request in flight may still reach the server
+	time.Sleep(10 * time.Millisecond)
+	stopped := pings.Load()
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, stopped, pings.Load())
+}
@@ -0,0 +1,98 @@
+package iplocate
+
+import (
+	"context"
+	"sync"
+)
+
+// History is a bounded, in-memory record of the most recent lookups, for
+// debugging dashboards and admin endpoints. It is safe for concurrent use.
+type History struct {
+	mu      sync.Mutex
+	entries []LookupEvent
+	next    int
+	full    bool
+}
+
+// NewHistory creates a history of the last size lookups. Add it to a
+// client with WithEventSink, or use WithHistory.
+func NewHistory(size int) *History {
+	if size < 1 {
+		size = 1
+	}
+	return &History{entries: make([]LookupEvent, size)}
+}
+
+// WithHistory keeps the last size lookups, available from History. Calling
+// it again replaces the history with a new one of the given size.
+func (c *Client) WithHistory(size int) *Client {
+	history := NewHistory(size)
+	if c.history == nil {
+		c.sinks = append(c.sinks, history)
+	}
+	for i, sink := range c.sinks {
+		if old, ok := sink.(*History); ok && old == c.history {
+			c.sinks[i] = history
+		}
+	}
+	c.history = history
+	return c
+}
+
+// History returns the lookup history enabled with WithHistory, or nil
+func (c *Client) History() *History {
+	return c.history
+}
+
+// Emit records the event. Its response is copied, as it may be reused
+// once Emit returns.
+func (h *History) Emit(ctx context.Context, event LookupEvent) {
+	if event.Response != nil {
+		resp := *event.Response
+		event.Response = &resp
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries[h.next] = event
+	h.next = (h.next + 1) % len(h.entries)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// Entries returns the recorded lookups, most recent first
+func (h *History) Entries() []LookupEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	n := h.len()
+	entries := make([]LookupEvent, n)
+	for i := 0; i < n; i++ {
+		entries[i] = h.entries[(h.next-1-i+len(h.entries))%len(h.entries)]
+	}
+	return entries
+}
+
+// Len returns the number of recorded lookups
+func (h *History) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.len()
+}
+
+// Clear removes every recorded lookup
+func (h *History) Clear() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	clear(h.entries)
+	h.next = 0
+	h.full = false
+}
+
+func (h *History) len() int {
+	if h.full {
+		return len(h.entries)
+	}
+	return h.next
+}
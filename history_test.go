@@ -0,0 +1,88 @@
+package iplocate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory(t *testing.T) {
+	h := NewHistory(3)
+	assert.Empty(t, h.Entries())
+
+	for i := 1; i <= 5; i++ {
+		h.Emit(context.Background(), LookupEvent{IP: "10.0.0." + strconv.Itoa(i)})
+	}
+	assert.Equal(t, 3, h.Len())
+
+	var ips []string
+	for _, entry := range h.Entries() {
+		ips = append(ips, entry.IP)
+	}
+	assert.Equal(t, []string{"10.0.0.5", "10.0.0.4", "10.0.0.3"}, ips)
+
+	h.Clear()
+	assert.Zero(t, h.Len())
+	assert.Empty(t, h.Entries())
+}
+
+func TestHistory_CopiesResponse(t *testing.T) {
+	h := NewHistory(2)
+	resp := &LookupResponse{IP: "8.8.8.8"}
+	h.Emit(context.Background(), LookupEvent{IP: "8.8.8.8", Response: resp})
+	resp.IP = "reused"
+
+	assert.Equal(t, "8.8.8.8", h.Entries()[0].Response.IP)
+}
+
+func TestWithHistory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8"})
+	}))
+	defer server.Close()
+
+	assert.Nil(t, NewClient(nil).History())
+
+	client := NewClient(nil).WithBaseURL(server.URL).WithHistory(10)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client.Lookup("8.8.8.8")
+		}()
+	}
+	wg.Wait()
+	_, err := client.Lookup("invalid")
+	require.Error(t, err)
+
+	entries := client.History().Entries()
+	require.Len(t, entries, 10)
+	assert.Equal(t, "invalid", entries[0].IP)
+	assert.Error(t, entries[0].Err)
+	assert.Equal(t, "8.8.8.8", entries[1].Response.IP)
+}
+
+func TestWithHistory_Replaces(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8"})
+	}))
+	defer server.Close()
+
+	client := NewClient(nil).WithBaseURL(server.URL).WithHistory(10)
+	first := client.History()
+	client.WithHistory(5)
+	require.Len(t, client.sinks, 1)
+
+	_, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Empty(t, first.Entries(), "the replaced history no longer receives events")
+	assert.Len(t, client.History().Entries(), 1)
+}
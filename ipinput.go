@@ -0,0 +1,56 @@
+package iplocate
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// ParseFlexibleIP parses an IP address in the forms found in logs, socket
+// addresses and old databases, in addition to the standard notations:
+//
+//   - surrounding whitespace, which is ignored
+//   - IPv6 zones such as fe80::1%eth0, which are dropped
+//   - IPv4-mapped IPv6 addresses such as ::ffff:1.2.3.4, which become IPv4
+//   - IPv4 addresses as a decimal integer, such as 134744072 for 8.8.8.8
+//   - IPv4 addresses as a hex integer, such as 0x08080808
+//
+// Lookup and the other lookup methods accept any of these forms.
+func ParseFlexibleIP(s string) (netip.Addr, error) {
+	s = strings.TrimSpace(s)
+	if addr, err := netip.ParseAddr(s); err == nil {
+		return addr.WithZone("").Unmap(), nil
+	}
+	if n, ok := parseIntegerIPv4(s); ok {
+		var ip [4]byte
+		binary.BigEndian.PutUint32(ip[:], n)
+		return netip.AddrFrom4(ip), nil
+	}
+	return netip.Addr{}, fmt.Errorf("invalid IP address: %s", s)
+}
+
+// parseIntegerIPv4 parses an IPv4 address written as a decimal or
+// 0x-prefixed hex integer
+func parseIntegerIPv4(s string) (uint32, bool) {
+	base := 10
+	if len(s) > 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		s, base = s[2:], 16
+	}
+	n, err := strconv.ParseUint(s, base, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint32(n), true
+}
+
+// normalizeIP returns ip in canonical form, as parsed by ParseFlexibleIP. It
+// returns false if ip isn't an IP address.
+func normalizeIP(ip string) (string, bool) {
+	addr, err := ParseFlexibleIP(ip)
+	if err != nil {
+		return "", false
+	}
+	return addr.String(), true
+}
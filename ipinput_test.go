@@ -0,0 +1,109 @@
+package iplocate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeIP(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"8.8.8.8", "8.8.8.8"},
+		{"  8.8.8.8\n", "8.8.8.8"},
+		{"fe80::1%eth0", "fe80::1"},
+		{"fe80::1%25", "fe80::1"},
+		{"::ffff:1.2.3.4", "1.2.3.4"},
+		{"::FFFF:0102:0304", "1.2.3.4"},
+		{"2001:4860:4860:0:0:0:0:8888", "2001:4860:4860::8888"},
+		{"2001:DB8::1", "2001:db8::1"},
+	}
+	for _, tt := range tests {
+		got, ok := normalizeIP(tt.input)
+		assert.True(t, ok, tt.input)
+		assert.Equal(t, tt.want, got, tt.input)
+	}
+
+	for _, input := range []string{"", "invalid", "1.2.3.4%eth0", "8.8.8", "example.com"} {
+		_, ok := normalizeIP(input)
+		assert.False(t, ok, input)
+	}
+}
+
+func TestLookup_NormalizesInput(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		ip := strings.TrimPrefix(r.URL.Path, "/lookup/")
+		json.NewEncoder(w).Encode(LookupResponse{IP: ip, CountryCode: stringPtr("US")})
+	}))
+	defer server.Close()
+
+	client := NewClient(nil).WithBaseURL(server.URL)
+	for _, input := range []string{" 1.2.3.4 ", "::ffff:1.2.3.4", "fe80::1%eth0"} {
+		_, err := client.Lookup(input)
+		require.NoError(t, err, input)
+	}
+	assert.Equal(t, []string{"/lookup/1.2.3.4", "/lookup/1.2.3.4", "/lookup/fe80::1"}, paths)
+
+	code, err := client.LookupCountry(context.Background(), "::ffff:8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "US", code)
+	assert.Equal(t, "/lookup/8.8.8.8", paths[len(paths)-1])
+
+	_, err = client.Lookup("1.2.3.4%eth0")
+	assert.EqualError(t, err, "invalid IP address: 1.2.3.4%eth0")
+}
+
+func TestParseFlexibleIP(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"8.8.8.8", "8.8.8.8"},
+		{"2001:4860:4860::8888", "2001:4860:4860::8888"},
+		{"134744072", "8.8.8.8"},
+		{" 134744072 ", "8.8.8.8"},
+		{"0", "0.0.0.0"},
+		{"4294967295", "255.255.255.255"},
+		{"0x08080808", "8.8.8.8"},
+		{"0X8080808", "8.8.8.8"},
+		{"0xC0A80001", "192.168.0.1"},
+		{"0xffffffff", "255.255.255.255"},
+	}
+	for _, tt := range tests {
+		addr, err := ParseFlexibleIP(tt.input)
+		require.NoError(t, err, tt.input)
+		assert.Equal(t, tt.want, addr.String(), tt.input)
+	}
+
+	for _, input := range []string{"", "0x", "4294967296", "0x100000000", "-1", "+134744072", "1_000", "0x_8", "0xg", "08.8.8.8"} {
+		_, err := ParseFlexibleIP(input)
+		assert.EqualError(t, err, "invalid IP address: "+input, input)
+	}
+}
+
+func TestLookup_IntegerIP(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8"})
+	}))
+	defer server.Close()
+
+	client := NewClient(nil).WithBaseURL(server.URL)
+	for _, input := range []string{"134744072", "0x08080808"} {
+		result, err := client.Lookup(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, "8.8.8.8", result.IP)
+	}
+	assert.Equal(t, []string{"/lookup/8.8.8.8", "/lookup/8.8.8.8"}, paths)
+}
@@ -0,0 +1,66 @@
+package iplocate
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// IPVersion selects the IP version used to connect to the API
+type IPVersion int
+
+const (
+	// IPVersionAuto connects over IPv6 or IPv4, whichever answers first
+	// (Happy Eyeballs), also to addresses from WithDNSCache. This is the
+	// default.
+	IPVersionAuto IPVersion = iota
+	// IPv4Only connects over IPv4 only
+	IPv4Only
+	// IPv6Only connects over IPv6 only
+	IPv6Only
+)
+
+// network returns the dial network for the version, or "" for any
+func (v IPVersion) network() string {
+	switch v {
+	case IPv4Only:
+		return "tcp4"
+	case IPv6Only:
+		return "tcp6"
+	default:
+		return ""
+	}
+}
+
+// WithIPVersionPreference sets the IP version of connections to the API.
+// Use IPv4Only on networks with broken IPv6, where connections otherwise
+// stall until the IPv4 fallback kicks in. It works together with
+// WithDNSCache and, like WithTLSConfig, requires an *http.Transport.
+// The QUIC connections of contrib/http3 are not affected.
+func (c *Client) WithIPVersionPreference(version IPVersion) *Client {
+	c.ipVersion = version
+	c.configureDialer()
+	return c
+}
+
+// configureDialer sets the transport's dial function from the DNS cache and
+// IP version settings
+func (c *Client) configureDialer() {
+	t := c.transport()
+	if t == nil {
+		return
+	}
+
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	dial := dialer.DialContext
+	if c.dnsCache != nil {
+		dial = c.dnsCache.dialContext(dialer)
+	}
+	if network := c.ipVersion.network(); network != "" {
+		next := dial
+		dial = func(ctx context.Context, _, addr string) (net.Conn, error) {
+			return next(ctx, network, addr)
+		}
+	}
+	t.DialContext = dial
+}
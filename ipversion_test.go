@@ -0,0 +1,56 @@
+package iplocate
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithIPVersionPreference(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8"})
+	}))
+	defer server.Close()
+
+	_, err := NewClient(nil).WithBaseURL(server.URL).WithIPVersionPreference(IPv4Only).Lookup("8.8.8.8")
+	assert.NoError(t, err)
+
+	_, err = NewClient(nil).WithBaseURL(server.URL).WithIPVersionPreference(IPv6Only).Lookup("8.8.8.8")
+	assert.Error(t, err, "the IPv4 server can't be reached over IPv6")
+
+	_, err = NewClient(nil).WithBaseURL(server.URL).WithIPVersionPreference(IPVersionAuto).Lookup("8.8.8.8")
+	assert.NoError(t, err)
+}
+
+func TestWithIPVersionPreference_DNSCache(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8"})
+	}))
+	defer server.Close()
+	_, port, err := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	require.NoError(t, err)
+
+	cache := NewDNSCache(0)
+	cache.lookupHost = func(ctx context.Context, host string) ([]string, error) {
+		return []string{"::1", "127.0.0.1"}, nil
+	}
+	baseURL := "http://api.test:" + port
+
+	// The preference applies whichever option is set first
+	_, err = NewClient(nil).WithBaseURL(baseURL).WithIPVersionPreference(IPv4Only).WithDNSCache(cache).Lookup("8.8.8.8")
+	assert.NoError(t, err)
+	_, err = NewClient(nil).WithBaseURL(baseURL).WithDNSCache(cache).WithIPVersionPreference(IPv4Only).Lookup("8.8.8.8")
+	assert.NoError(t, err)
+
+	_, err = NewClient(nil).WithBaseURL(baseURL).WithIPVersionPreference(IPv6Only).WithDNSCache(cache).Lookup("8.8.8.8")
+	assert.Error(t, err, "only the IPv6 address is dialed")
+	_, err = NewClient(nil).WithBaseURL(baseURL).WithDNSCache(cache).WithIPVersionPreference(IPv6Only).Lookup("8.8.8.8")
+	assert.Error(t, err)
+}
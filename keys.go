@@ -0,0 +1,210 @@
+package iplocate
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// keyRing holds several API keys and tracks which one is in use
+type keyRing struct {
+	mu      sync.Mutex
+	keys    []string
+	current int
+}
+
+// get returns the key in use and its index
+func (r *keyRing) get() (string, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.keys[r.current], r.current
+}
+
+// rotate moves on from the key at index failed. If another request already
+// rotated away from it, the current key is kept.
+func (r *keyRing) rotate(failed int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current == failed {
+		r.current = (r.current + 1) % len(r.keys)
+	}
+}
+
+// WithAPIKeys configures several API keys. Requests use one key at a time;
+// when the API rejects a key with 403 Forbidden or 429 Too Many Requests, the
+// client switches to the next key and retries the request, so lookups keep
+// flowing when a key exhausts its quota. Empty keys are ignored.
+func (c *Client) WithAPIKeys(keys ...string) *Client {
+	var ring keyRing
+	for _, key := range keys {
+		if key != "" {
+			ring.keys = append(ring.keys, key)
+		}
+	}
+
+	c.apiKey = ""
+	c.keys = nil
+	c.keyProvider = nil
+	switch len(ring.keys) {
+	case 0:
+	case 1:
+		c.apiKey = ring.keys[0]
+	default:
+		c.keys = &ring
+	}
+	return c
+}
+
+// KeyProvider supplies the API key for each request, e.g. from Vault or a
+// cloud secrets manager, so keys can be rotated without restarting. Wrap
+// providers that are slow or rate limited with CachedKeyProvider.
+type KeyProvider interface {
+	Key(ctx context.Context) (string, error)
+}
+
+// KeyProviderFunc adapts a function to the KeyProvider interface
+type KeyProviderFunc func(ctx context.Context) (string, error)
+
+// Key calls f
+func (f KeyProviderFunc) Key(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// WithKeyProvider asks p for the API key of every request instead of using
+// a fixed key. If p fails, the request fails without calling the API. When
+// the API rejects a key with 401 Unauthorized or 403 Forbidden and p is a
+// *CachedKey, the cached key is dropped and the request retried once with a
+// freshly fetched key.
+func (c *Client) WithKeyProvider(p KeyProvider) *Client {
+	c.apiKey = ""
+	c.keys = nil
+	c.keyProvider = &providedKeys{provider: p}
+	return c
+}
+
+// CachedKey is a KeyProvider that caches the key of another provider. It is
+// safe for concurrent use.
+type CachedKey struct {
+	provider KeyProvider
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	key     string
+	fetched time.Time
+}
+
+// CachedKeyProvider caches the key of p for ttl, so p is asked at most once
+// per ttl. A ttl of zero caches the key until Invalidate is called.
+func CachedKeyProvider(p KeyProvider, ttl time.Duration) *CachedKey {
+	return &CachedKey{provider: p, ttl: ttl, now: time.Now}
+}
+
+// Key returns the cached key, fetching it if it is missing or expired
+func (k *CachedKey) Key(ctx context.Context) (string, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.key != "" && (k.ttl == 0 || k.now().Sub(k.fetched) < k.ttl) {
+		return k.key, nil
+	}
+	key, err := k.provider.Key(ctx)
+	if err != nil {
+		return "", err
+	}
+	k.key = key
+	k.fetched = k.now()
+	return key, nil
+}
+
+// Invalidate drops the cached key, so the next Key call fetches it again
+func (k *CachedKey) Invalidate() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.key = ""
+}
+
+// maxProvidedKeys is how many of the keys returned by a KeyProvider are
+// remembered for redaction in debug output
+const maxProvidedKeys = 4
+
+// providedKeys fetches keys from a KeyProvider and remembers the latest ones
+type providedKeys struct {
+	provider KeyProvider
+
+	mu   sync.Mutex
+	seen []string
+}
+
+// get asks the provider for a key
+func (p *providedKeys) get(ctx context.Context) (string, error) {
+	key, err := p.provider.Key(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, seen := range p.seen {
+		if seen == key {
+			return key, nil
+		}
+	}
+	p.seen = append(p.seen, key)
+	if len(p.seen) > maxProvidedKeys {
+		p.seen = p.seen[1:]
+	}
+	return key, nil
+}
+
+// keys returns the latest keys returned by the provider
+func (p *providedKeys) keys() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.seen...)
+}
+
+// currentKey returns the API key for the next request and its index in the
+// key ring, or -1 when a single key or a key provider is configured
+func (c *Client) currentKey(ctx context.Context) (string, int, error) {
+	if c.keyProvider != nil {
+		key, err := c.keyProvider.get(ctx)
+		return key, -1, err
+	}
+	if c.keys == nil {
+		return c.apiKey, -1, nil
+	}
+	key, index := c.keys.get()
+	return key, index, nil
+}
+
+// rotateKey switches away from a key rejected with status. It reports
+// whether the request should be retried with another key.
+func (c *Client) rotateKey(index, status int, rotations int) bool {
+	if c.keyProvider != nil {
+		return c.refreshKey(status, rotations)
+	}
+	if c.keys == nil || rotations >= len(c.keys.keys)-1 {
+		return false
+	}
+	if status != http.StatusForbidden && status != http.StatusTooManyRequests {
+		return false
+	}
+	c.keys.rotate(index)
+	return true
+}
+
+// refreshKey drops the cached key of a CachedKey provider after the API
+// rejected it. It reports whether the request should be retried.
+func (c *Client) refreshKey(status int, rotations int) bool {
+	cached, ok := c.keyProvider.provider.(*CachedKey)
+	if !ok || rotations > 0 {
+		return false
+	}
+	if status != http.StatusUnauthorized && status != http.StatusForbidden {
+		return false
+	}
+	cached.Invalidate()
+	return true
+}
@@ -0,0 +1,188 @@
+package iplocate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// keyServer serves lookups, rejecting the keys in exhausted with status
+func keyServer(t *testing.T, status int, exhausted ...string) (*httptest.Server, *[]string) {
+	var mu sync.Mutex
+	var seen []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Query().Get("apikey")
+		mu.Lock()
+		seen = append(seen, key)
+		mu.Unlock()
+
+		for _, e := range exhausted {
+			if key == e {
+				w.WriteHeader(status)
+				json.NewEncoder(w).Encode(map[string]string{"error": "Quota exceeded"})
+				return
+			}
+		}
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8"})
+	}))
+	t.Cleanup(server.Close)
+	return server, &seen
+}
+
+func TestWithAPIKeys_RotatesOnRateLimit(t *testing.T) {
+	server, seen := keyServer(t, http.StatusTooManyRequests, "key-1")
+
+	client := NewClient(nil).WithBaseURL(server.URL).WithAPIKeys("key-1", "key-2")
+
+	_, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	_, err = client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+
+	// The second lookup starts with the key that worked
+	assert.Equal(t, []string{"key-1", "key-2", "key-2"}, *seen)
+}
+
+func TestWithAPIKeys_RotatesOnForbidden(t *testing.T) {
+	server, seen := keyServer(t, http.StatusForbidden, "key-1", "key-2")
+
+	client := NewClient(nil).WithBaseURL(server.URL).WithAPIKeys("key-1", "key-2", "key-3")
+
+	result, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "8.8.8.8", result.IP)
+	assert.Equal(t, []string{"key-1", "key-2", "key-3"}, *seen)
+}
+
+func TestWithAPIKeys_AllExhausted(t *testing.T) {
+	server, seen := keyServer(t, http.StatusTooManyRequests, "key-1", "key-2")
+
+	client := NewClient(nil).WithBaseURL(server.URL).WithAPIKeys("key-1", "key-2")
+
+	_, err := client.Lookup("8.8.8.8")
+	require.Error(t, err)
+	apiErr, ok := err.(*APIError)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusTooManyRequests, apiErr.StatusCode)
+	assert.Equal(t, []string{"key-1", "key-2"}, *seen)
+}
+
+func TestWithAPIKeys_NoRotationOnOtherErrors(t *testing.T) {
+	server, seen := keyServer(t, http.StatusBadRequest, "key-1")
+
+	client := NewClient(nil).WithBaseURL(server.URL).WithAPIKeys("key-1", "key-2")
+
+	_, err := client.Lookup("8.8.8.8")
+	require.Error(t, err)
+	assert.Equal(t, []string{"key-1"}, *seen)
+}
+
+func TestWithAPIKeys_Single(t *testing.T) {
+	client := NewClient(nil).WithAPIKeys("", "only-key")
+	assert.Equal(t, "only-key", client.apiKey)
+	assert.Nil(t, client.keys)
+
+	// WithAPIKey replaces any configured key ring
+	client.WithAPIKeys("key-1", "key-2").WithAPIKey("key-3")
+	key, _, err := client.currentKey(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "key-3", key)
+}
+
+func TestWithKeyProvider(t *testing.T) {
+	server, seen := keyServer(t, http.StatusForbidden)
+
+	var fetched int32
+	provider := KeyProviderFunc(func(ctx context.Context) (string, error) {
+		n := atomic.AddInt32(&fetched, 1)
+		return fmt.Sprintf("key-%d", n), nil
+	})
+	client := NewClient(nil).WithBaseURL(server.URL).WithAPIKey("static-key").WithKeyProvider(provider)
+
+	for i := 0; i < 2; i++ {
+		_, err := client.Lookup("8.8.8.8")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"key-1", "key-2"}, *seen, "the provider is asked for every request")
+
+	client.WithAPIKey("static-key")
+	_, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "static-key", (*seen)[2], "WithAPIKey replaces the provider")
+}
+
+func TestWithKeyProvider_Error(t *testing.T) {
+	server, seen := keyServer(t, http.StatusForbidden)
+	failure := errors.New("vault sealed")
+	client := NewClient(nil).WithBaseURL(server.URL).WithKeyProvider(KeyProviderFunc(func(ctx context.Context) (string, error) {
+		return "", failure
+	}))
+
+	_, err := client.Lookup("8.8.8.8")
+	assert.ErrorIs(t, err, failure)
+	assert.Empty(t, *seen, "the API is not called without a key")
+}
+
+func TestCachedKeyProvider(t *testing.T) {
+	var fetched int32
+	cached := CachedKeyProvider(KeyProviderFunc(func(ctx context.Context) (string, error) {
+		return fmt.Sprintf("key-%d", atomic.AddInt32(&fetched, 1)), nil
+	}), time.Minute)
+	clock := newFakeClock()
+	cached.now = clock.Now
+
+	key, err := cached.Key(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "key-1", key)
+	key, _ = cached.Key(context.Background())
+	assert.Equal(t, "key-1", key)
+
+	clock.advance(2 * time.Minute)
+	key, _ = cached.Key(context.Background())
+	assert.Equal(t, "key-2", key, "expired keys are fetched again")
+
+	cached.Invalidate()
+	key, _ = cached.Key(context.Background())
+	assert.Equal(t, "key-3", key)
+}
+
+func TestCachedKeyProvider_RefreshesRejectedKey(t *testing.T) {
+	server, seen := keyServer(t, http.StatusForbidden, "revoked-key")
+
+	keys := []string{"revoked-key", "new-key"}
+	var fetched int32
+	cached := CachedKeyProvider(KeyProviderFunc(func(ctx context.Context) (string, error) {
+		return keys[atomic.AddInt32(&fetched, 1)-1], nil
+	}), 0)
+	client := NewClient(nil).WithBaseURL(server.URL).WithKeyProvider(cached)
+
+	_, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	_, err = client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"revoked-key", "new-key", "new-key"}, *seen)
+}
+
+func TestWithKeyProvider_DebugRedaction(t *testing.T) {
+	server, _ := keyServer(t, http.StatusForbidden)
+	var out bytes.Buffer
+	client := NewClient(nil).WithBaseURL(server.URL).WithDebug(&out).
+		WithKeyProvider(KeyProviderFunc(func(ctx context.Context) (string, error) {
+			return "secret-provided-key", nil
+		}))
+
+	_, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.NotContains(t, out.String(), "secret-provided-key")
+}
@@ -0,0 +1,314 @@
+package iplocate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// ErrNotInDatabase is returned when a local database has no record for an IP address
+var ErrNotInDatabase = errors.New("IP address not found in local database")
+
+// errNoLocalDatabase is returned in offline mode when no local database is configured
+var errNoLocalDatabase = errors.New("offline mode requires a local database")
+
+// Database reads an IPLocate database file in MaxMind DB (MMDB) format, such
+// as ip-to-country.mmdb or ip-to-asn.mmdb. It is safe for concurrent use.
+type Database struct {
+	reader *maxminddb.Reader
+}
+
+// databaseRecord holds the fields found in IPLocate database files
+type databaseRecord struct {
+	CountryCode   string      `maxminddb:"country_code"`
+	CountryName   string      `maxminddb:"country_name"`
+	ContinentCode string      `maxminddb:"continent_code"`
+	ASN           interface{} `maxminddb:"asn"`
+	Name          string      `maxminddb:"name"`
+	Org           string      `maxminddb:"org"`
+	Domain        string      `maxminddb:"domain"`
+}
+
+// OpenDatabase opens the database file at path
+func OpenDatabase(path string) (*Database, error) {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	return &Database{reader: reader}, nil
+}
+
+// NewDatabaseFromBytes reads a database from its contents in memory
+func NewDatabaseFromBytes(data []byte) (*Database, error) {
+	reader, err := maxminddb.FromBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read database: %w", err)
+	}
+	return &Database{reader: reader}, nil
+}
+
+// Close releases the database file
+func (d *Database) Close() error {
+	return d.reader.Close()
+}
+
+// Lookup returns the data the database holds for ip. Country databases fill
+// in the country and continent; ASN databases fill in the ASN. Fields that
+// the database doesn't carry are left nil.
+func (d *Database) Lookup(ip string) (*LookupResponse, error) {
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		return nil, fmt.Errorf("invalid IP address: %s", ip)
+	}
+
+	var record databaseRecord
+	network, ok, err := d.reader.LookupNetwork(parsedIP, &record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read database record: %w", err)
+	}
+	if !ok {
+		return nil, ErrNotInDatabase
+	}
+
+	result := &LookupResponse{IP: ip}
+	if network != nil {
+		result.Network = stringValue(network.String())
+	}
+
+	asn := formatASN(record.ASN)
+	if record.CountryName != "" || record.ContinentCode != "" || (record.CountryCode != "" && asn == "") {
+		result.Country = stringValue(record.CountryName)
+		result.CountryCode = stringValue(record.CountryCode)
+		result.IsEU = isEUCountry(record.CountryCode)
+		result.Continent = stringValue(continentNames[strings.ToUpper(record.ContinentCode)])
+	}
+
+	if asn != "" {
+		name := record.Name
+		if name == "" {
+			name = record.Org
+		}
+		result.ASN = &ASN{
+			ASN:         asn,
+			Name:        name,
+			Domain:      record.Domain,
+			CountryCode: record.CountryCode,
+		}
+		if network != nil {
+			result.ASN.Route = network.String()
+		}
+	}
+
+	return result, nil
+}
+
+// localDatabases lazily opens the database files configured on a client
+type localDatabases struct {
+	mu      sync.Mutex
+	paths   []string
+	dbs     []*Database
+	offline bool
+
+	// inUse is held for reading while a lookup reads the databases, so close
+	// waits for lookups in flight before unmapping the files
+	inUse sync.RWMutex
+}
+
+// open returns the opened databases, opening any that are not open yet
+func (l *localDatabases) open() ([]*Database, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for len(l.dbs) < len(l.paths) {
+		db, err := OpenDatabase(l.paths[len(l.dbs)])
+		if err != nil {
+			return nil, err
+		}
+		l.dbs = append(l.dbs, db)
+	}
+	return l.dbs, nil
+}
+
+// close closes the opened databases once lookups in flight have finished.
+// They are reopened on the next lookup.
+func (l *localDatabases) close() error {
+	l.inUse.Lock()
+	defer l.inUse.Unlock()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var errs []error
+	for _, db := range l.dbs {
+		if err := db.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close database: %w", err))
+		}
+	}
+	l.dbs = nil
+	return errors.Join(errs...)
+}
+
+// lookup merges the records of all databases, earlier databases taking precedence
+func (l *localDatabases) lookup(ip string) (*LookupResponse, error) {
+	l.inUse.RLock()
+	defer l.inUse.RUnlock()
+
+	dbs, err := l.open()
+	if err != nil {
+		return nil, err
+	}
+	if len(dbs) == 0 {
+		return nil, errNoLocalDatabase
+	}
+
+	var result *LookupResponse
+	for _, db := range dbs {
+		record, err := db.Lookup(ip)
+		if errors.Is(err, ErrNotInDatabase) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if result == nil {
+			result = record
+		} else {
+			result.Fill(record)
+		}
+	}
+	if result == nil {
+		return nil, ErrNotInDatabase
+	}
+	return result, nil
+}
+
+func (c *Client) localDB() *localDatabases {
+	if c.local == nil {
+		c.local = &localDatabases{}
+	}
+	return c.local
+}
+
+// WithLocalDatabase adds an IPLocate database file (MMDB format) that serves
+// lookups when the API is unreachable, returns a server error or is rate
+// limited, or when offline mode is enabled. The file is opened on first use.
+// Call it once per file to combine e.g. a country and an ASN database.
+func (c *Client) WithLocalDatabase(path string) *Client {
+	local := c.localDB()
+	local.mu.Lock()
+	local.paths = append(local.paths, path)
+	local.mu.Unlock()
+	return c
+}
+
+// WithOfflineMode serves every Lookup from the local databases without
+// calling the API
+func (c *Client) WithOfflineMode(offline bool) *Client {
+	local := c.localDB()
+	local.mu.Lock()
+	local.offline = offline
+	local.mu.Unlock()
+	return c
+}
+
+// offline reports whether lookups must be served from local databases only
+func (c *Client) offline() bool {
+	if c.local == nil {
+		return false
+	}
+	c.local.mu.Lock()
+	defer c.local.mu.Unlock()
+	return c.local.offline
+}
+
+// hasLocalDatabase reports whether any local database is configured
+func (c *Client) hasLocalDatabase() bool {
+	if c.local == nil {
+		return false
+	}
+	c.local.mu.Lock()
+	defer c.local.mu.Unlock()
+	return len(c.local.paths) > 0
+}
+
+// shouldUseLocalDatabase reports whether a failed API lookup should be
+// answered from the local databases instead
+func shouldUseLocalDatabase(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, ErrQuotaExceeded) {
+		return true
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && !apiErr.unparsed {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= http.StatusInternalServerError
+	}
+	// Network errors, unreadable responses and error pages from proxies
+	return true
+}
+
+// formatASN formats an ASN stored as a number or string as "AS<number>"
+func formatASN(value interface{}) string {
+	var asn string
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		asn = v
+	case uint64:
+		asn = strconv.FormatUint(v, 10)
+	case uint32:
+		asn = strconv.FormatUint(uint64(v), 10)
+	case uint16:
+		asn = strconv.FormatUint(uint64(v), 10)
+	case int:
+		asn = strconv.Itoa(v)
+	default:
+		asn = fmt.Sprint(v)
+	}
+	asn = strings.TrimSpace(asn)
+	if asn == "" {
+		return ""
+	}
+	if !strings.HasPrefix(strings.ToUpper(asn), "AS") {
+		asn = "AS" + asn
+	}
+	return asn
+}
+
+// stringValue returns a pointer to s, or nil if s is empty
+func stringValue(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+var continentNames = map[string]string{
+	"AF": "Africa",
+	"AN": "Antarctica",
+	"AS": "Asia",
+	"EU": "Europe",
+	"NA": "North America",
+	"OC": "Oceania",
+	"SA": "South America",
+}
+
+var euCountries = map[string]bool{
+	"AT": true, "BE": true, "BG": true, "CY": true, "CZ": true, "DE": true,
+	"DK": true, "EE": true, "ES": true, "FI": true, "FR": true, "GR": true,
+	"HR": true, "HU": true, "IE": true, "IT": true, "LT": true, "LU": true,
+	"LV": true, "MT": true, "NL": true, "PL": true, "PT": true, "RO": true,
+	"SE": true, "SI": true, "SK": true,
+}
+
+func isEUCountry(code string) bool {
+	return euCountries[strings.ToUpper(code)]
+}
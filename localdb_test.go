@@ -0,0 +1,221 @@
+package iplocate
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/maxmind/mmdbwriter"
+	"github.com/maxmind/mmdbwriter/mmdbtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestDatabase writes an MMDB file with the given records keyed by CIDR
+func writeTestDatabase(t *testing.T, name string, records map[string]mmdbtype.Map) string {
+	t.Helper()
+
+	writer, err := mmdbwriter.New(mmdbwriter.Options{DatabaseType: name, RecordSize: 24})
+	require.NoError(t, err)
+	for cidr, record := range records {
+		_, network, err := net.ParseCIDR(cidr)
+		require.NoError(t, err)
+		require.NoError(t, writer.Insert(network, record))
+	}
+
+	path := filepath.Join(t.TempDir(), name+".mmdb")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = writer.WriteTo(f)
+	require.NoError(t, err)
+	return path
+}
+
+func testCountryDatabase(t *testing.T) string {
+	return writeTestDatabase(t, "ip-to-country", map[string]mmdbtype.Map{
+		"8.8.8.0/24": {
+			"country_code":   mmdbtype.String("US"),
+			"country_name":   mmdbtype.String("United States"),
+			"continent_code": mmdbtype.String("NA"),
+		},
+		"2.16.0.0/16": {
+			"country_code":   mmdbtype.String("DE"),
+			"country_name":   mmdbtype.String("Germany"),
+			"continent_code": mmdbtype.String("EU"),
+		},
+	})
+}
+
+func testASNDatabase(t *testing.T) string {
+	return writeTestDatabase(t, "ip-to-asn", map[string]mmdbtype.Map{
+		"8.8.8.0/24": {
+			"asn":          mmdbtype.Uint32(15169),
+			"name":         mmdbtype.String("Google LLC"),
+			"domain":       mmdbtype.String("google.com"),
+			"country_code": mmdbtype.String("US"),
+		},
+	})
+}
+
+func TestDatabase_Lookup(t *testing.T) {
+	db, err := OpenDatabase(testCountryDatabase(t))
+	require.NoError(t, err)
+	defer db.Close()
+
+	result, err := db.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "8.8.8.8", result.IP)
+	assert.Equal(t, "United States", *result.Country)
+	assert.Equal(t, "US", *result.CountryCode)
+	assert.Equal(t, "North America", *result.Continent)
+	assert.Equal(t, "8.8.8.0/24", *result.Network)
+	assert.False(t, result.IsEU)
+	assert.Nil(t, result.ASN)
+
+	result, err = db.Lookup("2.16.1.1")
+	require.NoError(t, err)
+	assert.True(t, result.IsEU)
+
+	_, err = db.Lookup("1.1.1.1")
+	assert.ErrorIs(t, err, ErrNotInDatabase)
+
+	_, err = db.Lookup("not-an-ip")
+	assert.Error(t, err)
+}
+
+func TestDatabase_LookupASN(t *testing.T) {
+	db, err := OpenDatabase(testASNDatabase(t))
+	require.NoError(t, err)
+	defer db.Close()
+
+	result, err := db.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	require.NotNil(t, result.ASN)
+	assert.Equal(t, "AS15169", result.ASN.ASN)
+	assert.Equal(t, "Google LLC", result.ASN.Name)
+	assert.Equal(t, "google.com", result.ASN.Domain)
+	assert.Equal(t, "8.8.8.0/24", result.ASN.Route)
+	assert.Nil(t, result.CountryCode)
+}
+
+func TestWithLocalDatabase_FallbackWhenUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	serverURL := server.URL
+	server.Close()
+
+	client := NewClient(nil).
+		WithBaseURL(serverURL).
+		WithLocalDatabase(testCountryDatabase(t)).
+		WithLocalDatabase(testASNDatabase(t))
+
+	result, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "US", *result.CountryCode)
+	require.NotNil(t, result.ASN)
+	assert.Equal(t, "AS15169", result.ASN.ASN)
+}
+
+func TestWithLocalDatabase_FallbackWhenRateLimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Rate limit exceeded"})
+	}))
+	defer server.Close()
+
+	client := NewClient(nil).WithBaseURL(server.URL).WithLocalDatabase(testCountryDatabase(t))
+
+	result, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "US", *result.CountryCode)
+
+	// IPs missing from the database still surface the API error
+	_, err = client.Lookup("1.1.1.1")
+	apiErr, ok := err.(*APIError)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusTooManyRequests, apiErr.StatusCode)
+}
+
+func TestWithLocalDatabase_NoFallbackOnClientError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid API key"})
+	}))
+	defer server.Close()
+
+	client := NewClient(nil).WithBaseURL(server.URL).WithLocalDatabase(testCountryDatabase(t))
+
+	_, err := client.Lookup("8.8.8.8")
+	apiErr, ok := err.(*APIError)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusForbidden, apiErr.StatusCode)
+}
+
+func TestWithOfflineMode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("offline mode must not call the API")
+	}))
+	defer server.Close()
+
+	client := NewClient(nil).
+		WithBaseURL(server.URL).
+		WithLocalDatabase(testCountryDatabase(t)).
+		WithOfflineMode(true)
+
+	result, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "United States", *result.Country)
+
+	_, err = client.Lookup("1.1.1.1")
+	assert.ErrorIs(t, err, ErrNotInDatabase)
+
+	_, err = NewClient(nil).WithOfflineMode(true).Lookup("8.8.8.8")
+	assert.Error(t, err)
+}
+
+func TestWithLocalDatabase_CloseDuringLookups(t *testing.T) {
+	client := NewClient(nil).WithLocalDatabase(testCountryDatabase(t)).WithOfflineMode(true)
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				assert.NoError(t, client.Close())
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 500; j++ {
+				result, err := client.Lookup("8.8.8.8")
+				if assert.NoError(t, err) {
+					assert.Equal(t, "US", *result.CountryCode)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(stop)
+	<-done
+}
+
+func TestFormatASN(t *testing.T) {
+	assert.Equal(t, "", formatASN(nil))
+	assert.Equal(t, "AS15169", formatASN(uint64(15169)))
+	assert.Equal(t, "AS15169", formatASN("15169"))
+	assert.Equal(t, "AS15169", formatASN("AS15169"))
+}
@@ -0,0 +1,286 @@
+package iplocate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DefaultRevalidationWindow is how long past its TTL a cached lookup with an
+// ETag is kept, so it can be revalidated with a conditional request instead
+// of being downloaded again
+const DefaultRevalidationWindow = 24 * time.Hour
+
+// ErrNoCache is returned by operations that need a cache when the client has none
+var ErrNoCache = errors.New("no cache configured")
+
+// errUnexpectedNotModified is returned when the API answers 304 to a request
+// that had no cached entry to revalidate
+var errUnexpectedNotModified = errors.New("unexpected 304 Not Modified response")
+
+// CacheEntry is the form in which the client stores lookups in a Cache,
+// encoded as JSON. It holds either a response or, with negative caching, the
+// API error of a lookup. Cache backends can decode stored values with
+// DecodeCacheEntry, e.g. to index them.
+//
+//easyjson:json
+type CacheEntry struct {
+	Response *LookupResponse `json:"response,omitempty"`
+	Error    *CachedError    `json:"error,omitempty"`
+	ETag     string          `json:"etag,omitempty"`
+	StoredAt time.Time       `json:"stored_at"`
+	// Refreshed records when the fields of a category were last refreshed
+	// on their own, with WithCategoryTTL
+	Refreshed map[Category]time.Time `json:"refreshed,omitempty"`
+}
+
+// CachedError is the serialized form of an APIError
+type CachedError struct {
+	Message    string    `json:"message"`
+	Code       ErrorCode `json:"code,omitempty"`
+	StatusCode int       `json:"status_code"`
+}
+
+// DecodeCacheEntry decodes a value the client stored in a Cache
+func DecodeCacheEntry(data []byte) (*CacheEntry, error) {
+	var entry CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("failed to decode cache entry: %w", err)
+	}
+	if entry.Response == nil && entry.Error == nil {
+		return nil, errors.New("failed to decode cache entry: no response or error")
+	}
+	return &entry, nil
+}
+
+// Encode returns the value the client stores in a Cache for the entry
+func (e *CacheEntry) Encode() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// WithCache caches lookup results in cache. Results younger than ttl are
+// returned without calling the API. Older results that came with an ETag
+// are revalidated with If-None-Match; a 304 Not Modified response refreshes
+// the cached result without downloading it again. A ttl of zero revalidates
+// on every lookup. A nil cache disables caching.
+func (c *Client) WithCache(cache Cache, ttl time.Duration) *Client {
+	c.cache = cache
+	c.cacheTTL = ttl
+	return c
+}
+
+// Cache returns the cache set with WithCache, or nil
+func (c *Client) Cache() Cache {
+	return c.cache
+}
+
+// Preload stores resp in the cache as the result of looking up resp.IP with
+// opts, as if it had just been fetched from the API. It returns ErrNoCache
+// if the client has no cache.
+func (c *Client) Preload(resp *LookupResponse, opts ...LookupOption) error {
+	if c.cache == nil {
+		return ErrNoCache
+	}
+	if resp == nil || net.ParseIP(resp.IP) == nil {
+		return errors.New("preloaded response has no valid IP address")
+	}
+	c.storeLookup(lookupCacheKey(resp.IP, c.lookupQuery(opts)), &CacheEntry{Response: resp, StoredAt: c.now()})
+	return nil
+}
+
+// WithNegativeCache also caches client errors (4xx) returned for a lookup,
+// such as invalid or unresolvable addresses, for ttl, so repeated lookups of
+// the same IP address don't use up quota. Authentication and rate limit
+// errors are never cached. It requires a cache set with WithCache; a ttl of
+// zero disables negative caching.
+func (c *Client) WithNegativeCache(ttl time.Duration) *Client {
+	c.negativeTTL = ttl
+	return c
+}
+
+// negativeCacheable reports whether err is a client error that depends only
+// on the looked up IP address
+func negativeCacheable(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.unparsed {
+		return false
+	}
+	switch apiErr.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return false
+	}
+	return apiErr.StatusCode >= 400 && apiErr.StatusCode < 500
+}
+
+// WithServeStaleOnError answers lookups with an expired cache entry, up to
+// maxStaleness past its TTL, when the API fails with a network error,
+// timeout, rate limit or server error. Results returned this way are
+// flagged with Result.Stale by LookupIter and LookupStream, listed in
+// BatchResult.Stale by LookupBatch and flagged with LookupEvent.Stale;
+// Lookup and LookupContext return them like fresh results. It requires a
+// cache set with WithCache; zero disables serving stale entries.
+func (c *Client) WithServeStaleOnError(maxStaleness time.Duration) *Client {
+	c.maxStaleness = maxStaleness
+	return c
+}
+
+// shouldServeStale reports whether a failed API lookup should be answered
+// with an expired cache entry
+func shouldServeStale(err error) bool {
+	// Unlike the local database fallback, a stale entry is also a good answer
+	// when the caller's deadline runs out
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	return shouldUseLocalDatabase(err)
+}
+
+// lookupCached looks up ip with the given query parameters, using the
+// lookup cache and the local database fallback. The result is stored in dst
+// if it is not nil.
+func (c *Client) lookupCached(ctx context.Context, ip string, query url.Values, dst *LookupResponse) (*LookupResponse, bool, error) {
+	key := lookupCacheKey(ip, query)
+	entry := c.cachedLookup(key)
+	now := c.now()
+	if entry != nil && entry.Error != nil {
+		if now.Sub(entry.StoredAt) < c.negativeTTL {
+			return nil, false, &APIError{Message: entry.Error.Message, Code: entry.Error.Code, StatusCode: entry.Error.StatusCode}
+		}
+		entry = nil
+	}
+	var expired []Category
+	if entry != nil {
+		expired = c.expiredCategories(entry, query, now, 0)
+		if len(expired) == 0 {
+			return into(dst, entry.Response), false, nil
+		}
+	}
+
+	// Refresh only the expired categories if others are still fresh
+	request := apiRequest{path: "/lookup/" + url.PathEscape(ip), query: query}
+	partial := entry != nil && len(expired) < len(requestedCategories(query))
+	if partial {
+		request.query = partialQuery(query, expired)
+	} else if entry != nil && entry.ETag != "" {
+		request.header = http.Header{"If-None-Match": {entry.ETag}}
+	}
+
+	result := dst
+	if result == nil || partial {
+		result = &LookupResponse{}
+	} else {
+		*result = LookupResponse{}
+	}
+	resp, err := c.doRequest(ctx, request, result)
+	if err != nil {
+		if entry != nil && len(c.expiredCategories(entry, query, now, c.maxStaleness)) == 0 && shouldServeStale(err) {
+			return into(dst, entry.Response), true, nil
+		}
+		if c.hasLocalDatabase() && shouldUseLocalDatabase(err) {
+			if local, localErr := c.local.lookup(ip); localErr == nil {
+				return into(dst, local), false, nil
+			}
+		}
+		if c.negativeTTL > 0 && negativeCacheable(err) {
+			var apiErr *APIError
+			errors.As(err, &apiErr)
+			c.storeLookup(key, &CacheEntry{Error: &CachedError{Message: apiErr.Message, Code: apiErr.Code, StatusCode: apiErr.StatusCode}, StoredAt: now})
+		}
+		return nil, false, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		if entry == nil {
+			return nil, false, errUnexpectedNotModified
+		}
+		entry.StoredAt = now
+		entry.Refreshed = nil
+		c.storeLookup(key, entry)
+		return into(dst, entry.Response), false, nil
+	}
+
+	if partial {
+		if entry.Refreshed == nil {
+			entry.Refreshed = make(map[Category]time.Time)
+		}
+		for _, category := range expired {
+			mergeCategory(entry.Response, result, category)
+			entry.Refreshed[category] = now
+		}
+		c.storeLookup(key, entry)
+		return into(dst, entry.Response), false, nil
+	}
+
+	c.storeLookup(key, &CacheEntry{Response: result, ETag: resp.Header.Get("ETag"), StoredAt: now})
+	return result, false, nil
+}
+
+// into copies result to dst and returns dst, or returns result if dst is nil
+func into(dst, result *LookupResponse) *LookupResponse {
+	if dst == nil {
+		return result
+	}
+	*dst = *result
+	return dst
+}
+
+// cachedLookup returns the cached entry for key, if any
+func (c *Client) cachedLookup(key string) *CacheEntry {
+	if c.cache == nil {
+		return nil
+	}
+	data, ok := c.cache.Get(key)
+	if !ok {
+		return nil
+	}
+	var entry CacheEntry
+	if err := c.unmarshalJSON(data, &entry); err != nil || (entry.Response == nil && entry.Error == nil) {
+		return nil
+	}
+	return &entry
+}
+
+// storeLookup caches an entry for as long as it can be served or revalidated
+func (c *Client) storeLookup(key string, entry *CacheEntry) {
+	if c.cache == nil {
+		return
+	}
+
+	ttl := c.longestTTL()
+	retention := ttl
+	if entry.ETag != "" {
+		retention += DefaultRevalidationWindow
+	}
+	if stale := ttl + c.maxStaleness; stale > retention {
+		retention = stale
+	}
+	if entry.Error != nil {
+		retention = c.negativeTTL
+	}
+	if retention <= 0 {
+		return
+	}
+
+	data, err := c.marshalJSON(c.minimizeEntry(entry))
+	if err != nil {
+		return
+	}
+	c.cache.Set(key, data, retention)
+}
+
+// lookupCacheKey returns the cache key for a lookup of ip with query parameters
+func lookupCacheKey(ip string, query url.Values) string {
+	if parsedIP := net.ParseIP(ip); parsedIP != nil {
+		ip = parsedIP.String()
+	}
+	key := "lookup:" + ip
+	if encoded := query.Encode(); encoded != "" {
+		key += "?" + encoded
+	}
+	return key
+}
@@ -0,0 +1,224 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package iplocate
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+	time "time"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjson2c870e2aDecodeGithubComIplocateGoIplocate(in *jlexer.Lexer, out *CacheEntry) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "response":
+			if in.IsNull() {
+				in.Skip()
+				out.Response = nil
+			} else {
+				if out.Response == nil {
+					out.Response = new(LookupResponse)
+				}
+				(*out.Response).UnmarshalEasyJSON(in)
+			}
+		case "error":
+			if in.IsNull() {
+				in.Skip()
+				out.Error = nil
+			} else {
+				if out.Error == nil {
+					out.Error = new(CachedError)
+				}
+				easyjson2c870e2aDecodeGithubComIplocateGoIplocate1(in, out.Error)
+			}
+		case "etag":
+			out.ETag = string(in.String())
+		case "stored_at":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.StoredAt).UnmarshalJSON(data))
+			}
+		case "refreshed":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Refreshed = make(map[Category]time.Time)
+				} else {
+					out.Refreshed = nil
+				}
+				for !in.IsDelim('}') {
+					key := Category(in.String())
+					in.WantColon()
+					var v1 time.Time
+					if data := in.Raw(); in.Ok() {
+						in.AddError((v1).UnmarshalJSON(data))
+					}
+					(out.Refreshed)[key] = v1
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson2c870e2aEncodeGithubComIplocateGoIplocate(out *jwriter.Writer, in CacheEntry) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if in.Response != nil {
+		const prefix string = ",\"response\":"
+		first = false
+		out.RawString(prefix[1:])
+		(*in.Response).MarshalEasyJSON(out)
+	}
+	if in.Error != nil {
+		const prefix string = ",\"error\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		easyjson2c870e2aEncodeGithubComIplocateGoIplocate1(out, *in.Error)
+	}
+	if in.ETag != "" {
+		const prefix string = ",\"etag\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.ETag))
+	}
+	{
+		const prefix string = ",\"stored_at\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Raw((in.StoredAt).MarshalJSON())
+	}
+	if len(in.Refreshed) != 0 {
+		const prefix string = ",\"refreshed\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('{')
+			v2First := true
+			for v2Name, v2Value := range in.Refreshed {
+				if v2First {
+					v2First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v2Name))
+				out.RawByte(':')
+				out.Raw((v2Value).MarshalJSON())
+			}
+			out.RawByte('}')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v CacheEntry) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson2c870e2aEncodeGithubComIplocateGoIplocate(w, v)
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *CacheEntry) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson2c870e2aDecodeGithubComIplocateGoIplocate(l, v)
+}
+func easyjson2c870e2aDecodeGithubComIplocateGoIplocate1(in *jlexer.Lexer, out *CachedError) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "message":
+			out.Message = string(in.String())
+		case "code":
+			out.Code = ErrorCode(in.String())
+		case "status_code":
+			out.StatusCode = int(in.Int())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson2c870e2aEncodeGithubComIplocateGoIplocate1(out *jwriter.Writer, in CachedError) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"message\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Message))
+	}
+	if in.Code != "" {
+		const prefix string = ",\"code\":"
+		out.RawString(prefix)
+		out.String(string(in.Code))
+	}
+	{
+		const prefix string = ",\"status_code\":"
+		out.RawString(prefix)
+		out.Int(int(in.StatusCode))
+	}
+	out.RawByte('}')
+}
@@ -0,0 +1,308 @@
+package iplocate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// etagServer serves lookups with an ETag and answers matching conditional
+// requests with 304 Not Modified
+func etagServer(t *testing.T, etag string) (*httptest.Server, *int32, *int32) {
+	var full, notModified int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if etag != "" && r.Header.Get("If-None-Match") == etag {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(&full, 1)
+		if etag != "" {
+			w.Header().Set("ETag", etag)
+		}
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8", CountryCode: stringPtr("US")})
+	}))
+	t.Cleanup(server.Close)
+	return server, &full, &notModified
+}
+
+func TestWithCache_ServesFreshEntries(t *testing.T) {
+	server, full, _ := etagServer(t, "")
+
+	client := NewClient(nil).WithBaseURL(server.URL).WithCache(NewMemoryCache(10), time.Hour)
+
+	for i := 0; i < 3; i++ {
+		result, err := client.Lookup("8.8.8.8")
+		require.NoError(t, err)
+		assert.Equal(t, "US", *result.CountryCode)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(full))
+}
+
+func TestWithCache_RevalidatesWithETag(t *testing.T) {
+	server, full, notModified := etagServer(t, `"v1"`)
+
+	now := time.Now()
+	client := NewClient(nil).WithBaseURL(server.URL).WithCache(NewMemoryCache(10), time.Minute)
+	client.now = func() time.Time { return now }
+
+	_, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+
+	now = now.Add(2 * time.Minute)
+	result, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "US", *result.CountryCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(full))
+	assert.Equal(t, int32(1), atomic.LoadInt32(notModified))
+
+	// The 304 refreshed the entry
+	_, err = client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(notModified))
+}
+
+func TestWithCache_ZeroTTLAlwaysRevalidates(t *testing.T) {
+	server, full, notModified := etagServer(t, `"v1"`)
+
+	client := NewClient(nil).WithBaseURL(server.URL).WithCache(NewMemoryCache(10), 0)
+
+	for i := 0; i < 3; i++ {
+		_, err := client.Lookup("8.8.8.8")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(full))
+	assert.Equal(t, int32(2), atomic.LoadInt32(notModified))
+}
+
+func TestWithCache_KeyIncludesFields(t *testing.T) {
+	server, full, _ := etagServer(t, "")
+
+	client := NewClient(nil).WithBaseURL(server.URL).WithCache(NewMemoryCache(10), time.Hour)
+
+	_, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	_, err = client.LookupCountry(context.Background(), "8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(full))
+}
+
+func TestWithCache_UnexpectedNotModified(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer server.Close()
+
+	_, err := NewClient(nil).WithBaseURL(server.URL).Lookup("8.8.8.8")
+	assert.ErrorIs(t, err, errUnexpectedNotModified)
+}
+
+// statusServer answers every lookup with status and counts requests
+func statusServer(t *testing.T, status int) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(status)
+		w.Write([]byte(`{"error":"Lookup failed"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestWithNegativeCache(t *testing.T) {
+	server, calls := statusServer(t, http.StatusNotFound)
+
+	now := time.Now()
+	client := NewClient(nil).WithBaseURL(server.URL).WithCache(NewMemoryCache(10), time.Hour).WithNegativeCache(time.Minute)
+	client.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		_, err := client.Lookup("8.8.8.8")
+		var apiErr *APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+		assert.Equal(t, "Lookup failed", apiErr.Message)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+
+	now = now.Add(2 * time.Minute)
+	_, err := client.Lookup("8.8.8.8")
+	require.Error(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(calls), "expired errors are looked up again")
+}
+
+func TestWithNegativeCache_KeepsErrorCode(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"Invalid IP address","code":"invalid_ip"}`))
+	}))
+	defer server.Close()
+
+	cache := NewMemoryCache(10)
+	client := NewClient(nil).WithBaseURL(server.URL).WithCache(cache, time.Hour).WithNegativeCache(time.Minute)
+
+	for i := 0; i < 2; i++ {
+		_, err := client.Lookup("8.8.8.8")
+		var apiErr *APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, ErrorCodeInvalidIP, apiErr.Code)
+		assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+		assert.Equal(t, "Invalid IP address", apiErr.Message)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "the second lookup is a cache hit")
+}
+
+func TestWithNegativeCache_SkipsAuthAndRateLimitErrors(t *testing.T) {
+	for _, status := range []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests, http.StatusInternalServerError} {
+		server, calls := statusServer(t, status)
+		client := NewClient(nil).WithBaseURL(server.URL).WithCache(NewMemoryCache(10), time.Hour).WithNegativeCache(time.Minute)
+
+		client.Lookup("8.8.8.8")
+		client.Lookup("8.8.8.8")
+		assert.Equal(t, int32(2), atomic.LoadInt32(calls), "status %d", status)
+	}
+}
+
+func TestWithNegativeCache_Disabled(t *testing.T) {
+	server, calls := statusServer(t, http.StatusNotFound)
+	client := NewClient(nil).WithBaseURL(server.URL).WithCache(NewMemoryCache(10), time.Hour)
+
+	client.Lookup("8.8.8.8")
+	client.Lookup("8.8.8.8")
+	assert.Equal(t, int32(2), atomic.LoadInt32(calls))
+}
+
+// flakyServer serves lookups until failing is set, then answers with status
+func flakyServer(t *testing.T, failing *atomic.Bool, status int) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(status)
+			w.Write([]byte(`{"error":"Unavailable"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8", CountryCode: stringPtr("US")})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWithServeStaleOnError(t *testing.T) {
+	var failing atomic.Bool
+	server := flakyServer(t, &failing, http.StatusServiceUnavailable)
+
+	now := time.Now()
+	client := NewClient(nil).WithBaseURL(server.URL).WithCache(NewMemoryCache(10), time.Minute).WithServeStaleOnError(time.Hour)
+	client.now = func() time.Time { return now }
+
+	_, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+
+	failing.Store(true)
+	now = now.Add(30 * time.Minute)
+	for _, result := range client.LookupIter(context.Background(), slices.Values([]string{"8.8.8.8"})) {
+		require.NoError(t, result.Err)
+		assert.True(t, result.Stale)
+		assert.Equal(t, "US", *result.Response.CountryCode)
+	}
+
+	batch, err := client.LookupBatch(context.Background(), []string{"8.8.8.8"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"8.8.8.8": true}, batch.Stale)
+
+	result, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "US", *result.CountryCode)
+
+	now = now.Add(time.Hour)
+	_, err = client.Lookup("8.8.8.8")
+	assert.Error(t, err, "entries past the maximum staleness are not served")
+}
+
+func TestWithServeStaleOnError_ClientErrors(t *testing.T) {
+	var failing atomic.Bool
+	server := flakyServer(t, &failing, http.StatusNotFound)
+
+	now := time.Now()
+	client := NewClient(nil).WithBaseURL(server.URL).WithCache(NewMemoryCache(10), time.Minute).WithServeStaleOnError(time.Hour)
+	client.now = func() time.Time { return now }
+
+	_, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+
+	failing.Store(true)
+	now = now.Add(2 * time.Minute)
+	_, err = client.Lookup("8.8.8.8")
+	assert.Error(t, err)
+}
+
+func TestWithServeStaleOnError_Disabled(t *testing.T) {
+	var failing atomic.Bool
+	server := flakyServer(t, &failing, http.StatusServiceUnavailable)
+
+	now := time.Now()
+	client := NewClient(nil).WithBaseURL(server.URL).WithCache(NewMemoryCache(10), time.Minute)
+	client.now = func() time.Time { return now }
+
+	_, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+
+	failing.Store(true)
+	now = now.Add(2 * time.Minute)
+	_, err = client.Lookup("8.8.8.8")
+	assert.Error(t, err)
+}
+
+func TestLookupCacheKey(t *testing.T) {
+	assert.Equal(t, "lookup:2001:db8::1", lookupCacheKey("2001:0db8:0::1", nil))
+	assert.Equal(t, "lookup:8.8.8.8?fields=asn", lookupCacheKey("8.8.8.8", url.Values{"fields": {"asn"}}))
+}
+
+func TestDecodeCacheEntry(t *testing.T) {
+	entry := &CacheEntry{Response: &LookupResponse{IP: "8.8.8.8"}, ETag: `"v1"`, StoredAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	data, err := entry.Encode()
+	require.NoError(t, err)
+
+	decoded, err := DecodeCacheEntry(data)
+	require.NoError(t, err)
+	assert.Equal(t, entry, decoded)
+
+	_, err = DecodeCacheEntry([]byte(`{}`))
+	assert.Error(t, err)
+	_, err = DecodeCacheEntry([]byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestPreload(t *testing.T) {
+	server, full, _ := etagServer(t, "")
+	client := NewClient(nil).WithBaseURL(server.URL)
+	assert.ErrorIs(t, client.Preload(&LookupResponse{IP: "8.8.8.8"}), ErrNoCache)
+	assert.Nil(t, client.Cache())
+
+	cache := NewMemoryCache(10)
+	client.WithCache(cache, time.Hour)
+	assert.Same(t, cache, client.Cache())
+	require.NoError(t, client.Preload(&LookupResponse{IP: "8.8.8.8", CountryCode: stringPtr("DE")}))
+	assert.Error(t, client.Preload(&LookupResponse{IP: "invalid"}))
+
+	result, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "DE", *result.CountryCode)
+	assert.Equal(t, int32(0), atomic.LoadInt32(full))
+
+	// Preloaded entries are keyed by the lookup options
+	require.NoError(t, client.Preload(&LookupResponse{IP: "1.1.1.1"}, Fields("ip")))
+	_, err = client.LookupContext(context.Background(), "1.1.1.1", Fields("ip"))
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), atomic.LoadInt32(full))
+}
@@ -0,0 +1,100 @@
+package iplocate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultMapZoom is the zoom level of map links, about city level, which
+// matches the accuracy of IP geolocation
+const DefaultMapZoom = 10
+
+// MapProvider selects the map service MapLink links to
+type MapProvider int
+
+const (
+	// MapGoogle links to Google Maps
+	MapGoogle MapProvider = iota
+	// MapOpenStreetMap links to OpenStreetMap
+	MapOpenStreetMap
+	// MapApple links to Apple Maps
+	MapApple
+	// MapBing links to Bing Maps
+	MapBing
+)
+
+// String returns the lowercase name of the provider
+func (p MapProvider) String() string {
+	switch p {
+	case MapGoogle:
+		return "google"
+	case MapOpenStreetMap:
+		return "openstreetmap"
+	case MapApple:
+		return "apple"
+	case MapBing:
+		return "bing"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseMapProvider returns the provider with the given name, as returned by
+// String and compared case-insensitively; "osm" is accepted for
+// OpenStreetMap
+func ParseMapProvider(name string) (MapProvider, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "google":
+		return MapGoogle, nil
+	case "openstreetmap", "osm":
+		return MapOpenStreetMap, nil
+	case "apple":
+		return MapApple, nil
+	case "bing":
+		return MapBing, nil
+	}
+	return 0, fmt.Errorf("unknown map provider: %q", name)
+}
+
+// MapOption customizes a map link
+type MapOption func(*mapOptions)
+
+type mapOptions struct {
+	zoom int
+}
+
+// Zoom sets the zoom level of a map link, from 1 (the whole world) to 19
+// (buildings), instead of DefaultMapZoom. Levels out of range are clamped.
+func Zoom(level int) MapOption {
+	return func(o *mapOptions) {
+		o.zoom = min(max(level, 1), 19)
+	}
+}
+
+// MapLink returns a link to the IP's coordinates, marked with a pin, on the
+// map of provider, or an empty string if the response has no coordinates or
+// the provider is unknown:
+//
+//	result.MapLink(iplocate.MapOpenStreetMap, iplocate.Zoom(6))
+func (r *LookupResponse) MapLink(provider MapProvider, opts ...MapOption) string {
+	lat, lon, ok := r.Coordinates()
+	if !ok {
+		return ""
+	}
+	o := mapOptions{zoom: DefaultMapZoom}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	switch provider {
+	case MapGoogle:
+		return fmt.Sprintf("https://maps.google.com/?q=%.4f,%.4f&z=%d", lat, lon, o.zoom)
+	case MapOpenStreetMap:
+		return fmt.Sprintf("https://www.openstreetmap.org/?mlat=%.4f&mlon=%.4f#map=%d/%.4f/%.4f", lat, lon, o.zoom, lat, lon)
+	case MapApple:
+		return fmt.Sprintf("https://maps.apple.com/?ll=%.4f,%.4f&q=%.4f,%.4f&z=%d", lat, lon, lat, lon, o.zoom)
+	case MapBing:
+		return fmt.Sprintf("https://www.bing.com/maps?cp=%.4f~%.4f&lvl=%d&sp=point.%.4f_%.4f_", lat, lon, o.zoom, lat, lon)
+	}
+	return ""
+}
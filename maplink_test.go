@@ -0,0 +1,43 @@
+package iplocate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapLink(t *testing.T) {
+	resp := &LookupResponse{Latitude: float64Ptr(52.52), Longitude: float64Ptr(13.405)}
+
+	assert.Equal(t, "https://maps.google.com/?q=52.5200,13.4050&z=10", resp.MapLink(MapGoogle))
+	assert.Equal(t, "https://www.openstreetmap.org/?mlat=52.5200&mlon=13.4050#map=6/52.5200/13.4050", resp.MapLink(MapOpenStreetMap, Zoom(6)))
+	assert.Equal(t, "https://maps.apple.com/?ll=52.5200,13.4050&q=52.5200,13.4050&z=14", resp.MapLink(MapApple, Zoom(14)))
+	assert.Equal(t, "https://www.bing.com/maps?cp=52.5200~13.4050&lvl=10&sp=point.52.5200_13.4050_", resp.MapLink(MapBing))
+	assert.Empty(t, resp.MapLink(MapProvider(42)))
+
+	assert.Equal(t, "https://maps.google.com/?q=52.5200,13.4050&z=19", resp.MapLink(MapGoogle, Zoom(30)))
+	assert.Equal(t, "https://maps.google.com/?q=52.5200,13.4050&z=1", resp.MapLink(MapGoogle, Zoom(-3)))
+}
+
+func TestMapLink_NoCoordinates(t *testing.T) {
+	for _, provider := range []MapProvider{MapGoogle, MapOpenStreetMap, MapApple, MapBing} {
+		assert.Empty(t, (&LookupResponse{}).MapLink(provider), provider.String())
+		assert.Empty(t, (&LookupResponse{Latitude: float64Ptr(1)}).MapLink(provider), provider.String())
+	}
+}
+
+func TestParseMapProvider(t *testing.T) {
+	for _, provider := range []MapProvider{MapGoogle, MapOpenStreetMap, MapApple, MapBing} {
+		parsed, err := ParseMapProvider(provider.String())
+		require.NoError(t, err)
+		assert.Equal(t, provider, parsed)
+	}
+	parsed, err := ParseMapProvider(" OSM ")
+	require.NoError(t, err)
+	assert.Equal(t, MapOpenStreetMap, parsed)
+
+	_, err = ParseMapProvider("here")
+	assert.EqualError(t, err, `unknown map provider: "here"`)
+	assert.Equal(t, "unknown", MapProvider(42).String())
+}
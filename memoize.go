@@ -0,0 +1,73 @@
+package iplocate
+
+import (
+	"sync"
+	"time"
+)
+
+// Memoize returns a function looking up IP addresses with client and
+// remembering successful results for ttl, or for the life of the function
+// if ttl isn't positive. It's meant for scripts and small tools that look
+// up the same addresses repeatedly without configuring WithCache. Failed
+// lookups aren't remembered, and each call returns its own copy of the
+// response. The function is safe for concurrent use.
+func Memoize(client *Client, ttl time.Duration) func(ip string) (*LookupResponse, error) {
+	m := &memo{client: client, ttl: ttl, entries: make(map[string]memoEntry)}
+	return m.lookup
+}
+
+type memo struct {
+	client *Client
+	ttl    time.Duration
+
+	mu      sync.Mutex
+	entries map[string]memoEntry
+	// swept is the number of entries left by the last sweep of expired ones
+	swept int
+}
+
+type memoEntry struct {
+	resp    *LookupResponse
+	expires time.Time
+}
+
+func (m *memo) lookup(ip string) (*LookupResponse, error) {
+	key, ok := normalizeIP(ip)
+	if !ok {
+		key = ip
+	}
+
+	m.mu.Lock()
+	entry, ok := m.entries[key]
+	m.mu.Unlock()
+	if ok && (m.ttl <= 0 || m.client.now().Before(entry.expires)) {
+		resp := *entry.resp
+		return &resp, nil
+	}
+
+	resp, err := m.client.Lookup(ip)
+	if err != nil {
+		return nil, err
+	}
+	stored := *resp
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[key] = memoEntry{resp: &stored, expires: m.client.now().Add(m.ttl)}
+	if m.ttl > 0 && len(m.entries) > 2*m.swept+64 {
+		m.sweep()
+	}
+	return resp, nil
+}
+
+// sweep removes expired entries, so memory stays bounded by the addresses
+// looked up within ttl
+func (m *memo) sweep() {
+	now := m.client.now()
+	for key, entry := range m.entries {
+		if !now.Before(entry.expires) {
+			delete(m.entries, key)
+		}
+	}
+	m.swept = len(m.entries)
+}
@@ -0,0 +1,104 @@
+package iplocate
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func memoizeServer(t *testing.T) (*httptest.Server, *int32) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		ip := strings.TrimPrefix(r.URL.Path, "/lookup/")
+		if ip == "192.0.2.1" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"Not found"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(LookupResponse{IP: ip, CountryCode: stringPtr("US")})
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestMemoize(t *testing.T) {
+	server, requests := memoizeServer(t)
+	clock := newFakeClock()
+	lookup := Memoize(NewClient(nil).WithBaseURL(server.URL).WithClock(clock), time.Minute)
+
+	first, err := lookup("8.8.8.8")
+	require.NoError(t, err)
+	second, err := lookup(" ::ffff:8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(requests))
+	assert.Equal(t, first, second)
+
+	second.CountryCode = stringPtr("XX")
+	third, err := lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "US", *third.CountryCode, "callers get their own copy")
+
+	clock.advance(2 * time.Minute)
+	_, err = lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(requests))
+}
+
+func TestMemoize_Errors(t *testing.T) {
+	server, requests := memoizeServer(t)
+	lookup := Memoize(NewClient(nil).WithBaseURL(server.URL), 0)
+
+	for i := 0; i < 2; i++ {
+		_, err := lookup("192.0.2.1")
+		assert.Error(t, err)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(requests), "failures aren't remembered")
+
+	_, err := lookup("invalid")
+	assert.EqualError(t, err, "invalid IP address: invalid")
+}
+
+func TestMemoize_Concurrent(t *testing.T) {
+	server, _ := memoizeServer(t)
+	clock := newFakeClock()
+	lookup := Memoize(NewClient(nil).WithBaseURL(server.URL).WithClock(clock), time.Second)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				resp, err := lookup(fmt.Sprintf("10.0.%d.%d", i, j))
+				assert.NoError(t, err)
+				assert.Equal(t, "US", *resp.CountryCode)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestMemo_Sweep(t *testing.T) {
+	server, _ := memoizeServer(t)
+	clock := newFakeClock()
+	m := &memo{client: NewClient(nil).WithBaseURL(server.URL).WithClock(clock), ttl: time.Minute, entries: make(map[string]memoEntry)}
+
+	for i := 0; i < 64; i++ {
+		_, err := m.lookup(fmt.Sprintf("10.0.0.%d", i))
+		require.NoError(t, err)
+	}
+	clock.advance(2 * time.Minute)
+	_, err := m.lookup("10.0.1.1")
+	require.NoError(t, err)
+	assert.Len(t, m.entries, 1, "expired entries are swept")
+}
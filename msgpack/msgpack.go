@@ -0,0 +1,30 @@
+// Package msgpack encodes IPLocate lookup results as MessagePack, for
+// compact caching or event streaming. Fields use the names of the JSON
+// encoding and unset fields are left out, which is usually less than half
+// the size of the JSON encoding.
+package msgpack
+
+import (
+	"fmt"
+
+	"github.com/iplocate/go-iplocate"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Marshal encodes resp as MessagePack
+func Marshal(resp *iplocate.LookupResponse) ([]byte, error) {
+	data, err := msgpack.Marshal(resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode msgpack: %w", err)
+	}
+	return data, nil
+}
+
+// Unmarshal decodes a response encoded with Marshal
+func Unmarshal(data []byte) (*iplocate.LookupResponse, error) {
+	var resp iplocate.LookupResponse
+	if err := msgpack.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode msgpack: %w", err)
+	}
+	return &resp, nil
+}
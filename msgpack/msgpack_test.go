@@ -0,0 +1,51 @@
+package msgpack
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/iplocate/go-iplocate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testResponse() *iplocate.LookupResponse {
+	country, code, network, provider := "United States", "US", "8.8.8.0/24", "Google Cloud"
+	lat, lon := 37.386, -122.0838
+	return &iplocate.LookupResponse{
+		IP:          "8.8.8.8",
+		Country:     &country,
+		CountryCode: &code,
+		Latitude:    &lat,
+		Longitude:   &lon,
+		Network:     &network,
+		ASN:         &iplocate.ASN{ASN: "AS15169", Name: "Google LLC", Type: "hosting"},
+		Privacy:     iplocate.Privacy{IsHosting: true},
+		Hosting:     &iplocate.Hosting{Provider: &provider},
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	resp := testResponse()
+
+	data, err := Marshal(resp)
+	require.NoError(t, err)
+	jsonData, err := json.Marshal(resp)
+	require.NoError(t, err)
+	assert.Less(t, len(data), len(jsonData))
+
+	decoded, err := Unmarshal(data)
+	require.NoError(t, err)
+	assert.Equal(t, resp, decoded)
+
+	_, err = Unmarshal([]byte{0xc1})
+	assert.ErrorContains(t, err, "failed to decode msgpack")
+}
+
+func TestMarshal_Empty(t *testing.T) {
+	data, err := Marshal(&iplocate.LookupResponse{IP: "8.8.8.8"})
+	require.NoError(t, err)
+	decoded, err := Unmarshal(data)
+	require.NoError(t, err)
+	assert.Equal(t, &iplocate.LookupResponse{IP: "8.8.8.8"}, decoded)
+}
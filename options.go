@@ -0,0 +1,79 @@
+package iplocate
+
+import (
+	"net/url"
+	"strings"
+)
+
+// LookupOption customizes a single lookup
+type LookupOption func(*lookupOptions)
+
+type lookupOptions struct {
+	fields   []string
+	language string
+
+	// failFast stops a batch at the first failed lookup
+	failFast bool
+	// onProgress is called as bulk lookups complete
+	onProgress ProgressFunc
+	// samples is how many addresses LookupCIDR looks up
+	samples int
+}
+
+// Fields limits the response of a single lookup to the given top-level
+// fields, e.g. "country_code" or "asn", overriding the client's WithFields.
+// Fields that are not requested are left empty in the LookupResponse.
+func Fields(fields ...string) LookupOption {
+	return func(o *lookupOptions) {
+		o.fields = fields
+	}
+}
+
+// WithFields limits every lookup response to the given top-level fields,
+// reducing bandwidth and decode time for callers that only need some of the
+// data. Use Fields to override it for a single lookup.
+func (c *Client) WithFields(fields ...string) *Client {
+	c.fields = fields
+	return c
+}
+
+// Language asks for the names in the response of a single lookup, such as
+// Country, City and Continent, in the given language, e.g. "de", overriding
+// the client's WithLanguage. An empty language asks for the API's default.
+func Language(lang string) LookupOption {
+	return func(o *lookupOptions) {
+		o.language = lang
+	}
+}
+
+// WithLanguage asks for localized names in every lookup response, e.g. "de"
+// for German country, city and continent names. Codes such as CountryCode
+// are not localized. Use Language to override it for a single lookup.
+// Responses are cached per language.
+func (c *Client) WithLanguage(lang string) *Client {
+	c.language = lang
+	return c
+}
+
+// lookupOptions applies opts on top of the client's defaults
+func (c *Client) lookupOptions(opts []LookupOption) lookupOptions {
+	o := lookupOptions{fields: c.fields, language: c.language}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// lookupQuery returns the query parameters for a lookup with opts
+func (c *Client) lookupQuery(opts []LookupOption) url.Values {
+	o := c.lookupOptions(opts)
+
+	query := url.Values{}
+	if len(o.fields) > 0 {
+		query.Set("fields", strings.Join(o.fields, ","))
+	}
+	if o.language != "" {
+		query.Set("lang", o.language)
+	}
+	return query
+}
@@ -0,0 +1,92 @@
+package iplocate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fieldsServer(t *testing.T, seen *[]string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*seen = append(*seen, r.URL.Query().Get("fields"))
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8", CountryCode: stringPtr("US")})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWithFields(t *testing.T) {
+	var seen []string
+	server := fieldsServer(t, &seen)
+
+	client := NewClient(nil).WithBaseURL(server.URL).WithAPIKey("test-key").WithFields("country_code", "asn")
+	result, err := client.Lookup("8.8.8.8")
+
+	require.NoError(t, err)
+	assert.Equal(t, "US", *result.CountryCode)
+	assert.Equal(t, []string{"country_code,asn"}, seen)
+}
+
+func TestFields_PerRequest(t *testing.T) {
+	var seen []string
+	server := fieldsServer(t, &seen)
+
+	client := NewClient(nil).WithBaseURL(server.URL).WithFields("asn")
+
+	_, err := client.LookupContext(context.Background(), "8.8.8.8", Fields("country_code"))
+	require.NoError(t, err)
+	_, err = client.LookupSelfContext(context.Background(), Fields())
+	require.NoError(t, err)
+	_, err = client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"country_code", "", "asn"}, seen)
+}
+
+func TestLookup_NoFieldsParameter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, ok := r.URL.Query()["fields"]
+		assert.False(t, ok)
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8"})
+	}))
+	defer server.Close()
+
+	_, err := NewClient(nil).WithBaseURL(server.URL).Lookup("8.8.8.8")
+	require.NoError(t, err)
+}
+
+func TestWithLanguage(t *testing.T) {
+	var seen []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.URL.Query().Get("lang"))
+		country := "Vereinigte Staaten"
+		if r.URL.Query().Get("lang") == "fr" {
+			country = "États-Unis"
+		}
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8", Country: &country})
+	}))
+	defer server.Close()
+
+	client := NewClient(nil).WithBaseURL(server.URL).WithLanguage("de").WithCache(NewMemoryCache(10), time.Minute)
+
+	result, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "Vereinigte Staaten", *result.Country)
+
+	result, err = client.LookupContext(context.Background(), "8.8.8.8", Language("fr"))
+	require.NoError(t, err)
+	assert.Equal(t, "États-Unis", *result.Country, "languages are cached separately")
+
+	_, err = client.LookupContext(context.Background(), "8.8.8.8", Language(""))
+	require.NoError(t, err)
+	_, err = client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"de", "fr", ""}, seen)
+}
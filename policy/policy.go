@@ -0,0 +1,257 @@
+// Package policy evaluates IPLocate lookup results against declarative
+// allow/deny/challenge rules. Rules can be declared in Go or loaded from
+// JSON or YAML.
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/iplocate/go-iplocate"
+	"gopkg.in/yaml.v3"
+)
+
+// Action is the outcome of a policy decision
+type Action string
+
+const (
+	// Allow lets the request through
+	Allow Action = "allow"
+	// Deny blocks the request
+	Deny Action = "deny"
+	// Challenge asks for additional verification, such as a captcha
+	Challenge Action = "challenge"
+)
+
+// valid reports whether the action is one of the known actions
+func (a Action) valid() bool {
+	return a == Allow || a == Deny || a == Challenge
+}
+
+// Privacy flag names accepted in Rule.Privacy
+const (
+	FlagAbuser      = "abuser"
+	FlagAnonymous   = "anonymous"
+	FlagBogon       = "bogon"
+	FlagHosting     = "hosting"
+	FlagIcloudRelay = "icloud_relay"
+	FlagProxy       = "proxy"
+	FlagTor         = "tor"
+	FlagVPN         = "vpn"
+)
+
+// Rule matches lookups by location, network and privacy flags.
+// Each non-empty criterion must match for the rule to apply; within a
+// criterion any listed value matches. A rule with no criteria matches
+// every lookup.
+type Rule struct {
+	Name   string `json:"name" yaml:"name"`
+	Action Action `json:"action" yaml:"action"`
+	// Countries are ISO 3166-1 alpha-2 country codes
+	Countries []string `json:"countries,omitempty" yaml:"countries,omitempty"`
+	// Continents are continent names as returned by the API, e.g. "Europe"
+	Continents []string `json:"continents,omitempty" yaml:"continents,omitempty"`
+	// ASNs are AS numbers with or without the "AS" prefix
+	ASNs []string `json:"asns,omitempty" yaml:"asns,omitempty"`
+	// CIDRs are networks the looked up IP must fall within
+	CIDRs []string `json:"cidrs,omitempty" yaml:"cidrs,omitempty"`
+	// Privacy lists privacy flag names, any of which must be set
+	Privacy []string `json:"privacy,omitempty" yaml:"privacy,omitempty"`
+}
+
+// Decision is the result of evaluating a policy
+type Decision struct {
+	Action Action
+	// Rule is the first matching rule, or nil if the default action applied
+	Rule *Rule
+}
+
+// Policy is an ordered list of rules with a default action.
+// The first matching rule decides.
+type Policy struct {
+	defaultAction Action
+	rules         []compiledRule
+}
+
+type compiledRule struct {
+	rule     Rule
+	networks *iplocate.CIDRSet
+}
+
+// Config is the serialized form of a policy
+type Config struct {
+	Default Action `json:"default" yaml:"default"`
+	Rules   []Rule `json:"rules" yaml:"rules"`
+}
+
+// New creates a policy from rules evaluated in order.
+// If no rule matches, defaultAction is returned.
+func New(defaultAction Action, rules ...Rule) (*Policy, error) {
+	if !defaultAction.valid() {
+		return nil, fmt.Errorf("invalid default action: %q", defaultAction)
+	}
+
+	p := &Policy{defaultAction: defaultAction}
+	for i, rule := range rules {
+		if !rule.Action.valid() {
+			return nil, fmt.Errorf("rule %d (%s): invalid action: %q", i, rule.Name, rule.Action)
+		}
+		for _, flag := range rule.Privacy {
+			if !knownFlag(flag) {
+				return nil, fmt.Errorf("rule %d (%s): unknown privacy flag: %q", i, rule.Name, flag)
+			}
+		}
+
+		compiled := compiledRule{rule: rule, networks: &iplocate.CIDRSet{}}
+		for _, cidr := range rule.CIDRs {
+			network, err := netip.ParsePrefix(cidr)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d (%s): invalid CIDR %q: %w", i, rule.Name, cidr, err)
+			}
+			compiled.networks.AddPrefix(network)
+		}
+		p.rules = append(p.rules, compiled)
+	}
+	return p, nil
+}
+
+// FromConfig creates a policy from its serialized form.
+// An empty default action means Allow.
+func FromConfig(cfg Config) (*Policy, error) {
+	if cfg.Default == "" {
+		cfg.Default = Allow
+	}
+	return New(cfg.Default, cfg.Rules...)
+}
+
+// LoadJSON reads a JSON encoded Config
+func LoadJSON(r io.Reader) (*Policy, error) {
+	var cfg Config
+	if err := json.NewDecoder(r).Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse policy: %w", err)
+	}
+	return FromConfig(cfg)
+}
+
+// LoadYAML reads a YAML encoded Config
+func LoadYAML(r io.Reader) (*Policy, error) {
+	var cfg Config
+	if err := yaml.NewDecoder(r).Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse policy: %w", err)
+	}
+	return FromConfig(cfg)
+}
+
+// LoadFile reads a policy from a .json, .yaml or .yml file
+func LoadFile(path string) (*Policy, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open policy: %w", err)
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return LoadJSON(f)
+	case ".yaml", ".yml":
+		return LoadYAML(f)
+	default:
+		return nil, fmt.Errorf("unsupported policy file extension: %s", path)
+	}
+}
+
+// Decide evaluates the rules against the lookup and returns the action of
+// the first matching rule. A nil response gets the default action.
+func (p *Policy) Decide(resp *iplocate.LookupResponse) Decision {
+	if resp != nil {
+		for i := range p.rules {
+			if p.rules[i].matches(resp) {
+				rule := p.rules[i].rule
+				return Decision{Action: rule.Action, Rule: &rule}
+			}
+		}
+	}
+	return Decision{Action: p.defaultAction}
+}
+
+// matches reports whether every criterion of the rule matches the lookup
+func (r *compiledRule) matches(resp *iplocate.LookupResponse) bool {
+	if len(r.rule.Countries) > 0 && !resp.InCountry(r.rule.Countries...) {
+		return false
+	}
+	if len(r.rule.Continents) > 0 && !containsFold(r.rule.Continents, deref(resp.Continent)) {
+		return false
+	}
+	if len(r.rule.ASNs) > 0 && !resp.InASN(r.rule.ASNs...) {
+		return false
+	}
+	if len(r.rule.CIDRs) > 0 && !r.networks.Contains(resp.IP) {
+		return false
+	}
+	if len(r.rule.Privacy) > 0 && !matchPrivacy(r.rule.Privacy, resp.Privacy) {
+		return false
+	}
+	return true
+}
+
+func knownFlag(flag string) bool {
+	_, ok := privacyFlag(flag, iplocate.Privacy{})
+	return ok
+}
+
+// privacyFlag returns the value of the named flag and whether the name is known
+func privacyFlag(flag string, p iplocate.Privacy) (set bool, ok bool) {
+	switch strings.ToLower(flag) {
+	case FlagAbuser:
+		return p.IsAbuser, true
+	case FlagAnonymous:
+		return p.IsAnonymous, true
+	case FlagBogon:
+		return p.IsBogon, true
+	case FlagHosting:
+		return p.IsHosting, true
+	case FlagIcloudRelay:
+		return p.IsIcloudRelay, true
+	case FlagProxy:
+		return p.IsProxy, true
+	case FlagTor:
+		return p.IsTor, true
+	case FlagVPN:
+		return p.IsVPN, true
+	default:
+		return false, false
+	}
+}
+
+func matchPrivacy(flags []string, p iplocate.Privacy) bool {
+	for _, flag := range flags {
+		if set, _ := privacyFlag(flag, p); set {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,126 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iplocate/go-iplocate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func testResponse() *iplocate.LookupResponse {
+	return &iplocate.LookupResponse{
+		IP:          "8.8.8.8",
+		CountryCode: stringPtr("US"),
+		Continent:   stringPtr("North America"),
+		ASN:         &iplocate.ASN{ASN: "AS15169"},
+		Privacy:     iplocate.Privacy{IsHosting: true},
+	}
+}
+
+func TestDecide_FirstMatchWins(t *testing.T) {
+	p, err := New(Allow,
+		Rule{Name: "block-tor", Action: Deny, Privacy: []string{FlagTor}},
+		Rule{Name: "challenge-hosting", Action: Challenge, Privacy: []string{FlagHosting, FlagVPN}},
+		Rule{Name: "deny-us", Action: Deny, Countries: []string{"us"}},
+	)
+	require.NoError(t, err)
+
+	decision := p.Decide(testResponse())
+	assert.Equal(t, Challenge, decision.Action)
+	require.NotNil(t, decision.Rule)
+	assert.Equal(t, "challenge-hosting", decision.Rule.Name)
+}
+
+func TestDecide_Default(t *testing.T) {
+	p, err := New(Deny, Rule{Name: "allow-eu", Action: Allow, Continents: []string{"Europe"}})
+	require.NoError(t, err)
+
+	decision := p.Decide(testResponse())
+	assert.Equal(t, Deny, decision.Action)
+	assert.Nil(t, decision.Rule)
+
+	assert.Equal(t, Deny, p.Decide(nil).Action)
+}
+
+func TestDecide_AllCriteriaMustMatch(t *testing.T) {
+	p, err := New(Allow, Rule{
+		Name:      "google-in-range",
+		Action:    Deny,
+		Countries: []string{"US"},
+		ASNs:      []string{"15169"},
+		CIDRs:     []string{"8.8.8.0/24"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, Deny, p.Decide(testResponse()).Action)
+
+	other := testResponse()
+	other.IP = "8.8.4.4"
+	assert.Equal(t, Allow, p.Decide(other).Action)
+
+	noASN := testResponse()
+	noASN.ASN = nil
+	assert.Equal(t, Allow, p.Decide(noASN).Action)
+}
+
+func TestNew_Invalid(t *testing.T) {
+	_, err := New("block")
+	assert.Error(t, err)
+
+	_, err = New(Allow, Rule{Name: "bad", Action: "drop"})
+	assert.Error(t, err)
+
+	_, err = New(Allow, Rule{Name: "bad", Action: Deny, CIDRs: []string{"not-a-cidr"}})
+	assert.ErrorContains(t, err, "invalid CIDR")
+
+	_, err = New(Allow, Rule{Name: "bad", Action: Deny, Privacy: []string{"satellite"}})
+	assert.ErrorContains(t, err, "unknown privacy flag")
+}
+
+func TestLoadJSON(t *testing.T) {
+	p, err := LoadJSON(strings.NewReader(`{
+		"default": "challenge",
+		"rules": [{"name": "allow-google", "action": "allow", "asns": ["AS15169"]}]
+	}`))
+	require.NoError(t, err)
+
+	decision := p.Decide(testResponse())
+	assert.Equal(t, Allow, decision.Action)
+	assert.Equal(t, "allow-google", decision.Rule.Name)
+}
+
+func TestLoadYAML(t *testing.T) {
+	p, err := LoadYAML(strings.NewReader(`
+rules:
+  - name: deny-anonymous
+    action: deny
+    privacy: [vpn, proxy, tor]
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, Allow, p.Decide(testResponse()).Action)
+
+	vpn := testResponse()
+	vpn.Privacy.IsVPN = true
+	assert.Equal(t, Deny, p.Decide(vpn).Action)
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "policy.yml")
+	require.NoError(t, os.WriteFile(path, []byte("default: deny\n"), 0o600))
+
+	p, err := LoadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, Deny, p.Decide(testResponse()).Action)
+
+	_, err = LoadFile(filepath.Join(dir, "policy.toml"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,46 @@
+package iplocate
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+var responsePool = sync.Pool{
+	New: func() interface{} {
+		return new(LookupResponse)
+	},
+}
+
+// AcquireResponse returns an empty LookupResponse from a shared pool. Pass it
+// to LookupInto and call ReleaseResponse once it is no longer used, so tight
+// enrichment loops can reuse responses instead of allocating one per lookup.
+func AcquireResponse() *LookupResponse {
+	resp := responsePool.Get().(*LookupResponse)
+	*resp = LookupResponse{}
+	return resp
+}
+
+// ReleaseResponse returns a response obtained from AcquireResponse to the
+// pool. The response must not be used afterwards.
+func ReleaseResponse(resp *LookupResponse) {
+	if resp != nil {
+		responsePool.Put(resp)
+	}
+}
+
+// LookupInto is like Lookup but stores the result in dst instead of
+// allocating a new response. Any previous contents of dst are replaced.
+func (c *Client) LookupInto(ip string, dst *LookupResponse) error {
+	return c.LookupIntoContext(context.Background(), ip, dst)
+}
+
+// LookupIntoContext is like LookupInto but uses ctx for the request and
+// accepts per-request options
+func (c *Client) LookupIntoContext(ctx context.Context, ip string, dst *LookupResponse, opts ...LookupOption) error {
+	if dst == nil {
+		return fmt.Errorf("nil destination for lookup of %s", ip)
+	}
+	_, _, err := c.lookup(ctx, ip, opts, dst)
+	return err
+}
@@ -0,0 +1,64 @@
+package iplocate
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupInto(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8", CountryCode: stringPtr("US")})
+	}))
+	defer server.Close()
+
+	client := NewClient(nil).WithBaseURL(server.URL)
+
+	// Stale data from a previous lookup must not leak into the result
+	dst := &LookupResponse{IP: "1.1.1.1", City: stringPtr("Sydney"), IsEU: true}
+	require.NoError(t, client.LookupInto("8.8.8.8", dst))
+	assert.Equal(t, "8.8.8.8", dst.IP)
+	assert.Equal(t, "US", *dst.CountryCode)
+	assert.Nil(t, dst.City)
+	assert.False(t, dst.IsEU)
+}
+
+func TestLookupInto_Cached(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8", CountryCode: stringPtr("US")})
+	}))
+	defer server.Close()
+
+	client := NewClient(nil).WithBaseURL(server.URL).WithCache(NewMemoryCache(10), time.Hour)
+
+	first := AcquireResponse()
+	require.NoError(t, client.LookupInto("8.8.8.8", first))
+	second := AcquireResponse()
+	require.NoError(t, client.LookupInto("8.8.8.8", second))
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, first, second)
+	ReleaseResponse(first)
+	ReleaseResponse(second)
+}
+
+func TestLookupInto_Errors(t *testing.T) {
+	client := NewClient(nil)
+	assert.Error(t, client.LookupInto("8.8.8.8", nil))
+	assert.Error(t, client.LookupInto("not-an-ip", &LookupResponse{}))
+}
+
+func TestAcquireResponse(t *testing.T) {
+	resp := AcquireResponse()
+	resp.IP = "8.8.8.8"
+	ReleaseResponse(resp)
+
+	assert.Equal(t, &LookupResponse{}, AcquireResponse())
+}
@@ -0,0 +1,149 @@
+package iplocate
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"net/netip"
+	"regexp"
+)
+
+// PrivacyLevel sets how much personal data the client keeps from lookups;
+// see WithPrivacyMode
+type PrivacyLevel int
+
+const (
+	// PrivacyOff keeps responses as returned by the API
+	PrivacyOff PrivacyLevel = iota
+	// PrivacyReduced rounds coordinates to two decimals, about 1 km, and
+	// drops postal codes
+	PrivacyReduced
+	// PrivacyStrict rounds coordinates to one decimal, about 11 km, drops
+	// postal codes, and replaces IP addresses with HashIP in lookup events
+	// and debug output. Events also leave out the Hostname.
+	PrivacyStrict
+)
+
+// coordinateDecimals is how many decimals of latitude and longitude each
+// level keeps
+var coordinateDecimals = map[PrivacyLevel]int{
+	PrivacyReduced: 2,
+	PrivacyStrict:  1,
+}
+
+// WithPrivacyMode minimizes the data kept from lookups, for teams with
+// strict data minimization requirements. Responses are reduced before they
+// are returned or cached, so a shared cache never holds exact coordinates.
+func (c *Client) WithPrivacyMode(level PrivacyLevel) *Client {
+	c.privacyLevel = level
+	return c
+}
+
+// WithIPHashKey sets the key of HashIP. By default a random key is
+// generated per client, so hashes can only be correlated within one
+// process; share a key to correlate them across instances.
+func (c *Client) WithIPHashKey(key []byte) *Client {
+	c.ipHashKey = key
+	return c
+}
+
+// HashIP returns a keyed hash of ip that identifies it in logs and metrics
+// without revealing it, e.g. "ip-3f2a9c0d51e6b784". Equivalent forms of an
+// address hash the same.
+func (c *Client) HashIP(ip string) string {
+	if addr, err := netip.ParseAddr(ip); err == nil {
+		ip = addr.Unmap().String()
+	}
+	c.ipHashOnce.Do(func() {
+		if c.ipHashKey == nil {
+			c.ipHashKey = make([]byte, 32)
+			rand.Read(c.ipHashKey)
+		}
+	})
+	mac := hmac.New(sha256.New, c.ipHashKey)
+	mac.Write([]byte(ip))
+	return "ip-" + hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// hashIPs reports whether IP addresses are hashed in events and debug output
+func (c *Client) hashIPs() bool {
+	return c.privacyLevel >= PrivacyStrict
+}
+
+// minimize reduces resp in place according to the privacy level. It
+// replaces rather than writes through pointer fields, which copies of the
+// response may share.
+func (c *Client) minimize(resp *LookupResponse) {
+	if resp == nil || c.privacyLevel == PrivacyOff {
+		return
+	}
+	decimals := coordinateDecimals[c.privacyLevel]
+	resp.Latitude = roundCoordinate(resp.Latitude, decimals)
+	resp.Longitude = roundCoordinate(resp.Longitude, decimals)
+	resp.PostalCode = nil
+}
+
+// minimizeEntry returns a copy of entry with its response minimized
+func (c *Client) minimizeEntry(entry *CacheEntry) *CacheEntry {
+	if entry.Response == nil || c.privacyLevel == PrivacyOff {
+		return entry
+	}
+	resp := *entry.Response
+	c.minimize(&resp)
+	minimized := *entry
+	minimized.Response = &resp
+	return &minimized
+}
+
+// privateEvent returns event with its IP addresses hashed if required
+func (c *Client) privateEvent(event LookupEvent) LookupEvent {
+	if !c.hashIPs() {
+		return event
+	}
+	event.IP = c.HashIP(event.IP)
+	if event.Response != nil {
+		resp := *event.Response
+		resp.IP = c.HashIP(resp.IP)
+		// Reverse DNS names often embed the address
+		resp.Hostname = nil
+		event.Response = &resp
+	}
+	if event.Err != nil {
+		event.Err = &redactedError{text: c.hashIPsInText(event.Err.Error()), err: event.Err}
+	}
+	return event
+}
+
+// redactedError replaces the text of an error with a redacted one, keeping
+// the original for errors.Is and errors.As
+type redactedError struct {
+	text string
+	err  error
+}
+
+func (e *redactedError) Error() string { return e.text }
+func (e *redactedError) Unwrap() error { return e.err }
+
+// ipCandidate matches text that may be an IPv4 or IPv6 address
+var ipCandidate = regexp.MustCompile(`[0-9A-Fa-f:.]*[.:][0-9A-Fa-f:.]*`)
+
+// hashIPsInText replaces every IP address in text with its hash
+func (c *Client) hashIPsInText(text string) string {
+	return ipCandidate.ReplaceAllStringFunc(text, func(s string) string {
+		if _, err := netip.ParseAddr(s); err != nil {
+			return s
+		}
+		return c.HashIP(s)
+	})
+}
+
+func roundCoordinate(f *float64, decimals int) *float64 {
+	if f == nil {
+		return nil
+	}
+	scale := math.Pow10(decimals)
+	rounded := math.Round(*f*scale) / scale
+	return &rounded
+}
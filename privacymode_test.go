@@ -0,0 +1,123 @@
+package iplocate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func preciseServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(LookupResponse{
+			IP:         "8.8.8.8",
+			Latitude:   float64Ptr(37.38605),
+			Longitude:  float64Ptr(-122.08385),
+			PostalCode: stringPtr("94035"),
+			City:       stringPtr("Mountain View"),
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWithPrivacyMode_Reduced(t *testing.T) {
+	cache := NewMemoryCache(10)
+	client := NewClient(nil).WithBaseURL(preciseServer(t).URL).WithCache(cache, time.Minute).WithPrivacyMode(PrivacyReduced)
+
+	result, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, 37.39, *result.Latitude)
+	assert.Equal(t, -122.08, *result.Longitude)
+	assert.Nil(t, result.PostalCode)
+	assert.Equal(t, "Mountain View", *result.City)
+	assert.Equal(t, "8.8.8.8", result.IP)
+
+	data, ok := cache.Get(lookupCacheKey("8.8.8.8", nil))
+	require.True(t, ok)
+	assert.NotContains(t, string(data), "37.386")
+	assert.NotContains(t, string(data), "94035")
+}
+
+func TestWithPrivacyMode_Strict(t *testing.T) {
+	var events []LookupEvent
+	var debug bytes.Buffer
+	client := NewClient(nil).
+		WithBaseURL(preciseServer(t).URL).
+		WithPrivacyMode(PrivacyStrict).
+		WithDebug(&debug).
+		WithEventSink(EventSinkFunc(func(ctx context.Context, event LookupEvent) {
+			events = append(events, event)
+		}))
+
+	result, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, 37.4, *result.Latitude)
+	assert.Equal(t, -122.1, *result.Longitude)
+	assert.Equal(t, "8.8.8.8", result.IP, "the caller gets the real IP")
+
+	hash := client.HashIP("8.8.8.8")
+	require.Len(t, events, 1)
+	assert.Equal(t, hash, events[0].IP)
+	assert.Equal(t, hash, events[0].Response.IP)
+	assert.Equal(t, 37.4, *events[0].Response.Latitude)
+
+	assert.NotContains(t, debug.String(), "8.8.8.8")
+	assert.Contains(t, debug.String(), hash)
+}
+
+func TestWithPrivacyMode_StrictErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"Invalid IP address: 8.8.8.8"}`))
+	}))
+	defer server.Close()
+
+	var events []LookupEvent
+	client := NewClient(nil).
+		WithBaseURL(server.URL).
+		WithPrivacyMode(PrivacyStrict).
+		WithEventSink(EventSinkFunc(func(ctx context.Context, event LookupEvent) {
+			events = append(events, event)
+		}))
+
+	_, err := client.Lookup("8.8.8.8")
+	require.Error(t, err)
+	require.Len(t, events, 1)
+	assert.NotContains(t, events[0].Err.Error(), "8.8.8.8")
+	assert.Contains(t, events[0].Err.Error(), client.HashIP("8.8.8.8"))
+	var apiErr *APIError
+	assert.True(t, errors.As(events[0].Err, &apiErr), "the API error can still be unwrapped")
+}
+
+func TestWithPrivacyMode_Off(t *testing.T) {
+	result, err := NewClient(nil).WithBaseURL(preciseServer(t).URL).Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, 37.38605, *result.Latitude)
+	assert.Equal(t, "94035", *result.PostalCode)
+}
+
+func TestHashIP(t *testing.T) {
+	client := NewClient(nil).WithIPHashKey([]byte("secret"))
+	hash := client.HashIP("8.8.8.8")
+	assert.True(t, strings.HasPrefix(hash, "ip-"))
+	assert.Len(t, hash, 19)
+	assert.Equal(t, hash, client.HashIP("::ffff:8.8.8.8"))
+	assert.Equal(t, hash, NewClient(nil).WithIPHashKey([]byte("secret")).HashIP("8.8.8.8"))
+	assert.NotEqual(t, hash, client.HashIP("8.8.4.4"))
+	assert.NotEqual(t, hash, NewClient(nil).HashIP("8.8.8.8"), "clients without a key use a random one")
+}
+
+func TestHashIPsInText(t *testing.T) {
+	client := NewClient(nil).WithIPHashKey([]byte("secret"))
+	text := client.hashIPsInText("GET /lookup/2001:db8::1 from 10.0.0.1 at 12:00:00 over HTTP/1.1")
+	assert.Equal(t, "GET /lookup/"+client.HashIP("2001:db8::1")+" from "+client.HashIP("10.0.0.1")+" at 12:00:00 over HTTP/1.1", text)
+}
@@ -0,0 +1,63 @@
+package iplocate
+
+import (
+	"sync"
+	"time"
+)
+
+// ProgressFunc receives the progress of a bulk lookup: the number of lookups
+// done so far, the total number of lookups or -1 if it is not known up front,
+// and how many of the done lookups failed
+type ProgressFunc func(done, total, errs int)
+
+// OnProgress makes LookupBatch, LookupStream and LookupIter call fn after
+// every lookup, e.g. to report status of a long-running enrichment job.
+// Calls are never concurrent, so fn needs no locking of its own, but it
+// should return quickly as lookups wait for it.
+func OnProgress(fn ProgressFunc) LookupOption {
+	return func(o *lookupOptions) {
+		o.onProgress = fn
+	}
+}
+
+// EstimateRemaining extrapolates how long the remaining lookups of a bulk
+// operation will take from the time the done ones took. It returns 0 if
+// nothing is done yet or the total is not known.
+func EstimateRemaining(elapsed time.Duration, done, total int) time.Duration {
+	if done <= 0 || total < done {
+		return 0
+	}
+	return time.Duration(float64(elapsed) / float64(done) * float64(total-done))
+}
+
+// progress counts completed lookups and reports them to a ProgressFunc
+type progress struct {
+	mu    sync.Mutex
+	fn    ProgressFunc
+	total int
+	done  int
+	errs  int
+}
+
+// newProgress returns a tracker for fn, or nil if fn is nil
+func newProgress(fn ProgressFunc, total int) *progress {
+	if fn == nil {
+		return nil
+	}
+	return &progress{fn: fn, total: total}
+}
+
+// record counts a completed lookup. It is a no-op on a nil tracker.
+func (p *progress) record(err error) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	if err != nil {
+		p.errs++
+	}
+	p.fn(p.done, p.total, p.errs)
+}
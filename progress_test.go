@@ -0,0 +1,72 @@
+package iplocate
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type progressCall struct {
+	done, total, errs int
+}
+
+func TestOnProgress_Batch(t *testing.T) {
+	var calls int32
+	server := newFailingServer(t, "192.0.2.1", &calls)
+	client := NewClient(nil).WithBaseURL(server.URL)
+
+	var progress []progressCall
+	_, err := client.LookupBatch(context.Background(), []string{"8.8.8.8", "192.0.2.1", "1.1.1.1"}, OnProgress(func(done, total, errs int) {
+		progress = append(progress, progressCall{done, total, errs})
+	}))
+	require.Error(t, err)
+
+	require.Len(t, progress, 3)
+	for i, call := range progress {
+		assert.Equal(t, i+1, call.done)
+		assert.Equal(t, 3, call.total)
+	}
+	assert.Equal(t, 1, progress[2].errs)
+}
+
+func TestOnProgress_Stream(t *testing.T) {
+	var calls int32
+	server := newEchoServer(t, &calls)
+	client := NewClient(nil).WithBaseURL(server.URL)
+
+	ips := make(chan string, 2)
+	ips <- "8.8.8.8"
+	ips <- "1.1.1.1"
+	close(ips)
+
+	var last progressCall
+	for range client.LookupStream(context.Background(), ips, OnProgress(func(done, total, errs int) {
+		last = progressCall{done, total, errs}
+	})) {
+	}
+	assert.Equal(t, progressCall{done: 2, total: -1}, last)
+}
+
+func TestOnProgress_Iter(t *testing.T) {
+	var calls int32
+	server := newEchoServer(t, &calls)
+	client := NewClient(nil).WithBaseURL(server.URL)
+
+	var progress []progressCall
+	for range client.LookupIter(context.Background(), slices.Values([]string{"8.8.8.8", "invalid"}), OnProgress(func(done, total, errs int) {
+		progress = append(progress, progressCall{done, total, errs})
+	})) {
+	}
+	assert.Equal(t, []progressCall{{1, -1, 0}, {2, -1, 1}}, progress)
+}
+
+func TestEstimateRemaining(t *testing.T) {
+	assert.Equal(t, 30*time.Second, EstimateRemaining(10*time.Second, 25, 100))
+	assert.Equal(t, time.Duration(0), EstimateRemaining(10*time.Second, 0, 100))
+	assert.Equal(t, time.Duration(0), EstimateRemaining(10*time.Second, 5, -1))
+	assert.Equal(t, time.Duration(0), EstimateRemaining(10*time.Second, 100, 100))
+}
@@ -0,0 +1,5 @@
+// Package iplocatev1 holds the Go code generated from lookup.proto, the
+// definition of the lookup service implemented by the grpcserver package.
+package iplocatev1
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative iplocate/v1/lookup.proto
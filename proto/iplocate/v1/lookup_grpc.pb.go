@@ -0,0 +1,161 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             v5.27.1
+// source: iplocate/v1/lookup.proto
+
+// Package iplocate.v1 defines a lookup service fronting the IPLocate API,
+// served by github.com/iplocate/go-iplocate/grpcserver.
+
+package iplocatev1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	LookupService_Lookup_FullMethodName      = "/iplocate.v1.LookupService/Lookup"
+	LookupService_BatchLookup_FullMethodName = "/iplocate.v1.LookupService/BatchLookup"
+)
+
+// LookupServiceClient is the client API for LookupService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// LookupService looks up IP addresses with the IPLocate API
+type LookupServiceClient interface {
+	// Lookup returns the data for one IP address
+	Lookup(ctx context.Context, in *LookupRequest, opts ...grpc.CallOption) (*LookupResponse, error)
+	// BatchLookup returns the data for several IP addresses. Failed lookups
+	// are reported per address instead of failing the call.
+	BatchLookup(ctx context.Context, in *BatchLookupRequest, opts ...grpc.CallOption) (*BatchLookupResponse, error)
+}
+
+type lookupServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLookupServiceClient(cc grpc.ClientConnInterface) LookupServiceClient {
+	return &lookupServiceClient{cc}
+}
+
+func (c *lookupServiceClient) Lookup(ctx context.Context, in *LookupRequest, opts ...grpc.CallOption) (*LookupResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LookupResponse)
+	err := c.cc.Invoke(ctx, LookupService_Lookup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lookupServiceClient) BatchLookup(ctx context.Context, in *BatchLookupRequest, opts ...grpc.CallOption) (*BatchLookupResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchLookupResponse)
+	err := c.cc.Invoke(ctx, LookupService_BatchLookup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// LookupServiceServer is the server API for LookupService service.
+// All implementations must embed UnimplementedLookupServiceServer
+// for forward compatibility
+//
+// LookupService looks up IP addresses with the IPLocate API
+type LookupServiceServer interface {
+	// Lookup returns the data for one IP address
+	Lookup(context.Context, *LookupRequest) (*LookupResponse, error)
+	// BatchLookup returns the data for several IP addresses. Failed lookups
+	// are reported per address instead of failing the call.
+	BatchLookup(context.Context, *BatchLookupRequest) (*BatchLookupResponse, error)
+	mustEmbedUnimplementedLookupServiceServer()
+}
+
+// UnimplementedLookupServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedLookupServiceServer struct {
+}
+
+func (UnimplementedLookupServiceServer) Lookup(context.Context, *LookupRequest) (*LookupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Lookup not implemented")
+}
+func (UnimplementedLookupServiceServer) BatchLookup(context.Context, *BatchLookupRequest) (*BatchLookupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchLookup not implemented")
+}
+func (UnimplementedLookupServiceServer) mustEmbedUnimplementedLookupServiceServer() {}
+
+// UnsafeLookupServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LookupServiceServer will
+// result in compilation errors.
+type UnsafeLookupServiceServer interface {
+	mustEmbedUnimplementedLookupServiceServer()
+}
+
+func RegisterLookupServiceServer(s grpc.ServiceRegistrar, srv LookupServiceServer) {
+	s.RegisterService(&LookupService_ServiceDesc, srv)
+}
+
+func _LookupService_Lookup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LookupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LookupServiceServer).Lookup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LookupService_Lookup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LookupServiceServer).Lookup(ctx, req.(*LookupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LookupService_BatchLookup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchLookupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LookupServiceServer).BatchLookup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LookupService_BatchLookup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LookupServiceServer).BatchLookup(ctx, req.(*BatchLookupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// LookupService_ServiceDesc is the grpc.ServiceDesc for LookupService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var LookupService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "iplocate.v1.LookupService",
+	HandlerType: (*LookupServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Lookup",
+			Handler:    _LookupService_Lookup_Handler,
+		},
+		{
+			MethodName: "BatchLookup",
+			Handler:    _LookupService_BatchLookup_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "iplocate/v1/lookup.proto",
+}
@@ -0,0 +1,139 @@
+package iplocate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// errNoProviders is returned by a Chain without providers
+var errNoProviders = errors.New("chain has no providers")
+
+// Provider answers IP lookups, e.g. from the API, a local database or a
+// user-supplied source. Chain combines providers.
+type Provider interface {
+	Lookup(ctx context.Context, ip string) (*LookupResponse, error)
+}
+
+// ProviderFunc adapts a function to the Provider interface, e.g. the
+// LookupContext method of a db.Manager
+type ProviderFunc func(ctx context.Context, ip string) (*LookupResponse, error)
+
+// Lookup calls f
+func (f ProviderFunc) Lookup(ctx context.Context, ip string) (*LookupResponse, error) {
+	return f(ctx, ip)
+}
+
+// APIProvider returns a provider looking IPs up with client and opts
+func APIProvider(client *Client, opts ...LookupOption) Provider {
+	return ProviderFunc(func(ctx context.Context, ip string) (*LookupResponse, error) {
+		return client.LookupContext(ctx, ip, opts...)
+	})
+}
+
+// DatabaseProvider returns a provider looking IPs up in a local database
+func DatabaseProvider(db *Database) Provider {
+	return ProviderFunc(func(ctx context.Context, ip string) (*LookupResponse, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return db.Lookup(ip)
+	})
+}
+
+// TimeoutProvider bounds each lookup of p by timeout. Within a Chain with
+// its own timeout, the shorter one applies.
+func TimeoutProvider(p Provider, timeout time.Duration) Provider {
+	return ProviderFunc(func(ctx context.Context, ip string) (*LookupResponse, error) {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return p.Lookup(ctx, ip)
+	})
+}
+
+// MergeStrategy is how a Chain combines the answers of its providers
+type MergeStrategy int
+
+const (
+	// MergeFirst returns the answer of the first provider that succeeds
+	MergeFirst MergeStrategy = iota
+	// MergeFill starts from the answer of the first provider that succeeds
+	// and asks every later provider to fill in the fields it is missing.
+	// Privacy flags set by any provider are kept.
+	MergeFill
+)
+
+// Chain is a Provider asking its providers in order, falling back to the
+// next one when a provider fails or times out, e.g.
+//
+//	iplocate.NewChain(iplocate.APIProvider(client), iplocate.DatabaseProvider(db))
+//
+// It is safe for concurrent use if its providers are.
+type Chain struct {
+	providers []Provider
+	timeout   time.Duration
+	strategy  MergeStrategy
+}
+
+// NewChain creates a chain of providers, asked in the order given
+func NewChain(providers ...Provider) *Chain {
+	return &Chain{providers: providers}
+}
+
+// WithTimeout bounds every provider's lookup by timeout, so a slow provider
+// falls back to the next one. Use TimeoutProvider to set it per provider.
+func (ch *Chain) WithTimeout(timeout time.Duration) *Chain {
+	ch.timeout = timeout
+	return ch
+}
+
+// WithMergeStrategy sets how answers are combined, MergeFirst by default
+func (ch *Chain) WithMergeStrategy(strategy MergeStrategy) *Chain {
+	ch.strategy = strategy
+	return ch
+}
+
+// Lookup asks the providers for ip in order. If every provider fails, the
+// error joins each provider's error.
+func (ch *Chain) Lookup(ctx context.Context, ip string) (*LookupResponse, error) {
+	if len(ch.providers) == 0 {
+		return nil, errNoProviders
+	}
+
+	var result *LookupResponse
+	var errs []error
+	for i, p := range ch.providers {
+		resp, err := ch.lookup(ctx, p, ip)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("provider %d failed: %w", i, err))
+			continue
+		}
+		if result == nil {
+			if ch.strategy == MergeFirst {
+				return resp, nil
+			}
+			copied := *resp
+			result = &copied
+			continue
+		}
+		result.Fill(resp)
+	}
+	if result == nil {
+		return nil, errors.Join(errs...)
+	}
+	return result, nil
+}
+
+// lookup asks one provider, bounded by the chain's timeout
+func (ch *Chain) lookup(ctx context.Context, p Provider, ip string) (*LookupResponse, error) {
+	if ch.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ch.timeout)
+		defer cancel()
+	}
+	return p.Lookup(ctx, ip)
+}
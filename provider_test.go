@@ -0,0 +1,130 @@
+package iplocate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func staticProvider(resp *LookupResponse, err error) ProviderFunc {
+	return func(ctx context.Context, ip string) (*LookupResponse, error) {
+		return resp, err
+	}
+}
+
+// slowProvider answers once ctx is done
+var slowProvider = ProviderFunc(func(ctx context.Context, ip string) (*LookupResponse, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+})
+
+func TestChain_Fallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"error": "temporarily unavailable"}`))
+	}))
+	defer server.Close()
+	client := NewClient(nil).WithBaseURL(server.URL)
+
+	db, err := OpenDatabase(testCountryDatabase(t))
+	require.NoError(t, err)
+	defer db.Close()
+
+	chain := NewChain(APIProvider(client), DatabaseProvider(db))
+	result, err := chain.Lookup(context.Background(), "8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "US", *result.CountryCode)
+
+	_, err = chain.Lookup(context.Background(), "192.0.2.1")
+	var apiErr *APIError
+	assert.ErrorAs(t, err, &apiErr, "every provider's error is kept")
+	assert.ErrorIs(t, err, ErrNotInDatabase)
+}
+
+func TestChain_MergeFirst(t *testing.T) {
+	first := &LookupResponse{IP: "8.8.8.8", CountryCode: stringPtr("US")}
+	second := &LookupResponse{IP: "8.8.8.8", City: stringPtr("Mountain View")}
+
+	result, err := NewChain(staticProvider(first, nil), staticProvider(second, nil)).Lookup(context.Background(), "8.8.8.8")
+	require.NoError(t, err)
+	assert.Same(t, first, result)
+}
+
+func TestChain_MergeFill(t *testing.T) {
+	first := &LookupResponse{IP: "8.8.8.8", CountryCode: stringPtr("US"), Privacy: Privacy{IsHosting: true}}
+	second := &LookupResponse{
+		IP:          "8.8.8.8",
+		CountryCode: stringPtr("CA"),
+		City:        stringPtr("Mountain View"),
+		ASN:         &ASN{ASN: "AS15169"},
+		Privacy:     Privacy{IsVPN: true},
+	}
+
+	chain := NewChain(
+		staticProvider(nil, errors.New("unavailable")),
+		staticProvider(first, nil),
+		staticProvider(nil, errors.New("unavailable")),
+		staticProvider(second, nil),
+	).WithMergeStrategy(MergeFill)
+
+	result, err := chain.Lookup(context.Background(), "8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "US", *result.CountryCode, "earlier providers take precedence")
+	assert.Equal(t, "Mountain View", *result.City)
+	assert.Equal(t, "AS15169", result.ASN.ASN)
+	assert.Equal(t, Privacy{IsHosting: true, IsVPN: true}, result.Privacy)
+	assert.Nil(t, first.City, "provider answers are not modified")
+}
+
+func TestChain_Timeouts(t *testing.T) {
+	answer := &LookupResponse{IP: "8.8.8.8"}
+
+	start := time.Now()
+	result, err := NewChain(slowProvider, staticProvider(answer, nil)).
+		WithTimeout(10*time.Millisecond).
+		Lookup(context.Background(), "8.8.8.8")
+	require.NoError(t, err)
+	assert.Same(t, answer, result)
+	assert.Less(t, time.Since(start), time.Second)
+
+	result, err = NewChain(TimeoutProvider(slowProvider, 10*time.Millisecond), staticProvider(answer, nil)).
+		Lookup(context.Background(), "8.8.8.8")
+	require.NoError(t, err)
+	assert.Same(t, answer, result)
+}
+
+func TestChain_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var asked bool
+	second := ProviderFunc(func(ctx context.Context, ip string) (*LookupResponse, error) {
+		asked = true
+		return &LookupResponse{}, nil
+	})
+	_, err := NewChain(slowProvider, second).Lookup(ctx, "8.8.8.8")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, asked, "a canceled lookup doesn't fall back")
+}
+
+func TestChain_Nested(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8", City: stringPtr("Mountain View")})
+	}))
+	defer server.Close()
+
+	inner := NewChain(staticProvider(nil, errors.New("unavailable")), APIProvider(NewClient(nil).WithBaseURL(server.URL)))
+	result, err := NewChain(inner).Lookup(context.Background(), "8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "Mountain View", *result.City)
+
+	_, err = NewChain().Lookup(context.Background(), "8.8.8.8")
+	assert.Error(t, err)
+}
@@ -0,0 +1,228 @@
+package iplocate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrQuotaExceeded is returned when a QuotaTracker refuses a request because
+// the daily budget has been used up
+var ErrQuotaExceeded = errors.New("daily quota exceeded")
+
+// DefaultQuotaWarningThreshold is the fraction of the daily limit at which a
+// QuotaTracker calls its warning callback
+const DefaultQuotaWarningThreshold = 0.9
+
+// QuotaUsage is a snapshot of the requests counted for a UTC day
+type QuotaUsage struct {
+	// Day is midnight UTC of the day being counted
+	Day   time.Time `json:"day"`
+	Used  int       `json:"used"`
+	Limit int       `json:"limit"`
+}
+
+// Remaining returns the number of requests left for the day
+func (u QuotaUsage) Remaining() int {
+	if u.Used >= u.Limit {
+		return 0
+	}
+	return u.Limit - u.Used
+}
+
+// QuotaStore persists the request counter of a QuotaTracker across restarts
+type QuotaStore interface {
+	// Load returns the last saved day and count. A store with nothing saved
+	// returns a zero day and no error.
+	Load() (day time.Time, used int, err error)
+	// Save records the count for the day
+	Save(day time.Time, used int) error
+}
+
+// QuotaTracker counts requests per UTC day against a plan limit.
+// By default it refuses requests with ErrQuotaExceeded once the limit is
+// reached, and calls an optional warning callback when usage first crosses
+// the warning threshold. It is safe for concurrent use.
+type QuotaTracker struct {
+	mu        sync.Mutex
+	limit     int
+	threshold float64
+	onWarning func(QuotaUsage)
+	soft      bool
+	store     QuotaStore
+	now       func() time.Time
+
+	loaded bool
+	day    time.Time
+	used   int
+	warned bool
+}
+
+// NewQuotaTracker creates a tracker for a plan allowing limit requests per UTC day
+func NewQuotaTracker(limit int) *QuotaTracker {
+	return &QuotaTracker{
+		limit:     limit,
+		threshold: DefaultQuotaWarningThreshold,
+		now:       time.Now,
+	}
+}
+
+// WithWarning sets a callback invoked once per day when usage reaches the
+// given fraction of the limit, e.g. 0.8 for 80%
+func (q *QuotaTracker) WithWarning(threshold float64, fn func(QuotaUsage)) *QuotaTracker {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.threshold = threshold
+	q.onWarning = fn
+	return q
+}
+
+// WithSoftLimit makes the tracker only warn, never refuse, when the limit is reached
+func (q *QuotaTracker) WithSoftLimit() *QuotaTracker {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.soft = true
+	return q
+}
+
+// WithStore persists the counter in store. The saved count is loaded on the
+// first request and saved after every counted request.
+func (q *QuotaTracker) WithStore(store QuotaStore) *QuotaTracker {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.store = store
+	q.loaded = false
+	return q
+}
+
+// Usage returns the requests counted so far today
+func (q *QuotaTracker) Usage() (QuotaUsage, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.sync(); err != nil {
+		return QuotaUsage{}, err
+	}
+	return q.usage(), nil
+}
+
+// Acquire counts one request, or returns ErrQuotaExceeded if the daily limit
+// has been reached and the tracker is not in soft limit mode
+func (q *QuotaTracker) Acquire() error {
+	q.mu.Lock()
+
+	if err := q.sync(); err != nil {
+		q.mu.Unlock()
+		return err
+	}
+
+	if q.used >= q.limit && !q.soft {
+		q.mu.Unlock()
+		return ErrQuotaExceeded
+	}
+
+	q.used++
+	if q.store != nil {
+		if err := q.store.Save(q.day, q.used); err != nil {
+			q.mu.Unlock()
+			return fmt.Errorf("failed to save quota usage: %w", err)
+		}
+	}
+
+	// Call the warning callback without holding the lock so it may use the tracker
+	onWarning := q.onWarning
+	warn := !q.warned && onWarning != nil && float64(q.used) >= q.threshold*float64(q.limit)
+	if warn {
+		q.warned = true
+	}
+	usage := q.usage()
+	q.mu.Unlock()
+
+	if warn {
+		onWarning(usage)
+	}
+	return nil
+}
+
+// sync loads the persisted counter and resets it when the UTC day changes.
+// The caller must hold q.mu.
+func (q *QuotaTracker) sync() error {
+	if !q.loaded && q.store != nil {
+		day, used, err := q.store.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load quota usage: %w", err)
+		}
+		q.day, q.used = day, used
+		q.warned = false
+	}
+	q.loaded = true
+
+	today := utcDay(q.now())
+	if !q.day.Equal(today) {
+		q.day = today
+		q.used = 0
+		q.warned = false
+	}
+	return nil
+}
+
+func (q *QuotaTracker) usage() QuotaUsage {
+	return QuotaUsage{Day: q.day, Used: q.used, Limit: q.limit}
+}
+
+// utcDay truncates t to midnight UTC
+func utcDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// FileQuotaStore is a QuotaStore that keeps the counter in a JSON file
+type FileQuotaStore struct {
+	path string
+}
+
+// NewFileQuotaStore creates a store backed by the file at path
+func NewFileQuotaStore(path string) *FileQuotaStore {
+	return &FileQuotaStore{path: path}
+}
+
+type fileQuotaState struct {
+	Day  time.Time `json:"day"`
+	Used int       `json:"used"`
+}
+
+// Load reads the counter from the file. A missing file is not an error.
+func (s *FileQuotaStore) Load() (time.Time, int, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return time.Time{}, 0, nil
+	}
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+
+	var state fileQuotaState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return time.Time{}, 0, err
+	}
+	return state.Day, state.Used, nil
+}
+
+// Save writes the counter to the file
+func (s *FileQuotaStore) Save(day time.Time, used int) error {
+	data, err := json.Marshal(fileQuotaState{Day: day, Used: used})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0o600)
+}
+
+// WithQuotaTracker counts every request sent to the API with tracker,
+// refusing requests once the daily budget is exhausted
+func (c *Client) WithQuotaTracker(tracker *QuotaTracker) *Client {
+	c.quota = tracker
+	return c
+}
@@ -0,0 +1,100 @@
+package iplocate
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuotaTracker_RefusesAtLimit(t *testing.T) {
+	tracker := NewQuotaTracker(2)
+
+	require.NoError(t, tracker.Acquire())
+	require.NoError(t, tracker.Acquire())
+	assert.ErrorIs(t, tracker.Acquire(), ErrQuotaExceeded)
+
+	usage, err := tracker.Usage()
+	require.NoError(t, err)
+	assert.Equal(t, 2, usage.Used)
+	assert.Equal(t, 0, usage.Remaining())
+}
+
+func TestQuotaTracker_SoftLimit(t *testing.T) {
+	tracker := NewQuotaTracker(1).WithSoftLimit()
+
+	require.NoError(t, tracker.Acquire())
+	require.NoError(t, tracker.Acquire())
+
+	usage, err := tracker.Usage()
+	require.NoError(t, err)
+	assert.Equal(t, 2, usage.Used)
+}
+
+func TestQuotaTracker_WarnsOnce(t *testing.T) {
+	var warnings []QuotaUsage
+	tracker := NewQuotaTracker(10).WithWarning(0.5, func(u QuotaUsage) {
+		warnings = append(warnings, u)
+	})
+
+	for i := 0; i < 7; i++ {
+		require.NoError(t, tracker.Acquire())
+	}
+
+	require.Len(t, warnings, 1)
+	assert.Equal(t, 5, warnings[0].Used)
+	assert.Equal(t, 5, warnings[0].Remaining())
+}
+
+func TestQuotaTracker_ResetsDaily(t *testing.T) {
+	now := time.Date(2025, 3, 1, 23, 59, 0, 0, time.UTC)
+	tracker := NewQuotaTracker(1)
+	tracker.now = func() time.Time { return now }
+
+	require.NoError(t, tracker.Acquire())
+	assert.ErrorIs(t, tracker.Acquire(), ErrQuotaExceeded)
+
+	now = now.Add(2 * time.Minute)
+	require.NoError(t, tracker.Acquire())
+
+	usage, err := tracker.Usage()
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC), usage.Day)
+	assert.Equal(t, 1, usage.Used)
+}
+
+func TestQuotaTracker_FileStore(t *testing.T) {
+	store := NewFileQuotaStore(filepath.Join(t.TempDir(), "quota.json"))
+
+	tracker := NewQuotaTracker(3).WithStore(store)
+	require.NoError(t, tracker.Acquire())
+	require.NoError(t, tracker.Acquire())
+
+	// A new tracker picks up where the previous one left off
+	restarted := NewQuotaTracker(3).WithStore(store)
+	require.NoError(t, restarted.Acquire())
+	assert.ErrorIs(t, restarted.Acquire(), ErrQuotaExceeded)
+}
+
+func TestLookup_QuotaExceeded(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8"})
+	}))
+	defer server.Close()
+
+	client := NewClient(nil).WithBaseURL(server.URL).WithQuotaTracker(NewQuotaTracker(1))
+
+	_, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+
+	_, err = client.Lookup("8.8.8.8")
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,210 @@
+package iplocate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// DefaultRDAPURL is the RDAP bootstrap service, which redirects IP queries
+// to the regional internet registry responsible for the address
+const DefaultRDAPURL = "https://rdap.org"
+
+// WithRDAPFallback makes AbuseContact query RDAP at baseURL, or at
+// DefaultRDAPURL if it is empty, when the API has no abuse email, phone or
+// address for an IP. The registry's abuse contact is normalized into an
+// Abuse, with missing fields filled in from the API's answer.
+func (c *Client) WithRDAPFallback(baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultRDAPURL
+	}
+	c.rdapURL = strings.TrimSuffix(baseURL, "/")
+	return c
+}
+
+// rdapNetwork is the part of an RDAP IP network response used for abuse contacts
+type rdapNetwork struct {
+	StartAddress string       `json:"startAddress"`
+	EndAddress   string       `json:"endAddress"`
+	Country      string       `json:"country"`
+	CIDRs        []rdapCIDR   `json:"cidr0_cidrs"`
+	Entities     []rdapEntity `json:"entities"`
+}
+
+type rdapCIDR struct {
+	V4Prefix string `json:"v4prefix"`
+	V6Prefix string `json:"v6prefix"`
+	Length   int    `json:"length"`
+}
+
+type rdapEntity struct {
+	Roles      []string          `json:"roles"`
+	VCardArray []json.RawMessage `json:"vcardArray"`
+	Entities   []rdapEntity      `json:"entities"`
+}
+
+// rdapAbuseContact queries RDAP for the abuse contact of ip
+func (c *Client) rdapAbuseContact(ctx context.Context, ip string) (*Abuse, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.rdapURL+"/ip/"+url.PathEscape(ip), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/rdap+json")
+	req.Header.Set("User-Agent", c.userAgentHeader())
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("RDAP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("RDAP request failed (%d)", resp.StatusCode)
+	}
+
+	var network rdapNetwork
+	if err := json.NewDecoder(&cappedReader{r: resp.Body, n: c.maxResponseSize()}).Decode(&network); err != nil {
+		return nil, fmt.Errorf("failed to parse RDAP response: %w", err)
+	}
+	return network.abuse(), nil
+}
+
+// abuse returns the network's abuse contact, or nil if it has none
+func (n *rdapNetwork) abuse() *Abuse {
+	entity := findAbuseEntity(n.Entities)
+	if entity == nil {
+		return nil
+	}
+	abuse := entity.vcard()
+	if !hasAbuseContact(abuse) {
+		return nil
+	}
+	abuse.CountryCode = stringValue(n.Country)
+	abuse.Network = stringValue(n.network())
+	return abuse
+}
+
+// network returns the network as a CIDR if available, else as a range
+func (n *rdapNetwork) network() string {
+	for _, cidr := range n.CIDRs {
+		prefix := cidr.V4Prefix
+		if prefix == "" {
+			prefix = cidr.V6Prefix
+		}
+		if prefix != "" {
+			return fmt.Sprintf("%s/%d", prefix, cidr.Length)
+		}
+	}
+	if n.StartAddress != "" && n.EndAddress != "" {
+		return n.StartAddress + " - " + n.EndAddress
+	}
+	return ""
+}
+
+// findAbuseEntity returns the first entity with the abuse role, searching
+// nested entities too, as registries often attach the abuse contact to the
+// registrant
+func findAbuseEntity(entities []rdapEntity) *rdapEntity {
+	for i := range entities {
+		if slices.Contains(entities[i].Roles, "abuse") {
+			return &entities[i]
+		}
+	}
+	for i := range entities {
+		if found := findAbuseEntity(entities[i].Entities); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// vcard reads the name, email, phone and address from the entity's jCard
+// (RFC 7095): ["vcard", [[name, params, type, value], ...]]
+func (e *rdapEntity) vcard() *Abuse {
+	abuse := &Abuse{}
+	if len(e.VCardArray) < 2 {
+		return abuse
+	}
+	var properties [][]json.RawMessage
+	if err := json.Unmarshal(e.VCardArray[1], &properties); err != nil {
+		return abuse
+	}
+
+	for _, property := range properties {
+		if len(property) < 4 {
+			continue
+		}
+		var name string
+		json.Unmarshal(property[0], &name)
+		switch name {
+		case "fn":
+			setOnce(&abuse.Name, jcardText(property[3]))
+		case "email":
+			setOnce(&abuse.Email, jcardText(property[3]))
+		case "tel":
+			setOnce(&abuse.Phone, strings.TrimPrefix(jcardText(property[3]), "tel:"))
+		case "adr":
+			var params struct {
+				Label string `json:"label"`
+			}
+			json.Unmarshal(property[1], &params)
+			address := params.Label
+			if address == "" {
+				address = jcardText(property[3])
+			}
+			setOnce(&abuse.Address, strings.ReplaceAll(address, "\n", ", "))
+		}
+	}
+	return abuse
+}
+
+// jcardText returns a jCard value as text. Structured values, such as the
+// components of an address, are joined with commas, skipping empty ones.
+func jcardText(value json.RawMessage) string {
+	var text string
+	if json.Unmarshal(value, &text) == nil {
+		return strings.TrimSpace(text)
+	}
+	var parts []json.RawMessage
+	if json.Unmarshal(value, &parts) != nil {
+		return ""
+	}
+	var texts []string
+	for _, part := range parts {
+		if t := jcardText(part); t != "" {
+			texts = append(texts, t)
+		}
+	}
+	return strings.Join(texts, ", ")
+}
+
+// setOnce sets *dst to value unless it is set already or value is empty
+func setOnce(dst **string, value string) {
+	if *dst == nil && value != "" {
+		*dst = &value
+	}
+}
+
+// mergeAbuse fills the fields missing from dst with those of src
+func mergeAbuse(dst, src *Abuse) *Abuse {
+	if dst == nil {
+		return src
+	}
+	merged := *dst
+	for _, field := range []struct{ dst, src **string }{
+		{&merged.Address, &src.Address},
+		{&merged.CountryCode, &src.CountryCode},
+		{&merged.Email, &src.Email},
+		{&merged.Name, &src.Name},
+		{&merged.Network, &src.Network},
+		{&merged.Phone, &src.Phone},
+	} {
+		if *field.dst == nil {
+			*field.dst = *field.src
+		}
+	}
+	return &merged
+}
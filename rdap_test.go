@@ -0,0 +1,104 @@
+package iplocate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rdapResponse is shaped like an ARIN answer, with the abuse contact nested
+// in the registrant entity
+const rdapResponse = `{
+  "objectClassName": "ip network",
+  "startAddress": "203.0.113.0",
+  "endAddress": "203.0.113.255",
+  "country": "US",
+  "cidr0_cidrs": [{"v4prefix": "203.0.113.0", "length": 24}],
+  "entities": [{
+    "roles": ["registrant"],
+    "vcardArray": ["vcard", [["version", {}, "text", "4.0"], ["fn", {}, "text", "Example Hosting"]]],
+    "entities": [{
+      "roles": ["abuse"],
+      "vcardArray": ["vcard", [
+        ["version", {}, "text", "4.0"],
+        ["fn", {}, "text", "Abuse Desk"],
+        ["adr", {"label": "1 Main St\nSpringfield\nUS"}, "text", ["", "", "", "", "", "", ""]],
+        ["tel", {"type": ["work", "voice"]}, "uri", "tel:+1-555-0100"],
+        ["email", {}, "text", "abuse@example.net"]
+      ]]
+    }]
+  }]
+}`
+
+func rdapServers(t *testing.T, apiAbuse *Abuse, rdapStatus int) (api, rdap *httptest.Server) {
+	api = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(LookupResponse{IP: "203.0.113.7", Abuse: apiAbuse})
+	}))
+	t.Cleanup(api.Close)
+	rdap = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/ip/203.0.113.7", r.URL.Path)
+		assert.Equal(t, "application/rdap+json", r.Header.Get("Accept"))
+		w.WriteHeader(rdapStatus)
+		w.Write([]byte(rdapResponse))
+	}))
+	t.Cleanup(rdap.Close)
+	return api, rdap
+}
+
+func TestAbuseContact_RDAPFallback(t *testing.T) {
+	api, rdap := rdapServers(t, &Abuse{Name: stringPtr("Example Hosting NOC")}, http.StatusOK)
+	client := NewClient(nil).WithBaseURL(api.URL).WithRDAPFallback(rdap.URL + "/")
+
+	abuse, err := client.AbuseContact(context.Background(), "203.0.113.7")
+	require.NoError(t, err)
+	assert.Equal(t, "abuse@example.net", *abuse.Email)
+	assert.Equal(t, "+1-555-0100", *abuse.Phone)
+	assert.Equal(t, "1 Main St, Springfield, US", *abuse.Address)
+	assert.Equal(t, "Example Hosting NOC", *abuse.Name, "fields from the API take precedence")
+	assert.Equal(t, "203.0.113.0/24", *abuse.Network)
+	assert.Equal(t, "US", *abuse.CountryCode)
+}
+
+func TestAbuseContact_RDAPNotUsed(t *testing.T) {
+	api, rdap := rdapServers(t, &Abuse{Email: stringPtr("noc@example.com")}, http.StatusOK)
+	rdap.Close()
+	client := NewClient(nil).WithBaseURL(api.URL).WithRDAPFallback(rdap.URL)
+
+	abuse, err := client.AbuseContact(context.Background(), "203.0.113.7")
+	require.NoError(t, err)
+	assert.Equal(t, "noc@example.com", *abuse.Email)
+
+	api, _ = rdapServers(t, nil, http.StatusOK)
+	_, err = NewClient(nil).WithBaseURL(api.URL).AbuseContact(context.Background(), "203.0.113.7")
+	assert.ErrorIs(t, err, ErrNoAbuseContact, "RDAP is opt-in")
+}
+
+func TestAbuseContact_RDAPErrors(t *testing.T) {
+	api, rdap := rdapServers(t, nil, http.StatusNotFound)
+	_, err := NewClient(nil).WithBaseURL(api.URL).WithRDAPFallback(rdap.URL).AbuseContact(context.Background(), "203.0.113.7")
+	assert.EqualError(t, err, "RDAP request failed (404)")
+
+	empty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"entities": [{"roles": ["registrant"]}]}`))
+	}))
+	defer empty.Close()
+	_, err = NewClient(nil).WithBaseURL(api.URL).WithRDAPFallback(empty.URL).AbuseContact(context.Background(), "203.0.113.7")
+	assert.True(t, errors.Is(err, ErrNoAbuseContact))
+}
+
+func TestRDAPNetwork_Range(t *testing.T) {
+	var network rdapNetwork
+	require.NoError(t, json.NewDecoder(strings.NewReader(`{"startAddress": "2001:db8::", "endAddress": "2001:db8::ffff"}`)).Decode(&network))
+	assert.Equal(t, "2001:db8:: - 2001:db8::ffff", network.network())
+}
+
+func TestWithRDAPFallback_Default(t *testing.T) {
+	assert.Equal(t, DefaultRDAPURL, NewClient(nil).WithRDAPFallback("").rdapURL)
+}
@@ -0,0 +1,69 @@
+package iplocate
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultReverseDNSTimeout bounds the PTR lookups made by WithReverseDNS
+	DefaultReverseDNSTimeout = 2 * time.Second
+	// DefaultReverseDNSCacheTTL is how long WithReverseDNS caches hostnames
+	DefaultReverseDNSCacheTTL = time.Hour
+)
+
+// reverseDNS resolves and caches the hostnames of looked up IPs
+type reverseDNS struct {
+	timeout    time.Duration
+	cache      Cache
+	ttl        time.Duration
+	lookupAddr func(ctx context.Context, addr string) ([]string, error)
+}
+
+// WithReverseDNS looks up the PTR record of every IP concurrently with the
+// API request and sets the hostname it names as the response's Hostname.
+// PTR lookups are bounded by DefaultReverseDNSTimeout; when they time out
+// or find nothing, Hostname is left nil. Hostnames, and their absence, are
+// cached in memory for DefaultReverseDNSCacheTTL.
+func (c *Client) WithReverseDNS() *Client {
+	c.rdns = &reverseDNS{
+		timeout:    DefaultReverseDNSTimeout,
+		cache:      NewMemoryCache(0),
+		ttl:        DefaultReverseDNSCacheTTL,
+		lookupAddr: net.DefaultResolver.LookupAddr,
+	}
+	return c
+}
+
+// start begins resolving ip and returns a function waiting for the result
+func (r *reverseDNS) start(ctx context.Context, ip string) func() *string {
+	key := "ptr:" + ip
+	if name, ok := r.cache.Get(key); ok {
+		return func() *string { return stringValue(string(name)) }
+	}
+
+	done := make(chan *string, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+
+		names, err := r.lookupAddr(ctx, ip)
+		var dnsErr *net.DNSError
+		switch {
+		case err == nil && len(names) > 0:
+			name := strings.TrimSuffix(names[0], ".")
+			r.cache.Set(key, []byte(name), r.ttl)
+			done <- &name
+		case err == nil || (errors.As(err, &dnsErr) && dnsErr.IsNotFound):
+			r.cache.Set(key, nil, r.ttl)
+			done <- nil
+		default:
+			// Timeouts and resolver failures are retried on the next lookup
+			done <- nil
+		}
+	}()
+	return func() *string { return <-done }
+}
@@ -0,0 +1,102 @@
+package iplocate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func reverseDNSClient(t *testing.T, lookupAddr func(ctx context.Context, addr string) ([]string, error)) *Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8"})
+	}))
+	t.Cleanup(server.Close)
+	client := NewClient(nil).WithBaseURL(server.URL).WithReverseDNS()
+	client.rdns.lookupAddr = lookupAddr
+	return client
+}
+
+func TestWithReverseDNS(t *testing.T) {
+	var calls int32
+	client := reverseDNSClient(t, func(ctx context.Context, addr string) ([]string, error) {
+		atomic.AddInt32(&calls, 1)
+		assert.Equal(t, "8.8.8.8", addr)
+		return []string{"dns.google."}, nil
+	})
+
+	for i := 0; i < 2; i++ {
+		result, err := client.Lookup("8.8.8.8")
+		require.NoError(t, err)
+		require.NotNil(t, result.Hostname)
+		assert.Equal(t, "dns.google", *result.Hostname)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "hostnames are cached")
+}
+
+func TestWithReverseDNS_NotFound(t *testing.T) {
+	var calls int32
+	client := reverseDNSClient(t, func(ctx context.Context, addr string) ([]string, error) {
+		atomic.AddInt32(&calls, 1)
+		return nil, &net.DNSError{Err: "no such host", Name: addr, IsNotFound: true}
+	})
+
+	for i := 0; i < 2; i++ {
+		result, err := client.Lookup("8.8.8.8")
+		require.NoError(t, err)
+		assert.Nil(t, result.Hostname)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "missing PTR records are cached")
+}
+
+func TestWithReverseDNS_Timeout(t *testing.T) {
+	var calls int32
+	client := reverseDNSClient(t, func(ctx context.Context, addr string) ([]string, error) {
+		atomic.AddInt32(&calls, 1)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	client.rdns.timeout = 10 * time.Millisecond
+
+	start := time.Now()
+	result, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Nil(t, result.Hostname)
+	assert.Less(t, time.Since(start), time.Second)
+
+	_, err = client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "timeouts are not cached")
+}
+
+func TestWithReverseDNS_LookupFails(t *testing.T) {
+	client := reverseDNSClient(t, func(ctx context.Context, addr string) ([]string, error) {
+		return nil, errors.New("unreachable")
+	})
+	_, err := client.Lookup("invalid")
+	assert.Error(t, err)
+}
+
+func TestWithReverseDNS_PrivacyStrict(t *testing.T) {
+	var events []LookupEvent
+	client := reverseDNSClient(t, func(ctx context.Context, addr string) ([]string, error) {
+		return []string{"host-8-8-8-8.example.net."}, nil
+	})
+	client.WithPrivacyMode(PrivacyStrict).WithEventSink(EventSinkFunc(func(ctx context.Context, event LookupEvent) {
+		events = append(events, event)
+	}))
+
+	result, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "host-8-8-8-8.example.net", *result.Hostname)
+	require.Len(t, events, 1)
+	assert.Nil(t, events[0].Response.Hostname)
+}
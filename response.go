@@ -0,0 +1,147 @@
+package iplocate
+
+import (
+	"strings"
+
+	"github.com/iplocate/go-iplocate/countries"
+)
+
+// IsAnonymizing reports whether the IP is a VPN, proxy, Tor exit node or iCloud Private Relay
+func (r *LookupResponse) IsAnonymizing() bool {
+	p := r.Privacy
+	return p.IsVPN || p.IsProxy || p.IsTor || p.IsIcloudRelay
+}
+
+// Flags returns the names of the flags that are set, in alphabetical order:
+// "abuser", "anonymous", "bogon", "hosting", "icloud_relay", "proxy", "tor"
+// and "vpn"
+func (p Privacy) Flags() []string {
+	var flags []string
+	for _, flag := range []struct {
+		name string
+		set  bool
+	}{
+		{"abuser", p.IsAbuser},
+		{"anonymous", p.IsAnonymous},
+		{"bogon", p.IsBogon},
+		{"hosting", p.IsHosting},
+		{"icloud_relay", p.IsIcloudRelay},
+		{"proxy", p.IsProxy},
+		{"tor", p.IsTor},
+		{"vpn", p.IsVPN},
+	} {
+		if flag.set {
+			flags = append(flags, flag.name)
+		}
+	}
+	return flags
+}
+
+// InEU reports whether the IP is located in a European Union member state
+func (r *LookupResponse) InEU() bool {
+	return r.IsEU
+}
+
+// InCountry reports whether the IP is located in any of the given ISO 3166-1
+// alpha-2 country codes. Codes are compared case-insensitively.
+func (r *LookupResponse) InCountry(codes ...string) bool {
+	if r.CountryCode == nil {
+		return false
+	}
+	for _, code := range codes {
+		if strings.EqualFold(code, *r.CountryCode) {
+			return true
+		}
+	}
+	return false
+}
+
+// InASN reports whether the IP belongs to any of the given autonomous systems.
+// ASNs may be given with or without the "AS" prefix, e.g. "AS15169" or "15169".
+func (r *LookupResponse) InASN(asns ...string) bool {
+	if r.ASN == nil || r.ASN.ASN == "" {
+		return false
+	}
+	own := normalizeASN(r.ASN.ASN)
+	for _, asn := range asns {
+		if normalizeASN(asn) == own {
+			return true
+		}
+	}
+	return false
+}
+
+// Coordinates returns the latitude and longitude of the IP.
+// ok is false if either coordinate is unavailable.
+func (r *LookupResponse) Coordinates() (lat, lon float64, ok bool) {
+	if r.Latitude == nil || r.Longitude == nil {
+		return 0, 0, false
+	}
+	return *r.Latitude, *r.Longitude, true
+}
+
+// CountryInfo returns the offline metadata of the IP's country, such as its
+// localized names, flag emoji and currency symbol. ok is false if the
+// response has no known country code.
+func (r *LookupResponse) CountryInfo() (info countries.Country, ok bool) {
+	if r.CountryCode == nil {
+		return countries.Country{}, false
+	}
+	return countries.Lookup(*r.CountryCode)
+}
+
+// Fill sets the fields r is missing from src and adds the privacy flags src
+// sets, e.g. to complete an API answer with a local database record. Nested
+// objects are copied as a whole: r.ASN is only set from src if it is nil.
+func (r *LookupResponse) Fill(src *LookupResponse) {
+	fill := func(dst **string, src *string) {
+		if *dst == nil {
+			*dst = src
+		}
+	}
+	fill(&r.Hostname, src.Hostname)
+	fill(&r.Country, src.Country)
+	if r.CountryCode == nil {
+		r.CountryCode = src.CountryCode
+		r.IsEU = src.IsEU
+	}
+	fill(&r.City, src.City)
+	fill(&r.Continent, src.Continent)
+	if r.Latitude == nil && r.Longitude == nil {
+		r.Latitude, r.Longitude = src.Latitude, src.Longitude
+	}
+	fill(&r.TimeZone, src.TimeZone)
+	fill(&r.PostalCode, src.PostalCode)
+	fill(&r.Subdivision, src.Subdivision)
+	fill(&r.CurrencyCode, src.CurrencyCode)
+	fill(&r.CallingCode, src.CallingCode)
+	fill(&r.Network, src.Network)
+	if r.ASN == nil {
+		r.ASN = src.ASN
+	}
+	if r.Company == nil {
+		r.Company = src.Company
+	}
+	if r.Hosting == nil {
+		r.Hosting = src.Hosting
+	}
+	if r.Abuse == nil {
+		r.Abuse = src.Abuse
+	}
+
+	p, q := &r.Privacy, src.Privacy
+	p.IsAbuser = p.IsAbuser || q.IsAbuser
+	p.IsAnonymous = p.IsAnonymous || q.IsAnonymous
+	p.IsBogon = p.IsBogon || q.IsBogon
+	p.IsHosting = p.IsHosting || q.IsHosting
+	p.IsIcloudRelay = p.IsIcloudRelay || q.IsIcloudRelay
+	p.IsProxy = p.IsProxy || q.IsProxy
+	p.IsTor = p.IsTor || q.IsTor
+	p.IsVPN = p.IsVPN || q.IsVPN
+}
+
+// normalizeASN strips whitespace and the optional "AS" prefix
+func normalizeASN(asn string) string {
+	asn = strings.ToUpper(strings.TrimSpace(asn))
+	return strings.TrimPrefix(asn, "AS")
+}
@@ -0,0 +1,124 @@
+package iplocate
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsAnonymizing(t *testing.T) {
+	resp := &LookupResponse{}
+	assert.False(t, resp.IsAnonymizing())
+
+	resp.Privacy.IsHosting = true
+	assert.False(t, resp.IsAnonymizing())
+
+	for _, set := range []func(*Privacy){
+		func(p *Privacy) { p.IsVPN = true },
+		func(p *Privacy) { p.IsProxy = true },
+		func(p *Privacy) { p.IsTor = true },
+		func(p *Privacy) { p.IsIcloudRelay = true },
+	} {
+		resp := &LookupResponse{}
+		set(&resp.Privacy)
+		assert.True(t, resp.IsAnonymizing())
+	}
+}
+
+func TestPrivacyFlags(t *testing.T) {
+	assert.Empty(t, Privacy{}.Flags())
+	assert.Equal(t, []string{"hosting", "tor", "vpn"}, Privacy{IsVPN: true, IsTor: true, IsHosting: true}.Flags())
+}
+
+func TestInEU(t *testing.T) {
+	assert.False(t, (&LookupResponse{}).InEU())
+	assert.True(t, (&LookupResponse{IsEU: true}).InEU())
+}
+
+func TestInCountry(t *testing.T) {
+	resp := &LookupResponse{}
+	assert.False(t, resp.InCountry("US"))
+
+	resp.CountryCode = stringPtr("US")
+	assert.True(t, resp.InCountry("US"))
+	assert.True(t, resp.InCountry("CA", "us"))
+	assert.False(t, resp.InCountry("CA", "MX"))
+	assert.False(t, resp.InCountry())
+}
+
+func TestInASN(t *testing.T) {
+	resp := &LookupResponse{}
+	assert.False(t, resp.InASN("AS15169"))
+
+	resp.ASN = &ASN{ASN: "AS15169"}
+	assert.True(t, resp.InASN("AS15169"))
+	assert.True(t, resp.InASN("15169"))
+	assert.True(t, resp.InASN("AS13335", "as15169"))
+	assert.False(t, resp.InASN("AS13335"))
+}
+
+func TestCoordinates(t *testing.T) {
+	resp := &LookupResponse{Latitude: float64Ptr(37.386)}
+	_, _, ok := resp.Coordinates()
+	assert.False(t, ok)
+
+	resp.Longitude = float64Ptr(-122.0838)
+	lat, lon, ok := resp.Coordinates()
+	assert.True(t, ok)
+	assert.Equal(t, 37.386, lat)
+	assert.Equal(t, -122.0838, lon)
+}
+
+func TestCountryInfo(t *testing.T) {
+	_, ok := (&LookupResponse{}).CountryInfo()
+	assert.False(t, ok)
+
+	info, ok := (&LookupResponse{CountryCode: stringPtr("JP")}).CountryInfo()
+	assert.True(t, ok)
+	assert.Equal(t, "Japan", info.Name())
+	assert.Equal(t, "Japon", info.LocalizedName("fr"))
+	assert.Equal(t, "🇯🇵", info.Flag())
+	assert.Equal(t, "¥", info.CurrencySymbol())
+}
+
+// filledResponse returns a response with every field but IP set
+func filledResponse() *LookupResponse {
+	var fill func(v reflect.Value)
+	fill = func(v reflect.Value) {
+		switch v.Kind() {
+		case reflect.Pointer:
+			v.Set(reflect.New(v.Type().Elem()))
+			fill(v.Elem())
+		case reflect.Struct:
+			for i := 0; i < v.NumField(); i++ {
+				fill(v.Field(i))
+			}
+		case reflect.String:
+			v.SetString("x")
+		case reflect.Bool:
+			v.SetBool(true)
+		case reflect.Float64:
+			v.SetFloat(1)
+		}
+	}
+	resp := &LookupResponse{}
+	fill(reflect.ValueOf(resp).Elem())
+	resp.IP = ""
+	return resp
+}
+
+func TestFill(t *testing.T) {
+	src := filledResponse()
+	dst := &LookupResponse{}
+	dst.Fill(src)
+	assert.Equal(t, src, dst, "every field is filled")
+
+	dst = &LookupResponse{IP: "8.8.8.8", CountryCode: stringPtr("US"), ASN: &ASN{ASN: "AS15169"}}
+	dst.Fill(&LookupResponse{CountryCode: stringPtr("DE"), IsEU: true, City: stringPtr("Berlin"), ASN: &ASN{ASN: "AS3320"}, Privacy: Privacy{IsVPN: true}})
+	assert.Equal(t, "US", *dst.CountryCode)
+	assert.False(t, dst.IsEU, "IsEU goes with the country code")
+	assert.Equal(t, "Berlin", *dst.City)
+	assert.Equal(t, "AS15169", dst.ASN.ASN)
+	assert.True(t, dst.Privacy.IsVPN)
+}
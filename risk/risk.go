@@ -0,0 +1,192 @@
+// Package risk computes a 0-100 risk score from IPLocate lookup results.
+// The score combines privacy flags, the ASN type and hosting information
+// using configurable weights.
+package risk
+
+import (
+	"sort"
+
+	"github.com/iplocate/go-iplocate"
+)
+
+// MaxScore is the highest score a lookup can receive
+const MaxScore = 100
+
+// Level is a coarse risk classification derived from a score
+type Level int
+
+const (
+	// Low risk: the score is below the medium threshold
+	Low Level = iota
+	// Medium risk: the score is at or above the medium threshold
+	Medium
+	// High risk: the score is at or above the high threshold
+	High
+)
+
+// String returns the lowercase name of the level
+func (l Level) String() string {
+	switch l {
+	case Low:
+		return "low"
+	case Medium:
+		return "medium"
+	case High:
+		return "high"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText encodes the level as its name
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// Weights controls how much each signal contributes to the score.
+// A weight of zero disables the signal.
+type Weights struct {
+	Abuser      int `json:"abuser"`
+	Anonymous   int `json:"anonymous"`
+	Bogon       int `json:"bogon"`
+	Hosting     int `json:"hosting"`
+	IcloudRelay int `json:"icloud_relay"`
+	Proxy       int `json:"proxy"`
+	Tor         int `json:"tor"`
+	VPN         int `json:"vpn"`
+	// HostingProvider applies when the response identifies a hosting provider
+	HostingProvider int `json:"hosting_provider"`
+	// ASNTypes maps an ASN type (e.g. "hosting", "isp") to a weight
+	ASNTypes map[string]int `json:"asn_types"`
+}
+
+// DefaultWeights returns the weights used by Score
+func DefaultWeights() Weights {
+	return Weights{
+		Abuser:          60,
+		Anonymous:       20,
+		Bogon:           30,
+		Hosting:         15,
+		IcloudRelay:     10,
+		Proxy:           35,
+		Tor:             50,
+		VPN:             30,
+		HostingProvider: 10,
+		ASNTypes: map[string]int{
+			"hosting": 15,
+		},
+	}
+}
+
+// Thresholds are the minimum scores for the medium and high levels
+type Thresholds struct {
+	Medium int `json:"medium"`
+	High   int `json:"high"`
+}
+
+// DefaultThresholds returns the thresholds used by Score
+func DefaultThresholds() Thresholds {
+	return Thresholds{Medium: 30, High: 70}
+}
+
+// Result is the outcome of scoring a lookup
+type Result struct {
+	// Score is between 0 and MaxScore
+	Score int `json:"score"`
+	// Level is the classification of Score against the scorer's thresholds
+	Level Level `json:"level"`
+	// Factors lists the signals that contributed to the score, sorted by name
+	Factors []string `json:"factors"`
+}
+
+// IsHighRisk reports whether the score reached the high threshold
+func (r Result) IsHighRisk() bool {
+	return r.Level == High
+}
+
+// IsMediumRisk reports whether the score reached the medium but not the high threshold
+func (r Result) IsMediumRisk() bool {
+	return r.Level == Medium
+}
+
+// IsLowRisk reports whether the score is below the medium threshold
+func (r Result) IsLowRisk() bool {
+	return r.Level == Low
+}
+
+// Scorer scores lookups with a fixed set of weights and thresholds
+type Scorer struct {
+	weights    Weights
+	thresholds Thresholds
+}
+
+// NewScorer creates a scorer with the given weights and thresholds
+func NewScorer(weights Weights, thresholds Thresholds) *Scorer {
+	return &Scorer{
+		weights:    weights,
+		thresholds: thresholds,
+	}
+}
+
+var defaultScorer = NewScorer(DefaultWeights(), DefaultThresholds())
+
+// Score scores the lookup using DefaultWeights and DefaultThresholds
+func Score(resp *iplocate.LookupResponse) Result {
+	return defaultScorer.Score(resp)
+}
+
+// Score computes the risk score for the lookup. A nil response scores zero.
+func (s *Scorer) Score(resp *iplocate.LookupResponse) Result {
+	if resp == nil {
+		return Result{Level: Low, Factors: []string{}}
+	}
+
+	score := 0
+	factors := []string{}
+	add := func(name string, set bool, weight int) {
+		if set && weight != 0 {
+			score += weight
+			factors = append(factors, name)
+		}
+	}
+
+	p := resp.Privacy
+	add("abuser", p.IsAbuser, s.weights.Abuser)
+	add("anonymous", p.IsAnonymous, s.weights.Anonymous)
+	add("bogon", p.IsBogon, s.weights.Bogon)
+	add("hosting", p.IsHosting, s.weights.Hosting)
+	add("icloud_relay", p.IsIcloudRelay, s.weights.IcloudRelay)
+	add("proxy", p.IsProxy, s.weights.Proxy)
+	add("tor", p.IsTor, s.weights.Tor)
+	add("vpn", p.IsVPN, s.weights.VPN)
+	add("hosting_provider", resp.Hosting != nil && resp.Hosting.Provider != nil, s.weights.HostingProvider)
+	if resp.ASN != nil && resp.ASN.Type != "" {
+		add("asn_type:"+resp.ASN.Type, true, s.weights.ASNTypes[resp.ASN.Type])
+	}
+
+	if score < 0 {
+		score = 0
+	}
+	if score > MaxScore {
+		score = MaxScore
+	}
+	sort.Strings(factors)
+
+	return Result{
+		Score:   score,
+		Level:   s.level(score),
+		Factors: factors,
+	}
+}
+
+// level classifies a score against the scorer's thresholds
+func (s *Scorer) level(score int) Level {
+	switch {
+	case score >= s.thresholds.High:
+		return High
+	case score >= s.thresholds.Medium:
+		return Medium
+	default:
+		return Low
+	}
+}
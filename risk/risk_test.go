@@ -0,0 +1,76 @@
+package risk
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/iplocate/go-iplocate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestScore_Clean(t *testing.T) {
+	resp := &iplocate.LookupResponse{
+		IP:  "203.0.113.1",
+		ASN: &iplocate.ASN{ASN: "AS64496", Type: "isp"},
+	}
+
+	result := Score(resp)
+	assert.Equal(t, 0, result.Score)
+	assert.Equal(t, Low, result.Level)
+	assert.True(t, result.IsLowRisk())
+	assert.Empty(t, result.Factors)
+}
+
+func TestScore_HostingVPN(t *testing.T) {
+	resp := &iplocate.LookupResponse{
+		IP:      "203.0.113.1",
+		ASN:     &iplocate.ASN{ASN: "AS64496", Type: "hosting"},
+		Privacy: iplocate.Privacy{IsVPN: true, IsHosting: true, IsAnonymous: true},
+		Hosting: &iplocate.Hosting{Provider: stringPtr("Example Cloud")},
+	}
+
+	result := Score(resp)
+	assert.Equal(t, 30+15+20+10+15, result.Score)
+	assert.True(t, result.IsHighRisk())
+	assert.Equal(t, []string{"anonymous", "asn_type:hosting", "hosting", "hosting_provider", "vpn"}, result.Factors)
+}
+
+func TestScore_Capped(t *testing.T) {
+	resp := &iplocate.LookupResponse{
+		Privacy: iplocate.Privacy{IsAbuser: true, IsTor: true, IsProxy: true},
+	}
+
+	result := Score(resp)
+	assert.Equal(t, MaxScore, result.Score)
+	assert.Equal(t, High, result.Level)
+}
+
+func TestScore_Nil(t *testing.T) {
+	result := Score(nil)
+	assert.Equal(t, 0, result.Score)
+	assert.True(t, result.IsLowRisk())
+}
+
+func TestScorer_CustomWeights(t *testing.T) {
+	scorer := NewScorer(Weights{VPN: 50}, Thresholds{Medium: 40, High: 80})
+	resp := &iplocate.LookupResponse{
+		Privacy: iplocate.Privacy{IsVPN: true, IsTor: true},
+	}
+
+	result := scorer.Score(resp)
+	assert.Equal(t, 50, result.Score)
+	assert.Equal(t, []string{"vpn"}, result.Factors)
+	assert.True(t, result.IsMediumRisk())
+	assert.False(t, result.IsHighRisk())
+}
+
+func TestResult_JSON(t *testing.T) {
+	data, err := json.Marshal(Result{Score: 75, Level: High, Factors: []string{"tor"}})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"score":75,"level":"high","factors":["tor"]}`, string(data))
+}
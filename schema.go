@@ -0,0 +1,180 @@
+package iplocate
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// schemaDescriptions describes each field of LookupResponse by JSON path
+var schemaDescriptions = map[string]string{
+	"":                        "IP geolocation and threat intelligence data from the IPLocate.io API",
+	"ip":                      "The looked up IP address",
+	"hostname":                "Reverse DNS name of the IP, set by the client rather than the API",
+	"country":                 "Country name in English, or in the requested language",
+	"country_code":            "ISO 3166-1 alpha-2 country code",
+	"is_eu":                   "Whether the country is a member of the European Union",
+	"city":                    "City name",
+	"continent":               "Continent name",
+	"latitude":                "Approximate latitude in decimal degrees",
+	"longitude":               "Approximate longitude in decimal degrees",
+	"time_zone":               "IANA time zone, such as America/New_York",
+	"postal_code":             "Postal or ZIP code",
+	"subdivision":             "First-level subdivision, such as a state or region",
+	"currency_code":           "ISO 4217 code of the country's currency",
+	"calling_code":            "International calling code of the country, without the +",
+	"network":                 "Network the IP belongs to, in CIDR notation",
+	"asn":                     "Autonomous system announcing the IP",
+	"asn.asn":                 "Autonomous system number, such as AS15169",
+	"asn.route":               "Announced route containing the IP, in CIDR notation",
+	"asn.netname":             "Network name registered for the route",
+	"asn.name":                "Name of the organization operating the AS",
+	"asn.country_code":        "ISO 3166-1 alpha-2 country code of the AS",
+	"asn.domain":              "Domain of the organization operating the AS",
+	"asn.type":                "Type of the AS, such as isp, hosting, business or education",
+	"asn.rir":                 "Regional internet registry of the AS, such as ARIN or RIPE",
+	"privacy":                 "Threat and anonymity flags of the IP",
+	"privacy.is_abuser":       "Whether the IP is known for abusive activity",
+	"privacy.is_anonymous":    "Whether the IP hides its user, through a VPN, proxy, Tor or relay",
+	"privacy.is_bogon":        "Whether the IP is a bogon: reserved, private or unallocated",
+	"privacy.is_hosting":      "Whether the IP belongs to a hosting or cloud provider",
+	"privacy.is_icloud_relay": "Whether the IP is an iCloud Private Relay egress address",
+	"privacy.is_proxy":        "Whether the IP is an open or commercial proxy",
+	"privacy.is_tor":          "Whether the IP is a Tor exit node",
+	"privacy.is_vpn":          "Whether the IP belongs to a VPN service",
+	"company":                 "Company the IP is assigned to",
+	"company.name":            "Company name",
+	"company.domain":          "Company domain",
+	"company.country_code":    "ISO 3166-1 alpha-2 country code of the company",
+	"company.type":            "Type of the company, such as isp, hosting, business or education",
+	"hosting":                 "Hosting or cloud provider details, for hosting IPs",
+	"hosting.provider":        "Name of the hosting provider",
+	"hosting.domain":          "Domain of the hosting provider",
+	"hosting.network":         "Network of the provider containing the IP, in CIDR notation",
+	"hosting.region":          "Cloud region, such as us-east-1",
+	"hosting.service":         "Cloud service, such as EC2",
+	"abuse":                   "Abuse contact for the network",
+	"abuse.address":           "Postal address of the abuse contact",
+	"abuse.country_code":      "ISO 3166-1 alpha-2 country code of the abuse contact",
+	"abuse.email":             "Email address to report abuse to",
+	"abuse.name":              "Name of the abuse contact",
+	"abuse.network":           "Network the abuse contact is responsible for, in CIDR notation",
+	"abuse.phone":             "Phone number of the abuse contact",
+}
+
+// schemaFormats adds validation keywords to fields by JSON path
+var schemaFormats = map[string]map[string]any{
+	"ip":           {"anyOf": []any{map[string]any{"format": "ipv4"}, map[string]any{"format": "ipv6"}}},
+	"country_code": {"pattern": "^[A-Z]{2}$"},
+	"latitude":     {"minimum": -90, "maximum": 90},
+	"longitude":    {"minimum": -180, "maximum": 180},
+	"abuse.email":  {"format": "email"},
+}
+
+var schema = sync.OnceValue(func() []byte {
+	root := objectSchema(reflect.TypeOf(LookupResponse{}), "")
+	root["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	root["title"] = "LookupResponse"
+	data, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	return data
+})
+
+// Schema returns a JSON Schema (draft 2020-12) of LookupResponse as encoded
+// to JSON, for validation layers, data catalogs and ETL tools that generate
+// models from schemas. Fields the API may not know are nullable; fields
+// always present in the encoding are required.
+func Schema() []byte {
+	return append([]byte(nil), schema()...)
+}
+
+// objectSchema returns the schema of a struct type, with path the JSON path
+// of the struct
+func objectSchema(t reflect.Type, path string) map[string]any {
+	properties := make(map[string]any)
+	required := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, omitempty, ok := jsonFieldName(field)
+		if !ok {
+			continue
+		}
+		fieldPath := name
+		if path != "" {
+			fieldPath = path + "." + name
+		}
+		properties[name] = fieldSchema(field.Type, fieldPath)
+		if !omitempty {
+			required = append(required, name)
+		}
+	}
+	s := map[string]any{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+	if description, ok := schemaDescriptions[path]; ok {
+		s["description"] = description
+	}
+	return s
+}
+
+// fieldSchema returns the schema of a field type. Pointers are nullable.
+func fieldSchema(t reflect.Type, path string) map[string]any {
+	nullable := t.Kind() == reflect.Pointer
+	if nullable {
+		t = t.Elem()
+	}
+
+	var s map[string]any
+	var typeName string
+	switch t.Kind() {
+	case reflect.Struct:
+		s = objectSchema(t, path)
+		typeName = "object"
+	case reflect.Bool:
+		typeName = "boolean"
+	case reflect.Float32, reflect.Float64:
+		typeName = "number"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		typeName = "integer"
+	default:
+		typeName = "string"
+	}
+	if s == nil {
+		s = map[string]any{}
+		if description, ok := schemaDescriptions[path]; ok {
+			s["description"] = description
+		}
+	}
+	if nullable {
+		s["type"] = []string{typeName, "null"}
+	} else {
+		s["type"] = typeName
+	}
+	for keyword, value := range schemaFormats[path] {
+		s[keyword] = value
+	}
+	return s
+}
+
+// jsonFieldName returns the JSON name of a struct field and whether it is
+// omitted when empty, or false if the field isn't encoded
+func jsonFieldName(field reflect.StructField) (name string, omitempty bool, ok bool) {
+	if !field.IsExported() {
+		return "", false, false
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, false
+	}
+	name, options, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name, strings.Contains(","+options+",", ",omitempty,"), true
+}
@@ -0,0 +1,119 @@
+package iplocate
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseSchema(t *testing.T) map[string]any {
+	var schema map[string]any
+	require.NoError(t, json.Unmarshal(Schema(), &schema))
+	return schema
+}
+
+// schemaProperty returns the schema of the field at a JSON path like "asn.name"
+func schemaProperty(schema map[string]any, path string) map[string]any {
+	for _, name := range strings.Split(path, ".") {
+		properties, _ := schema["properties"].(map[string]any)
+		schema, _ = properties[name].(map[string]any)
+	}
+	return schema
+}
+
+func TestSchema(t *testing.T) {
+	schema := parseSchema(t)
+	assert.Equal(t, "https://json-schema.org/draft/2020-12/schema", schema["$schema"])
+	assert.Equal(t, "LookupResponse", schema["title"])
+	assert.Equal(t, "object", schema["type"])
+
+	for _, path := range append([]string{"hostname"}, DefaultCSVColumns...) {
+		property := schemaProperty(schema, path)
+		require.NotNil(t, property, path)
+		assert.NotEmpty(t, property["description"], path)
+	}
+
+	assert.Equal(t, "string", schemaProperty(schema, "ip")["type"])
+	assert.Equal(t, []any{"string", "null"}, schemaProperty(schema, "country")["type"])
+	assert.Equal(t, []any{"number", "null"}, schemaProperty(schema, "latitude")["type"])
+	assert.Equal(t, float64(-90), schemaProperty(schema, "latitude")["minimum"])
+	assert.Equal(t, "boolean", schemaProperty(schema, "is_eu")["type"])
+	assert.Equal(t, []any{"object", "null"}, schemaProperty(schema, "asn")["type"])
+	assert.Equal(t, "string", schemaProperty(schema, "asn.name")["type"])
+	assert.Equal(t, "object", schemaProperty(schema, "privacy")["type"])
+	assert.Equal(t, "boolean", schemaProperty(schema, "privacy.is_vpn")["type"])
+	assert.Equal(t, []any{"string", "null"}, schemaProperty(schema, "hosting.provider")["type"])
+
+	required := schema["required"].([]any)
+	assert.Contains(t, required, "ip")
+	assert.Contains(t, required, "privacy")
+	assert.NotContains(t, required, "hostname", "hostname is omitted when empty")
+}
+
+func TestSchema_ReturnsCopy(t *testing.T) {
+	data := Schema()
+	data[0] = 'x'
+	assert.True(t, json.Valid(Schema()))
+}
+
+func TestSchema_MatchesEncoding(t *testing.T) {
+	resp := &LookupResponse{
+		IP:          "203.0.113.7",
+		Hostname:    stringPtr("host.example.com"),
+		CountryCode: stringPtr("US"),
+		Latitude:    float64Ptr(47.6),
+		ASN:         &ASN{ASN: "AS64500", Name: "Example Hosting"},
+		Privacy:     Privacy{IsHosting: true},
+		Company:     &Company{Name: "Example"},
+		Hosting:     &Hosting{Provider: stringPtr("Example Cloud")},
+		Abuse:       &Abuse{Email: stringPtr("abuse@example.com")},
+	}
+	data, err := json.Marshal(resp)
+	require.NoError(t, err)
+	var encoded map[string]any
+	require.NoError(t, json.Unmarshal(data, &encoded))
+
+	assertMatchesSchema(t, parseSchema(t), encoded, "")
+}
+
+// assertMatchesSchema checks that every field of an encoded object is in the
+// schema with a matching type, and that every required field is present
+func assertMatchesSchema(t *testing.T, schema, object map[string]any, path string) {
+	properties := schema["properties"].(map[string]any)
+	for _, name := range schema["required"].([]any) {
+		assert.Contains(t, object, name, path)
+	}
+	for name, value := range object {
+		property, ok := properties[name].(map[string]any)
+		if !assert.True(t, ok, "%s.%s is not in the schema", path, name) {
+			continue
+		}
+		types := []any{property["type"]}
+		if list, ok := property["type"].([]any); ok {
+			types = list
+		}
+		assert.Contains(t, types, jsonType(value), "%s.%s", path, name)
+		if nested, ok := value.(map[string]any); ok {
+			assertMatchesSchema(t, property, nested, path+"."+name)
+		}
+	}
+}
+
+func jsonType(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case map[string]any:
+		return "object"
+	}
+	return "array"
+}
@@ -0,0 +1,169 @@
+// Package siem formats IPLocate lookup results for SIEM pipelines: as CEF
+// events for ArcSight and as LEEF events for QRadar. Each event carries the
+// location, network, ASN and privacy flags of the looked up IP. SyslogSink
+// forwards every lookup a client makes to a syslog collector.
+package siem
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/iplocate/go-iplocate"
+)
+
+// Header holds the header fields of a CEF or LEEF event
+type Header struct {
+	Vendor  string
+	Product string
+	Version string
+	// EventID is the CEF signature ID or the LEEF event ID
+	EventID string
+	// Name is the CEF event name. LEEF has no name field.
+	Name string
+	// Severity is the CEF severity, from 0 to 10. LEEF sets sev as an attribute.
+	Severity int
+}
+
+// DefaultHeader identifies events as IP enrichments made by this module
+var DefaultHeader = Header{
+	Vendor:   "IPLocate",
+	Product:  "go-iplocate",
+	Version:  iplocate.Version,
+	EventID:  "ip-lookup",
+	Name:     "IP address enrichment",
+	Severity: 1,
+}
+
+// CEFExtension returns the CEF extension of a lookup: src, slat and slong,
+// plus custom strings (cs1 to cs6, each with its label) for the country
+// code, city, ASN, ASN name, network and privacy flags. Fields the lookup
+// doesn't carry are left out.
+func CEFExtension(resp *iplocate.LookupResponse) string {
+	var pairs []string
+	add := func(key, value string) {
+		if value != "" {
+			pairs = append(pairs, key+"="+escapeCEFValue(value))
+		}
+	}
+	custom := func(n int, label, value string) {
+		if value != "" {
+			add("cs"+strconv.Itoa(n)+"Label", label)
+			add("cs"+strconv.Itoa(n), value)
+		}
+	}
+
+	add("src", resp.IP)
+	if lat, lon, ok := resp.Coordinates(); ok {
+		add("slat", formatFloat(lat))
+		add("slong", formatFloat(lon))
+	}
+	custom(1, "countryCode", deref(resp.CountryCode))
+	custom(2, "city", deref(resp.City))
+	if resp.ASN != nil {
+		custom(3, "asn", resp.ASN.ASN)
+		custom(4, "asnName", resp.ASN.Name)
+	}
+	custom(5, "network", deref(resp.Network))
+	custom(6, "privacyFlags", strings.Join(resp.Privacy.Flags(), ","))
+	return strings.Join(pairs, " ")
+}
+
+// FormatCEF returns a complete CEF event for a lookup:
+//
+//	CEF:0|Vendor|Product|Version|EventID|Name|Severity|Extension
+func FormatCEF(h Header, resp *iplocate.LookupResponse) string {
+	header := []string{
+		"CEF:0",
+		escapeCEFHeader(h.Vendor),
+		escapeCEFHeader(h.Product),
+		escapeCEFHeader(h.Version),
+		escapeCEFHeader(h.EventID),
+		escapeCEFHeader(h.Name),
+		strconv.Itoa(h.Severity),
+	}
+	return strings.Join(header, "|") + "|" + CEFExtension(resp)
+}
+
+// LEEFAttributes returns the tab separated LEEF attributes of a lookup: src,
+// srcCountry, srcCountryName, srcCity, srcLat, srcLong, srcNetwork, asn,
+// asnName, asnDomain, asnType, and one boolean per privacy flag, e.g. isVPN.
+// Fields the lookup doesn't carry are left out; the flags are always set.
+func LEEFAttributes(resp *iplocate.LookupResponse) string {
+	var pairs []string
+	add := func(key, value string) {
+		if value != "" {
+			pairs = append(pairs, key+"="+escapeLEEFValue(value))
+		}
+	}
+
+	add("src", resp.IP)
+	add("srcCountry", deref(resp.CountryCode))
+	add("srcCountryName", deref(resp.Country))
+	add("srcCity", deref(resp.City))
+	if lat, lon, ok := resp.Coordinates(); ok {
+		add("srcLat", formatFloat(lat))
+		add("srcLong", formatFloat(lon))
+	}
+	add("srcNetwork", deref(resp.Network))
+	if resp.ASN != nil {
+		add("asn", resp.ASN.ASN)
+		add("asnName", resp.ASN.Name)
+		add("asnDomain", resp.ASN.Domain)
+		add("asnType", resp.ASN.Type)
+	}
+
+	p := resp.Privacy
+	add("isAbuser", strconv.FormatBool(p.IsAbuser))
+	add("isAnonymous", strconv.FormatBool(p.IsAnonymous))
+	add("isBogon", strconv.FormatBool(p.IsBogon))
+	add("isHosting", strconv.FormatBool(p.IsHosting))
+	add("isIcloudRelay", strconv.FormatBool(p.IsIcloudRelay))
+	add("isProxy", strconv.FormatBool(p.IsProxy))
+	add("isTor", strconv.FormatBool(p.IsTor))
+	add("isVPN", strconv.FormatBool(p.IsVPN))
+	return strings.Join(pairs, "\t")
+}
+
+// leefDelimiter is the LEEF 2.0 delimiter field declaring tab separated
+// attributes, as a hex character code
+const leefDelimiter = "x09"
+
+// FormatLEEF returns a complete LEEF 2.0 event for a lookup, with the
+// header severity set as the sev attribute and the delimiter field set to
+// tab:
+//
+//	LEEF:2.0|Vendor|Product|Version|EventID|x09|Attributes
+func FormatLEEF(h Header, resp *iplocate.LookupResponse) string {
+	header := []string{
+		"LEEF:2.0",
+		escapeLEEFHeader(h.Vendor),
+		escapeLEEFHeader(h.Product),
+		escapeLEEFHeader(h.Version),
+		escapeLEEFHeader(h.EventID),
+		leefDelimiter,
+	}
+	return strings.Join(header, "|") + "|" + "sev=" + strconv.Itoa(h.Severity) + "\t" + LEEFAttributes(resp)
+}
+
+var (
+	cefHeaderEscaper  = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\n", " ", "\r", " ")
+	cefValueEscaper   = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`)
+	leefHeaderEscaper = strings.NewReplacer(`|`, " ", "\n", " ", "\r", " ")
+	leefValueEscaper  = strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+)
+
+func escapeCEFHeader(s string) string  { return cefHeaderEscaper.Replace(s) }
+func escapeCEFValue(s string) string   { return cefValueEscaper.Replace(s) }
+func escapeLEEFHeader(s string) string { return leefHeaderEscaper.Replace(s) }
+func escapeLEEFValue(s string) string  { return leefValueEscaper.Replace(s) }
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,81 @@
+package siem
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/iplocate/go-iplocate"
+	"github.com/stretchr/testify/assert"
+)
+
+func stringPtr(s string) *string { return &s }
+
+func float64Ptr(f float64) *float64 { return &f }
+
+func testResponse() *iplocate.LookupResponse {
+	return &iplocate.LookupResponse{
+		IP:          "185.220.101.1",
+		Country:     stringPtr("Germany"),
+		CountryCode: stringPtr("DE"),
+		City:        stringPtr("Berlin"),
+		Latitude:    float64Ptr(52.52),
+		Longitude:   float64Ptr(13.405),
+		Network:     stringPtr("185.220.101.0/24"),
+		ASN:         &iplocate.ASN{ASN: "AS60729", Name: "Stiftung Erneuerbare Freiheit", Type: "hosting"},
+		Privacy:     iplocate.Privacy{IsTor: true, IsAnonymous: true},
+	}
+}
+
+func TestCEFExtension(t *testing.T) {
+	assert.Equal(t,
+		"src=185.220.101.1 slat=52.52 slong=13.405 cs1Label=countryCode cs1=DE cs2Label=city cs2=Berlin "+
+			"cs3Label=asn cs3=AS60729 cs4Label=asnName cs4=Stiftung Erneuerbare Freiheit "+
+			"cs5Label=network cs5=185.220.101.0/24 cs6Label=privacyFlags cs6=anonymous,tor",
+		CEFExtension(testResponse()))
+
+	assert.Equal(t, "src=8.8.8.8", CEFExtension(&iplocate.LookupResponse{IP: "8.8.8.8"}))
+}
+
+func TestCEFExtension_Escaping(t *testing.T) {
+	resp := &iplocate.LookupResponse{IP: "8.8.8.8", ASN: &iplocate.ASN{Name: `a=b\c` + "\nd"}}
+	assert.Equal(t, `src=8.8.8.8 cs4Label=asnName cs4=a\=b\\c\nd`, CEFExtension(resp))
+}
+
+func TestFormatCEF(t *testing.T) {
+	h := Header{Vendor: "Acme|Corp", Product: "gateway", Version: "1.0", EventID: "100", Name: "Tor login", Severity: 7}
+	event := FormatCEF(h, &iplocate.LookupResponse{IP: "8.8.8.8"})
+	assert.Equal(t, `CEF:0|Acme\|Corp|gateway|1.0|100|Tor login|7|src=8.8.8.8`, event)
+
+	assert.True(t, strings.HasPrefix(FormatCEF(DefaultHeader, testResponse()), "CEF:0|IPLocate|go-iplocate|"))
+}
+
+func TestLEEFAttributes(t *testing.T) {
+	attrs := strings.Split(LEEFAttributes(testResponse()), "\t")
+	assert.Equal(t, []string{
+		"src=185.220.101.1",
+		"srcCountry=DE",
+		"srcCountryName=Germany",
+		"srcCity=Berlin",
+		"srcLat=52.52",
+		"srcLong=13.405",
+		"srcNetwork=185.220.101.0/24",
+		"asn=AS60729",
+		"asnName=Stiftung Erneuerbare Freiheit",
+		"asnType=hosting",
+		"isAbuser=false",
+		"isAnonymous=true",
+		"isBogon=false",
+		"isHosting=false",
+		"isIcloudRelay=false",
+		"isProxy=false",
+		"isTor=true",
+		"isVPN=false",
+	}, attrs)
+}
+
+func TestFormatLEEF(t *testing.T) {
+	h := Header{Vendor: "Acme", Product: "gateway", Version: "1.0", EventID: "100", Severity: 5}
+	resp := &iplocate.LookupResponse{IP: "8.8.8.8", City: stringPtr("Mountain\tView")}
+	event := FormatLEEF(h, resp)
+	assert.True(t, strings.HasPrefix(event, "LEEF:2.0|Acme|gateway|1.0|100|x09|sev=5\tsrc=8.8.8.8\tsrcCity=Mountain View\t"), event)
+}
@@ -0,0 +1,286 @@
+package siem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iplocate/go-iplocate"
+)
+
+// Syslog facilities
+const (
+	FacilityUser   = 1
+	FacilityDaemon = 3
+	FacilityLocal0 = 16
+)
+
+// Syslog severities used for lookup events
+const (
+	severityError   = 3
+	severityWarning = 4
+	severityInfo    = 6
+)
+
+// DefaultSDID is the structured data ID of lookup events. 32473 is the
+// private enterprise number reserved for documentation; set your own with
+// WithSDID when your collector expects it.
+const DefaultSDID = "iplocate@32473"
+
+// rfc5424Time is the RFC 5424 timestamp format, with microseconds
+const rfc5424Time = "2006-01-02T15:04:05.000000Z07:00"
+
+// DefaultWriteTimeout bounds how long a message written to a connection
+// opened by DialSyslog may block the lookup that emitted it
+const DefaultWriteTimeout = time.Second
+
+// redialDelay is how long messages are dropped after a failed write or dial
+// before the collector is dialed again
+const redialDelay = 5 * time.Second
+
+// errDisconnected is reported for messages dropped while the collector is unreachable
+var errDisconnected = errors.New("not connected to syslog")
+
+// SyslogSink is an iplocate.EventSink that writes each lookup as an RFC 5424
+// syslog message with the results as structured data:
+//
+//	<134>1 2024-01-01T12:00:00.000000Z host app 4242 lookup [iplocate@32473 ip="185.220.101.1" country="DE" ... flags="anonymous,tor"] lookup 185.220.101.1: DE AS60729
+//
+// Lookups of flagged IPs are logged as warnings, failed lookups as errors and
+// all others as informational. It is safe for concurrent use.
+type SyslogSink struct {
+	mu       sync.Mutex
+	w        io.Writer
+	framed   bool
+	facility int
+	hostname string
+	appName  string
+	sdID     string
+	onError  func(error)
+
+	// Set by DialSyslog, which writes to conn and redials after failures
+	network      string
+	addr         string
+	conn         net.Conn
+	writeTimeout time.Duration
+	redialDelay  time.Duration
+	redialAt     time.Time
+	closed       bool
+}
+
+// NewSyslogSink creates a sink writing one newline terminated message per
+// Write call to w, such as a UDP connection or a log file
+func NewSyslogSink(w io.Writer) *SyslogSink {
+	hostname, _ := os.Hostname()
+	return &SyslogSink{
+		w:        w,
+		facility: FacilityLocal0,
+		hostname: hostname,
+		appName:  "iplocate",
+		sdID:     DefaultSDID,
+	}
+}
+
+// DialSyslog connects to a syslog collector at addr. Over "tcp", messages
+// are framed with octet counting (RFC 6587); over "udp", each message is a
+// datagram. Writes time out after DefaultWriteTimeout so a stalled
+// collector doesn't hold up lookups. After a failed write the connection is
+// closed, messages are dropped for five seconds and the collector is then
+// dialed again. Close the sink to close the connection.
+func DialSyslog(network, addr string) (*SyslogSink, error) {
+	conn, err := net.DialTimeout(network, addr, DefaultWriteTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+	s := NewSyslogSink(conn)
+	s.framed = strings.HasPrefix(network, "tcp")
+	s.network = network
+	s.addr = addr
+	s.conn = conn
+	s.writeTimeout = DefaultWriteTimeout
+	s.redialDelay = redialDelay
+	return s, nil
+}
+
+// WithFacility sets the syslog facility, FacilityLocal0 by default
+func (s *SyslogSink) WithFacility(facility int) *SyslogSink {
+	s.facility = facility
+	return s
+}
+
+// WithHostname sets the HOSTNAME field, the local hostname by default
+func (s *SyslogSink) WithHostname(hostname string) *SyslogSink {
+	s.hostname = hostname
+	return s
+}
+
+// WithAppName sets the APP-NAME field, "iplocate" by default
+func (s *SyslogSink) WithAppName(appName string) *SyslogSink {
+	s.appName = appName
+	return s
+}
+
+// WithSDID sets the structured data ID, DefaultSDID by default
+func (s *SyslogSink) WithSDID(id string) *SyslogSink {
+	s.sdID = id
+	return s
+}
+
+// WithOctetCounting prefixes each message with its length, as required by
+// collectors that read messages from a stream (RFC 6587)
+func (s *SyslogSink) WithOctetCounting() *SyslogSink {
+	s.framed = true
+	return s
+}
+
+// WithWriteTimeout sets how long a write to a connection opened by
+// DialSyslog may take, DefaultWriteTimeout by default
+func (s *SyslogSink) WithWriteTimeout(d time.Duration) *SyslogSink {
+	s.writeTimeout = d
+	return s
+}
+
+// WithErrorHandler sets a callback for failed writes, which are otherwise dropped
+func (s *SyslogSink) WithErrorHandler(fn func(error)) *SyslogSink {
+	s.onError = fn
+	return s
+}
+
+// Emit writes the event as a syslog message
+func (s *SyslogSink) Emit(ctx context.Context, event iplocate.LookupEvent) {
+	msg := s.Format(event)
+	if s.framed {
+		msg = strconv.Itoa(len(msg)) + " " + msg
+	} else {
+		msg += "\n"
+	}
+
+	s.mu.Lock()
+	err := s.write(msg)
+	s.mu.Unlock()
+	if err != nil && s.onError != nil {
+		s.onError(fmt.Errorf("failed to write syslog message: %w", err))
+	}
+}
+
+// write writes a message to the writer, or to the connection of a dialed
+// sink, redialing it if a previous write failed. It must be called with mu held.
+func (s *SyslogSink) write(msg string) error {
+	if s.addr == "" {
+		_, err := io.WriteString(s.w, msg)
+		return err
+	}
+	if s.closed {
+		return net.ErrClosed
+	}
+
+	if s.conn == nil {
+		if time.Now().Before(s.redialAt) {
+			return errDisconnected
+		}
+		conn, err := net.DialTimeout(s.network, s.addr, s.writeTimeout)
+		if err != nil {
+			s.redialAt = time.Now().Add(s.redialDelay)
+			return fmt.Errorf("failed to connect to syslog: %w", err)
+		}
+		s.conn = conn
+	}
+
+	s.conn.SetWriteDeadline(time.Now().Add(s.writeTimeout))
+	if _, err := io.WriteString(s.conn, msg); err != nil {
+		s.conn.Close()
+		s.conn = nil
+		s.redialAt = time.Now().Add(s.redialDelay)
+		return err
+	}
+	return nil
+}
+
+// Close closes the connection opened by DialSyslog. It does nothing for
+// sinks created with NewSyslogSink.
+func (s *SyslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// Format returns the RFC 5424 message for an event, without framing
+func (s *SyslogSink) Format(event iplocate.LookupEvent) string {
+	severity := severityInfo
+	params := []string{sdParam("ip", event.IP)}
+	var msg string
+
+	if event.Err != nil {
+		severity = severityError
+		params = append(params, sdParam("error", event.Err.Error()))
+		msg = fmt.Sprintf("lookup %s failed: %v", event.IP, event.Err)
+	} else if resp := event.Response; resp != nil {
+		flags := resp.Privacy.Flags()
+		if len(flags) > 0 {
+			severity = severityWarning
+		}
+		summary := []string{deref(resp.CountryCode)}
+		params = appendParam(params, "country", deref(resp.CountryCode))
+		params = appendParam(params, "city", deref(resp.City))
+		params = appendParam(params, "network", deref(resp.Network))
+		if resp.ASN != nil {
+			summary = append(summary, resp.ASN.ASN)
+			params = appendParam(params, "asn", resp.ASN.ASN)
+			params = appendParam(params, "asnName", resp.ASN.Name)
+		}
+		params = appendParam(params, "flags", strings.Join(flags, ","))
+		msg = strings.TrimSpace(fmt.Sprintf("lookup %s: %s", event.IP, strings.Join(summary, " ")))
+	}
+
+	params = append(params, sdParam("durationMs", strconv.FormatInt(event.Duration.Milliseconds(), 10)))
+	if event.Stale {
+		params = append(params, sdParam("stale", "true"))
+	}
+
+	header := []string{
+		"<" + strconv.Itoa(s.facility*8+severity) + ">1",
+		event.Time.UTC().Format(rfc5424Time),
+		nilValue(s.hostname),
+		nilValue(s.appName),
+		strconv.Itoa(os.Getpid()),
+		"lookup",
+	}
+	return strings.Join(header, " ") + " [" + s.sdID + " " + strings.Join(params, " ") + "] " + msg
+}
+
+var sdValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// sdParam formats a structured data parameter
+func sdParam(name, value string) string {
+	return name + `="` + sdValueEscaper.Replace(value) + `"`
+}
+
+// appendParam appends the parameter if value is not empty
+func appendParam(params []string, name, value string) []string {
+	if value == "" {
+		return params
+	}
+	return append(params, sdParam(name, value))
+}
+
+// nilValue returns the RFC 5424 NILVALUE "-" for empty header fields
+func nilValue(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return strings.ReplaceAll(s, " ", "_")
+}
@@ -0,0 +1,207 @@
+package siem
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iplocate/go-iplocate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEvent() iplocate.LookupEvent {
+	return iplocate.LookupEvent{
+		Time:     time.Date(2024, 1, 2, 3, 4, 5, 6000, time.UTC),
+		IP:       "185.220.101.1",
+		Response: testResponse(),
+		Duration: 12 * time.Millisecond,
+	}
+}
+
+func TestSyslogSink_Format(t *testing.T) {
+	sink := NewSyslogSink(nil).WithHostname("web-1").WithAppName("api")
+
+	pid := strconv.Itoa(os.Getpid())
+	assert.Equal(t,
+		"<132>1 2024-01-02T03:04:05.000006Z web-1 api "+pid+" lookup "+
+			`[iplocate@32473 ip="185.220.101.1" country="DE" city="Berlin" network="185.220.101.0/24" asn="AS60729" asnName="Stiftung Erneuerbare Freiheit" flags="anonymous,tor" durationMs="12"] `+
+			"lookup 185.220.101.1: DE AS60729",
+		sink.Format(testEvent()))
+}
+
+func TestSyslogSink_FormatSeverities(t *testing.T) {
+	sink := NewSyslogSink(nil).WithFacility(FacilityUser).WithSDID("lookup@12345")
+
+	clean := iplocate.LookupEvent{IP: "8.8.8.8", Response: &iplocate.LookupResponse{IP: "8.8.8.8"}, Stale: true}
+	msg := sink.Format(clean)
+	assert.True(t, strings.HasPrefix(msg, "<14>1 "), msg)
+	assert.Contains(t, msg, `[lookup@12345 ip="8.8.8.8" durationMs="0" stale="true"] lookup 8.8.8.8:`)
+
+	failed := iplocate.LookupEvent{IP: "8.8.8.8", Err: errors.New(`bad "key"]`)}
+	msg = sink.Format(failed)
+	assert.True(t, strings.HasPrefix(msg, "<11>1 "), msg)
+	assert.Contains(t, msg, `error="bad \"key\"\]"`)
+	assert.True(t, strings.HasSuffix(msg, `lookup 8.8.8.8 failed: bad "key"]`), msg)
+}
+
+func TestSyslogSink_Emit(t *testing.T) {
+	var buf strings.Builder
+	sink := NewSyslogSink(&buf)
+	sink.Emit(context.Background(), testEvent())
+	sink.Emit(context.Background(), testEvent())
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Len(t, lines, 2)
+
+	buf.Reset()
+	sink.WithOctetCounting().Emit(context.Background(), testEvent())
+	length, msg, ok := strings.Cut(buf.String(), " ")
+	require.True(t, ok)
+	assert.Equal(t, strconv.Itoa(len(msg)), length)
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) { return 0, errors.New("broken pipe") }
+
+func TestSyslogSink_ErrorHandler(t *testing.T) {
+	var got error
+	sink := NewSyslogSink(failingWriter{}).WithErrorHandler(func(err error) { got = err })
+	sink.Emit(context.Background(), testEvent())
+	assert.EqualError(t, got, "failed to write syslog message: broken pipe")
+}
+
+func TestDialSyslog(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadString(']')
+		received <- line
+	}()
+
+	sink, err := DialSyslog("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	sink.Emit(context.Background(), testEvent())
+	require.NoError(t, sink.Close())
+
+	select {
+	case line := <-received:
+		assert.Regexp(t, `^\d+ <132>1 `, line)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message received")
+	}
+}
+
+func TestDialSyslog_WriteTimeout(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	// Accept the connection but never read from it
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			accepted <- conn
+		}
+	}()
+
+	var got error
+	sink, err := DialSyslog("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer sink.Close()
+	sink.WithWriteTimeout(50 * time.Millisecond).WithErrorHandler(func(err error) { got = err })
+	defer func() { (<-accepted).Close() }()
+
+	event := testEvent()
+	event.Err = errors.New(strings.Repeat("x", 16<<20))
+	start := time.Now()
+	sink.Emit(context.Background(), event)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.ErrorIs(t, got, os.ErrDeadlineExceeded)
+
+	got = nil
+	sink.Emit(context.Background(), testEvent())
+	assert.ErrorIs(t, got, errDisconnected, "messages are dropped until the collector is dialed again")
+}
+
+func TestDialSyslog_Redials(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		// Drop the first connection, read from the second
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		conn.Close()
+		conn, err = listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadString(']')
+		received <- line
+	}()
+
+	sink, err := DialSyslog("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer sink.Close()
+	sink.redialDelay = 0
+
+	deadline := time.After(5 * time.Second)
+	for {
+		sink.Emit(context.Background(), testEvent())
+		select {
+		case line := <-received:
+			assert.Regexp(t, `^\d+ <132>1 `, line)
+			return
+		case <-deadline:
+			t.Fatal("no message received after the connection dropped")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestDialSyslog_EmitAfterClose(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	sink, err := DialSyslog("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	require.NoError(t, sink.Close())
+
+	var got error
+	sink.WithErrorHandler(func(err error) { got = err })
+	sink.Emit(context.Background(), testEvent())
+	assert.ErrorIs(t, got, net.ErrClosed)
+}
+
+func TestSyslogSink_WithClient(t *testing.T) {
+	var buf strings.Builder
+	client := iplocate.NewClient(nil).WithLocalDatabase("missing.mmdb").WithOfflineMode(true).
+		WithEventSink(NewSyslogSink(&buf))
+
+	_, err := client.Lookup("8.8.8.8")
+	require.Error(t, err)
+	assert.Contains(t, buf.String(), `[iplocate@32473 ip="8.8.8.8" error="`)
+}
@@ -0,0 +1,90 @@
+package iplocate
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+)
+
+// Value stores the response as JSON, e.g. in a Postgres jsonb column:
+//
+//	db.Exec("INSERT INTO lookups (ip, data) VALUES ($1, $2)", result.IPInet(), result)
+//
+// A nil response is stored as NULL.
+func (r *LookupResponse) Value() (driver.Value, error) {
+	if r == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode response: %w", err)
+	}
+	return data, nil
+}
+
+// Scan reads a response stored by Value from a JSON, jsonb or text column.
+// NULL leaves the response unchanged.
+func (r *LookupResponse) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into LookupResponse", src)
+	}
+
+	*r = LookupResponse{}
+	if err := json.Unmarshal(data, r); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// Inet is an IP address or network for a Postgres inet or cidr column.
+// The empty Inet is stored as NULL.
+type Inet string
+
+// Value returns the address or network, validating it first
+func (i Inet) Value() (driver.Value, error) {
+	if i == "" {
+		return nil, nil
+	}
+	if _, err := netip.ParseAddr(string(i)); err == nil {
+		return string(i), nil
+	}
+	if _, err := netip.ParsePrefix(string(i)); err == nil {
+		return string(i), nil
+	}
+	return nil, fmt.Errorf("invalid inet value: %q", string(i))
+}
+
+// Scan reads an inet or cidr column. NULL scans as the empty Inet.
+func (i *Inet) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*i = ""
+	case []byte:
+		*i = Inet(v)
+	case string:
+		*i = Inet(v)
+	default:
+		return fmt.Errorf("cannot scan %T into Inet", src)
+	}
+	return nil
+}
+
+// IPInet returns the looked up IP address for an inet column
+func (r *LookupResponse) IPInet() Inet {
+	return Inet(r.IP)
+}
+
+// NetworkInet returns the network of the IP address for a cidr or inet
+// column, or the empty Inet (stored as NULL) if the API didn't return one
+func (r *LookupResponse) NetworkInet() Inet {
+	return Inet(stringOrEmpty(r.Network))
+}
@@ -0,0 +1,71 @@
+package iplocate
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupResponse_ValueScan(t *testing.T) {
+	resp := &LookupResponse{IP: "8.8.8.8", CountryCode: stringPtr("US"), ASN: &ASN{ASN: "AS15169"}}
+
+	var valuer driver.Valuer = resp
+	value, err := valuer.Value()
+	require.NoError(t, err)
+	require.IsType(t, []byte(nil), value)
+
+	var scanned LookupResponse
+	require.NoError(t, scanned.Scan(value))
+	assert.Equal(t, *resp, scanned)
+
+	var fromText LookupResponse
+	require.NoError(t, fromText.Scan(string(value.([]byte))))
+	assert.Equal(t, *resp, fromText)
+}
+
+func TestLookupResponse_ValueScanNull(t *testing.T) {
+	var resp *LookupResponse
+	value, err := resp.Value()
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	scanned := LookupResponse{IP: "8.8.8.8"}
+	require.NoError(t, scanned.Scan(nil))
+	assert.Equal(t, "8.8.8.8", scanned.IP)
+}
+
+func TestLookupResponse_ScanErrors(t *testing.T) {
+	var resp LookupResponse
+	assert.EqualError(t, resp.Scan(42), "cannot scan int into LookupResponse")
+	assert.ErrorContains(t, resp.Scan([]byte("{")), "failed to decode response")
+}
+
+func TestInet(t *testing.T) {
+	resp := &LookupResponse{IP: "2001:db8::1", Network: stringPtr("2001:db8::/32")}
+
+	value, err := resp.IPInet().Value()
+	require.NoError(t, err)
+	assert.Equal(t, "2001:db8::1", value)
+
+	value, err = resp.NetworkInet().Value()
+	require.NoError(t, err)
+	assert.Equal(t, "2001:db8::/32", value)
+
+	value, err = (&LookupResponse{IP: "8.8.8.8"}).NetworkInet().Value()
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	_, err = Inet("not-an-ip").Value()
+	assert.EqualError(t, err, `invalid inet value: "not-an-ip"`)
+}
+
+func TestInet_Scan(t *testing.T) {
+	var i Inet
+	require.NoError(t, i.Scan([]byte("10.0.0.0/8")))
+	assert.Equal(t, Inet("10.0.0.0/8"), i)
+	require.NoError(t, i.Scan(nil))
+	assert.Empty(t, i)
+	assert.Error(t, i.Scan(1.5))
+}
@@ -0,0 +1,137 @@
+package iplocate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrNotInStaticTable is returned when a StaticProvider has no entry for an IP address
+var ErrNotInStaticTable = errors.New("IP address not found in static table")
+
+// staticPrefix is a CIDR entry of a StaticProvider
+type staticPrefix struct {
+	prefix netip.Prefix
+	resp   *LookupResponse
+}
+
+// StaticProvider answers lookups from a table of IP addresses and CIDR
+// ranges, such as office IPs or internal VPN ranges. It is safe for
+// concurrent use.
+type StaticProvider struct {
+	mu       sync.RWMutex
+	addrs    map[netip.Addr]*LookupResponse
+	prefixes []staticPrefix
+}
+
+// NewStaticProvider creates a provider answering for the IP addresses and
+// CIDR ranges in entries, which may be nil
+func NewStaticProvider(entries map[string]LookupResponse) (*StaticProvider, error) {
+	s := &StaticProvider{addrs: make(map[netip.Addr]*LookupResponse)}
+	for key, resp := range entries {
+		if err := s.Add(key, resp); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Add sets the response for an IP address or CIDR range. An IP address
+// takes precedence over the ranges containing it, and narrower ranges over
+// wider ones. Adding the same address or range again replaces its response.
+func (s *StaticProvider) Add(ipOrCIDR string, resp LookupResponse) error {
+	if !strings.Contains(ipOrCIDR, "/") {
+		addr, err := netip.ParseAddr(ipOrCIDR)
+		if err != nil {
+			return fmt.Errorf("invalid IP address or CIDR: %q", ipOrCIDR)
+		}
+		s.mu.Lock()
+		s.addrs[addr.Unmap()] = &resp
+		s.mu.Unlock()
+		return nil
+	}
+
+	prefix, err := netip.ParsePrefix(ipOrCIDR)
+	if err != nil {
+		return fmt.Errorf("invalid IP address or CIDR: %q", ipOrCIDR)
+	}
+	prefix = normalizePrefix(prefix)
+	if resp.Network == nil {
+		resp.Network = stringValue(prefix.String())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, p := range s.prefixes {
+		if p.prefix == prefix {
+			s.prefixes[i].resp = &resp
+			return nil
+		}
+	}
+	s.prefixes = append(s.prefixes, staticPrefix{prefix: prefix, resp: &resp})
+	sort.SliceStable(s.prefixes, func(i, j int) bool {
+		return s.prefixes[i].prefix.Bits() > s.prefixes[j].prefix.Bits()
+	})
+	return nil
+}
+
+// Len returns the number of addresses and ranges in the table
+func (s *StaticProvider) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.addrs) + len(s.prefixes)
+}
+
+// Match returns the response for ip, with IP set to ip, if the table has
+// an entry for it
+func (s *StaticProvider) Match(ip string) (*LookupResponse, bool) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return nil, false
+	}
+	addr = addr.Unmap()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	resp, ok := s.addrs[addr]
+	if !ok {
+		for _, p := range s.prefixes {
+			if p.prefix.Contains(addr) {
+				resp, ok = p.resp, true
+				break
+			}
+		}
+	}
+	if !ok {
+		return nil, false
+	}
+	result := *resp
+	result.IP = ip
+	return &result, true
+}
+
+// Lookup returns the response for ip, or ErrNotInStaticTable
+func (s *StaticProvider) Lookup(ctx context.Context, ip string) (*LookupResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if _, err := netip.ParseAddr(ip); err != nil {
+		return nil, fmt.Errorf("invalid IP address: %s", ip)
+	}
+	if resp, ok := s.Match(ip); ok {
+		return resp, nil
+	}
+	return nil, ErrNotInStaticTable
+}
+
+// WithStaticProvider answers lookups of the IPs in the table of s without
+// calling the API, so they never count against the quota. Other IPs are
+// looked up as usual.
+func (c *Client) WithStaticProvider(s *StaticProvider) *Client {
+	c.static = s
+	return c
+}
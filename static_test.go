@@ -0,0 +1,106 @@
+package iplocate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticProvider_Match(t *testing.T) {
+	static, err := NewStaticProvider(map[string]LookupResponse{
+		"10.0.0.0/8":    {Company: &Company{Name: "Internal"}},
+		"10.1.0.0/16":   {Company: &Company{Name: "Office VPN"}, Privacy: Privacy{IsVPN: true}},
+		"10.1.2.3":      {Company: &Company{Name: "Build server"}},
+		"2001:db8::/32": {Company: &Company{Name: "Lab"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 4, static.Len())
+
+	resp, ok := static.Match("10.9.9.9")
+	require.True(t, ok)
+	assert.Equal(t, "10.9.9.9", resp.IP)
+	assert.Equal(t, "Internal", resp.Company.Name)
+	assert.Equal(t, "10.0.0.0/8", *resp.Network, "the range is set as the network")
+
+	resp, ok = static.Match("10.1.200.1")
+	require.True(t, ok)
+	assert.Equal(t, "Office VPN", resp.Company.Name, "narrower ranges take precedence")
+	assert.True(t, resp.Privacy.IsVPN)
+
+	resp, ok = static.Match("::ffff:10.1.2.3")
+	require.True(t, ok)
+	assert.Equal(t, "Build server", resp.Company.Name, "addresses take precedence")
+	assert.Equal(t, "::ffff:10.1.2.3", resp.IP)
+
+	resp, ok = static.Match("2001:db8::1")
+	require.True(t, ok)
+	assert.Equal(t, "Lab", resp.Company.Name)
+
+	_, ok = static.Match("8.8.8.8")
+	assert.False(t, ok)
+	_, ok = static.Match("invalid")
+	assert.False(t, ok)
+
+	require.NoError(t, static.Add("10.0.0.0/8", LookupResponse{Company: &Company{Name: "Corp"}}))
+	assert.Equal(t, 4, static.Len(), "adding a range again replaces it")
+	resp, _ = static.Match("10.9.9.9")
+	assert.Equal(t, "Corp", resp.Company.Name)
+
+	assert.Error(t, static.Add("10.0.0.0/33", LookupResponse{}))
+	assert.Error(t, static.Add("office", LookupResponse{}))
+	_, err = NewStaticProvider(map[string]LookupResponse{"invalid": {}})
+	assert.Error(t, err)
+}
+
+func TestStaticProvider_Lookup(t *testing.T) {
+	static, err := NewStaticProvider(nil)
+	require.NoError(t, err)
+	require.NoError(t, static.Add("192.168.0.0/16", LookupResponse{CountryCode: stringPtr("DE")}))
+
+	resp, err := static.Lookup(context.Background(), "192.168.1.1")
+	require.NoError(t, err)
+	assert.Equal(t, "DE", *resp.CountryCode)
+
+	_, err = static.Lookup(context.Background(), "8.8.8.8")
+	assert.ErrorIs(t, err, ErrNotInStaticTable)
+	_, err = static.Lookup(context.Background(), "invalid")
+	assert.Error(t, err)
+}
+
+func TestWithStaticProvider(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8"})
+	}))
+	defer server.Close()
+
+	static, err := NewStaticProvider(map[string]LookupResponse{
+		"10.0.0.0/8": {Company: &Company{Name: "Internal"}},
+	})
+	require.NoError(t, err)
+	tracker := NewQuotaTracker(10)
+	client := NewClient(nil).WithBaseURL(server.URL).WithStaticProvider(static).WithQuotaTracker(tracker)
+
+	result, err := client.Lookup("10.1.2.3")
+	require.NoError(t, err)
+	assert.Equal(t, "Internal", result.Company.Name)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&requests))
+	usage, err := tracker.Usage()
+	require.NoError(t, err)
+	assert.Equal(t, 0, usage.Used, "static answers don't use the quota")
+
+	_, err = client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	result, err = NewChain(static, APIProvider(client)).Lookup(context.Background(), "10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, "Internal", result.Company.Name)
+}
@@ -0,0 +1,323 @@
+// Package staticmap renders static map images centered on the coordinates
+// of IPLocate lookups, for embedding in reports and emails. Maps are
+// composed from OpenStreetMap tiles, or tiles of any server using the same
+// scheme, with a pin on the location and the tile attribution drawn in the
+// corner. Tiles are cached, as the OpenStreetMap tile usage policy asks.
+package staticmap
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iplocate/go-iplocate"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	// DefaultTileURL is the OpenStreetMap tile server. Its usage policy
+	// allows light use only; set your own tile server for bulk rendering.
+	DefaultTileURL = "https://tile.openstreetmap.org/{z}/{x}/{y}.png"
+	// DefaultAttribution is the attribution required for OpenStreetMap tiles
+	DefaultAttribution = "© OpenStreetMap contributors"
+	// DefaultWidth and DefaultHeight are the size of rendered maps in pixels
+	DefaultWidth  = 600
+	DefaultHeight = 300
+	// DefaultZoom is the zoom level of rendered maps, about city level
+	DefaultZoom = iplocate.DefaultMapZoom
+	// DefaultTileCacheTTL is how long tiles are cached. The OpenStreetMap
+	// tile usage policy asks for at least seven days.
+	DefaultTileCacheTTL = 7 * 24 * time.Hour
+)
+
+// tileSize is the size of map tiles in pixels
+const tileSize = 256
+
+// maxTileSize caps the size of a downloaded tile
+const maxTileSize = 1 << 20
+
+// ErrNoCoordinates is returned when a lookup has no coordinates to center on
+var ErrNoCoordinates = errors.New("lookup has no coordinates")
+
+var (
+	// background fills areas without tiles, beyond the poles
+	background  = color.RGBA{0xe5, 0xe3, 0xdf, 0xff}
+	pinColor    = color.RGBA{0xd9, 0x30, 0x25, 0xff}
+	pinOutline  = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	labelShade  = color.RGBA{0xff, 0xff, 0xff, 0xc0}
+	labelColor  = color.RGBA{0x33, 0x33, 0x33, 0xff}
+	pinRadius   = 7.0
+	pinBorder   = 2.0
+	labelMargin = 3
+)
+
+// Renderer renders static maps. It is safe for concurrent use.
+type Renderer struct {
+	tileURL     string
+	attribution string
+	width       int
+	height      int
+	zoom        int
+	httpClient  *http.Client
+	cache       iplocate.Cache
+	cacheTTL    time.Duration
+}
+
+// NewRenderer creates a renderer of DefaultWidth by DefaultHeight maps at
+// DefaultZoom from DefaultTileURL, caching tiles in memory
+func NewRenderer() *Renderer {
+	return &Renderer{
+		tileURL:     DefaultTileURL,
+		attribution: DefaultAttribution,
+		width:       DefaultWidth,
+		height:      DefaultHeight,
+		zoom:        DefaultZoom,
+		httpClient:  &http.Client{Timeout: 30 * time.Second},
+		cache:       iplocate.NewMemoryCache(256),
+		cacheTTL:    DefaultTileCacheTTL,
+	}
+}
+
+// WithTileServer sets the tile URL template, with {z}, {x} and {y}
+// placeholders, and the attribution its tiles require
+func (r *Renderer) WithTileServer(urlTemplate, attribution string) *Renderer {
+	r.tileURL = urlTemplate
+	r.attribution = attribution
+	return r
+}
+
+// WithSize sets the size of rendered maps in pixels
+func (r *Renderer) WithSize(width, height int) *Renderer {
+	r.width = width
+	r.height = height
+	return r
+}
+
+// WithZoom sets the zoom level of rendered maps, clamped to 1 to 19
+func (r *Renderer) WithZoom(zoom int) *Renderer {
+	r.zoom = min(max(zoom, 1), 19)
+	return r
+}
+
+// WithHTTPClient sets the HTTP client tiles are downloaded with
+func (r *Renderer) WithHTTPClient(httpClient *http.Client) *Renderer {
+	r.httpClient = httpClient
+	return r
+}
+
+// WithCache sets the cache of downloaded tiles, kept for ttl. A nil cache
+// disables caching.
+func (r *Renderer) WithCache(cache iplocate.Cache, ttl time.Duration) *Renderer {
+	r.cache = cache
+	r.cacheTTL = ttl
+	return r
+}
+
+// Attribution returns the attribution of the tiles, drawn on every map.
+// Show it next to the map as well where it may be cropped or scaled down.
+func (r *Renderer) Attribution() string {
+	return r.attribution
+}
+
+// Render returns a map centered on the coordinates of resp, with a pin on
+// them. It fails with ErrNoCoordinates if resp has none.
+func (r *Renderer) Render(ctx context.Context, resp *iplocate.LookupResponse) (image.Image, error) {
+	if resp == nil {
+		return nil, ErrNoCoordinates
+	}
+	lat, lon, ok := resp.Coordinates()
+	if !ok {
+		return nil, ErrNoCoordinates
+	}
+	return r.RenderAt(ctx, lat, lon)
+}
+
+// RenderAt returns a map centered on lat and lon, with a pin on them
+func (r *Renderer) RenderAt(ctx context.Context, lat, lon float64) (image.Image, error) {
+	img := image.NewRGBA(image.Rect(0, 0, r.width, r.height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+
+	// Pixel coordinates of the center in the world map at this zoom
+	cx, cy := project(lat, lon, r.zoom)
+	left := int(math.Floor(cx)) - r.width/2
+	top := int(math.Floor(cy)) - r.height/2
+	tiles := 1 << r.zoom
+
+	for ty := floorDiv(top, tileSize); ty <= floorDiv(top+r.height-1, tileSize); ty++ {
+		if ty < 0 || ty >= tiles {
+			continue
+		}
+		for tx := floorDiv(left, tileSize); tx <= floorDiv(left+r.width-1, tileSize); tx++ {
+			tile, err := r.tile(ctx, r.zoom, ((tx%tiles)+tiles)%tiles, ty)
+			if err != nil {
+				return nil, err
+			}
+			at := image.Pt(tx*tileSize-left, ty*tileSize-top)
+			draw.Draw(img, image.Rectangle{Min: at, Max: at.Add(image.Pt(tileSize, tileSize))}, tile, tile.Bounds().Min, draw.Src)
+		}
+	}
+
+	x, y := cx-float64(left), cy-float64(top)
+	drawDisc(img, x, y, pinRadius+pinBorder, pinOutline)
+	drawDisc(img, x, y, pinRadius, pinColor)
+	r.drawAttribution(img)
+	return img, nil
+}
+
+// WritePNG renders the map of resp like Render and writes it to w as a PNG
+func (r *Renderer) WritePNG(ctx context.Context, w io.Writer, resp *iplocate.LookupResponse) error {
+	img, err := r.Render(ctx, resp)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// tile returns a map tile, from the cache or the tile server
+func (r *Renderer) tile(ctx context.Context, z, x, y int) (image.Image, error) {
+	tileURL := strings.NewReplacer(
+		"{z}", strconv.Itoa(z),
+		"{x}", strconv.Itoa(x),
+		"{y}", strconv.Itoa(y),
+	).Replace(r.tileURL)
+
+	key := "tile:" + tileURL
+	if r.cache != nil {
+		if data, ok := r.cache.Get(key); ok {
+			if img, _, err := image.Decode(bytes.NewReader(data)); err == nil {
+				return img, nil
+			}
+		}
+	}
+
+	data, err := r.download(ctx, tileURL)
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode tile %d/%d/%d: %w", z, x, y, err)
+	}
+	if r.cache != nil {
+		r.cache.Set(key, data, r.cacheTTL)
+	}
+	return img, nil
+}
+
+// download fetches a tile
+func (r *Renderer) download(ctx context.Context, tileURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", tileURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	// The OpenStreetMap tile usage policy requires an identifying User-Agent
+	req.Header.Set("User-Agent", iplocate.DefaultUserAgent)
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("tile request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("tile request failed (%d)", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxTileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tile: %w", err)
+	}
+	if len(data) > maxTileSize {
+		return nil, fmt.Errorf("failed to read tile: %w", iplocate.ErrResponseTooLarge)
+	}
+	return data, nil
+}
+
+// drawAttribution draws the attribution in the bottom right corner, on a
+// translucent background
+func (r *Renderer) drawAttribution(img *image.RGBA) {
+	if r.attribution == "" {
+		return
+	}
+	face := basicfont.Face7x13
+	text := asciiText(r.attribution)
+	width := font.MeasureString(face, text).Ceil()
+	height := face.Metrics().Height.Ceil()
+
+	box := image.Rect(
+		r.width-width-2*labelMargin, r.height-height-2*labelMargin,
+		r.width, r.height,
+	)
+	draw.Draw(img, box, image.NewUniform(labelShade), image.Point{}, draw.Over)
+
+	drawer := font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(labelColor),
+		Face: face,
+		Dot:  fixed.P(box.Min.X+labelMargin, box.Max.Y-labelMargin-face.Metrics().Descent.Ceil()),
+	}
+	drawer.DrawString(text)
+}
+
+// asciiText replaces the characters the built-in font lacks, writing ©
+// as (c)
+func asciiText(s string) string {
+	s = strings.ReplaceAll(s, "©", "(c)")
+	return strings.Map(func(r rune) rune {
+		if r < ' ' || r > '~' {
+			return '?'
+		}
+		return r
+	}, s)
+}
+
+// project returns the Web Mercator pixel coordinates of lat and lon at zoom
+func project(lat, lon float64, zoom int) (x, y float64) {
+	// Web Mercator is undefined at the poles
+	lat = math.Max(math.Min(lat, 85.05112878), -85.05112878)
+	size := float64(int(tileSize) << zoom)
+	x = (lon + 180) / 360 * size
+	sin := math.Sin(lat * math.Pi / 180)
+	y = (0.5 - math.Log((1+sin)/(1-sin))/(4*math.Pi)) * size
+	return x, y
+}
+
+// floorDiv divides rounding towards negative infinity
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+// drawDisc draws a filled, anti-aliased disc of an opaque color
+func drawDisc(img *image.RGBA, cx, cy, radius float64, c color.RGBA) {
+	bounds := image.Rect(int(cx-radius)-1, int(cy-radius)-1, int(cx+radius)+2, int(cy+radius)+2).Intersect(img.Bounds())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			// Coverage of the pixel, from its center's distance to the edge
+			d := math.Hypot(float64(x)+0.5-cx, float64(y)+0.5-cy)
+			coverage := math.Max(0, math.Min(1, radius-d+0.5))
+			if coverage == 0 {
+				continue
+			}
+			dst := img.RGBAAt(x, y)
+			blend := func(src, dst uint8) uint8 {
+				return uint8(math.Round(float64(src)*coverage + float64(dst)*(1-coverage)))
+			}
+			img.SetRGBA(x, y, color.RGBA{blend(c.R, dst.R), blend(c.G, dst.G), blend(c.B, dst.B), 0xff})
+		}
+	}
+}
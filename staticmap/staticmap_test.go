@@ -0,0 +1,143 @@
+package staticmap
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/iplocate/go-iplocate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func float64Ptr(f float64) *float64 { return &f }
+
+var tileColor = color.RGBA{0x40, 0x80, 0x40, 0xff}
+
+// tileServer serves solid tiles and records the requested paths
+func tileServer(t *testing.T) (*httptest.Server, func() []string) {
+	var buf bytes.Buffer
+	tile := image.NewRGBA(image.Rect(0, 0, tileSize, tileSize))
+	for i := range tile.Pix {
+		tile.Pix[i] = []byte{tileColor.R, tileColor.G, tileColor.B, tileColor.A}[i%4]
+	}
+	require.NoError(t, png.Encode(&buf, tile))
+
+	var mu sync.Mutex
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		assert.Equal(t, iplocate.DefaultUserAgent, r.Header.Get("User-Agent"))
+		if r.URL.Path == "/missing.png" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(buf.Bytes())
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), paths...)
+	}
+}
+
+var berlin = &iplocate.LookupResponse{Latitude: float64Ptr(52.52), Longitude: float64Ptr(13.405)}
+
+func TestRender(t *testing.T) {
+	server, paths := tileServer(t)
+	renderer := NewRenderer().WithTileServer(server.URL+"/{z}/{x}/{y}.png", DefaultAttribution)
+
+	img, err := renderer.Render(context.Background(), berlin)
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, DefaultWidth, DefaultHeight), img.Bounds())
+
+	// A 600x300 map spans three or four tiles across and two or three down
+	requested := paths()
+	assert.GreaterOrEqual(t, len(requested), 6)
+	assert.LessOrEqual(t, len(requested), 12)
+	assert.Contains(t, requested, "/10/550/335.png", "the tile holding Berlin")
+
+	rgba := img.(*image.RGBA)
+	assert.Equal(t, pinColor, rgba.RGBAAt(DefaultWidth/2, DefaultHeight/2), "the pin is at the center")
+	assert.Equal(t, tileColor, rgba.RGBAAt(10, 10))
+	assert.NotEqual(t, tileColor, rgba.RGBAAt(DefaultWidth-3, DefaultHeight-3), "the attribution is in the corner")
+
+	_, err = renderer.Render(context.Background(), berlin)
+	require.NoError(t, err)
+	assert.Len(t, paths(), len(requested), "tiles are cached")
+}
+
+func TestRender_NoCoordinates(t *testing.T) {
+	renderer := NewRenderer()
+	_, err := renderer.Render(context.Background(), &iplocate.LookupResponse{IP: "203.0.113.7"})
+	assert.ErrorIs(t, err, ErrNoCoordinates)
+	_, err = renderer.Render(context.Background(), nil)
+	assert.ErrorIs(t, err, ErrNoCoordinates)
+}
+
+func TestRenderAt_Edges(t *testing.T) {
+	server, paths := tileServer(t)
+	renderer := NewRenderer().
+		WithTileServer(server.URL+"/{z}/{x}/{y}.png", "").
+		WithSize(512, 512).
+		WithZoom(1).
+		WithCache(nil, 0)
+
+	img, err := renderer.RenderAt(context.Background(), 85, 179)
+	require.NoError(t, err)
+	assert.Equal(t, background, img.(*image.RGBA).RGBAAt(256, 10), "beyond the pole")
+	for _, path := range paths() {
+		var z, x, y int
+		_, err := fmt.Sscanf(path, "/%d/%d/%d.png", &z, &x, &y)
+		require.NoError(t, err)
+		assert.Equal(t, 1, z)
+		assert.True(t, x >= 0 && x < 2 && y >= 0 && y < 2, path)
+	}
+	assert.Contains(t, paths(), "/1/0/0.png", "tiles wrap around the antimeridian")
+}
+
+func TestRender_TileError(t *testing.T) {
+	server, _ := tileServer(t)
+	renderer := NewRenderer().WithTileServer(server.URL+"/missing.png", DefaultAttribution)
+
+	_, err := renderer.Render(context.Background(), berlin)
+	assert.EqualError(t, err, "tile request failed (404)")
+}
+
+func TestWritePNG(t *testing.T) {
+	server, _ := tileServer(t)
+	renderer := NewRenderer().WithTileServer(server.URL+"/{z}/{x}/{y}.png", DefaultAttribution).WithSize(200, 100)
+
+	var buf bytes.Buffer
+	require.NoError(t, renderer.WritePNG(context.Background(), &buf, berlin))
+	img, err := png.Decode(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 200, 100), img.Bounds())
+	assert.Equal(t, DefaultAttribution, renderer.Attribution())
+}
+
+func TestProject(t *testing.T) {
+	x, y := project(0, 0, 0)
+	assert.InDelta(t, 128, x, 1e-9)
+	assert.InDelta(t, 128, y, 1e-9)
+
+	x, y = project(90, -180, 1)
+	assert.InDelta(t, 0, x, 1e-9)
+	assert.InDelta(t, 0, y, 1e-6)
+}
+
+func TestASCIIText(t *testing.T) {
+	assert.Equal(t, "(c) OpenStreetMap contributors", asciiText(DefaultAttribution))
+	assert.Equal(t, "Tiles ? Stamen", asciiText("Tiles — Stamen"))
+}
@@ -0,0 +1,162 @@
+package iplocate
+
+import (
+	"context"
+	"iter"
+	"sync"
+	"time"
+)
+
+// DefaultBatchConcurrency is how many lookups streams and batches run at once
+const DefaultBatchConcurrency = 8
+
+// WithBatchConcurrency sets how many lookups LookupStream and LookupBatch
+// run at once. Zero or negative values restore DefaultBatchConcurrency.
+func (c *Client) WithBatchConcurrency(n int) *Client {
+	c.batchConcurrency = n
+	return c
+}
+
+// WithBatchInterval spaces out the lookups of LookupIter, LookupStream and
+// LookupBatch so that they start at least interval apart, keeping bulk jobs
+// under the plan's rate limit. Zero disables pacing.
+func (c *Client) WithBatchInterval(interval time.Duration) *Client {
+	c.batchInterval = interval
+	return c
+}
+
+// concurrency returns the number of concurrent bulk lookups
+func (c *Client) concurrency() int {
+	if c.batchConcurrency <= 0 {
+		return DefaultBatchConcurrency
+	}
+	return c.batchConcurrency
+}
+
+// pacer spaces out the start of lookups shared between several goroutines
+type pacer struct {
+	mu       sync.Mutex
+	interval time.Duration
+	clock    Clock
+	next     time.Time
+}
+
+// newPacer returns a pacer for interval, or nil if interval is not positive
+func newPacer(interval time.Duration, clock Clock) *pacer {
+	if interval <= 0 {
+		return nil
+	}
+	return &pacer{interval: interval, clock: clock}
+}
+
+// wait blocks until the next lookup may start, or returns the context error.
+// A nil pacer doesn't wait.
+func (p *pacer) wait(ctx context.Context) error {
+	if p == nil || ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	p.mu.Lock()
+	now := p.clock.Now()
+	start := p.next
+	if start.Before(now) {
+		start = now
+	}
+	p.next = start.Add(p.interval)
+	p.mu.Unlock()
+
+	return sleepContext(ctx, p.clock, start.Sub(now))
+}
+
+// Result is the outcome of looking up one IP address in a stream or batch
+type Result struct {
+	IP       string
+	Response *LookupResponse
+	// Stale is set when Response is an expired cache entry served because
+	// the API failed; see WithServeStaleOnError
+	Stale bool
+	Err   error
+}
+
+// LookupIter looks up each IP address of ips as the caller ranges over the
+// returned sequence, so no lookup runs ahead of the consumer:
+//
+//	for ip, result := range client.LookupIter(ctx, slices.Values(ips)) {
+//		...
+//	}
+//
+// Failed lookups are yielded with Result.Err set and iteration continues.
+// If ctx is canceled, the pending IP address is yielded with the context
+// error and iteration stops.
+func (c *Client) LookupIter(ctx context.Context, ips iter.Seq[string], opts ...LookupOption) iter.Seq2[string, Result] {
+	return func(yield func(string, Result) bool) {
+		progress := newProgress(c.lookupOptions(opts).onProgress, -1)
+		pace := newPacer(c.batchInterval, c.clock)
+		for ip := range ips {
+			if err := pace.wait(ctx); err != nil {
+				yield(ip, Result{IP: ip, Err: err})
+				return
+			}
+			resp, stale, err := c.lookup(ctx, ip, opts, nil)
+			progress.record(err)
+			if !yield(ip, Result{IP: ip, Response: resp, Stale: stale, Err: err}) {
+				return
+			}
+		}
+	}
+}
+
+// LookupStream looks up the IP addresses received from ips and sends the
+// results to the returned channel in the order they complete. At most
+// DefaultBatchConcurrency lookups run at once (see WithBatchConcurrency),
+// and no new lookup starts while results are waiting to be received, so a
+// slow consumer slows the stream down. The channel is closed once ips is
+// closed and drained, or when ctx is canceled; results still in flight at
+// that point are dropped.
+func (c *Client) LookupStream(ctx context.Context, ips <-chan string, opts ...LookupOption) <-chan Result {
+	return c.stream(ctx, ips, -1, opts)
+}
+
+// stream implements LookupStream, reporting progress against total lookups
+func (c *Client) stream(ctx context.Context, ips <-chan string, total int, opts []LookupOption) <-chan Result {
+	out := make(chan Result)
+	progress := newProgress(c.lookupOptions(opts).onProgress, total)
+	pace := newPacer(c.batchInterval, c.clock)
+
+	var wg sync.WaitGroup
+	for i := 0; i < c.concurrency(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				var ip string
+				select {
+				case <-ctx.Done():
+					return
+				case next, ok := <-ips:
+					if !ok {
+						return
+					}
+					ip = next
+				}
+				if pace.wait(ctx) != nil {
+					return
+				}
+
+				resp, stale, err := c.lookup(ctx, ip, opts, nil)
+				progress.record(err)
+				select {
+				case out <- Result{IP: ip, Response: resp, Stale: stale, Err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
@@ -0,0 +1,228 @@
+package iplocate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newEchoServer answers lookups with the requested IP and counts requests
+func newEchoServer(t *testing.T, calls *int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		ip := strings.TrimPrefix(r.URL.Path, "/lookup/")
+		json.NewEncoder(w).Encode(LookupResponse{IP: ip})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestLookupIter(t *testing.T) {
+	var calls int32
+	server := newEchoServer(t, &calls)
+	client := NewClient(nil).WithBaseURL(server.URL)
+
+	var ips []string
+	var errs int
+	for ip, result := range client.LookupIter(context.Background(), slices.Values([]string{"8.8.8.8", "invalid", "1.1.1.1"})) {
+		ips = append(ips, ip)
+		if result.Err != nil {
+			errs++
+			continue
+		}
+		assert.Equal(t, ip, result.Response.IP)
+	}
+
+	assert.Equal(t, []string{"8.8.8.8", "invalid", "1.1.1.1"}, ips)
+	assert.Equal(t, 1, errs)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestLookupIter_Break(t *testing.T) {
+	var calls int32
+	server := newEchoServer(t, &calls)
+	client := NewClient(nil).WithBaseURL(server.URL)
+
+	for _, result := range client.LookupIter(context.Background(), slices.Values([]string{"8.8.8.8", "1.1.1.1", "9.9.9.9"})) {
+		require.NoError(t, result.Err)
+		break
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "no lookups run ahead of the consumer")
+}
+
+func TestLookupIter_Canceled(t *testing.T) {
+	var calls int32
+	server := newEchoServer(t, &calls)
+	client := NewClient(nil).WithBaseURL(server.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var results []Result
+	for _, result := range client.LookupIter(ctx, slices.Values([]string{"8.8.8.8", "1.1.1.1", "9.9.9.9"})) {
+		results = append(results, result)
+		cancel()
+	}
+
+	require.Len(t, results, 2)
+	assert.NoError(t, results[0].Err)
+	assert.ErrorIs(t, results[1].Err, context.Canceled)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestLookupStream(t *testing.T) {
+	var calls int32
+	server := newEchoServer(t, &calls)
+	client := NewClient(nil).WithBaseURL(server.URL)
+
+	ips := make(chan string)
+	go func() {
+		defer close(ips)
+		for _, ip := range []string{"8.8.8.8", "1.1.1.1", "9.9.9.9", "invalid"} {
+			ips <- ip
+		}
+	}()
+
+	found := map[string]bool{}
+	var errs int
+	for result := range client.LookupStream(context.Background(), ips) {
+		if result.Err != nil {
+			assert.Equal(t, "invalid", result.IP)
+			errs++
+			continue
+		}
+		assert.Equal(t, result.IP, result.Response.IP)
+		found[result.IP] = true
+	}
+
+	assert.Equal(t, map[string]bool{"8.8.8.8": true, "1.1.1.1": true, "9.9.9.9": true}, found)
+	assert.Equal(t, 1, errs)
+}
+
+func TestLookupStream_BoundedConcurrency(t *testing.T) {
+	var active, peak int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&active, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		<-release
+		atomic.AddInt32(&active, -1)
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8"})
+	}))
+	defer server.Close()
+	client := NewClient(nil).WithBaseURL(server.URL)
+
+	ips := make(chan string, 2*DefaultBatchConcurrency)
+	for i := 0; i < 2*DefaultBatchConcurrency; i++ {
+		ips <- "8.8.8.8"
+	}
+	close(ips)
+
+	results := client.LookupStream(context.Background(), ips)
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&active) == DefaultBatchConcurrency
+	}, 5*time.Second, 10*time.Millisecond)
+	close(release)
+
+	count := 0
+	for range results {
+		count++
+	}
+	assert.Equal(t, 2*DefaultBatchConcurrency, count)
+	assert.Equal(t, int32(DefaultBatchConcurrency), atomic.LoadInt32(&peak))
+}
+
+func TestLookupStream_Canceled(t *testing.T) {
+	var calls int32
+	server := newEchoServer(t, &calls)
+	client := NewClient(nil).WithBaseURL(server.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ips := make(chan string)
+	results := client.LookupStream(ctx, ips)
+	cancel()
+
+	select {
+	case _, ok := <-results:
+		assert.False(t, ok)
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream not closed after cancel")
+	}
+}
+
+func TestWithBatchConcurrency(t *testing.T) {
+	var active, peak int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&active, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&active, -1)
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8"})
+	}))
+	defer server.Close()
+
+	client := NewClient(nil).WithBaseURL(server.URL).WithBatchConcurrency(2)
+	ips := make([]string, 10)
+	for i := range ips {
+		ips[i] = "8.8.8.8"
+	}
+	_, err := client.LookupBatch(context.Background(), ips)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(2))
+
+	assert.Equal(t, DefaultBatchConcurrency, NewClient(nil).WithBatchConcurrency(0).concurrency())
+}
+
+func TestWithBatchInterval(t *testing.T) {
+	var mu sync.Mutex
+	var starts []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		starts = append(starts, time.Now())
+		mu.Unlock()
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8"})
+	}))
+	defer server.Close()
+
+	interval := 20 * time.Millisecond
+	client := NewClient(nil).WithBaseURL(server.URL).WithBatchInterval(interval)
+	_, err := client.LookupBatch(context.Background(), []string{"8.8.8.8", "8.8.8.8", "8.8.8.8", "8.8.8.8"})
+	require.NoError(t, err)
+
+	require.Len(t, starts, 4)
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+	assert.GreaterOrEqual(t, starts[3].Sub(starts[0]), 3*interval-5*time.Millisecond)
+}
+
+func TestPacer_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var nilPacer *pacer
+	assert.ErrorIs(t, nilPacer.wait(ctx), context.Canceled)
+
+	p := newPacer(time.Hour, systemClock{})
+	require.NoError(t, p.wait(context.Background()))
+	assert.ErrorIs(t, p.wait(ctx), context.Canceled)
+}
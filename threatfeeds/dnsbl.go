@@ -0,0 +1,212 @@
+package threatfeeds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iplocate/go-iplocate"
+)
+
+// DefaultDNSBLTimeout bounds each blocklist query made by a DNSBL
+const DefaultDNSBLTimeout = 2 * time.Second
+
+// DefaultBlocklists are widely used IP blocklists with free tiers for low
+// volume, non-commercial use. Check each operator's terms before querying
+// them at scale; Spamhaus rejects queries sent through public resolvers.
+var DefaultBlocklists = []string{
+	"zen.spamhaus.org",
+	"bl.spamcop.net",
+	"b.barracudacentral.org",
+}
+
+// DNSBL checks IPs against DNS blocklists, queried concurrently. It is safe
+// for concurrent use.
+type DNSBL struct {
+	zones      []string
+	timeout    time.Duration
+	lookupHost func(ctx context.Context, host string) ([]string, error)
+}
+
+// NewDNSBL creates a checker querying the given blocklist zones, or
+// DefaultBlocklists if none are given
+func NewDNSBL(zones ...string) *DNSBL {
+	if len(zones) == 0 {
+		zones = DefaultBlocklists
+	}
+	return &DNSBL{
+		zones:      append([]string(nil), zones...),
+		timeout:    DefaultDNSBLTimeout,
+		lookupHost: net.DefaultResolver.LookupHost,
+	}
+}
+
+// WithTimeout sets how long each blocklist query may take
+func (d *DNSBL) WithTimeout(timeout time.Duration) *DNSBL {
+	d.timeout = timeout
+	return d
+}
+
+// WithResolver sets the resolver blocklists are queried with, e.g. one
+// pointing at a local caching resolver
+func (d *DNSBL) WithResolver(resolver *net.Resolver) *DNSBL {
+	d.lookupHost = resolver.LookupHost
+	return d
+}
+
+// Verdict is the answer of one blocklist for an IP
+type Verdict struct {
+	// Zone is the blocklist's DNS zone, e.g. "zen.spamhaus.org"
+	Zone string
+	// Listed reports whether the IP is on the blocklist
+	Listed bool
+	// Codes are the addresses the blocklist answered with, e.g.
+	// "127.0.0.2", which most blocklists use to tell listing reasons apart
+	Codes []string
+	// Err is set if the blocklist couldn't be queried. Listed is then false.
+	Err error
+}
+
+// Check queries every blocklist for ip concurrently and returns their
+// verdicts in the order of the zones
+func (d *DNSBL) Check(ctx context.Context, ip string) ([]Verdict, error) {
+	name, err := reverseName(ip)
+	if err != nil {
+		return nil, err
+	}
+
+	verdicts := make([]Verdict, len(d.zones))
+	var wg sync.WaitGroup
+	for i, zone := range d.zones {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			verdicts[i] = d.query(ctx, name, zone)
+		}()
+	}
+	wg.Wait()
+	return verdicts, nil
+}
+
+// query looks the reversed IP name up in one blocklist zone
+func (d *DNSBL) query(ctx context.Context, name, zone string) Verdict {
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+
+	verdict := Verdict{Zone: zone}
+	addrs, err := d.lookupHost(ctx, name+"."+zone)
+	var dnsErr *net.DNSError
+	switch {
+	case errors.As(err, &dnsErr) && dnsErr.IsNotFound:
+		return verdict
+	case err != nil:
+		verdict.Err = fmt.Errorf("failed to query %s: %w", zone, err)
+		return verdict
+	}
+
+	for _, addr := range addrs {
+		// 127.255.255.0/24 answers are errors, e.g. a refused public resolver
+		if strings.HasPrefix(addr, "127.255.255.") {
+			verdict.Err = fmt.Errorf("%s refused the query (%s)", zone, addr)
+			return verdict
+		}
+	}
+	verdict.Listed = len(addrs) > 0
+	verdict.Codes = addrs
+	return verdict
+}
+
+// reverseName returns the DNSBL query name of ip: the octets of an IPv4
+// address, or the nibbles of an IPv6 address, in reverse order
+func reverseName(ip string) (string, error) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return "", fmt.Errorf("invalid IP address: %q", ip)
+	}
+	addr = addr.Unmap()
+
+	var labels []string
+	if addr.Is4() {
+		b := addr.As4()
+		for i := len(b) - 1; i >= 0; i-- {
+			labels = append(labels, strconv.Itoa(int(b[i])))
+		}
+	} else {
+		b := addr.As16()
+		for i := len(b) - 1; i >= 0; i-- {
+			labels = append(labels, strconv.FormatUint(uint64(b[i]&0xf), 16), strconv.FormatUint(uint64(b[i]>>4), 16))
+		}
+	}
+	return strings.Join(labels, "."), nil
+}
+
+// ThreatReport combines an IPLocate lookup with blocklist verdicts for an IP
+type ThreatReport struct {
+	IP string
+	// Response is the API's response, or nil if the lookup failed
+	Response *iplocate.LookupResponse
+	// Blocklists are the verdicts of each blocklist
+	Blocklists []Verdict
+}
+
+// ListedOn returns the zones of the blocklists listing the IP
+func (r *ThreatReport) ListedOn() []string {
+	var zones []string
+	for _, v := range r.Blocklists {
+		if v.Listed {
+			zones = append(zones, v.Zone)
+		}
+	}
+	return zones
+}
+
+// Flags returns the privacy flags set by the API, see Privacy.Flags, plus
+// "blocklisted" if any blocklist lists the IP
+func (r *ThreatReport) Flags() []string {
+	var flags []string
+	if r.Response != nil {
+		flags = r.Response.Privacy.Flags()
+	}
+	if len(r.ListedOn()) > 0 {
+		flags = append(flags, "blocklisted")
+	}
+	return flags
+}
+
+// Threat reports whether the API flags the IP as an abuser, Tor exit node
+// or proxy, or any blocklist lists it
+func (r *ThreatReport) Threat() bool {
+	if resp := r.Response; resp != nil && (resp.Privacy.IsAbuser || resp.Privacy.IsTor || resp.Privacy.IsProxy) {
+		return true
+	}
+	return len(r.ListedOn()) > 0
+}
+
+// Lookup looks ip up with client while querying the blocklists, and
+// combines both into a report. If the lookup fails, the report holds only
+// the blocklist verdicts and is returned along with the API error.
+func (d *DNSBL) Lookup(ctx context.Context, client *iplocate.Client, ip string, opts ...iplocate.LookupOption) (*ThreatReport, error) {
+	report := &ThreatReport{IP: ip}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		report.Blocklists, _ = d.Check(ctx, ip)
+	}()
+
+	resp, err := client.LookupContext(ctx, ip, opts...)
+	<-done
+	if err != nil {
+		if report.Blocklists == nil {
+			return nil, err
+		}
+		return report, err
+	}
+	report.Response = resp
+	return report, nil
+}
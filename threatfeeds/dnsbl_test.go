@@ -0,0 +1,118 @@
+package threatfeeds
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iplocate/go-iplocate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBlocklists answers DNSBL queries from a table of names
+func fakeBlocklists(answers map[string][]string) func(ctx context.Context, host string) ([]string, error) {
+	return func(ctx context.Context, host string) ([]string, error) {
+		if strings.HasSuffix(host, ".slow.example") {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		if addrs, ok := answers[host]; ok {
+			return addrs, nil
+		}
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+}
+
+func TestReverseName(t *testing.T) {
+	name, err := reverseName("192.0.2.1")
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.0.192", name)
+
+	name, err = reverseName("::ffff:192.0.2.1")
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.0.192", name)
+
+	name, err = reverseName("2001:db8::1")
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2", name)
+
+	_, err = reverseName("invalid")
+	assert.Error(t, err)
+}
+
+func TestDNSBLCheck(t *testing.T) {
+	dnsbl := NewDNSBL("listed.example", "clean.example", "refused.example", "slow.example")
+	dnsbl.lookupHost = fakeBlocklists(map[string][]string{
+		"2.0.0.127.listed.example":  {"127.0.0.2", "127.0.0.4"},
+		"2.0.0.127.refused.example": {"127.255.255.254"},
+	})
+	dnsbl.WithTimeout(10 * time.Millisecond)
+
+	verdicts, err := dnsbl.Check(context.Background(), "127.0.0.2")
+	require.NoError(t, err)
+	require.Len(t, verdicts, 4)
+
+	assert.Equal(t, Verdict{Zone: "listed.example", Listed: true, Codes: []string{"127.0.0.2", "127.0.0.4"}}, verdicts[0])
+	assert.Equal(t, Verdict{Zone: "clean.example"}, verdicts[1])
+	assert.False(t, verdicts[2].Listed)
+	assert.ErrorContains(t, verdicts[2].Err, "refused")
+	assert.False(t, verdicts[3].Listed)
+	assert.ErrorIs(t, verdicts[3].Err, context.DeadlineExceeded)
+
+	_, err = dnsbl.Check(context.Background(), "invalid")
+	assert.Error(t, err)
+}
+
+func TestNewDNSBL_Defaults(t *testing.T) {
+	assert.Equal(t, DefaultBlocklists, NewDNSBL().zones)
+}
+
+func TestDNSBLLookup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/192.0.2.9") {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(iplocate.LookupResponse{IP: "192.0.2.1", Privacy: iplocate.Privacy{IsHosting: true}})
+	}))
+	defer server.Close()
+	client := iplocate.NewClient(nil).WithBaseURL(server.URL)
+
+	dnsbl := NewDNSBL("listed.example", "clean.example")
+	dnsbl.lookupHost = fakeBlocklists(map[string][]string{
+		"1.2.0.192.listed.example": {"127.0.0.2"},
+		"9.2.0.192.listed.example": {"127.0.0.2"},
+	})
+
+	report, err := dnsbl.Lookup(context.Background(), client, "192.0.2.1")
+	require.NoError(t, err)
+	require.NotNil(t, report.Response)
+	assert.Equal(t, "192.0.2.1", report.IP)
+	assert.Equal(t, []string{"listed.example"}, report.ListedOn())
+	assert.Equal(t, []string{"hosting", "blocklisted"}, report.Flags())
+	assert.True(t, report.Threat())
+
+	report, err = dnsbl.Lookup(context.Background(), client, "192.0.2.9")
+	assert.Error(t, err)
+	require.NotNil(t, report, "blocklist verdicts are kept when the API fails")
+	assert.Nil(t, report.Response)
+	assert.Equal(t, []string{"blocklisted"}, report.Flags())
+
+	_, err = dnsbl.Lookup(context.Background(), client, "invalid")
+	assert.Error(t, err)
+}
+
+func TestThreatReport(t *testing.T) {
+	clean := &ThreatReport{IP: "192.0.2.1", Response: &iplocate.LookupResponse{}, Blocklists: []Verdict{{Zone: "clean.example"}}}
+	assert.False(t, clean.Threat())
+	assert.Empty(t, clean.Flags())
+
+	tor := &ThreatReport{IP: "192.0.2.1", Response: &iplocate.LookupResponse{Privacy: iplocate.Privacy{IsTor: true}}}
+	assert.True(t, tor.Threat())
+}
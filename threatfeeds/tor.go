@@ -0,0 +1,239 @@
+// Package threatfeeds loads public threat feeds to cross-check IPLocate
+// lookups locally. TorExitList keeps the Tor Project's list of exit node
+// addresses up to date, so Privacy.IsTor can be corroborated, or answered
+// when the API is unavailable. DNSBL checks IPs against DNS blocklists
+// alongside a lookup.
+package threatfeeds
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iplocate/go-iplocate"
+)
+
+const (
+	// DefaultTorExitListURL is the Tor Project's bulk list of exit node addresses
+	DefaultTorExitListURL = "https://check.torproject.org/torbulkexitlist"
+	// DefaultUpdateInterval is how often a started list is refreshed. The
+	// Tor Project updates the list about every 30 minutes.
+	DefaultUpdateInterval = time.Hour
+)
+
+// TorExitList is a set of Tor exit node addresses. It is safe for concurrent use.
+type TorExitList struct {
+	url        string
+	httpClient *http.Client
+	interval   time.Duration
+	onError    func(error)
+
+	mu      sync.RWMutex
+	addrs   map[netip.Addr]struct{}
+	updated time.Time
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewTorExitList creates an empty list. Call Update or Load to fill it.
+func NewTorExitList() *TorExitList {
+	return &TorExitList{
+		url:        DefaultTorExitListURL,
+		httpClient: &http.Client{Timeout: time.Minute},
+		interval:   DefaultUpdateInterval,
+		addrs:      make(map[netip.Addr]struct{}),
+	}
+}
+
+// WithURL sets the URL the list is downloaded from, e.g. a mirror
+func (l *TorExitList) WithURL(url string) *TorExitList {
+	l.url = url
+	return l
+}
+
+// WithHTTPClient sets the HTTP client used for downloads
+func (l *TorExitList) WithHTTPClient(httpClient *http.Client) *TorExitList {
+	l.httpClient = httpClient
+	return l
+}
+
+// WithUpdateInterval sets how often a started list is refreshed
+func (l *TorExitList) WithUpdateInterval(interval time.Duration) *TorExitList {
+	l.interval = interval
+	return l
+}
+
+// WithErrorHandler sets a callback for errors from background updates
+func (l *TorExitList) WithErrorHandler(fn func(error)) *TorExitList {
+	l.onError = fn
+	return l
+}
+
+// Update downloads the list and replaces the current addresses. On error,
+// the current addresses are kept.
+func (l *TorExitList) Update(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", l.url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", iplocate.DefaultUserAgent)
+
+	resp, err := l.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download failed (%d)", resp.StatusCode)
+	}
+	return l.Load(resp.Body)
+}
+
+// Load replaces the addresses with those read from r. It accepts the bulk
+// list, one address per line, and the detailed exit-addresses format, whose
+// "ExitAddress <ip> <date>" lines hold the addresses. An empty list is
+// rejected, as it most likely means a broken download.
+func (l *TorExitList) Load(r io.Reader) error {
+	addrs := make(map[netip.Addr]struct{})
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		field := fields[0]
+		if field == "ExitAddress" && len(fields) > 1 {
+			field = fields[1]
+		}
+		if addr, err := netip.ParseAddr(field); err == nil {
+			addrs[addr.Unmap()] = struct{}{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read Tor exit list: %w", err)
+	}
+	if len(addrs) == 0 {
+		return errors.New("no addresses in Tor exit list")
+	}
+
+	l.mu.Lock()
+	l.addrs = addrs
+	l.updated = time.Now()
+	l.mu.Unlock()
+	return nil
+}
+
+// Start refreshes the list in the background every update interval until
+// Close is called. Errors are passed to the error handler. Start must be
+// called at most once.
+func (l *TorExitList) Start() {
+	l.stop = make(chan struct{})
+	l.done = make(chan struct{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-l.stop
+		cancel()
+	}()
+
+	go func() {
+		defer close(l.done)
+		ticker := time.NewTicker(l.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-l.stop:
+				return
+			case <-ticker.C:
+				if err := l.Update(ctx); err != nil && l.onError != nil {
+					l.onError(err)
+				}
+			}
+		}
+	}()
+}
+
+// Close stops background updates
+func (l *TorExitList) Close() error {
+	l.stopOnce.Do(func() {
+		if l.stop != nil {
+			close(l.stop)
+			<-l.done
+		}
+	})
+	return nil
+}
+
+// Contains reports whether ip is a Tor exit node
+func (l *TorExitList) Contains(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	_, ok := l.addrs[addr.Unmap()]
+	return ok
+}
+
+// Len returns the number of addresses in the list
+func (l *TorExitList) Len() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return len(l.addrs)
+}
+
+// Updated returns when the list was last loaded, or the zero time
+func (l *TorExitList) Updated() time.Time {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.updated
+}
+
+// TorCheck compares the API's Tor flag for an IP with the exit list
+type TorCheck struct {
+	// Reported is the API's Privacy.IsTor
+	Reported bool
+	// Listed reports whether the IP is on the exit list
+	Listed bool
+}
+
+// Agree reports whether the API and the list agree
+func (c TorCheck) Agree() bool {
+	return c.Reported == c.Listed
+}
+
+// Check compares the Tor flag of an API response with the list
+func (l *TorExitList) Check(resp *iplocate.LookupResponse) TorCheck {
+	return TorCheck{Reported: resp.Privacy.IsTor, Listed: l.Contains(resp.IP)}
+}
+
+// Lookup looks ip up with client and marks it as Tor, and anonymous, if it
+// is on the list, even when the API doesn't flag it. If the lookup fails,
+// Lookup returns a response holding only the IP and the flags from the
+// list, along with the API error, so callers can still act on listed exit
+// nodes while the API is unavailable.
+func (l *TorExitList) Lookup(ctx context.Context, client *iplocate.Client, ip string, opts ...iplocate.LookupOption) (*iplocate.LookupResponse, error) {
+	resp, err := client.LookupContext(ctx, ip, opts...)
+	if err != nil {
+		if _, parseErr := netip.ParseAddr(ip); parseErr != nil {
+			return nil, err
+		}
+		resp = &iplocate.LookupResponse{IP: ip}
+	}
+	if l.Contains(ip) {
+		resp.Privacy.IsTor = true
+		resp.Privacy.IsAnonymous = true
+	}
+	return resp, err
+}
@@ -0,0 +1,130 @@
+package threatfeeds
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/iplocate/go-iplocate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const bulkList = "185.220.101.1\n185.220.101.2\n2001:db8::7\n"
+
+const exitAddresses = `ExitNode 0011BD2485AD45D984EC4159C88FC066E5E3300E
+Published 2024-01-01 10:00:00
+LastStatus 2024-01-01 11:00:00
+ExitAddress 162.247.74.27 2024-01-01 11:03:23
+`
+
+func TestLoad(t *testing.T) {
+	list := NewTorExitList()
+	require.NoError(t, list.Load(strings.NewReader(bulkList)))
+	assert.Equal(t, 3, list.Len())
+	assert.True(t, list.Contains("185.220.101.1"))
+	assert.True(t, list.Contains("::ffff:185.220.101.2"))
+	assert.True(t, list.Contains("2001:db8::7"))
+	assert.False(t, list.Contains("8.8.8.8"))
+	assert.False(t, list.Contains("invalid"))
+	assert.False(t, list.Updated().IsZero())
+
+	require.NoError(t, list.Load(strings.NewReader(exitAddresses)))
+	assert.Equal(t, 1, list.Len())
+	assert.True(t, list.Contains("162.247.74.27"))
+	assert.False(t, list.Contains("185.220.101.1"), "loading replaces the addresses")
+
+	assert.Error(t, list.Load(strings.NewReader("<html>maintenance</html>\n")))
+	assert.Equal(t, 1, list.Len(), "a failed load keeps the addresses")
+}
+
+func TestUpdate(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) > 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		assert.Equal(t, iplocate.DefaultUserAgent, r.Header.Get("User-Agent"))
+		w.Write([]byte(bulkList))
+	}))
+	defer server.Close()
+
+	list := NewTorExitList().WithURL(server.URL)
+	require.NoError(t, list.Update(context.Background()))
+	assert.Equal(t, 3, list.Len())
+
+	assert.EqualError(t, list.Update(context.Background()), "download failed (503)")
+	assert.Equal(t, 3, list.Len())
+}
+
+func TestStart(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write([]byte(bulkList))
+	}))
+	defer server.Close()
+
+	list := NewTorExitList().WithURL(server.URL).WithUpdateInterval(10 * time.Millisecond)
+	list.Start()
+	assert.Eventually(t, func() bool { return list.Len() == 3 }, time.Second, 5*time.Millisecond)
+	require.NoError(t, list.Close())
+	require.NoError(t, list.Close())
+}
+
+func TestCheck(t *testing.T) {
+	list := NewTorExitList()
+	require.NoError(t, list.Load(strings.NewReader(bulkList)))
+
+	check := list.Check(&iplocate.LookupResponse{IP: "185.220.101.1", Privacy: iplocate.Privacy{IsTor: true}})
+	assert.True(t, check.Agree())
+
+	check = list.Check(&iplocate.LookupResponse{IP: "185.220.101.2"})
+	assert.Equal(t, TorCheck{Reported: false, Listed: true}, check)
+	assert.False(t, check.Agree())
+}
+
+func TestLookup(t *testing.T) {
+	var down atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		code := "DE"
+		json.NewEncoder(w).Encode(iplocate.LookupResponse{IP: strings.TrimPrefix(r.URL.Path, "/lookup/"), CountryCode: &code})
+	}))
+	defer server.Close()
+
+	client := iplocate.NewClient(nil).WithBaseURL(server.URL)
+	list := NewTorExitList()
+	require.NoError(t, list.Load(strings.NewReader(bulkList)))
+	ctx := context.Background()
+
+	resp, err := list.Lookup(ctx, client, "185.220.101.1")
+	require.NoError(t, err)
+	assert.Equal(t, "DE", *resp.CountryCode)
+	assert.True(t, resp.Privacy.IsTor)
+	assert.True(t, resp.Privacy.IsAnonymous)
+
+	resp, err = list.Lookup(ctx, client, "8.8.8.8")
+	require.NoError(t, err)
+	assert.False(t, resp.Privacy.IsTor)
+
+	down.Store(true)
+	resp, err = list.Lookup(ctx, client, "185.220.101.2")
+	assert.Error(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, "185.220.101.2", resp.IP)
+	assert.True(t, resp.Privacy.IsTor)
+
+	resp, err = list.Lookup(ctx, client, "invalid")
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+}
@@ -0,0 +1,35 @@
+package iplocate
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNoTimeZone is returned when a response has no time zone
+var ErrNoTimeZone = errors.New("no time zone in response")
+
+// Location loads the IANA time zone of the IP, e.g. "America/New_York". It
+// returns ErrNoTimeZone if the response has none. Loading relies on the
+// system time zone database; import time/tzdata to embed one in binaries
+// that run where it is missing, such as scratch containers.
+func (r *LookupResponse) Location() (*time.Location, error) {
+	if r.TimeZone == nil || *r.TimeZone == "" {
+		return nil, ErrNoTimeZone
+	}
+	loc, err := time.LoadLocation(*r.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load time zone: %w", err)
+	}
+	return loc, nil
+}
+
+// LocalTime returns t in the time zone of the IP, e.g. to show a user's
+// local time: resp.LocalTime(time.Now())
+func (r *LookupResponse) LocalTime(t time.Time) (time.Time, error) {
+	loc, err := r.Location()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.In(loc), nil
+}
@@ -0,0 +1,33 @@
+package iplocate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocation(t *testing.T) {
+	resp := &LookupResponse{TimeZone: stringPtr("America/New_York")}
+	loc, err := resp.Location()
+	require.NoError(t, err)
+	assert.Equal(t, "America/New_York", loc.String())
+
+	_, err = (&LookupResponse{}).Location()
+	assert.ErrorIs(t, err, ErrNoTimeZone)
+
+	_, err = (&LookupResponse{TimeZone: stringPtr("Mars/Olympus_Mons")}).Location()
+	assert.ErrorContains(t, err, "failed to load time zone")
+}
+
+func TestLocalTime(t *testing.T) {
+	resp := &LookupResponse{TimeZone: stringPtr("Asia/Tokyo")}
+	local, err := resp.LocalTime(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, 21, local.Hour())
+	assert.Equal(t, "JST", local.Format("MST"))
+
+	_, err = (&LookupResponse{}).LocalTime(time.Now())
+	assert.ErrorIs(t, err, ErrNoTimeZone)
+}
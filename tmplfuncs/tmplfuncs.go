@@ -0,0 +1,116 @@
+// Package tmplfuncs provides template functions for rendering IPLocate
+// lookups in dashboards and email reports with html/template or
+// text/template:
+//
+//	tmpl := template.Must(template.New("ip").Funcs(tmplfuncs.FuncMap()).Parse(
+//		`{{countryFlag .}} {{countryName . "de"}} ({{anonymizeIP .IP}}) <a href="{{mapLink .}}">map</a>`))
+//	tmpl.Execute(w, resp)
+//
+// Functions taking a country accept a country code as a string or *string,
+// or a *iplocate.LookupResponse. Missing data renders as an empty string.
+package tmplfuncs
+
+import (
+	"strings"
+
+	"github.com/iplocate/go-iplocate"
+	"github.com/iplocate/go-iplocate/countries"
+)
+
+// FuncMap returns the template functions, for the Funcs method of an
+// html/template or text/template Template:
+//
+//   - countryName returns the name of a country, in the language given as
+//     an optional second argument, e.g. "de"
+//   - countryFlag returns the flag emoji of a country
+//   - anonymizeIP zeroes the host part of an IP address, keeping its /24
+//     for IPv4 and its /48 for IPv6
+//   - mapLink returns a map link to the coordinates of a
+//     *iplocate.LookupResponse, on Google Maps or the provider given as an
+//     optional second argument, e.g. "osm"
+func FuncMap() map[string]any {
+	return map[string]any{
+		"countryName": CountryName,
+		"countryFlag": CountryFlag,
+		"anonymizeIP": AnonymizeIP,
+		"mapLink":     MapLink,
+	}
+}
+
+// CountryName returns the name of the country of v in lang, or in English
+// if lang is omitted or has no translation
+func CountryName(v any, lang ...string) string {
+	country, ok := countries.Lookup(countryCode(v))
+	if !ok {
+		return ""
+	}
+	if len(lang) > 0 {
+		return country.LocalizedName(lang[0])
+	}
+	return country.Name()
+}
+
+// CountryFlag returns the flag emoji of the country of v
+func CountryFlag(v any) string {
+	return countries.Flag(countryCode(v))
+}
+
+// AnonymizeIP returns the network of v, an IP address as a string or the
+// IP of a *iplocate.LookupResponse, with the host part zeroed: the /24 of
+// an IPv4 address and the /48 of an IPv6 address, as commonly done before
+// storing or displaying addresses
+func AnonymizeIP(v any) string {
+	var ip string
+	switch v := v.(type) {
+	case string:
+		ip = v
+	case *iplocate.LookupResponse:
+		if v != nil {
+			ip = v.IP
+		}
+	}
+	addr, err := iplocate.ParseFlexibleIP(ip)
+	if err != nil {
+		return ""
+	}
+	bits := 48
+	if addr.Is4() {
+		bits = 24
+	}
+	prefix, _ := addr.Prefix(bits)
+	return prefix.Addr().String()
+}
+
+// MapLink returns a link to the coordinates of resp on the map of provider,
+// named as for iplocate.ParseMapProvider and Google Maps if omitted, or an
+// empty string if resp has no coordinates or the provider is unknown
+func MapLink(resp *iplocate.LookupResponse, provider ...string) string {
+	if resp == nil {
+		return ""
+	}
+	p := iplocate.MapGoogle
+	if len(provider) > 0 {
+		var err error
+		if p, err = iplocate.ParseMapProvider(provider[0]); err != nil {
+			return ""
+		}
+	}
+	return resp.MapLink(p)
+}
+
+// countryCode returns the country code held by v
+func countryCode(v any) string {
+	switch v := v.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case *string:
+		if v != nil {
+			return strings.TrimSpace(*v)
+		}
+	case *iplocate.LookupResponse:
+		if v != nil && v.CountryCode != nil {
+			return *v.CountryCode
+		}
+	}
+	return ""
+}
@@ -0,0 +1,74 @@
+package tmplfuncs
+
+import (
+	htmltemplate "html/template"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/iplocate/go-iplocate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stringPtr(s string) *string    { return &s }
+func float64Ptr(f float64) *float64 { return &f }
+
+var testResponse = &iplocate.LookupResponse{
+	IP:          "203.0.113.77",
+	CountryCode: stringPtr("DE"),
+	Latitude:    float64Ptr(52.52),
+	Longitude:   float64Ptr(13.405),
+}
+
+func TestCountryName(t *testing.T) {
+	assert.Equal(t, "Germany", CountryName("DE"))
+	assert.Equal(t, "Deutschland", CountryName(stringPtr("de"), "de"))
+	assert.Equal(t, "Allemagne", CountryName(testResponse, "fr"))
+	assert.Equal(t, "Germany", CountryName(testResponse, "ja"))
+	assert.Empty(t, CountryName("XX"))
+	assert.Empty(t, CountryName((*string)(nil)))
+	assert.Empty(t, CountryName(&iplocate.LookupResponse{}))
+	assert.Empty(t, CountryName(42))
+}
+
+func TestCountryFlag(t *testing.T) {
+	assert.Equal(t, "🇩🇪", CountryFlag(testResponse))
+	assert.Equal(t, "🇺🇸", CountryFlag("US"))
+	assert.Empty(t, CountryFlag((*iplocate.LookupResponse)(nil)))
+}
+
+func TestAnonymizeIP(t *testing.T) {
+	assert.Equal(t, "203.0.113.0", AnonymizeIP("203.0.113.77"))
+	assert.Equal(t, "203.0.113.0", AnonymizeIP(testResponse))
+	assert.Equal(t, "2001:db8:85a3::", AnonymizeIP("2001:db8:85a3:8d3:1319:8a2e:370:7348"))
+	assert.Equal(t, "192.0.2.0", AnonymizeIP("::ffff:192.0.2.1"))
+	assert.Empty(t, AnonymizeIP("invalid"))
+	assert.Empty(t, AnonymizeIP(nil))
+}
+
+func TestMapLink(t *testing.T) {
+	assert.Equal(t, "https://maps.google.com/?q=52.5200,13.4050&z=10", MapLink(testResponse))
+	assert.Empty(t, MapLink(&iplocate.LookupResponse{IP: "203.0.113.77"}))
+	assert.Empty(t, MapLink(nil))
+	assert.Equal(t, "https://www.openstreetmap.org/?mlat=52.5200&mlon=13.4050#map=10/52.5200/13.4050", MapLink(testResponse, "osm"))
+	assert.Empty(t, MapLink(testResponse, "here"))
+}
+
+func TestFuncMap_TextTemplate(t *testing.T) {
+	tmpl := template.Must(template.New("report").Funcs(FuncMap()).Parse(
+		`{{countryFlag .}} {{countryName .CountryCode "de"}} {{anonymizeIP .IP}} {{mapLink .}}`))
+
+	var out strings.Builder
+	require.NoError(t, tmpl.Execute(&out, testResponse))
+	assert.Equal(t, "🇩🇪 Deutschland 203.0.113.0 https://maps.google.com/?q=52.5200,13.4050&z=10", out.String())
+}
+
+func TestFuncMap_HTMLTemplate(t *testing.T) {
+	tmpl := htmltemplate.Must(htmltemplate.New("report").Funcs(FuncMap()).Parse(
+		`<a href="{{mapLink .}}">{{countryName .}}</a>`))
+
+	var out strings.Builder
+	require.NoError(t, tmpl.Execute(&out, testResponse))
+	assert.Equal(t, `<a href="https://maps.google.com/?q=52.5200,13.4050&amp;z=10">Germany</a>`, out.String())
+}
@@ -0,0 +1,178 @@
+package iplocate
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// fallbackTransport is implemented by round trippers wrapping an
+// *http.Transport, such as the HTTP/3 transport of contrib/http3, so the
+// transport options configure the wrapped transport. CloneWithFallback
+// returns a copy wrapping the given transport, which lets the client
+// configure its own copy.
+type fallbackTransport interface {
+	FallbackTransport() *http.Transport
+	CloneWithFallback(fallback *http.Transport) http.RoundTripper
+}
+
+// transport returns the *http.Transport of the client's HTTP client so it can
+// be configured. The first call replaces the transport with a clone, or a
+// clone of http.DefaultTransport if none is set, so the caller's transport
+// is never modified. It returns nil if the HTTP client uses a custom
+// RoundTripper that doesn't wrap an *http.Transport.
+func (c *Client) transport() *http.Transport {
+	if c.ownTransport != nil {
+		return c.ownTransport
+	}
+
+	var t *http.Transport
+	switch rt := c.httpClient.Transport.(type) {
+	case nil:
+		t = http.DefaultTransport.(*http.Transport).Clone()
+		c.httpClient.Transport = t
+	case *http.Transport:
+		t = rt.Clone()
+		c.httpClient.Transport = t
+	case fallbackTransport:
+		t = rt.FallbackTransport().Clone()
+		c.httpClient.Transport = rt.CloneWithFallback(t)
+	default:
+		return nil
+	}
+	c.ownTransport = t
+	return t
+}
+
+// tlsConfig returns the transport's TLS configuration, creating it if needed
+func (c *Client) tlsConfig() *tls.Config {
+	t := c.transport()
+	if t == nil {
+		return nil
+	}
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{}
+	}
+	return t.TLSClientConfig
+}
+
+// WithTLSConfig sets the TLS configuration used to connect to the API, e.g.
+// to trust a private CA. It has no effect if the HTTP client passed to
+// NewClient uses a RoundTripper other than *http.Transport.
+func (c *Client) WithTLSConfig(config *tls.Config) *Client {
+	if t := c.transport(); t != nil {
+		t.TLSClientConfig = config
+	}
+	return c
+}
+
+// WithClientCertificate presents the PEM encoded certificate and key in
+// certFile and keyFile to endpoints that require mutual TLS. The files are
+// read on the first connection; if they can't be loaded, requests fail with
+// the error. Like WithTLSConfig, it requires an *http.Transport.
+func (c *Client) WithClientCertificate(certFile, keyFile string) *Client {
+	config := c.tlsConfig()
+	if config == nil {
+		return c
+	}
+
+	loader := &certificateLoader{certFile: certFile, keyFile: keyFile}
+	config.Certificates = nil
+	config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		return loader.load()
+	}
+	return c
+}
+
+// certificateLoader reads a client certificate once it is first needed
+type certificateLoader struct {
+	certFile string
+	keyFile  string
+
+	mu   sync.Mutex
+	cert *tls.Certificate
+}
+
+// load returns the certificate, reading it again after a failed attempt
+func (l *certificateLoader) load() (*tls.Certificate, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.cert != nil {
+		return l.cert, nil
+	}
+	cert, err := tls.LoadX509KeyPair(l.certFile, l.keyFile)
+	if err != nil {
+		return nil, err
+	}
+	l.cert = &cert
+	return l.cert, nil
+}
+
+// WithProxy routes API requests through the proxy at proxyURL, e.g.
+// "http://proxy.example.com:3128". If proxyURL is invalid, requests fail
+// with the parse error. Like WithTLSConfig, it requires an *http.Transport.
+func (c *Client) WithProxy(proxyURL string) *Client {
+	t := c.transport()
+	if t == nil {
+		return c
+	}
+
+	parsed, err := url.Parse(proxyURL)
+	if err == nil && parsed.Host == "" {
+		err = errors.New("missing host")
+	}
+	if err != nil {
+		err = fmt.Errorf("invalid proxy URL %q: %w", proxyURL, err)
+		t.Proxy = func(*http.Request) (*url.URL, error) {
+			return nil, err
+		}
+		return c
+	}
+	t.Proxy = http.ProxyURL(parsed)
+	return c
+}
+
+// WithProxyFromEnvironment routes API requests through the proxy named by the
+// HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables
+func (c *Client) WithProxyFromEnvironment() *Client {
+	if t := c.transport(); t != nil {
+		t.Proxy = http.ProxyFromEnvironment
+	}
+	return c
+}
+
+// WithTransportTuning sizes the connection pool for high-throughput use.
+// Up to maxIdleConns connections to the API are kept open for idleTimeout
+// between requests, so lookups don't pay for a new handshake each time.
+// http2 controls whether HTTP/2 is negotiated with TLS endpoints. Zero values
+// keep the transport defaults. Like WithTLSConfig, it requires an
+// *http.Transport.
+func (c *Client) WithTransportTuning(maxIdleConns int, idleTimeout time.Duration, http2 bool) *Client {
+	t := c.transport()
+	if t == nil {
+		return c
+	}
+
+	if maxIdleConns > 0 {
+		t.MaxIdleConns = maxIdleConns
+		// All requests go to the same few hosts
+		t.MaxIdleConnsPerHost = maxIdleConns
+	}
+	if idleTimeout > 0 {
+		t.IdleConnTimeout = idleTimeout
+	}
+
+	t.ForceAttemptHTTP2 = http2
+	if http2 {
+		t.TLSNextProto = nil
+	} else {
+		// A non-nil empty map disables HTTP/2
+		t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	return c
+}
@@ -0,0 +1,238 @@
+package iplocate
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeClientCertificate writes a self-signed client certificate and its key as PEM files
+func writeClientCertificate(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "go-iplocate test client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "client.crt")
+	keyFile = filepath.Join(dir, "client.key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+// newMutualTLSServer starts a TLS server that requires a client certificate
+func newMutualTLSServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8"})
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func serverRoots(server *httptest.Server) *x509.CertPool {
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	return roots
+}
+
+func TestWithTLSConfig(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8"})
+	}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	_, err := NewClient(nil).WithBaseURL(server.URL).Lookup("8.8.8.8")
+	require.Error(t, err, "the test server's certificate is not trusted by default")
+
+	client := NewClient(nil).WithBaseURL(server.URL).WithTLSConfig(&tls.Config{RootCAs: serverRoots(server)})
+	result, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "8.8.8.8", result.IP)
+}
+
+func TestWithTLSConfig_DoesNotModifyDefaultTransport(t *testing.T) {
+	httpClient := &http.Client{Transport: http.DefaultTransport}
+	NewClient(httpClient).WithTLSConfig(&tls.Config{ServerName: "example.com"})
+
+	assert.Same(t, http.DefaultTransport, httpClient.Transport, "the caller's client is not modified")
+	if config := http.DefaultTransport.(*http.Transport).TLSClientConfig; config != nil {
+		assert.Empty(t, config.ServerName)
+	}
+}
+
+func TestTransportOptions_DoNotModifyCallerTransport(t *testing.T) {
+	transport := &http.Transport{}
+	httpClient := &http.Client{Transport: transport, Timeout: time.Minute}
+	client := NewClient(httpClient).
+		WithTimeout(time.Second).
+		WithTLSConfig(&tls.Config{ServerName: "example.com"}).
+		WithProxy("http://proxy.example.com:3128").
+		WithTransportTuning(64, time.Minute, false)
+
+	assert.Same(t, transport, httpClient.Transport)
+	assert.Equal(t, time.Minute, httpClient.Timeout)
+	if transport.TLSClientConfig != nil {
+		assert.Empty(t, transport.TLSClientConfig.ServerName)
+	}
+	assert.Nil(t, transport.Proxy)
+	assert.Zero(t, transport.MaxIdleConns)
+
+	own := client.httpClient.Transport.(*http.Transport)
+	assert.NotSame(t, transport, own)
+	assert.Equal(t, "example.com", own.TLSClientConfig.ServerName)
+	assert.NotNil(t, own.Proxy)
+	assert.Equal(t, 64, own.MaxIdleConns)
+	assert.Equal(t, time.Second, client.httpClient.Timeout)
+}
+
+func TestWithClientCertificate(t *testing.T) {
+	server := newMutualTLSServer(t)
+	certFile, keyFile := writeClientCertificate(t)
+
+	client := NewClient(nil).
+		WithBaseURL(server.URL).
+		WithTLSConfig(&tls.Config{RootCAs: serverRoots(server)}).
+		WithClientCertificate(certFile, keyFile)
+
+	result, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "8.8.8.8", result.IP)
+}
+
+func TestWithClientCertificate_MissingFiles(t *testing.T) {
+	server := newMutualTLSServer(t)
+	dir := t.TempDir()
+
+	client := NewClient(nil).
+		WithBaseURL(server.URL).
+		WithTLSConfig(&tls.Config{RootCAs: serverRoots(server)}).
+		WithClientCertificate(filepath.Join(dir, "missing.crt"), filepath.Join(dir, "missing.key"))
+
+	_, err := client.Lookup("8.8.8.8")
+	assert.Error(t, err)
+}
+
+func TestWithProxy(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8"})
+	}))
+	defer proxy.Close()
+
+	client := NewClient(nil).WithBaseURL("http://api.iplocate.test/api").WithProxy(proxy.URL)
+	result, err := client.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "8.8.8.8", result.IP)
+	assert.Equal(t, []string{"http://api.iplocate.test/api/lookup/8.8.8.8"}, proxied)
+}
+
+func TestWithProxy_Invalid(t *testing.T) {
+	client := NewClient(nil).WithBaseURL("http://api.iplocate.test/api").WithProxy("::not a url")
+	_, err := client.Lookup("8.8.8.8")
+	assert.ErrorContains(t, err, "invalid proxy URL")
+}
+
+func TestWithProxyFromEnvironment(t *testing.T) {
+	client := NewClient(nil).WithProxyFromEnvironment()
+	transport, ok := client.httpClient.Transport.(*http.Transport)
+	require.True(t, ok)
+	assert.NotNil(t, transport.Proxy)
+}
+
+func TestWithTransportTuning(t *testing.T) {
+	client := NewClient(nil).WithTransportTuning(64, 2*time.Minute, false)
+	transport := client.httpClient.Transport.(*http.Transport)
+	assert.Equal(t, 64, transport.MaxIdleConns)
+	assert.Equal(t, 64, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, 2*time.Minute, transport.IdleConnTimeout)
+	assert.False(t, transport.ForceAttemptHTTP2)
+	assert.NotNil(t, transport.TLSNextProto)
+
+	client.WithTransportTuning(0, 0, true)
+	assert.Equal(t, 64, transport.MaxIdleConns)
+	assert.Equal(t, 2*time.Minute, transport.IdleConnTimeout)
+	assert.True(t, transport.ForceAttemptHTTP2)
+	assert.Nil(t, transport.TLSNextProto)
+}
+
+func TestWithTransportTuning_HTTP2(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Proto", r.Proto)
+		json.NewEncoder(w).Encode(LookupResponse{IP: "8.8.8.8"})
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	for _, http2 := range []bool{true, false} {
+		var protos []string
+		client := NewClient(nil).
+			WithBaseURL(server.URL).
+			WithTLSConfig(&tls.Config{RootCAs: serverRoots(server)}).
+			WithTransportTuning(8, time.Minute, http2)
+		client.httpClient.Transport = &protoRecorder{next: client.httpClient.Transport, protos: &protos}
+
+		_, err := client.Lookup("8.8.8.8")
+		require.NoError(t, err)
+		if http2 {
+			assert.Equal(t, []string{"HTTP/2.0"}, protos)
+		} else {
+			assert.Equal(t, []string{"HTTP/1.1"}, protos)
+		}
+	}
+}
+
+// protoRecorder records the protocol of every response
+type protoRecorder struct {
+	next   http.RoundTripper
+	protos *[]string
+}
+
+func (p *protoRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := p.next.RoundTrip(req)
+	if err == nil {
+		*p.protos = append(*p.protos, resp.Proto)
+	}
+	return resp, err
+}
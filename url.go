@@ -0,0 +1,60 @@
+package iplocate
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// LookupURL looks up the host of rawURL, such as an indicator from a threat
+// intel feed. URLs without a scheme, like "example.com/login", are accepted.
+// A hostname is resolved first, through the DNS cache set with WithDNSCache
+// if any, and its first address is looked up.
+func (c *Client) LookupURL(ctx context.Context, rawURL string, opts ...LookupOption) (*LookupResponse, error) {
+	host, err := urlHost(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ParseFlexibleIP(host); err == nil {
+		return c.LookupContext(ctx, host, opts...)
+	}
+
+	addrs, err := c.resolveHost(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", host, err)
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("failed to resolve %s: no addresses found", host)
+	}
+	return c.LookupContext(ctx, addrs[0], opts...)
+}
+
+// urlHost returns the host of rawURL, without port or IPv6 brackets
+func urlHost(rawURL string) (string, error) {
+	withScheme := strings.TrimSpace(rawURL)
+	if !strings.Contains(withScheme, "://") {
+		withScheme = "http://" + withScheme
+	}
+	u, err := url.Parse(withScheme)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	host := u.Hostname()
+	if host == "" {
+		return "", fmt.Errorf("invalid URL: %s has no host", rawURL)
+	}
+	return strings.TrimSuffix(host, "."), nil
+}
+
+// resolveHost returns the addresses of host
+func (c *Client) resolveHost(ctx context.Context, host string) ([]string, error) {
+	if c.lookupHost != nil {
+		return c.lookupHost(ctx, host)
+	}
+	if c.dnsCache != nil {
+		return c.dnsCache.LookupHost(ctx, host)
+	}
+	return net.DefaultResolver.LookupHost(ctx, host)
+}
@@ -0,0 +1,36 @@
+package iplocate
+
+import (
+	"context"
+	"time"
+)
+
+// usagePath is the account usage endpoint, relative to the base URL
+const usagePath = "/account/usage"
+
+// Usage describes the plan and request consumption of the API key
+type Usage struct {
+	Plan         string `json:"plan"`
+	DailyLimit   int    `json:"daily_limit"`
+	RequestsUsed int    `json:"requests_used"`
+	// ResetAt is when the daily request count resets
+	ResetAt time.Time `json:"reset_at"`
+}
+
+// Remaining returns the number of requests left until ResetAt
+func (u *Usage) Remaining() int {
+	if u.RequestsUsed >= u.DailyLimit {
+		return 0
+	}
+	return u.DailyLimit - u.RequestsUsed
+}
+
+// Usage returns the plan, daily limit and requests used by the configured
+// API key, so quota consumption can be monitored
+func (c *Client) Usage(ctx context.Context) (*Usage, error) {
+	var usage Usage
+	if _, err := c.doRequest(ctx, apiRequest{path: usagePath}, &usage); err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
@@ -0,0 +1,54 @@
+package iplocate
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsage_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/account/usage", r.URL.Path)
+		assert.Equal(t, "test-api-key", r.URL.Query().Get("apikey"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"plan": "Free",
+			"daily_limit": 1000,
+			"requests_used": 250,
+			"reset_at": "2025-01-02T00:00:00Z"
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(nil).WithAPIKey("test-api-key").WithBaseURL(server.URL)
+	usage, err := client.Usage(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "Free", usage.Plan)
+	assert.Equal(t, 1000, usage.DailyLimit)
+	assert.Equal(t, 250, usage.RequestsUsed)
+	assert.Equal(t, 750, usage.Remaining())
+	assert.Equal(t, time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), usage.ResetAt)
+}
+
+func TestUsage_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error": "Invalid API key"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(nil).WithBaseURL(server.URL)
+	_, err := client.Usage(context.Background())
+
+	require.Error(t, err)
+	apiErr, ok := err.(*APIError)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusForbidden, apiErr.StatusCode)
+}
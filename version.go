@@ -0,0 +1,47 @@
+package iplocate
+
+import (
+	"runtime/debug"
+	"strings"
+)
+
+// modulePath is the import path of this module
+const modulePath = "github.com/iplocate/go-iplocate"
+
+// fallbackVersion is reported when the module version is unknown, e.g. in
+// tests or builds from a local checkout
+const fallbackVersion = "1.0.0"
+
+// Version is the version of this module, taken from the build info of the
+// binary it is linked into
+var Version = buildVersion()
+
+// DefaultUserAgent is the User-Agent sent when none is configured
+var DefaultUserAgent = "go-iplocate/" + Version
+
+func buildVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return fallbackVersion
+	}
+	return versionFromBuildInfo(info)
+}
+
+// versionFromBuildInfo finds the module version among the main module and
+// its dependencies, without the leading "v"
+func versionFromBuildInfo(info *debug.BuildInfo) string {
+	modules := append([]*debug.Module{&info.Main}, info.Deps...)
+	for _, module := range modules {
+		if module == nil || module.Path != modulePath {
+			continue
+		}
+		if module.Replace != nil && module.Replace.Version != "" {
+			module = module.Replace
+		}
+		if module.Version == "" || module.Version == "(devel)" {
+			return fallbackVersion
+		}
+		return strings.TrimPrefix(module.Version, "v")
+	}
+	return fallbackVersion
+}
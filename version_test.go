@@ -0,0 +1,53 @@
+package iplocate
+
+import (
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionFromBuildInfo(t *testing.T) {
+	tests := []struct {
+		name string
+		info *debug.BuildInfo
+		want string
+	}{
+		{
+			name: "dependency",
+			info: &debug.BuildInfo{
+				Main: debug.Module{Path: "example.com/app", Version: "(devel)"},
+				Deps: []*debug.Module{{Path: modulePath, Version: "v1.4.2"}},
+			},
+			want: "1.4.2",
+		},
+		{
+			name: "replaced dependency",
+			info: &debug.BuildInfo{
+				Deps: []*debug.Module{{Path: modulePath, Version: "v1.4.2", Replace: &debug.Module{Path: "example.com/fork", Version: "v1.5.0"}}},
+			},
+			want: "1.5.0",
+		},
+		{
+			name: "local checkout",
+			info: &debug.BuildInfo{Main: debug.Module{Path: modulePath, Version: "(devel)"}},
+			want: fallbackVersion,
+		},
+		{
+			name: "not a dependency",
+			info: &debug.BuildInfo{Main: debug.Module{Path: "example.com/app"}},
+			want: fallbackVersion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, versionFromBuildInfo(tt.info))
+		})
+	}
+}
+
+func TestDefaultUserAgent(t *testing.T) {
+	assert.NotEmpty(t, Version)
+	assert.Equal(t, "go-iplocate/"+Version, DefaultUserAgent)
+}
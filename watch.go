@@ -0,0 +1,248 @@
+package iplocate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// WatchEvent reports a change, or a failed check, of an IP address watched
+// by a Watcher
+type WatchEvent struct {
+	IP   string
+	Time time.Time
+	// Previous is the last successful lookup of the IP before this check
+	Previous *LookupResponse
+	// Current is the lookup made by this check, nil if it failed
+	Current *LookupResponse
+	// Changes lists the fields that differ between Previous and Current
+	Changes []FieldChange
+	// Err is set when the lookup failed
+	Err error
+}
+
+// Watcher looks up a set of IP addresses on an interval, such as partner or
+// allowlisted addresses, and reports those whose data changed since the
+// previous check, as found by Diff. The first check of an address only
+// records its data. Lookups go through the client, so give it no cache, or
+// a cache TTL shorter than the interval, for checks to reach the API. It is
+// safe for concurrent use.
+type Watcher struct {
+	client   *Client
+	interval time.Duration
+	opts     []LookupOption
+	onEvent  func(WatchEvent)
+
+	mu     sync.Mutex
+	last   map[string]*LookupResponse
+	events chan WatchEvent
+
+	// sendMu is held for reading while an event is sent, so Close can wait
+	// for sends before closing the events channel
+	sendMu    sync.RWMutex
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewWatcher creates a watcher checking its addresses with client every
+// interval, once started with Start
+func NewWatcher(client *Client, interval time.Duration) *Watcher {
+	return &Watcher{
+		client:   client,
+		interval: interval,
+		last:     make(map[string]*LookupResponse),
+		closed:   make(chan struct{}),
+	}
+}
+
+// WithCallback calls fn with every event, from the watcher's goroutine
+func (w *Watcher) WithCallback(fn func(WatchEvent)) *Watcher {
+	w.onEvent = fn
+	return w
+}
+
+// WithLookupOptions sets the options used for every lookup, e.g. Fields to
+// watch only some of the data
+func (w *Watcher) WithLookupOptions(opts ...LookupOption) *Watcher {
+	w.opts = opts
+	return w
+}
+
+// Events returns a channel receiving every event, which is closed by Close.
+// Call it before Start, and keep receiving from it: checks wait for each
+// event to be received.
+func (w *Watcher) Events() <-chan WatchEvent {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.events == nil {
+		w.events = make(chan WatchEvent, 16)
+	}
+	return w.events
+}
+
+// Add adds IP addresses to watch, in any of the forms accepted by
+// ParseFlexibleIP. Addresses already watched are left as is.
+func (w *Watcher) Add(ips ...string) error {
+	normalized := make([]string, len(ips))
+	for i, ip := range ips {
+		var ok bool
+		if normalized[i], ok = normalizeIP(ip); !ok {
+			return fmt.Errorf("invalid IP address: %s", ip)
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, ip := range normalized {
+		if _, ok := w.last[ip]; !ok {
+			w.last[ip] = nil
+		}
+	}
+	return nil
+}
+
+// Remove stops watching IP addresses
+func (w *Watcher) Remove(ips ...string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, ip := range ips {
+		if normalized, ok := normalizeIP(ip); ok {
+			delete(w.last, normalized)
+		}
+	}
+}
+
+// IPs returns the watched IP addresses, sorted
+func (w *Watcher) IPs() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	ips := make([]string, 0, len(w.last))
+	for ip := range w.last {
+		ips = append(ips, ip)
+	}
+	sort.Strings(ips)
+	return ips
+}
+
+// Start checks the addresses right away and then every interval until
+// Close is called. Start must be called at most once.
+func (w *Watcher) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	w.done = make(chan struct{})
+
+	go func() {
+		defer close(w.done)
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			w.Check(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Check looks up every watched address once and reports the changes and
+// failures, without waiting for the interval. It returns the error of
+// LookupBatch, listing the failed lookups.
+func (w *Watcher) Check(ctx context.Context) error {
+	ips := w.IPs()
+	if len(ips) == 0 {
+		return nil
+	}
+	result, err := w.client.LookupBatch(ctx, ips, w.opts...)
+	if result == nil {
+		return err
+	}
+
+	now := w.client.now()
+	for _, ip := range ips {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		event, ok := w.update(ip, result)
+		if !ok {
+			continue
+		}
+		event.Time = now
+		w.emit(ctx, event)
+	}
+	return err
+}
+
+// update records the outcome of the check of ip and returns the event it
+// should be reported with, if any
+func (w *Watcher) update(ip string, result *BatchResult) (WatchEvent, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	previous, watched := w.last[ip]
+	if !watched {
+		return WatchEvent{}, false
+	}
+
+	if err, failed := result.Errors[ip]; failed {
+		return WatchEvent{IP: ip, Previous: previous, Err: err}, true
+	}
+	current, ok := result.Responses[ip]
+	if !ok {
+		// The check was interrupted before ip was looked up
+		return WatchEvent{}, false
+	}
+	w.last[ip] = current
+	if previous == nil {
+		return WatchEvent{}, false
+	}
+	changes := Diff(previous, current)
+	if len(changes) == 0 {
+		return WatchEvent{}, false
+	}
+	return WatchEvent{IP: ip, Previous: previous, Current: current, Changes: changes}, true
+}
+
+// emit passes event to the callback and the events channel
+func (w *Watcher) emit(ctx context.Context, event WatchEvent) {
+	if w.onEvent != nil {
+		w.onEvent(event)
+	}
+	w.sendMu.RLock()
+	defer w.sendMu.RUnlock()
+	w.mu.Lock()
+	events := w.events
+	w.mu.Unlock()
+	if events == nil {
+		return
+	}
+	select {
+	case events <- event:
+	case <-ctx.Done():
+	case <-w.closed:
+	}
+}
+
+// Close stops the watcher, waits for a running check to finish and closes
+// the events channel. Checks running concurrently stop sending events.
+func (w *Watcher) Close() {
+	w.closeOnce.Do(func() { close(w.closed) })
+	if w.cancel != nil {
+		w.cancel()
+		<-w.done
+	}
+	w.sendMu.Lock()
+	defer w.sendMu.Unlock()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.events != nil {
+		close(w.events)
+		w.events = nil
+	}
+}
@@ -0,0 +1,168 @@
+package iplocate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// watchServer answers lookups with the country currently set for each IP
+type watchServer struct {
+	mu        sync.Mutex
+	countries map[string]string
+	failing   map[string]bool
+}
+
+func newWatchServer(t *testing.T, countries map[string]string) (*watchServer, *Client) {
+	ws := &watchServer{countries: countries, failing: make(map[string]bool)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := strings.TrimPrefix(r.URL.Path, "/lookup/")
+		ws.mu.Lock()
+		defer ws.mu.Unlock()
+		if ws.failing[ip] {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error":"Internal error"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(LookupResponse{IP: ip, CountryCode: stringPtr(ws.countries[ip])})
+	}))
+	t.Cleanup(server.Close)
+	return ws, NewClient(nil).WithBaseURL(server.URL)
+}
+
+func (ws *watchServer) set(ip, country string) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.countries[ip] = country
+}
+
+func (ws *watchServer) fail(ip string) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.failing[ip] = true
+}
+
+func TestWatcher_Check(t *testing.T) {
+	ws, client := newWatchServer(t, map[string]string{"203.0.113.7": "US", "198.51.100.1": "DE"})
+
+	var events []WatchEvent
+	watcher := NewWatcher(client, time.Hour).WithCallback(func(event WatchEvent) {
+		events = append(events, event)
+	})
+	require.NoError(t, watcher.Add("203.0.113.7", " ::ffff:198.51.100.1 "))
+	assert.Equal(t, []string{"198.51.100.1", "203.0.113.7"}, watcher.IPs())
+
+	require.NoError(t, watcher.Check(context.Background()))
+	assert.Empty(t, events, "the first check records a baseline")
+
+	require.NoError(t, watcher.Check(context.Background()))
+	assert.Empty(t, events)
+
+	ws.set("203.0.113.7", "NL")
+	require.NoError(t, watcher.Check(context.Background()))
+	require.Len(t, events, 1)
+	assert.Equal(t, "203.0.113.7", events[0].IP)
+	assert.Equal(t, "US", *events[0].Previous.CountryCode)
+	assert.Equal(t, "NL", *events[0].Current.CountryCode)
+	assert.Equal(t, []FieldChange{{Field: "country_code", Category: CategoryGeo, Old: "US", New: "NL"}}, events[0].Changes)
+	assert.False(t, events[0].Time.IsZero())
+
+	require.NoError(t, watcher.Check(context.Background()))
+	assert.Len(t, events, 1, "the new data is the baseline of the next check")
+}
+
+func TestWatcher_Failure(t *testing.T) {
+	ws, client := newWatchServer(t, map[string]string{"203.0.113.7": "US"})
+
+	var events []WatchEvent
+	watcher := NewWatcher(client, time.Hour).WithCallback(func(event WatchEvent) {
+		events = append(events, event)
+	})
+	require.NoError(t, watcher.Add("203.0.113.7"))
+	require.NoError(t, watcher.Check(context.Background()))
+
+	ws.fail("203.0.113.7")
+	err := watcher.Check(context.Background())
+	var batchErr *BatchError
+	assert.ErrorAs(t, err, &batchErr)
+	require.Len(t, events, 1)
+	assert.Error(t, events[0].Err)
+	assert.Nil(t, events[0].Current)
+	assert.Equal(t, "US", *events[0].Previous.CountryCode, "the last successful lookup is kept")
+}
+
+func TestWatcher_AddRemove(t *testing.T) {
+	watcher := NewWatcher(NewClient(nil), time.Hour)
+	assert.EqualError(t, watcher.Add("203.0.113.7", "invalid"), "invalid IP address: invalid")
+	assert.Empty(t, watcher.IPs())
+
+	require.NoError(t, watcher.Add("203.0.113.7", "198.51.100.1"))
+	watcher.Remove("203.0.113.7")
+	assert.Equal(t, []string{"198.51.100.1"}, watcher.IPs())
+	assert.NoError(t, NewWatcher(NewClient(nil), time.Hour).Check(context.Background()))
+}
+
+func TestWatcher_Start(t *testing.T) {
+	ws, client := newWatchServer(t, map[string]string{"203.0.113.7": "US"})
+
+	watcher := NewWatcher(client, 10*time.Millisecond)
+	require.NoError(t, watcher.Add("203.0.113.7"))
+	events := watcher.Events()
+	watcher.Start()
+
+	time.Sleep(30 * time.Millisecond)
+	ws.set("203.0.113.7", "FR")
+
+	select {
+	case event := <-events:
+		assert.Equal(t, "203.0.113.7", event.IP)
+		assert.Equal(t, "FR", *event.Current.CountryCode)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change event")
+	}
+
+	watcher.Close()
+	for range events {
+	}
+}
+
+func TestWatcher_CloseDuringCheck(t *testing.T) {
+	countries := make(map[string]string)
+	var ips []string
+	for i := 1; i <= 40; i++ {
+		ip := "203.0.113." + strconv.Itoa(i)
+		countries[ip] = "US"
+		ips = append(ips, ip)
+	}
+	ws, client := newWatchServer(t, countries)
+
+	watcher := NewWatcher(client, time.Hour)
+	require.NoError(t, watcher.Add(ips...))
+	watcher.Events()
+	require.NoError(t, watcher.Check(context.Background()))
+	for _, ip := range ips {
+		ws.set(ip, "DE")
+	}
+
+	// Nobody reads the events, so the check blocks once the channel is full
+	checked := make(chan error, 1)
+	go func() { checked <- watcher.Check(context.Background()) }()
+	require.Eventually(t, func() bool { return len(watcher.Events()) == cap(watcher.Events()) }, 5*time.Second, time.Millisecond)
+
+	watcher.Close()
+	select {
+	case err := <-checked:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("check still blocked after Close")
+	}
+}
@@ -0,0 +1,231 @@
+// Package xlsx exports IPLocate lookup results as an Excel workbook, for
+// reports delivered to people who work in spreadsheets. The workbook has a
+// Geo, an ASN and a Privacy sheet with one row per IP address, and is
+// written with the standard library only.
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/iplocate/go-iplocate"
+)
+
+// Sheet is a worksheet of the exported workbook
+type Sheet struct {
+	Name string
+	// Columns are CSV column names, see iplocate.DefaultCSVColumns
+	Columns []string
+}
+
+// DefaultSheets are the sheets written by Write
+var DefaultSheets = []Sheet{
+	{Name: "Geo", Columns: []string{
+		"ip", "country", "country_code", "is_eu", "city", "continent", "latitude", "longitude",
+		"time_zone", "postal_code", "subdivision", "currency_code", "calling_code",
+	}},
+	{Name: "ASN", Columns: []string{
+		"ip", "network", "asn.asn", "asn.route", "asn.netname", "asn.name", "asn.country_code",
+		"asn.domain", "asn.type", "asn.rir", "company.name", "company.domain", "company.type",
+		"hosting.provider", "hosting.service", "abuse.email", "abuse.phone",
+	}},
+	{Name: "Privacy", Columns: []string{
+		"ip", "privacy.is_abuser", "privacy.is_anonymous", "privacy.is_bogon", "privacy.is_hosting",
+		"privacy.is_icloud_relay", "privacy.is_proxy", "privacy.is_tor", "privacy.is_vpn",
+	}},
+}
+
+// Write writes resps as a workbook with DefaultSheets to w
+func Write(w io.Writer, resps []*iplocate.LookupResponse) error {
+	return WriteSheets(w, resps, DefaultSheets)
+}
+
+// WriteFile writes resps as a workbook with DefaultSheets to the named file
+func WriteFile(name string, resps []*iplocate.LookupResponse) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to create workbook: %w", err)
+	}
+	if err := Write(f, resps); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// WriteSheets writes resps as a workbook with the given sheets to w. Each
+// sheet starts with a bold header row; coordinates are written as numbers
+// and flags as booleans, so they can be sorted and filtered.
+func WriteSheets(w io.Writer, resps []*iplocate.LookupResponse, sheets []Sheet) error {
+	if len(sheets) == 0 {
+		return errors.New("workbook needs at least one sheet")
+	}
+	z := zip.NewWriter(w)
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", contentTypes(len(sheets))},
+		{"_rels/.rels", rootRels},
+		{"xl/workbook.xml", workbook(sheets)},
+		{"xl/_rels/workbook.xml.rels", workbookRels(len(sheets))},
+		{"xl/styles.xml", styles},
+	}
+	for _, part := range parts {
+		if err := writePart(z, part.name, part.content); err != nil {
+			return err
+		}
+	}
+	for i, sheet := range sheets {
+		if err := writePart(z, fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), worksheet(sheet, resps)); err != nil {
+			return err
+		}
+	}
+	if err := z.Close(); err != nil {
+		return fmt.Errorf("failed to write workbook: %w", err)
+	}
+	return nil
+}
+
+func writePart(z *zip.Writer, name, content string) error {
+	part, err := z.Create(name)
+	if err == nil {
+		_, err = io.WriteString(part, content)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write workbook: %w", err)
+	}
+	return nil
+}
+
+const xmlHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+const rootRels = xmlHeader + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+// styles defines the default cell style (0) and a bold one for headers (1)
+const styles = xmlHeader + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+	`</styleSheet>`
+
+func contentTypes(sheets int) string {
+	var b strings.Builder
+	b.WriteString(xmlHeader + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	b.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	b.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	b.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	b.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for i := 1; i <= sheets; i++ {
+		fmt.Fprintf(&b, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i)
+	}
+	b.WriteString(`</Types>`)
+	return b.String()
+}
+
+func workbook(sheets []Sheet) string {
+	var b strings.Builder
+	b.WriteString(xmlHeader + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, sheet := range sheets {
+		fmt.Fprintf(&b, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escape(sheet.Name), i+1, i+1)
+	}
+	b.WriteString(`</sheets></workbook>`)
+	return b.String()
+}
+
+func workbookRels(sheets int) string {
+	var b strings.Builder
+	b.WriteString(xmlHeader + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := 1; i <= sheets; i++ {
+		fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i, i)
+	}
+	fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, sheets+1)
+	b.WriteString(`</Relationships>`)
+	return b.String()
+}
+
+// worksheet returns the sheet XML, with the header row frozen
+func worksheet(sheet Sheet, resps []*iplocate.LookupResponse) string {
+	var b strings.Builder
+	b.WriteString(xmlHeader + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	b.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	b.WriteString(`<sheetData>`)
+
+	b.WriteString(`<row r="1">`)
+	for i, name := range sheet.Columns {
+		fmt.Fprintf(&b, `<c r="%s1" t="inlineStr" s="1"><is><t>%s</t></is></c>`, columnName(i), escape(name))
+	}
+	b.WriteString(`</row>`)
+
+	for n, resp := range resps {
+		row := n + 2
+		fmt.Fprintf(&b, `<row r="%d">`, row)
+		for i, value := range resp.CSVRecord(sheet.Columns...) {
+			if value == "" {
+				continue
+			}
+			ref := columnName(i) + strconv.Itoa(row)
+			switch kind(sheet.Columns[i]) {
+			case number:
+				fmt.Fprintf(&b, `<c r="%s"><v>%s</v></c>`, ref, value)
+			case boolean:
+				v := "0"
+				if value == "true" {
+					v = "1"
+				}
+				fmt.Fprintf(&b, `<c r="%s" t="b"><v>%s</v></c>`, ref, v)
+			default:
+				fmt.Fprintf(&b, `<c r="%s" t="inlineStr"><is><t>%s</t></is></c>`, ref, escape(value))
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+type cellKind int
+
+const (
+	text cellKind = iota
+	number
+	boolean
+)
+
+// kind returns how the values of a CSV column are stored in cells
+func kind(column string) cellKind {
+	switch {
+	case column == "latitude" || column == "longitude":
+		return number
+	case column == "is_eu" || strings.HasPrefix(column, "privacy."):
+		return boolean
+	default:
+		return text
+	}
+}
+
+// columnName returns the spreadsheet name of the zero-based column i: A, B, ..., Z, AA, ...
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
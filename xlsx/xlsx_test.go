@@ -0,0 +1,118 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/iplocate/go-iplocate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testResponses() []*iplocate.LookupResponse {
+	country, code, lat, lon := "United States", "US", 37.386, -122.0838
+	return []*iplocate.LookupResponse{
+		{
+			IP: "8.8.8.8", Country: &country, CountryCode: &code, Latitude: &lat, Longitude: &lon,
+			ASN:     &iplocate.ASN{ASN: "AS15169", Name: "Google LLC"},
+			Privacy: iplocate.Privacy{IsHosting: true},
+		},
+		{IP: "192.0.2.1", Privacy: iplocate.Privacy{IsBogon: true}},
+	}
+}
+
+// readParts returns the contents of every part of the workbook by name
+func readParts(t *testing.T, data []byte) map[string]string {
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	parts := make(map[string]string)
+	for _, f := range r.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		parts[f.Name] = string(content)
+	}
+	return parts
+}
+
+type sheetXML struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			R      string `xml:"r,attr"`
+			T      string `xml:"t,attr"`
+			Value  string `xml:"v"`
+			Inline string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, testResponses()))
+	parts := readParts(t, buf.Bytes())
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml",
+		"xl/worksheets/sheet1.xml", "xl/worksheets/sheet2.xml", "xl/worksheets/sheet3.xml"} {
+		assert.Contains(t, parts, name)
+	}
+	assert.Contains(t, parts["xl/workbook.xml"], `<sheet name="Geo" sheetId="1" r:id="rId1"/>`)
+	assert.Contains(t, parts["xl/workbook.xml"], `<sheet name="Privacy" sheetId="3" r:id="rId3"/>`)
+
+	var geo sheetXML
+	require.NoError(t, xml.Unmarshal([]byte(parts["xl/worksheets/sheet1.xml"]), &geo))
+	require.Len(t, geo.Rows, 3)
+	assert.Equal(t, "ip", geo.Rows[0].Cells[0].Inline)
+	assert.Equal(t, "8.8.8.8", geo.Rows[1].Cells[0].Inline)
+	// latitude is the seventh column
+	var lat string
+	for _, c := range geo.Rows[1].Cells {
+		if c.R == "G2" {
+			lat = c.Value
+			assert.Empty(t, c.T, "coordinates are numbers")
+		}
+	}
+	assert.Equal(t, "37.386", lat)
+
+	var privacy sheetXML
+	require.NoError(t, xml.Unmarshal([]byte(parts["xl/worksheets/sheet3.xml"]), &privacy))
+	bogon := privacy.Rows[2].Cells[3]
+	assert.Equal(t, "D3", bogon.R)
+	assert.Equal(t, "b", bogon.T)
+	assert.Equal(t, "1", bogon.Value)
+}
+
+func TestWriteSheets(t *testing.T) {
+	var buf bytes.Buffer
+	sheets := []Sheet{{Name: "R&D", Columns: []string{"ip", "asn.name"}}}
+	require.NoError(t, WriteSheets(&buf, testResponses(), sheets))
+	parts := readParts(t, buf.Bytes())
+	assert.Contains(t, parts["xl/workbook.xml"], `name="R&amp;D"`)
+	assert.Contains(t, parts["xl/worksheets/sheet1.xml"], `<c r="B2" t="inlineStr"><is><t>Google LLC</t></is></c>`)
+	assert.NotContains(t, parts, "xl/worksheets/sheet2.xml")
+
+	assert.Error(t, WriteSheets(&buf, nil, nil))
+}
+
+func TestWriteFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "report.xlsx")
+	require.NoError(t, WriteFile(name, testResponses()))
+	r, err := zip.OpenReader(name)
+	require.NoError(t, err)
+	defer r.Close()
+	assert.Len(t, r.File, 8)
+}
+
+func TestColumnName(t *testing.T) {
+	assert.Equal(t, "A", columnName(0))
+	assert.Equal(t, "Z", columnName(25))
+	assert.Equal(t, "AA", columnName(26))
+	assert.Equal(t, "AZ", columnName(51))
+	assert.Equal(t, "BA", columnName(52))
+}